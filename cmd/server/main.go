@@ -2,7 +2,14 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// База часовых поясов внутри бинарника: в alpine-образе нет /usr/share/zoneinfo,
+	// а часовой пояс события нужен для live-экранов.
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 
@@ -12,6 +19,9 @@ import (
 	"wdpl_back/internal/shared/postgres"
 )
 
+// shutdownTimeout — сколько ждать завершения текущих запросов после сигнала остановки.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Загружаем .env только в dev. В Docker задаём APP_ENV=production — тогда используются переменные контейнера.
 	// Локально удобно хранить секреты в .env и не задавать их вручную.
@@ -41,10 +51,22 @@ func main() {
 		os.Exit(1)
 	}
 
-	app := router.NewFiberApp(cfg, logg, db)
+	// Время жизни приложения: SIGINT/SIGTERM отменяют фоновые задачи и останавливают сервер.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app := router.NewFiberApp(ctx, cfg, logg, db)
+
+	go func() {
+		<-ctx.Done()
+		if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			logg.Error("server shutdown failed", "error", err)
+		}
+	}()
 
 	if err := app.Listen(cfg.ServerAddress()); err != nil {
 		logg.Error("server stopped with error", "error", err)
 		os.Exit(1)
 	}
+	logg.Info("server stopped")
 }
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/users"
+)
+
+// Действия журнала аудита над пользователями (target_type = AuditTargetUser).
+const (
+	AuditTargetUser = auth.AuditTargetUser
+
+	ActionUserRoleChanged = "user.role_changed"
+	ActionUserDeactivated = "user.deactivated"
+	ActionUserReactivated = "user.reactivated"
+	ActionUserSignedOut   = "user.signed_out"
+)
+
+// Actor — администратор, выполняющий изменение; IP и UserAgent попадают в журнал аудита.
+type Actor struct {
+	UserID    string
+	IP        string
+	UserAgent string
+}
+
+// UserDetails — пользователь вместе с профилем (Profile == nil, если профиль ещё не создан).
+type UserDetails struct {
+	User    *auth.User
+	Profile *users.UserProfile
+}
+
+// userState — снимок пользователя для before/after в журнале аудита.
+type userState struct {
+	Role     string `json:"role"`
+	IsActive bool   `json:"isActive"`
+	// RevokedSessions — сколько сессий завершено изменением (только в after).
+	RevokedSessions int `json:"revokedSessions,omitempty"`
+}
+
+func stateOf(u *auth.User) userState {
+	return userState{Role: u.Role, IsActive: u.IsActive}
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UserResponse — пользователь в ответах админских эндпоинтов.
+type UserResponse struct {
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	IsActive      bool      `json:"isActive"`
+	EmailVerified bool      `json:"emailVerified"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// ProfileResponse — профиль пользователя (user_profiles) в карточке пользователя.
+type ProfileResponse struct {
+	DisplayName string  `json:"displayName"`
+	AvatarURL   *string `json:"avatarURL,omitempty"`
+	Bio         *string `json:"bio,omitempty"`
+	Locale      string  `json:"locale"`
+	Timezone    string  `json:"timezone"`
+}
+
+// UserDetailsResponse — ответ GET /api/admin/users/:id. Profile отсутствует, если профиль не создан.
+type UserDetailsResponse struct {
+	UserResponse
+	Profile *ProfileResponse `json:"profile,omitempty"`
+}
+
+// ChangeRoleRequest — тело PUT /api/admin/users/:id/role.
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// SignOutResponse — ответ POST /api/admin/users/:id/sign-out.
+type SignOutResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// AuditEntryResponse — запись журнала аудита в ответе GET /api/admin/audit.
+type AuditEntryResponse struct {
+	ID         int64           `json:"id"`
+	ActorID    string          `json:"actorId,omitempty"`
+	APIKeyID   string          `json:"apiKeyId,omitempty"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"targetType"`
+	TargetID   string          `json:"targetId"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	UserAgent  string          `json:"userAgent,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,272 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/audit"
+	"wdpl_back/internal/shared/http/handler"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/http/response"
+)
+
+// Handler реализует админские HTTP-эндпоинты управления пользователями.
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+// NewHandler создаёт handler администрирования.
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// ListUsers — GET /api/admin/users?search=&role=&active=&limit=&offset=. Новые пользователи первыми.
+func (h *Handler) ListUsers(c *fiber.Ctx) error {
+	limit, offset := handler.LimitOffset(c, 50, 200, 0)
+	filter := auth.UserFilter{
+		Search: c.Query("search"),
+		Role:   c.Query("role"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if v := c.Query("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			return response.WriteError(c, fiber.StatusBadRequest, "invalid active")
+		}
+		filter.IsActive = &active
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListUsers(ctx, filter)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := make([]UserResponse, 0, len(list))
+	for _, u := range list {
+		resp = append(resp, userToResponse(u))
+	}
+	return c.JSON(resp)
+}
+
+// ListAudit — GET /api/admin/audit?actorId=&action=&targetType=&targetId=&from=&to=&limit=&offset=.
+// Журнал аудита, новые записи первыми; from и to — RFC 3339 или дата (YYYY-MM-DD), to не включается.
+func (h *Handler) ListAudit(c *fiber.Ctx) error {
+	limit, offset := handler.LimitOffset(c, 50, 200, 0)
+	filter := audit.Filter{
+		ActorID:    c.Query("actorId"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("targetType"),
+		TargetID:   c.Query("targetId"),
+		Limit:      limit,
+		Offset:     offset,
+	}
+	if filter.ActorID != "" {
+		if _, err := uuid.Parse(filter.ActorID); err != nil {
+			return response.WriteError(c, fiber.StatusBadRequest, "invalid actorId")
+		}
+	}
+	var err error
+	if filter.From, err = parseAuditTime(c.Query("from")); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid from")
+	}
+	if filter.To, err = parseAuditTime(c.Query("to")); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid to")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListAudit(ctx, filter)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := make([]AuditEntryResponse, 0, len(list))
+	for _, e := range list {
+		resp = append(resp, auditEntryToResponse(e))
+	}
+	return c.JSON(resp)
+}
+
+// GetUser — GET /api/admin/users/:id. Пользователь с профилем.
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusNotFound, ErrUserNotFound.Error())
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	details, err := h.service.GetUser(ctx, userID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := UserDetailsResponse{UserResponse: userToResponse(details.User)}
+	if p := details.Profile; p != nil {
+		resp.Profile = &ProfileResponse{
+			DisplayName: p.DisplayName,
+			AvatarURL:   p.AvatarURL,
+			Bio:         p.Bio,
+			Locale:      p.Locale,
+			Timezone:    p.Timezone,
+		}
+	}
+	return c.JSON(resp)
+}
+
+// ChangeRole — PUT /api/admin/users/:id/role. Роль из реестра auth.Roles.
+func (h *Handler) ChangeRole(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusNotFound, ErrUserNotFound.Error())
+	}
+	var req ChangeRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	user, err := h.service.ChangeRole(ctx, actor, userID, req.Role)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(userToResponse(user))
+}
+
+// Deactivate — POST /api/admin/users/:id/deactivate. Отключает учётную запись и завершает её сессии.
+func (h *Handler) Deactivate(c *fiber.Ctx) error {
+	return h.setActive(c, false)
+}
+
+// Reactivate — POST /api/admin/users/:id/reactivate.
+func (h *Handler) Reactivate(c *fiber.Ctx) error {
+	return h.setActive(c, true)
+}
+
+func (h *Handler) setActive(c *fiber.Ctx, active bool) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusNotFound, ErrUserNotFound.Error())
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	user, err := h.service.SetActive(ctx, actor, userID, active)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(userToResponse(user))
+}
+
+// SignOut — POST /api/admin/users/:id/sign-out. Завершает все сессии пользователя.
+func (h *Handler) SignOut(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusNotFound, ErrUserNotFound.Error())
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	revoked, err := h.service.SignOut(ctx, actor, userID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(SignOutResponse{Revoked: revoked})
+}
+
+// actorFromCtx собирает Actor из клеймов и запроса (после RequireAuth).
+func actorFromCtx(c *fiber.Ctx) (Actor, bool) {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return Actor{}, false
+	}
+	return Actor{UserID: claims.UserID, IP: c.IP(), UserAgent: c.Get("User-Agent")}, true
+}
+
+// userIDParam возвращает :id; не-UUID заведомо не существует (и не должен доходить до запроса к uuid-колонке).
+func userIDParam(c *fiber.Ctx) (string, bool) {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// writeServiceError маппит ошибки сервиса в HTTP-коды.
+func writeServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidRole), errors.Is(err, ErrInvalidPeriod):
+		return response.WriteError(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrSelfChange), errors.Is(err, ErrLastAdmin):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+func userToResponse(u *auth.User) UserResponse {
+	return UserResponse{
+		ID:            u.ID,
+		Email:         u.Email,
+		Role:          u.Role,
+		IsActive:      u.IsActive,
+		EmailVerified: u.EmailVerified(),
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+	}
+}
+
+// parseAuditTime разбирает границу периода: RFC 3339 или дата в UTC; "" — без границы.
+func parseAuditTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+func auditEntryToResponse(e *audit.Entry) AuditEntryResponse {
+	return AuditEntryResponse{
+		ID:         e.ID,
+		ActorID:    e.ActorID,
+		APIKeyID:   e.APIKeyID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     e.Before,
+		After:      e.After,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		CreatedAt:  e.CreatedAt,
+	}
+}
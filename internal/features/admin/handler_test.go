@@ -0,0 +1,156 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/authutils/authtest"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+)
+
+// newTestAdminApp поднимает админские маршруты поверх моков (без БД).
+func newTestAdminApp(t *testing.T) (*fiber.App, *config.Config, *testDeps) {
+	t.Helper()
+	cfg := &config.Config{
+		JWTSecret:         "test-jwt-secret-at-least-32-bytes-for-admin",
+		AccessTokenTTLMin: 15,
+	}
+	svc, d := newTestService(t)
+	h := NewHandler(svc)
+
+	app := fiber.New()
+	g := app.Group("/api/admin", middleware.RequireAuth(cfg), middleware.RequireRole(AdminRoles...))
+	g.Get("/users", h.ListUsers)
+	g.Get("/users/:id", h.GetUser)
+	g.Put("/users/:id/role", h.ChangeRole)
+	g.Post("/users/:id/deactivate", h.Deactivate)
+	g.Post("/users/:id/sign-out", h.SignOut)
+	g.Get("/audit", h.ListAudit)
+	return app, cfg, d
+}
+
+func TestHandler_AdminOnly(t *testing.T) {
+	app, cfg, _ := newTestAdminApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/users", adminID, auth.RoleEditor, ""))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(httptest.NewRequest("GET", "/api/admin/users", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+}
+
+func TestHandler_ListAndGetUser(t *testing.T) {
+	app, cfg, _ := newTestAdminApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/users?search=alice&active=true", adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var list []UserResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Len(t, list, 1)
+	assert.Equal(t, userID, list[0].ID)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/users?active=maybe", adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/users/"+userID, adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var details UserDetailsResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&details))
+	assert.Equal(t, "Alice@example.com", details.Email)
+	assert.Nil(t, details.Profile)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/users/not-a-uuid", adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+}
+
+func TestHandler_ChangeRole(t *testing.T) {
+	app, cfg, d := newTestAdminApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "PUT", "/api/admin/users/"+userID+"/role", adminID, auth.RoleAdmin, `{"role":"superuser"}`))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "PUT", "/api/admin/users/"+adminID+"/role", adminID, auth.RoleAdmin, `{"role":"user"}`))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "PUT", "/api/admin/users/"+userID+"/role", adminID, auth.RoleAdmin, `{"role":"organizer"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var user UserResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&user))
+	assert.Equal(t, auth.RoleOrganizer, user.Role)
+	require.Len(t, d.audit.entries, 1)
+	assert.Equal(t, "0.0.0.0", d.audit.entries[0].IP)
+}
+
+func TestHandler_DeactivateAndSignOut(t *testing.T) {
+	app, cfg, d := newTestAdminApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/admin/users/"+userID+"/sign-out", adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var out SignOutResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+	assert.Equal(t, 2, out.Revoked)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/admin/users/"+userID+"/deactivate", adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var user UserResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&user))
+	assert.False(t, user.IsActive)
+	assert.Len(t, d.audit.entries, 2)
+}
+
+func TestHandler_ListAudit(t *testing.T) {
+	app, cfg, _ := newTestAdminApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "PUT", "/api/admin/users/"+userID+"/role", adminID, auth.RoleAdmin, `{"role":"editor"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/admin/users/"+userID+"/sign-out", adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/audit?targetType=user&targetId="+userID, adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var list []AuditEntryResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Len(t, list, 2)
+	assert.Equal(t, ActionUserSignedOut, list[0].Action, "новые первыми")
+	assert.Equal(t, ActionUserRoleChanged, list[1].Action)
+	assert.Equal(t, adminID, list[1].ActorID)
+	assert.JSONEq(t, `{"role":"editor","isActive":true}`, string(list[1].After))
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/audit?action="+ActionUserRoleChanged+"&from=2020-01-01", adminID, auth.RoleAdmin, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	list = nil
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	assert.Len(t, list, 1)
+
+	for _, query := range []string{"actorId=nobody", "from=yesterday", "from=2024-02-01&to=2024-01-01"} {
+		res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/audit?"+query, adminID, auth.RoleAdmin, ""))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode, query)
+	}
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/admin/audit", adminID, auth.RoleEditor, ""))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+}
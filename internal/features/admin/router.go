@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/users"
+	"wdpl_back/internal/shared/audit"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/postgres"
+)
+
+// AdminRoles — кто управляет пользователями.
+var AdminRoles = []string{auth.RoleAdmin}
+
+// RegisterRoutes вешает админские эндпоинты на api (под /api/admin). Все — только admin.
+func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
+	authRepo := auth.NewPostgresRepository(db)
+	svc := NewService(authRepo, authRepo, users.NewPostgresProfileRepository(db), audit.NewPostgresReader(db))
+	h := NewHandler(svc)
+
+	g := api.Group("/admin", middleware.RequireAuth(cfg), middleware.RequireRole(AdminRoles...))
+	g.Get("/users", h.ListUsers)
+	g.Get("/users/:id", h.GetUser)
+	g.Put("/users/:id/role", h.ChangeRole)
+	g.Post("/users/:id/deactivate", h.Deactivate)
+	g.Post("/users/:id/reactivate", h.Reactivate)
+	g.Post("/users/:id/sign-out", h.SignOut)
+	g.Get("/audit", h.ListAudit)
+}
@@ -0,0 +1,217 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/users"
+	"wdpl_back/internal/shared/audit"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrInvalidRole  = errors.New("unknown role")
+	// ErrSelfChange — администратор не меняет свою роль и активность: так легко остаться без админов.
+	ErrSelfChange = errors.New("cannot change own role or status")
+	// ErrLastAdmin — понижение или отключение последнего активного администратора.
+	ErrLastAdmin = errors.New("cannot demote or deactivate the last active admin")
+	// ErrInvalidPeriod — начало периода выборки журнала не раньше конца.
+	ErrInvalidPeriod = errors.New("from must be before to")
+)
+
+// Service — управление пользователями для администраторов. Каждое изменение пишется в журнал аудита
+// в той же транзакции; auditLog — чтение журнала.
+type Service struct {
+	users    auth.UserRepository
+	tx       auth.Transactor
+	profiles users.ProfileRepository
+	auditLog audit.Reader
+}
+
+// NewService создаёт сервис администрирования пользователей.
+func NewService(userRepo auth.UserRepository, tx auth.Transactor, profiles users.ProfileRepository, auditLog audit.Reader) *Service {
+	return &Service{users: userRepo, tx: tx, profiles: profiles, auditLog: auditLog}
+}
+
+// ListUsers возвращает пользователей по фильтру (поиск по email, роль, активность).
+func (s *Service) ListUsers(ctx context.Context, filter auth.UserFilter) ([]*auth.User, error) {
+	if filter.Role != "" && !auth.IsValidRole(filter.Role) {
+		return nil, ErrInvalidRole
+	}
+	return s.users.ListUsers(ctx, filter)
+}
+
+// GetUser возвращает пользователя с профилем.
+func (s *Service) GetUser(ctx context.Context, userID string) (*UserDetails, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	profile, err := s.profiles.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &UserDetails{User: user, Profile: profile}, nil
+}
+
+// ChangeRole назначает пользователю роль из реестра auth.Roles. Уже выданные access-токены
+// перестают приниматься (поколение растёт при смене роли).
+func (s *Service) ChangeRole(ctx context.Context, actor Actor, userID, role string) (*auth.User, error) {
+	if !auth.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if actor.UserID == userID {
+		return nil, ErrSelfChange
+	}
+	var updated *auth.User
+	err := s.tx.InTx(ctx, func(repos *auth.TxRepositories) error {
+		admins, user, err := lockUser(ctx, repos.Users, userID)
+		if err != nil {
+			return err
+		}
+		updated = user
+		if user.Role == role {
+			return nil
+		}
+		if role != auth.RoleAdmin {
+			if err := keepAdmin(admins, user); err != nil {
+				return err
+			}
+		}
+		before := stateOf(user)
+		user.Role = role
+		if err := repos.Users.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+		return appendAudit(ctx, repos.Audit, actor, ActionUserRoleChanged, userID, before, stateOf(user))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SetActive включает или отключает учётную запись. При отключении завершаются все сессии пользователя.
+func (s *Service) SetActive(ctx context.Context, actor Actor, userID string, active bool) (*auth.User, error) {
+	if actor.UserID == userID {
+		return nil, ErrSelfChange
+	}
+	var updated *auth.User
+	err := s.tx.InTx(ctx, func(repos *auth.TxRepositories) error {
+		admins, user, err := lockUser(ctx, repos.Users, userID)
+		if err != nil {
+			return err
+		}
+		updated = user
+		if user.IsActive == active {
+			return nil
+		}
+		if !active {
+			if err := keepAdmin(admins, user); err != nil {
+				return err
+			}
+		}
+		before := stateOf(user)
+		user.IsActive = active
+		if err := repos.Users.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+		after := stateOf(user)
+		action := ActionUserReactivated
+		if !active {
+			action = ActionUserDeactivated
+			if after.RevokedSessions, err = repos.RefreshTokens.RevokeAllSessions(ctx, userID); err != nil {
+				return err
+			}
+		}
+		return appendAudit(ctx, repos.Audit, actor, action, userID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SignOut завершает все сессии пользователя: отзывает refresh-токены и увеличивает поколение,
+// чтобы уже выданные access-токены тоже перестали приниматься. Возвращает число отозванных сессий.
+func (s *Service) SignOut(ctx context.Context, actor Actor, userID string) (int, error) {
+	var revoked int
+	err := s.tx.InTx(ctx, func(repos *auth.TxRepositories) error {
+		user, err := getUser(ctx, repos.Users, userID)
+		if err != nil {
+			return err
+		}
+		if revoked, err = repos.RefreshTokens.RevokeAllSessions(ctx, userID); err != nil {
+			return err
+		}
+		if err := repos.Users.BumpTokenGeneration(ctx, userID); err != nil {
+			return err
+		}
+		after := stateOf(user)
+		after.RevokedSessions = revoked
+		return appendAudit(ctx, repos.Audit, actor, ActionUserSignedOut, userID, nil, after)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+func getUser(ctx context.Context, repo auth.UserRepository, userID string) (*auth.User, error) {
+	user, err := repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// lockUser блокирует до конца транзакции строки активных администраторов и пользователя userID
+// (администраторов — первыми, в одном порядке у всех транзакций) и возвращает id администраторов и пользователя.
+func lockUser(ctx context.Context, repo auth.UserRepository, userID string) ([]string, *auth.User, error) {
+	admins, err := repo.LockActiveAdmins(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := repo.LockUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, ErrUserNotFound
+	}
+	return admins, user, nil
+}
+
+// keepAdmin запрещает лишать прав последнего активного администратора.
+func keepAdmin(admins []string, user *auth.User) error {
+	if user.Role == auth.RoleAdmin && user.IsActive && len(admins) <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// appendAudit пишет запись об изменении пользователя. before == nil — без состояния «до».
+func appendAudit(ctx context.Context, w audit.Writer, actor Actor, action, userID string, before, after any) error {
+	entry, err := audit.NewEntry(actor.UserID, action, AuditTargetUser, userID, before, after)
+	if err != nil {
+		return err
+	}
+	entry.IP = actor.IP
+	entry.UserAgent = actor.UserAgent
+	return w.Append(ctx, entry)
+}
+
+// ListAudit возвращает записи журнала аудита по фильтру — новые первыми.
+func (s *Service) ListAudit(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, ErrInvalidPeriod
+	}
+	return s.auditLog.List(ctx, filter)
+}
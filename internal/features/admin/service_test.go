@@ -0,0 +1,354 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/users"
+	"wdpl_back/internal/shared/audit"
+)
+
+const (
+	adminID = "00000000-0000-0000-0000-00000000000a"
+	userID  = "00000000-0000-0000-0000-000000000001"
+)
+
+// mockUserRepo — in-memory auth.UserRepository.
+type mockUserRepo struct {
+	users map[string]*auth.User
+}
+
+func (m *mockUserRepo) CreateUser(_ context.Context, user *auth.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *mockUserRepo) GetUserByEmail(_ context.Context, email string) (*auth.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockUserRepo) GetUserByID(_ context.Context, id string) (*auth.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (m *mockUserRepo) LockUser(ctx context.Context, id string) (*auth.User, error) {
+	return m.GetUserByID(ctx, id)
+}
+
+func (m *mockUserRepo) LockActiveAdmins(_ context.Context) ([]string, error) {
+	var ids []string
+	for _, u := range m.users {
+		if u.Role == auth.RoleAdmin && u.IsActive {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockUserRepo) ListUsers(_ context.Context, filter auth.UserFilter) ([]*auth.User, error) {
+	var list []*auth.User
+	for _, u := range m.users {
+		if !strings.Contains(strings.ToLower(u.Email), strings.ToLower(filter.Search)) {
+			continue
+		}
+		if filter.Role != "" && u.Role != filter.Role {
+			continue
+		}
+		if filter.IsActive != nil && u.IsActive != *filter.IsActive {
+			continue
+		}
+		list = append(list, u)
+	}
+	return list, nil
+}
+
+// UpdateUser повторяет триггер БД: смена роли или активности увеличивает поколение.
+func (m *mockUserRepo) UpdateUser(_ context.Context, user *auth.User) error {
+	stored := m.users[user.ID]
+	if stored.Role != user.Role || stored.IsActive != user.IsActive {
+		user.TokenGeneration = stored.TokenGeneration + 1
+	}
+	user.UpdatedAt = time.Now()
+	cp := *user
+	m.users[user.ID] = &cp
+	return nil
+}
+
+func (m *mockUserRepo) BumpTokenGeneration(_ context.Context, id string) error {
+	m.users[id].TokenGeneration++
+	return nil
+}
+
+// mockSessions — из RefreshTokenRepository сервису нужен только RevokeAllSessions.
+type mockSessions struct {
+	auth.RefreshTokenRepository
+	active map[string]int
+}
+
+func (m *mockSessions) RevokeAllSessions(_ context.Context, id string) (int, error) {
+	n := m.active[id]
+	delete(m.active, id)
+	return n, nil
+}
+
+type mockAudit struct {
+	entries []*audit.Entry
+}
+
+func (m *mockAudit) Append(_ context.Context, entries ...*audit.Entry) error {
+	for _, e := range entries {
+		e.ID = int64(len(m.entries) + 1)
+		e.CreatedAt = time.Now()
+		m.entries = append(m.entries, e)
+	}
+	return nil
+}
+
+// List — записи по фильтру (кроме периода), новые первыми.
+func (m *mockAudit) List(_ context.Context, f audit.Filter) ([]*audit.Entry, error) {
+	var list []*audit.Entry
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		e := m.entries[i]
+		if (f.ActorID == "" || e.ActorID == f.ActorID) && (f.Action == "" || e.Action == f.Action) &&
+			(f.TargetType == "" || e.TargetType == f.TargetType) && (f.TargetID == "" || e.TargetID == f.TargetID) {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+// mockTransactor выполняет fn поверх тех же моков без отката. Транзакции идут по одной —
+// как под блокировками строк в БД.
+type mockTransactor struct {
+	mu    sync.Mutex
+	repos *auth.TxRepositories
+}
+
+func (m *mockTransactor) InTx(_ context.Context, fn func(repos *auth.TxRepositories) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(m.repos)
+}
+
+type mockProfileRepo struct {
+	profiles map[string]*users.UserProfile
+}
+
+func (m *mockProfileRepo) GetByUserID(_ context.Context, id string) (*users.UserProfile, error) {
+	return m.profiles[id], nil
+}
+
+func (m *mockProfileRepo) Create(_ context.Context, p *users.UserProfile) error {
+	m.profiles[p.UserID] = p
+	return nil
+}
+
+func (m *mockProfileRepo) Update(_ context.Context, p *users.UserProfile) error {
+	m.profiles[p.UserID] = p
+	return nil
+}
+
+type testDeps struct {
+	users    *mockUserRepo
+	sessions *mockSessions
+	audit    *mockAudit
+	profiles *mockProfileRepo
+}
+
+func newTestService(t *testing.T) (*Service, *testDeps) {
+	t.Helper()
+	d := &testDeps{
+		users: &mockUserRepo{users: map[string]*auth.User{
+			adminID: {ID: adminID, Email: "admin@example.com", Role: auth.RoleAdmin, IsActive: true},
+			userID:  {ID: userID, Email: "Alice@example.com", Role: auth.RoleUser, IsActive: true},
+		}},
+		sessions: &mockSessions{active: map[string]int{userID: 2}},
+		audit:    &mockAudit{},
+		profiles: &mockProfileRepo{profiles: map[string]*users.UserProfile{}},
+	}
+	tx := &mockTransactor{repos: &auth.TxRepositories{Users: d.users, RefreshTokens: d.sessions, Audit: d.audit}}
+	return NewService(d.users, tx, d.profiles, d.audit), d
+}
+
+var testActor = Actor{UserID: adminID, IP: "10.0.0.1", UserAgent: "test-agent"}
+
+func TestChangeRole_UpdatesAndAudits(t *testing.T) {
+	svc, d := newTestService(t)
+
+	user, err := svc.ChangeRole(context.Background(), testActor, userID, auth.RoleEditor)
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleEditor, user.Role)
+	assert.Equal(t, 1, user.TokenGeneration, "смена роли гасит выданные access-токены")
+	assert.Equal(t, auth.RoleEditor, d.users.users[userID].Role)
+
+	require.Len(t, d.audit.entries, 1)
+	e := d.audit.entries[0]
+	assert.Equal(t, ActionUserRoleChanged, e.Action)
+	assert.Equal(t, AuditTargetUser, e.TargetType)
+	assert.Equal(t, userID, e.TargetID)
+	assert.Equal(t, adminID, e.ActorID)
+	assert.Equal(t, "10.0.0.1", e.IP)
+	assert.Equal(t, "test-agent", e.UserAgent)
+	assert.JSONEq(t, `{"role":"user","isActive":true}`, string(e.Before))
+	assert.JSONEq(t, `{"role":"editor","isActive":true}`, string(e.After))
+}
+
+func TestChangeRole_Rejects(t *testing.T) {
+	svc, d := newTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.ChangeRole(ctx, testActor, userID, "superuser")
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	_, err = svc.ChangeRole(ctx, testActor, adminID, auth.RoleUser)
+	assert.ErrorIs(t, err, ErrSelfChange)
+
+	_, err = svc.ChangeRole(ctx, testActor, "00000000-0000-0000-0000-0000000000ff", auth.RoleUser)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	assert.Empty(t, d.audit.entries)
+}
+
+func TestChangeRole_SameRoleIsNoop(t *testing.T) {
+	svc, d := newTestService(t)
+
+	user, err := svc.ChangeRole(context.Background(), testActor, userID, auth.RoleUser)
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleUser, user.Role)
+	assert.Empty(t, d.audit.entries)
+}
+
+func TestSetActive_DeactivateRevokesSessions(t *testing.T) {
+	svc, d := newTestService(t)
+	ctx := context.Background()
+
+	user, err := svc.SetActive(ctx, testActor, userID, false)
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	assert.Empty(t, d.sessions.active)
+
+	require.Len(t, d.audit.entries, 1)
+	assert.Equal(t, ActionUserDeactivated, d.audit.entries[0].Action)
+	var after userState
+	require.NoError(t, json.Unmarshal(d.audit.entries[0].After, &after))
+	assert.Equal(t, 2, after.RevokedSessions)
+
+	user, err = svc.SetActive(ctx, testActor, userID, true)
+	require.NoError(t, err)
+	assert.True(t, user.IsActive)
+	require.Len(t, d.audit.entries, 2)
+	assert.Equal(t, ActionUserReactivated, d.audit.entries[1].Action)
+
+	_, err = svc.SetActive(ctx, testActor, adminID, false)
+	assert.ErrorIs(t, err, ErrSelfChange)
+}
+
+func TestChangeRole_KeepsLastActiveAdmin(t *testing.T) {
+	svc, d := newTestService(t)
+	ctx := context.Background()
+	const secondAdminID = "00000000-0000-0000-0000-00000000000b"
+	d.users.users[secondAdminID] = &auth.User{ID: secondAdminID, Email: "second@example.com", Role: auth.RoleAdmin, IsActive: true}
+
+	// Два администратора одновременно понижают друг друга: проходит только одно понижение.
+	errs := make(chan error, 2)
+	var wg sync.WaitGroup
+	for _, pair := range [][2]string{{adminID, secondAdminID}, {secondAdminID, adminID}} {
+		wg.Add(1)
+		go func(actorID, targetID string) {
+			defer wg.Done()
+			_, err := svc.ChangeRole(ctx, Actor{UserID: actorID}, targetID, auth.RoleUser)
+			errs <- err
+		}(pair[0], pair[1])
+	}
+	wg.Wait()
+	close(errs)
+	var failed []error
+	for err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	require.Len(t, failed, 1)
+	assert.ErrorIs(t, failed[0], ErrLastAdmin)
+	admins, err := d.users.LockActiveAdmins(ctx)
+	require.NoError(t, err)
+	assert.Len(t, admins, 1)
+	require.Len(t, d.audit.entries, 1)
+
+	// Последнего администратора не отключить и не понизить (действует, например, API-ключ сервиса).
+	service := Actor{UserID: "00000000-0000-0000-0000-0000000000cc"}
+	_, err = svc.SetActive(ctx, service, admins[0], false)
+	assert.ErrorIs(t, err, ErrLastAdmin)
+	_, err = svc.ChangeRole(ctx, service, admins[0], auth.RoleEditor)
+	assert.ErrorIs(t, err, ErrLastAdmin)
+	assert.Len(t, d.audit.entries, 1)
+}
+
+func TestSignOut_RevokesAndBumpsGeneration(t *testing.T) {
+	svc, d := newTestService(t)
+
+	revoked, err := svc.SignOut(context.Background(), testActor, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+	assert.Equal(t, 1, d.users.users[userID].TokenGeneration)
+
+	require.Len(t, d.audit.entries, 1)
+	e := d.audit.entries[0]
+	assert.Equal(t, ActionUserSignedOut, e.Action)
+	assert.Nil(t, e.Before)
+	assert.JSONEq(t, `{"role":"user","isActive":true,"revokedSessions":2}`, string(e.After))
+}
+
+func TestListUsers_Filters(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	list, err := svc.ListUsers(ctx, auth.UserFilter{Search: "alice"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, userID, list[0].ID)
+
+	list, err = svc.ListUsers(ctx, auth.UserFilter{Role: auth.RoleAdmin})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, adminID, list[0].ID)
+
+	_, err = svc.ListUsers(ctx, auth.UserFilter{Role: "root"})
+	assert.ErrorIs(t, err, ErrInvalidRole)
+}
+
+func TestGetUser_WithProfile(t *testing.T) {
+	svc, d := newTestService(t)
+	ctx := context.Background()
+
+	details, err := svc.GetUser(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, details.Profile)
+
+	d.profiles.profiles[userID] = &users.UserProfile{UserID: userID, DisplayName: "Alice"}
+	details, err = svc.GetUser(ctx, userID)
+	require.NoError(t, err)
+	require.NotNil(t, details.Profile)
+	assert.Equal(t, "Alice", details.Profile.DisplayName)
+
+	_, err = svc.GetUser(ctx, "00000000-0000-0000-0000-0000000000ff")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
@@ -0,0 +1,78 @@
+package apikeys
+
+import "time"
+
+// Права (scopes) API-ключей. Каждое открывает ключу свой набор маршрутов (middleware.RequireAuthOrAPIKey),
+// а что именно можно сделать на маршруте, решают обычные проверки по роли и командам пользователя ключа.
+const (
+	// ScopeEventsRead — чтение: черновики, дни и команды событий, скрытые сессии в публичном API.
+	ScopeEventsRead = "events:read"
+	// ScopeDraftsWrite — сохранение черновиков событий и дней.
+	ScopeDraftsWrite = "drafts:write"
+	// ScopePublish — публикация черновиков.
+	ScopePublish = "publish"
+)
+
+// Scopes — все права, которые можно выдать ключу.
+var Scopes = []string{ScopeEventsRead, ScopeDraftsWrite, ScopePublish}
+
+// IsValidScope — scope есть в Scopes.
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Действия журнала аудита над ключами (target_type = AuditTargetAPIKey).
+const (
+	AuditTargetAPIKey = "api_key"
+
+	ActionAPIKeyIssued  = "api_key.issued"
+	ActionAPIKeyRevoked = "api_key.revoked"
+)
+
+// APIKey — выпущенный ключ. Сам ключ ("wdpl_<Prefix>_<секрет>") не хранится: только Prefix,
+// по которому ключ находится и показывается в списке, и HMAC секрета.
+type APIKey struct {
+	ID         string
+	Prefix     string
+	SecretHash string
+	Name       string
+	// UserID — пользователь (обычно сервисная учётная запись), от имени которого действует ключ.
+	UserID     string
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedBy  string
+	CreatedAt  time.Time
+}
+
+// Active — ключ не отозван и не истёк.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
+
+// Actor — администратор, выпускающий или отзывающий ключ; IP и UserAgent попадают в журнал аудита.
+type Actor struct {
+	UserID    string
+	IP        string
+	UserAgent string
+}
+
+// keyState — снимок ключа для before/after в журнале аудита (без хеша секрета).
+type keyState struct {
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	UserID    string     `json:"userId"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+func stateOf(k *APIKey) keyState {
+	return keyState{Name: k.Name, Prefix: k.Prefix, UserID: k.UserID, Scopes: k.Scopes, ExpiresAt: k.ExpiresAt, RevokedAt: k.RevokedAt}
+}
@@ -0,0 +1,46 @@
+package apikeys
+
+import "time"
+
+// IssueRequest — тело POST /api/admin/api-keys. ExpiresAt не указан — ключ на DefaultTTL.
+type IssueRequest struct {
+	UserID    string     `json:"userId" validate:"required,uuid"`
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// APIKeyResponse — ключ в списке и ответах: только префикс, секрета нет.
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Prefix     string     `json:"prefix"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"userId"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedBy  string     `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// IssueResponse — ответ POST /api/admin/api-keys. Key показывается только здесь.
+type IssueResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func keyToResponse(k *APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Prefix:     k.Prefix,
+		Name:       k.Name,
+		UserID:     k.UserID,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+	}
+}
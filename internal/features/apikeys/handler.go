@@ -0,0 +1,112 @@
+package apikeys
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/shared/http/handler"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/http/response"
+)
+
+// Handler реализует админские HTTP-эндпоинты API-ключей.
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+// NewHandler создаёт handler API-ключей.
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Issue — POST /api/admin/api-keys. В ответе полный ключ — единственный раз.
+func (h *Handler) Issue(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	var req IssueRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	key, plain, err := h.service.Issue(ctx, actor, IssueInput{
+		UserID:    req.UserID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(IssueResponse{APIKeyResponse: keyToResponse(key), Key: plain})
+}
+
+// List — GET /api/admin/api-keys?limit=&offset=. Новые первыми, включая отозванные.
+func (h *Handler) List(c *fiber.Ctx) error {
+	limit, offset := handler.LimitOffset(c, 50, 200, 0)
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.List(ctx, limit, offset)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := make([]APIKeyResponse, 0, len(list))
+	for _, k := range list {
+		resp = append(resp, keyToResponse(k))
+	}
+	return c.JSON(resp)
+}
+
+// Revoke — DELETE /api/admin/api-keys/:id. Ключ остаётся в списке с revokedAt.
+func (h *Handler) Revoke(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	key, err := h.service.Revoke(ctx, actor, c.Params("id"))
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(keyToResponse(key))
+}
+
+// actorFromCtx собирает Actor из клеймов и запроса (после RequireAuth).
+func actorFromCtx(c *fiber.Ctx) (Actor, bool) {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return Actor{}, false
+	}
+	return Actor{UserID: claims.UserID, IP: c.IP(), UserAgent: c.Get("User-Agent")}, true
+}
+
+// writeServiceError маппит ошибки сервиса в HTTP-коды.
+func writeServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrKeyNotFound), errors.Is(err, ErrUserNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidName), errors.Is(err, ErrInvalidScope), errors.Is(err, ErrNoScopes),
+		errors.Is(err, ErrInvalidExpiry):
+		return response.WriteError(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrUserInactive):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
@@ -0,0 +1,127 @@
+package apikeys
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/authutils"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+)
+
+// newTestKeysApp поднимает админские маршруты ключей и маршрут с RequireAuthOrAPIKey поверх моков (без БД).
+func newTestKeysApp(t *testing.T) (*fiber.App, *config.Config, *mockUsers) {
+	t.Helper()
+	cfg := &config.Config{
+		JWTSecret:         "test-jwt-secret-at-least-32-bytes-for-apikeys",
+		AccessTokenTTLMin: 15,
+	}
+	svc, _, users, _ := newTestService(t)
+	h := NewHandler(svc)
+
+	app := fiber.New()
+	api := app.Group("/api", middleware.WithAPIKeyValidator(svc))
+	g := api.Group("/admin/api-keys", middleware.RequireAuth(cfg), middleware.RequireRole(IssuerRoles...))
+	g.Get("", h.List)
+	g.Post("", h.Issue)
+	g.Delete("/:id", h.Revoke)
+
+	// Маршрут как черновики событий: scope открывает его, роль проверяется как для JWT.
+	api.Post("/drafts", middleware.RequireAuthOrAPIKey(cfg, ScopeDraftsWrite), middleware.RequireRole(auth.RoleEditor), func(c *fiber.Ctx) error {
+		claims, _ := middleware.ClaimsFromCtx(c)
+		return c.SendString(claims.UserID)
+	})
+	return app, cfg, users
+}
+
+func bearer(t *testing.T, cfg *config.Config, userID, role string) string {
+	t.Helper()
+	token, _, err := authutils.GenerateAccessToken(cfg, userID, role)
+	require.NoError(t, err)
+	return "Bearer " + token
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, url, authorization, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	resBody, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(resBody)
+}
+
+func TestHandler_IssueAndUseKey(t *testing.T) {
+	app, cfg, users := newTestKeysApp(t)
+	admin := bearer(t, cfg, adminID, auth.RoleAdmin)
+
+	status, _ := doRequest(t, app, "POST", "/api/admin/api-keys", bearer(t, cfg, serviceID, auth.RoleEditor), `{}`)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, _ = doRequest(t, app, "POST", "/api/admin/api-keys", admin, `{"userId":"`+serviceID+`","name":"Ticketing","scopes":["root"]}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, body := doRequest(t, app, "POST", "/api/admin/api-keys", admin, `{"userId":"`+serviceID+`","name":"Ticketing","scopes":["drafts:write"]}`)
+	require.Equal(t, fiber.StatusCreated, status)
+	var issued IssueResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &issued))
+	require.NotEmpty(t, issued.Key)
+
+	// Список показывает префикс, но не ключ.
+	status, body = doRequest(t, app, "GET", "/api/admin/api-keys", admin, "")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Contains(t, body, issued.Prefix)
+	assert.NotContains(t, body, issued.Key)
+
+	// Ключ действует от имени своего пользователя.
+	status, body = doRequest(t, app, "POST", "/api/drafts", "ApiKey "+issued.Key, "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, serviceID, body)
+	status, _ = doRequest(t, app, "POST", "/api/drafts", "ApiKey wdpl_nope_nope", "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	// Роль пользователя ключа по-прежнему проверяется.
+	users.users[serviceID].Role = auth.RoleUser
+	status, _ = doRequest(t, app, "POST", "/api/drafts", "ApiKey "+issued.Key, "")
+	assert.Equal(t, fiber.StatusForbidden, status)
+	users.users[serviceID].Role = auth.RoleEditor
+
+	// Ключи не открывают маршруты без RequireAuthOrAPIKey.
+	status, _ = doRequest(t, app, "GET", "/api/admin/api-keys", "ApiKey "+issued.Key, "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	status, _ = doRequest(t, app, "DELETE", "/api/admin/api-keys/"+issued.ID, admin, "")
+	require.Equal(t, fiber.StatusOK, status)
+	status, _ = doRequest(t, app, "POST", "/api/drafts", "ApiKey "+issued.Key, "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+}
+
+func TestHandler_KeyWithoutScope(t *testing.T) {
+	app, cfg, _ := newTestKeysApp(t)
+	admin := bearer(t, cfg, adminID, auth.RoleAdmin)
+
+	status, body := doRequest(t, app, "POST", "/api/admin/api-keys", admin, `{"userId":"`+serviceID+`","name":"Signage","scopes":["events:read"]}`)
+	require.Equal(t, fiber.StatusCreated, status)
+	var issued IssueResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &issued))
+
+	status, _ = doRequest(t, app, "POST", "/api/drafts", "ApiKey "+issued.Key, "")
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	// JWT на том же маршруте работает как раньше.
+	status, _ = doRequest(t, app, "POST", "/api/drafts", bearer(t, cfg, serviceID, auth.RoleEditor), "")
+	assert.Equal(t, fiber.StatusOK, status)
+}
@@ -0,0 +1,50 @@
+package apikeys
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// keyMarker — начало каждого ключа: по нему ключ легко найти в логах и конфигах (секрет-сканеры).
+const keyMarker = "wdpl_"
+
+// generateKey возвращает новый ключ "wdpl_<prefix>_<secret>": prefix — 48 бит в hex (без "_"),
+// secret — 256 бит в base64url.
+func generateKey() (key, prefix, secret string, err error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(buf)
+	buf = make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	secret = base64.RawURLEncoding.EncodeToString(buf)
+	return keyMarker + prefix + "_" + secret, prefix, secret, nil
+}
+
+// parseKey разбирает ключ на prefix и secret. Секрет в base64url может содержать "_", поэтому режем по первому.
+func parseKey(key string) (prefix, secret string, ok bool) {
+	rest, ok := strings.CutPrefix(key, keyMarker)
+	if !ok {
+		return "", "", false
+	}
+	prefix, secret, ok = strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", "", false
+	}
+	return prefix, secret, true
+}
+
+// hashSecret — HMAC-SHA256 секрета в hex с ключом API_KEY_SECRET: по утёкшей таблице без него
+// нельзя даже проверить кандидата (как refresh-токены в auth).
+func hashSecret(hashKey, secret string) string {
+	mac := hmac.New(sha256.New, []byte(hashKey))
+	mac.Write([]byte(secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
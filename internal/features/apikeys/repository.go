@@ -0,0 +1,25 @@
+package apikeys
+
+import (
+	"context"
+	"time"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/audit"
+)
+
+// Repository — хранилище ключей (public.api_keys). Изменения пишутся в журнал аудита в той же транзакции.
+type Repository interface {
+	Create(ctx context.Context, key *APIKey, entry *audit.Entry) error
+	GetByID(ctx context.Context, id string) (*APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	// List — ключи, новые первыми.
+	List(ctx context.Context, limit, offset int) ([]*APIKey, error)
+	Revoke(ctx context.Context, id string, at time.Time, entry *audit.Entry) error
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+// UserDirectory — пользователи, от имени которых действуют ключи (реализует auth.UserRepository).
+type UserDirectory interface {
+	GetUserByID(ctx context.Context, id string) (*auth.User, error)
+}
@@ -0,0 +1,142 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/shared/audit"
+	"wdpl_back/internal/shared/postgres"
+)
+
+type postgresRepository struct{ db *postgres.DB }
+
+// NewPostgresRepository возвращает реализацию Repository для PostgreSQL.
+func NewPostgresRepository(db *postgres.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+const keyColumns = `id, prefix, secret_hash, name, user_id, scopes, expires_at, last_used_at, revoked_at,
+	COALESCE(created_by::text, ''), created_at`
+
+// rowScanner — общее у *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanKey(row rowScanner) (*APIKey, error) {
+	var k APIKey
+	var scopes []byte
+	var lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(&k.ID, &k.Prefix, &k.SecretHash, &k.Name, &k.UserID, &scopes, &k.ExpiresAt,
+		&lastUsedAt, &revokedAt, &k.CreatedBy, &k.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopes, &k.Scopes); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
+	return &k, nil
+}
+
+func (r *postgresRepository) Create(ctx context.Context, key *APIKey, entry *audit.Entry) error {
+	if key.ID == "" {
+		key.ID = uuid.NewString()
+	}
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO public.api_keys (id, prefix, secret_hash, name, user_id, scopes, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid, $9)
+	`, key.ID, key.Prefix, key.SecretHash, key.Name, key.UserID, scopes, key.ExpiresAt, key.CreatedBy, key.CreatedAt); err != nil {
+		return err
+	}
+	if err := audit.NewPostgresWriter(tx).Append(ctx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *postgresRepository) GetByID(ctx context.Context, id string) (*APIKey, error) {
+	if uuid.Validate(id) != nil {
+		return nil, nil
+	}
+	k, err := scanKey(r.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM public.api_keys WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return k, err
+}
+
+func (r *postgresRepository) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	k, err := scanKey(r.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM public.api_keys WHERE prefix = $1`, prefix))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return k, err
+}
+
+func (r *postgresRepository) List(ctx context.Context, limit, offset int) ([]*APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+keyColumns+` FROM public.api_keys
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*APIKey
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, k)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresRepository) Revoke(ctx context.Context, id string, at time.Time, entry *audit.Entry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE public.api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL
+	`, id, at); err != nil {
+		return err
+	}
+	if err := audit.NewPostgresWriter(tx).Append(ctx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *postgresRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE public.api_keys SET last_used_at = $2 WHERE id = $1`, id, at)
+	return err
+}
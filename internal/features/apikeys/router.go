@@ -0,0 +1,30 @@
+package apikeys
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/postgres"
+)
+
+// IssuerRoles — кто выпускает и отзывает ключи.
+var IssuerRoles = []string{auth.RoleAdmin}
+
+// NewValidator — проверка ключей для middleware.WithAPIKeyValidator.
+func NewValidator(db *postgres.DB, cfg *config.Config) middleware.APIKeyValidator {
+	return NewService(NewPostgresRepository(db), auth.NewPostgresRepository(db), cfg.APIKeyHashSecret())
+}
+
+// RegisterRoutes вешает управление ключами на api (под /api/admin/api-keys). Все — только admin и только по JWT:
+// ключом ключи не выпускают.
+func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
+	svc := NewService(NewPostgresRepository(db), auth.NewPostgresRepository(db), cfg.APIKeyHashSecret())
+	h := NewHandler(svc)
+
+	g := api.Group("/admin/api-keys", middleware.RequireAuth(cfg), middleware.RequireRole(IssuerRoles...))
+	g.Get("", h.List)
+	g.Post("", h.Issue)
+	g.Delete("/:id", h.Revoke)
+}
@@ -0,0 +1,205 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/shared/audit"
+	"wdpl_back/internal/shared/authutils"
+)
+
+var (
+	ErrKeyNotFound   = errors.New("api key not found")
+	ErrInvalidName   = errors.New("name is required")
+	ErrInvalidScope  = errors.New("unknown scope")
+	ErrNoScopes      = errors.New("at least one scope is required")
+	ErrInvalidExpiry = errors.New("expiry must be in the future and within max ttl")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUserInactive  = errors.New("user is not active")
+)
+
+const (
+	// DefaultTTL — срок ключа, если expiresAt не указан.
+	DefaultTTL = 90 * 24 * time.Hour
+	// MaxTTL — ключи не бессрочные: дольше года — только перевыпуском.
+	MaxTTL = 365 * 24 * time.Hour
+	// lastUsedPrecision — last_used_at обновляется не чаще раза в минуту, а не на каждый запрос.
+	lastUsedPrecision = time.Minute
+)
+
+// IssueInput — параметры нового ключа. ExpiresAt == nil — DefaultTTL.
+type IssueInput struct {
+	UserID    string
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// Service — выпуск, отзыв и проверка API-ключей. Реализует middleware.APIKeyValidator.
+type Service struct {
+	repo    Repository
+	users   UserDirectory
+	hashKey string
+	now     func() time.Time
+}
+
+// NewService создаёт сервис ключей; hashKey — ключ HMAC секретов (config.APIKeyHashSecret).
+func NewService(repo Repository, users UserDirectory, hashKey string) *Service {
+	return &Service{repo: repo, users: users, hashKey: hashKey, now: time.Now}
+}
+
+// Issue выпускает ключ и возвращает его вместе с самим ключом: он показывается один раз и нигде не хранится.
+func (s *Service) Issue(ctx context.Context, actor Actor, in IssueInput) (*APIKey, string, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, "", ErrInvalidName
+	}
+	scopes, err := normalizeScopes(in.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.now().UTC()
+	expiresAt := now.Add(DefaultTTL)
+	if in.ExpiresAt != nil {
+		expiresAt = in.ExpiresAt.UTC()
+	}
+	if !expiresAt.After(now) || expiresAt.Sub(now) > MaxTTL {
+		return nil, "", ErrInvalidExpiry
+	}
+	user, err := s.users.GetUserByID(ctx, in.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user == nil {
+		return nil, "", ErrUserNotFound
+	}
+	if !user.IsActive {
+		return nil, "", ErrUserInactive
+	}
+
+	plain, prefix, secret, err := generateKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key := &APIKey{
+		ID:         uuid.NewString(),
+		Prefix:     prefix,
+		SecretHash: hashSecret(s.hashKey, secret),
+		Name:       name,
+		UserID:     user.ID,
+		Scopes:     scopes,
+		ExpiresAt:  expiresAt,
+		CreatedBy:  actor.UserID,
+		CreatedAt:  now,
+	}
+	entry, err := newAuditEntry(actor, ActionAPIKeyIssued, key.ID, nil, stateOf(key))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.repo.Create(ctx, key, entry); err != nil {
+		return nil, "", err
+	}
+	return key, plain, nil
+}
+
+// List возвращает ключи, включая отозванные и истёкшие.
+func (s *Service) List(ctx context.Context, limit, offset int) ([]*APIKey, error) {
+	return s.repo.List(ctx, limit, offset)
+}
+
+// Revoke отзывает ключ: запросы с ним сразу получают 401. Повторный отзыв ничего не меняет.
+func (s *Service) Revoke(ctx context.Context, actor Actor, id string) (*APIKey, error) {
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+	before := stateOf(key)
+	now := s.now().UTC()
+	key.RevokedAt = &now
+	entry, err := newAuditEntry(actor, ActionAPIKeyRevoked, key.ID, before, stateOf(key))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Revoke(ctx, key.ID, now, entry); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ValidateAPIKey реализует middleware.APIKeyValidator: ключ действует, если он не отозван и не истёк,
+// а его пользователь активен. Роль берётся текущая — смена роли пользователя сразу меняет права ключа.
+func (s *Service) ValidateAPIKey(ctx context.Context, raw string) (*authutils.UserClaims, error) {
+	prefix, secret, ok := parseKey(raw)
+	if !ok {
+		return nil, nil
+	}
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil || key == nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(hashSecret(s.hashKey, secret)), []byte(key.SecretHash)) {
+		return nil, nil
+	}
+	now := s.now().UTC()
+	if !key.Active(now) {
+		return nil, nil
+	}
+	user, err := s.users.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, nil
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedPrecision {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	return &authutils.UserClaims{
+		UserID:   user.ID,
+		Role:     user.Role,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// normalizeScopes проверяет scopes и убирает повторы, сохраняя порядок.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrNoScopes
+	}
+	seen := make(map[string]bool, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !IsValidScope(scope) {
+			return nil, ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			out = append(out, scope)
+		}
+	}
+	return out, nil
+}
+
+func newAuditEntry(actor Actor, action, keyID string, before, after any) (*audit.Entry, error) {
+	entry, err := audit.NewEntry(actor.UserID, action, AuditTargetAPIKey, keyID, before, after)
+	if err != nil {
+		return nil, err
+	}
+	entry.IP = actor.IP
+	entry.UserAgent = actor.UserAgent
+	return entry, nil
+}
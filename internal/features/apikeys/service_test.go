@@ -0,0 +1,222 @@
+package apikeys
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/audit"
+)
+
+// mockRepo — in-memory реализация Repository; записи аудита копятся в entries.
+type mockRepo struct {
+	keys    map[string]*APIKey
+	entries []*audit.Entry
+	touches int
+}
+
+func (m *mockRepo) Create(_ context.Context, key *APIKey, entry *audit.Entry) error {
+	cp := *key
+	m.keys[key.ID] = &cp
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *mockRepo) GetByID(_ context.Context, id string) (*APIKey, error) {
+	if k, ok := m.keys[id]; ok {
+		cp := *k
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) GetByPrefix(_ context.Context, prefix string) (*APIKey, error) {
+	for _, k := range m.keys {
+		if k.Prefix == prefix {
+			cp := *k
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) List(_ context.Context, _, _ int) ([]*APIKey, error) {
+	var list []*APIKey
+	for _, k := range m.keys {
+		list = append(list, k)
+	}
+	return list, nil
+}
+
+func (m *mockRepo) Revoke(_ context.Context, id string, at time.Time, entry *audit.Entry) error {
+	if k, ok := m.keys[id]; ok && k.RevokedAt == nil {
+		k.RevokedAt = &at
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *mockRepo) TouchLastUsed(_ context.Context, id string, at time.Time) error {
+	m.keys[id].LastUsedAt = &at
+	m.touches++
+	return nil
+}
+
+// mockUsers — пользователи ключей.
+type mockUsers struct {
+	users map[string]*auth.User
+}
+
+func (m *mockUsers) GetUserByID(_ context.Context, id string) (*auth.User, error) {
+	return m.users[id], nil
+}
+
+const (
+	adminID   = "11111111-1111-1111-1111-111111111111"
+	serviceID = "22222222-2222-2222-2222-222222222222"
+)
+
+var testActor = Actor{UserID: adminID, IP: "10.0.0.1"}
+
+func newTestService(t *testing.T) (*Service, *mockRepo, *mockUsers, *time.Time) {
+	t.Helper()
+	repo := &mockRepo{keys: map[string]*APIKey{}}
+	users := &mockUsers{users: map[string]*auth.User{
+		adminID:   {ID: adminID, Role: auth.RoleAdmin, IsActive: true},
+		serviceID: {ID: serviceID, Role: auth.RoleEditor, IsActive: true},
+	}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc := NewService(repo, users, "test-api-key-secret")
+	svc.now = func() time.Time { return now }
+	return svc, repo, users, &now
+}
+
+func TestIssue_StoresOnlyPrefixAndHash(t *testing.T) {
+	svc, repo, _, now := newTestService(t)
+	ctx := context.Background()
+
+	key, plain, err := svc.Issue(ctx, testActor, IssueInput{UserID: serviceID, Name: " Signage ", Scopes: []string{ScopeEventsRead, ScopeEventsRead}})
+	require.NoError(t, err)
+	assert.Equal(t, "Signage", key.Name)
+	assert.Equal(t, []string{ScopeEventsRead}, key.Scopes)
+	assert.Equal(t, now.Add(DefaultTTL), key.ExpiresAt)
+	assert.Equal(t, keyMarker+key.Prefix+"_", plain[:len(keyMarker)+len(key.Prefix)+1])
+
+	stored := repo.keys[key.ID]
+	assert.NotContains(t, stored.SecretHash, plain[len(keyMarker)+len(key.Prefix)+1:])
+	require.Len(t, repo.entries, 1)
+	assert.Equal(t, ActionAPIKeyIssued, repo.entries[0].Action)
+	assert.Equal(t, key.ID, repo.entries[0].TargetID)
+	assert.NotContains(t, string(repo.entries[0].After), stored.SecretHash)
+}
+
+func TestIssue_Validates(t *testing.T) {
+	svc, _, users, now := newTestService(t)
+	ctx := context.Background()
+	valid := IssueInput{UserID: serviceID, Name: "Ticketing", Scopes: []string{ScopeDraftsWrite}}
+
+	in := valid
+	in.Name = " "
+	_, _, err := svc.Issue(ctx, testActor, in)
+	assert.ErrorIs(t, err, ErrInvalidName)
+
+	in = valid
+	in.Scopes = nil
+	_, _, err = svc.Issue(ctx, testActor, in)
+	assert.ErrorIs(t, err, ErrNoScopes)
+	in.Scopes = []string{"admin"}
+	_, _, err = svc.Issue(ctx, testActor, in)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+
+	in = valid
+	past := now.Add(-time.Minute)
+	in.ExpiresAt = &past
+	_, _, err = svc.Issue(ctx, testActor, in)
+	assert.ErrorIs(t, err, ErrInvalidExpiry)
+	far := now.Add(MaxTTL + time.Hour)
+	in.ExpiresAt = &far
+	_, _, err = svc.Issue(ctx, testActor, in)
+	assert.ErrorIs(t, err, ErrInvalidExpiry)
+
+	in = valid
+	in.UserID = "33333333-3333-3333-3333-333333333333"
+	_, _, err = svc.Issue(ctx, testActor, in)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	users.users[serviceID].IsActive = false
+	_, _, err = svc.Issue(ctx, testActor, valid)
+	assert.ErrorIs(t, err, ErrUserInactive)
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	svc, repo, users, now := newTestService(t)
+	ctx := context.Background()
+	key, plain, err := svc.Issue(ctx, testActor, IssueInput{UserID: serviceID, Name: "Ticketing", Scopes: []string{ScopeDraftsWrite, ScopePublish}})
+	require.NoError(t, err)
+
+	claims, err := svc.ValidateAPIKey(ctx, plain)
+	require.NoError(t, err)
+	require.NotNil(t, claims)
+	assert.Equal(t, serviceID, claims.UserID)
+	assert.Equal(t, auth.RoleEditor, claims.Role)
+	assert.Equal(t, key.ID, claims.APIKeyID)
+	assert.True(t, claims.HasScope(ScopePublish))
+	assert.False(t, claims.HasScope(ScopeEventsRead))
+	assert.Equal(t, *now, *repo.keys[key.ID].LastUsedAt)
+
+	// last_used_at обновляется не чаще раза в минуту.
+	_, err = svc.ValidateAPIKey(ctx, plain)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.touches)
+
+	// Чужой секрет с верным префиксом, мусор и ключ без маркера не принимаются.
+	for _, raw := range []string{keyMarker + key.Prefix + "_wrong", "garbage", plain[len(keyMarker):]} {
+		claims, err = svc.ValidateAPIKey(ctx, raw)
+		require.NoError(t, err)
+		assert.Nil(t, claims, raw)
+	}
+
+	// Роль берётся текущая; неактивный пользователь — ключ не действует.
+	users.users[serviceID].Role = auth.RoleUser
+	claims, err = svc.ValidateAPIKey(ctx, plain)
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleUser, claims.Role)
+	users.users[serviceID].IsActive = false
+	claims, err = svc.ValidateAPIKey(ctx, plain)
+	require.NoError(t, err)
+	assert.Nil(t, claims)
+}
+
+func TestValidateAPIKey_ExpiredAndRevoked(t *testing.T) {
+	svc, repo, _, now := newTestService(t)
+	ctx := context.Background()
+	expiresAt := now.Add(time.Hour)
+	_, plain, err := svc.Issue(ctx, testActor, IssueInput{UserID: serviceID, Name: "Short", Scopes: []string{ScopeEventsRead}, ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	key, other, err := svc.Issue(ctx, testActor, IssueInput{UserID: serviceID, Name: "Signage", Scopes: []string{ScopeEventsRead}})
+	require.NoError(t, err)
+
+	*now = now.Add(2 * time.Hour)
+	claims, err := svc.ValidateAPIKey(ctx, plain)
+	require.NoError(t, err)
+	assert.Nil(t, claims)
+
+	revoked, err := svc.Revoke(ctx, testActor, key.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	claims, err = svc.ValidateAPIKey(ctx, other)
+	require.NoError(t, err)
+	assert.Nil(t, claims)
+
+	// Повторный отзыв не пишет аудит.
+	_, err = svc.Revoke(ctx, testActor, key.ID)
+	require.NoError(t, err)
+	assert.Len(t, repo.entries, 3)
+	assert.Equal(t, ActionAPIKeyRevoked, repo.entries[2].Action)
+
+	_, err = svc.Revoke(ctx, testActor, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+
+	"wdpl_back/internal/shared/audit"
+)
+
+// AuditTargetUser — target_type записей журнала аудита о пользователе.
+const AuditTargetUser = "user"
+
+// ActionUserSignedIn — вход: выдана новая сессия (семья refresh-токенов).
+const ActionUserSignedIn = "user.signed_in"
+
+// Способ входа в записи ActionUserSignedIn.
+const (
+	SignInPassword = "password"
+	SignInOIDC     = "oidc"
+	// SignInMFA — вход завершён вторым фактором (после пароля или OIDC), в том числе при подключении TOTP.
+	SignInMFA = "mfa"
+	// SignInSignUp — сессия выдана сразу при регистрации.
+	SignInSignUp = "sign_up"
+)
+
+// signInState — after записи о входе.
+type signInState struct {
+	Method    string `json:"method"`
+	SessionID string `json:"sessionId"`
+}
+
+// WithAudit включает журнал аудита входов: запись пишется в одной транзакции с новым refresh-токеном.
+func (s *Service) WithAudit(tx Transactor) *Service {
+	s.tx = tx
+	return s
+}
+
+// saveSession сохраняет refresh-токен новой сессии и, если журнал подключён, запись о входе.
+func (s *Service) saveSession(ctx context.Context, user *User, rt *RefreshToken, method, userAgent, ip string) error {
+	if s.tx == nil {
+		return s.refreshTokenRepo.CreateRefreshToken(ctx, rt)
+	}
+	entry, err := audit.NewEntry(user.ID, ActionUserSignedIn, AuditTargetUser, user.ID, nil, signInState{Method: method, SessionID: rt.FamilyID})
+	if err != nil {
+		return err
+	}
+	entry.IP = ip
+	entry.UserAgent = userAgent
+	return s.tx.InTx(ctx, func(r *TxRepositories) error {
+		if err := r.RefreshTokens.CreateRefreshToken(ctx, rt); err != nil {
+			return err
+		}
+		return r.Audit.Append(ctx, entry)
+	})
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/audit"
+)
+
+// mockAuditTx — транзакция поверх тех же моков: журнал копится в entries, ошибка fn откатывает его.
+type mockAuditTx struct {
+	users   *mockUserRepo
+	refresh *mockRefreshRepo
+	entries []*audit.Entry
+	fail    error
+}
+
+func (m *mockAuditTx) InTx(_ context.Context, fn func(repos *TxRepositories) error) error {
+	w := &mockAuditWriter{fail: m.fail}
+	if err := fn(&TxRepositories{Users: m.users, RefreshTokens: m.refresh, Audit: w}); err != nil {
+		return err
+	}
+	m.entries = append(m.entries, w.entries...)
+	return nil
+}
+
+type mockAuditWriter struct {
+	entries []*audit.Entry
+	fail    error
+}
+
+func (w *mockAuditWriter) Append(_ context.Context, entries ...*audit.Entry) error {
+	if w.fail != nil {
+		return w.fail
+	}
+	w.entries = append(w.entries, entries...)
+	return nil
+}
+
+func TestLogin_RecordsSignIn(t *testing.T) {
+	s, users, refresh := newTestService(t)
+	tx := &mockAuditTx{users: users, refresh: refresh}
+	s.WithAudit(tx)
+	ctx := context.Background()
+
+	user, _, err := s.Register(ctx, "test@example.com", "password123")
+	require.NoError(t, err)
+	_, _, err = s.Login(ctx, "test@example.com", "wrong", "agent", "10.0.0.1")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, tokens, err := s.Login(ctx, "test@example.com", "password123", "agent", "10.0.0.1")
+	require.NoError(t, err)
+
+	// Регистрация и успешный вход; неудачная попытка сессии не создаёт.
+	require.Len(t, tx.entries, 2)
+	assert.Contains(t, string(tx.entries[0].After), `"method":"sign_up"`)
+	e := tx.entries[1]
+	assert.Equal(t, ActionUserSignedIn, e.Action)
+	assert.Equal(t, AuditTargetUser, e.TargetType)
+	assert.Equal(t, user.ID, e.TargetID)
+	assert.Equal(t, user.ID, e.ActorID)
+	assert.Equal(t, "10.0.0.1", e.IP)
+	assert.Equal(t, "agent", e.UserAgent)
+
+	rt, err := refresh.GetRefreshToken(ctx, s.hashRefreshToken(tokens.RefreshToken))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"method":"password","sessionId":"`+rt.FamilyID+`"}`, string(e.After))
+}
+
+func TestLogin_AuditFailureFailsSignIn(t *testing.T) {
+	s, users, refresh := newTestService(t)
+	ctx := context.Background()
+	_, _, err := s.Register(ctx, "test@example.com", "password123")
+	require.NoError(t, err)
+
+	s.WithAudit(&mockAuditTx{users: users, refresh: refresh, fail: errors.New("audit down")})
+	_, tokens, err := s.Login(ctx, "test@example.com", "password123", "agent", "10.0.0.1")
+	require.Error(t, err)
+	assert.Nil(t, tokens)
+}
@@ -1,6 +1,26 @@
 package auth
 
-import "time"
+import (
+	"slices"
+	"time"
+)
+
+// Роли пользователей (auth.users.role). Других ролей нет: их назначение проверяется по Roles.
+const (
+	RoleUser      = "user"
+	RoleStaff     = "staff"
+	RoleEditor    = "editor"
+	RoleOrganizer = "organizer"
+	RoleAdmin     = "admin"
+)
+
+// Roles — все роли, которые можно назначить пользователю.
+var Roles = []string{RoleUser, RoleStaff, RoleEditor, RoleOrganizer, RoleAdmin}
+
+// IsValidRole — role есть в реестре Roles.
+func IsValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
 
 // User — доменная модель пользователя для фичи авторизации.
 type User struct {
@@ -10,18 +30,119 @@ type User struct {
 	Role           string
 	IsActive       bool
 	SupabaseUserID *string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	// EmailVerifiedAt — когда подтверждён email; nil — ещё не подтверждён.
+	EmailVerifiedAt *time.Time
+	// TokenGeneration растёт при смене роли, активности и сбросе пароля; access-токены прошлых поколений не принимаются.
+	TokenGeneration int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// EmailVerified — email подтверждён по ссылке из письма.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // RefreshToken — доменная модель refresh‑токена.
+// Каждый refresh заменяет токен новым в той же семье (FamilyID — id первого токена входа);
+// ReplacedBy — id токена-замены, заданный у уже ротированного токена.
+// Сам токен в БД не хранится — только TokenHash (HMAC с REFRESH_SECRET).
 type RefreshToken struct {
+	ID         string
+	UserID     string
+	FamilyID   string
+	TokenHash  string
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	ReplacedBy *string
+	UserAgent  *string
+	IP         *string
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// Session — активный вход пользователя: семья refresh-токенов с действующим токеном.
+// ID — id семьи; CreatedAt — время входа, LastUsedAt — последнего входа или refresh;
+// UserAgent и IP — последнего refresh.
+type Session struct {
+	ID         string
+	UserID     string
+	UserAgent  *string
+	IP         *string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// PasswordResetToken — одноразовый токен сброса пароля. В БД хранится только хеш (TokenHash):
+// утечка таблицы не даёт сбросить чужой пароль.
+type PasswordResetToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// EmailVerificationToken — одноразовый токен из письма подтверждения email. Как и у сброса пароля, хранится только хеш.
+type EmailVerificationToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// OIDCLoginState — незавершённый вход через OIDC: state (хранится хеш), code_verifier PKCE и nonce.
+// Одноразовый: забирается при возврате с провайдера.
+type OIDCLoginState struct {
+	StateHash    string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+}
+
+// UserIdentity — учётная запись OIDC-провайдера (Issuer + Subject), привязанная к пользователю.
+type UserIdentity struct {
+	Issuer    string
+	Subject   string
+	UserID    string
+	Email     string
+	CreatedAt time.Time
+}
+
+// Назначение MFAChallenge.
+const (
+	MFAPurposeVerify = "verify"
+	MFAPurposeEnroll = "enroll"
+)
+
+// UserMFA — TOTP пользователя. Secret зашифрован (см. mfaCipher); EnabledAt == nil — подключение начато,
+// но не подтверждено кодом. LastUsedStep — шаг последнего принятого кода.
+type UserMFA struct {
+	UserID       string
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+// Enabled — TOTP подключён и спрашивается при входе.
+func (m *UserMFA) Enabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+// MFAChallenge — второй шаг входа: пароль (или OIDC) принят, токены выдаются после кода (MFAPurposeVerify)
+// или после подключения TOTP, если оно обязательно для роли (MFAPurposeEnroll). В БД — только хеш токена.
+type MFAChallenge struct {
 	ID        string
+	TokenHash string
 	UserID    string
-	Token     string
+	Purpose   string
+	Attempts  int
 	ExpiresAt time.Time
-	RevokedAt *time.Time
-	UserAgent *string
-	IP        *string
+	UsedAt    *time.Time
 	CreatedAt time.Time
 }
@@ -1,5 +1,7 @@
 package auth
 
+import "time"
+
 // SignUpRequest описывает тело запроса регистрации.
 type SignUpRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -12,10 +14,114 @@ type SignInRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// ForgotPasswordRequest — тело запроса ссылки сброса пароля.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest — тело запроса смены пароля по токену из письма.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// VerifyEmailRequest — тело запроса подтверждения email по токену из письма.
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest — тело запроса повторного письма подтверждения.
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// AuthResponse — ответ sign-up/sign-in. Токенов нет, если вход до подтверждения email запрещён
+// (UNVERIFIED_EMAIL_ACCESS=deny) и пользователь только что зарегистрировался.
 type AuthResponse struct {
-	UserID       string `json:"userID"`
-	Email        string `json:"email"`
-	Role         string `json:"role"`
-	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"`
+	UserID        string `json:"userID"`
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"emailVerified"`
+	AccessToken   string `json:"accessToken,omitempty"`
+	RefreshToken  string `json:"refreshToken,omitempty"`
+}
+
+// SessionResponse — активная сессия в GET /api/auth/sessions. device/os/browser распознаны по User-Agent (пусто — не распознано).
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device,omitempty"`
+	OS         string    `json:"os,omitempty"`
+	Browser    string    `json:"browser,omitempty"`
+	UserAgent  *string   `json:"userAgent,omitempty"`
+	IP         *string   `json:"ip,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	Current    bool      `json:"current"`
+}
+
+// SignOutAllResponse — ответ POST /api/auth/sign-out-all.
+type SignOutAllResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// OIDCStartResponse — ответ POST /api/auth/oidc/start: фронт переходит на authorizationUrl.
+type OIDCStartResponse struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+}
+
+// OIDCCallbackRequest — тело POST /api/auth/oidc/callback: code и state из адреса возврата с провайдера.
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// MFAChallengeResponse — ответ sign-in (и OIDC callback), когда нужен второй шаг: токены выдаёт
+// POST /api/auth/mfa/verify по mfaToken и коду, а при enrollmentRequired — /api/auth/mfa/setup/confirm
+// после подключения TOTP.
+type MFAChallengeResponse struct {
+	MFARequired        bool      `json:"mfaRequired"`
+	MFAToken           string    `json:"mfaToken"`
+	ExpiresAt          time.Time `json:"expiresAt"`
+	EnrollmentRequired bool      `json:"enrollmentRequired"`
+}
+
+// MFAVerifyRequest — тело POST /api/auth/mfa/verify: код из приложения или код восстановления.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MFASetupRequest — тело POST /api/auth/mfa/setup (обязательное подключение TOTP при входе).
+type MFASetupRequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+}
+
+// MFACodeRequest — тело запросов с кодом из приложения (или кодом восстановления, где он принимается).
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TOTPEnrollmentResponse — секрет для ручного ввода, otpauth://-ссылка и она же QR-кодом (data URL PNG).
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauthUrl"`
+	QRCode     string `json:"qrCode"`
+}
+
+// RecoveryCodesResponse — коды восстановления; показываются один раз.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFASetupResponse — ответ POST /api/auth/mfa/setup/confirm: вход завершён, плюс коды восстановления.
+type MFASetupResponse struct {
+	AuthResponse
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFAStatusResponse — ответ GET /api/auth/mfa.
+type MFAStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recoveryCodesLeft"`
 }
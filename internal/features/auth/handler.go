@@ -1,13 +1,17 @@
 package auth
 
 import (
+	"context"
+	"encoding/base64"
 	"errors"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	qrcode "github.com/skip2/go-qrcode"
 
 	"wdpl_back/internal/shared/http/handler"
+	"wdpl_back/internal/shared/http/middleware"
 	"wdpl_back/internal/shared/http/response"
 )
 
@@ -21,6 +25,13 @@ type Handler struct {
 // Важно: фронт не имеет доступа к значению, только браузер отправляет его на бекенд.
 const refreshCookieName = "refreshToken"
 
+// oidcStateCookieName — HTTP‑only cookie с хешем state входа через OIDC: callback принимается только
+// от браузера, который этот вход начал.
+const oidcStateCookieName = "oidcState"
+
+// totpQRSize — сторона QR-кода подключения TOTP, px.
+const totpQRSize = 256
+
 func NewHandler(service *Service) *Handler {
 	return &Handler{
 		service:  service,
@@ -54,15 +65,7 @@ func (h *Handler) SignUp(c *fiber.Ctx) error {
 		return response.WriteInternalError(c, err)
 	}
 
-	setRefreshCookie(c, tokens.RefreshToken, tokens.RefreshExpiry)
-
-	return c.JSON(AuthResponse{
-		UserID:       user.ID,
-		Email:        user.Email,
-		Role:         user.Role,
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
-	})
+	return c.JSON(authResponse(c, user, tokens))
 }
 
 func (h *Handler) SignIn(c *fiber.Ctx) error {
@@ -82,24 +85,28 @@ func (h *Handler) SignIn(c *fiber.Ctx) error {
 
 	user, tokens, err := h.service.Login(ctx, req.Email, req.Password, userAgent, ip)
 	if err != nil {
+		var challenge *MFAChallengeError
+		if errors.As(err, &challenge) {
+			return c.JSON(mfaChallengeResponse(challenge))
+		}
+		var limited *RateLimitError
+		if errors.As(err, &limited) {
+			middleware.SetRetryAfter(c, limited.RetryAfter)
+			return response.WriteError(c, fiber.StatusTooManyRequests, err.Error())
+		}
 		if errors.Is(err, ErrInvalidCredentials) {
 			return response.WriteError(c, fiber.StatusUnauthorized, "invalid credentials")
 		}
 		if errors.Is(err, ErrUserInactive) {
 			return response.WriteError(c, fiber.StatusForbidden, "user is inactive")
 		}
+		if errors.Is(err, ErrEmailNotVerified) {
+			return response.WriteError(c, fiber.StatusForbidden, "email not verified")
+		}
 		return response.WriteInternalError(c, err)
 	}
 
-	setRefreshCookie(c, tokens.RefreshToken, tokens.RefreshExpiry)
-
-	return c.JSON(AuthResponse{
-		UserID:       user.ID,
-		Email:        user.Email,
-		Role:         user.Role,
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
-	})
+	return c.JSON(authResponse(c, user, tokens))
 }
 
 func (h *Handler) Refresh(c *fiber.Ctx) error {
@@ -132,6 +139,15 @@ func (h *Handler) Refresh(c *fiber.Ctx) error {
 		if errors.Is(err, ErrInvalidCredentials) {
 			return response.WriteError(c, fiber.StatusUnauthorized, "invalid refresh token")
 		}
+		if errors.Is(err, ErrUserInactive) {
+			return response.WriteError(c, fiber.StatusForbidden, "user is inactive")
+		}
+		if errors.Is(err, ErrEmailNotVerified) {
+			return response.WriteError(c, fiber.StatusForbidden, "email not verified")
+		}
+		if errors.Is(err, ErrMFAEnrollmentRequired) {
+			return response.WriteError(c, fiber.StatusForbidden, err.Error())
+		}
 		return response.WriteInternalError(c, err)
 	}
 
@@ -176,6 +192,444 @@ func (h *Handler) SignOut(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// ForgotPassword — POST /api/auth/forgot-password. 202 для любого email: ответ не выдаёт, зарегистрирован ли он;
+// 429 — исчерпан лимит писем на этот email.
+func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+
+	ctx, cancel := handler.TimeoutContext(c, 15*time.Second)
+	defer cancel()
+
+	if err := h.service.RequestPasswordReset(ctx, req.Email); err != nil {
+		var limited *RateLimitError
+		if errors.As(err, &limited) {
+			middleware.SetRetryAfter(c, limited.RetryAfter)
+			return response.WriteError(c, fiber.StatusTooManyRequests, err.Error())
+		}
+		return response.WriteInternalError(c, err)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ResetPassword — POST /api/auth/reset-password. Новый пароль по токену из письма; все сессии завершаются.
+func (h *Handler) ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := h.service.ResetPassword(ctx, req.Token, req.Password); err != nil {
+		if errors.Is(err, ErrInvalidResetToken) {
+			return response.WriteError(c, fiber.StatusBadRequest, "invalid or expired reset token")
+		}
+		return response.WriteInternalError(c, err)
+	}
+
+	clearRefreshCookie(c)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// VerifyEmail — POST /api/auth/verify-email. Подтверждение email по токену из письма.
+func (h *Handler) VerifyEmail(c *fiber.Ctx) error {
+	var req VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := h.service.VerifyEmail(ctx, req.Token); err != nil {
+		if errors.Is(err, ErrInvalidVerificationToken) {
+			return response.WriteError(c, fiber.StatusBadRequest, "invalid or expired verification token")
+		}
+		return response.WriteInternalError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResendVerification — POST /api/auth/resend-verification. 202 для любого email; 429 с Retry-After при частых запросах.
+func (h *Handler) ResendVerification(c *fiber.Ctx) error {
+	var req ResendVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+
+	ctx, cancel := handler.TimeoutContext(c, 15*time.Second)
+	defer cancel()
+
+	if err := h.service.ResendVerification(ctx, req.Email); err != nil {
+		var limited *RateLimitError
+		if errors.As(err, &limited) {
+			middleware.SetRetryAfter(c, limited.RetryAfter)
+			return response.WriteError(c, fiber.StatusTooManyRequests, err.Error())
+		}
+		return response.WriteInternalError(c, err)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ListSessions — GET /api/auth/sessions. Активные входы текущего пользователя; current — сессия этого access-токена.
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	sessions, err := h.service.ListSessions(ctx, claims.UserID)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		r := SessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			Current:    s.ID == claims.SessionID,
+		}
+		if s.UserAgent != nil {
+			info := ParseUserAgent(*s.UserAgent)
+			r.Device, r.OS, r.Browser = info.Device, info.OS, info.Browser
+		}
+		resp = append(resp, r)
+	}
+	return c.JSON(resp)
+}
+
+// RevokeSession — DELETE /api/auth/sessions/:id. Завершение своей сессии; для текущей ещё и очищается refresh-cookie.
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := h.service.RevokeSession(ctx, claims.UserID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "session not found")
+		}
+		return response.WriteInternalError(c, err)
+	}
+	if sessionID == claims.SessionID {
+		clearRefreshCookie(c)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SignOutAll — POST /api/auth/sign-out-all. Завершает все сессии пользователя, кроме текущей.
+func (h *Handler) SignOutAll(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	n, err := h.service.SignOutOthers(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	return c.JSON(SignOutAllResponse{Revoked: n})
+}
+
+// OIDCStart — POST /api/auth/oidc/start. Адрес страницы входа провайдера (PKCE); 404 — OIDC не настроен.
+func (h *Handler) OIDCStart(c *fiber.Ctx) error {
+	ctx, cancel := handler.TimeoutContext(c, 15*time.Second)
+	defer cancel()
+
+	authURL, stateHash, err := h.service.StartOIDC(ctx)
+	if err != nil {
+		return writeOIDCError(c, err)
+	}
+	setOIDCStateCookie(c, stateHash, time.Now().Add(oidcLoginTTL))
+	return c.JSON(OIDCStartResponse{AuthorizationURL: authURL})
+}
+
+// OIDCCallback — POST /api/auth/oidc/callback. Завершает вход по code и state; ответ как у sign-in.
+// State должен совпасть с cookie, выставленной на start, — иначе 401.
+func (h *Handler) OIDCCallback(c *fiber.Ctx) error {
+	var req OIDCCallbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+
+	ctx, cancel := handler.TimeoutContext(c, 15*time.Second)
+	defer cancel()
+
+	boundStateHash := c.Cookies(oidcStateCookieName)
+	clearOIDCStateCookie(c)
+	user, tokens, err := h.service.CompleteOIDC(ctx, req.Code, req.State, boundStateHash, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		var challenge *MFAChallengeError
+		if errors.As(err, &challenge) {
+			return c.JSON(mfaChallengeResponse(challenge))
+		}
+		return writeOIDCError(c, err)
+	}
+	return c.JSON(authResponse(c, user, tokens))
+}
+
+// writeOIDCError маппит ошибки входа через OIDC в HTTP-коды.
+func writeOIDCError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrOIDCDisabled):
+		return response.WriteError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidOIDCState), errors.Is(err, ErrOIDCLoginFailed), errors.Is(err, ErrInvalidCredentials):
+		return response.WriteError(c, fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, ErrOIDCEmailNotVerified), errors.Is(err, ErrUserInactive):
+		return response.WriteError(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, ErrOIDCAccountNotVerified):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+// MFAVerify — POST /api/auth/mfa/verify. Второй шаг входа: mfaToken из sign-in и код; ответ как у sign-in.
+// После mfaMaxAttempts неверных кодов токен перестаёт действовать — вход начинается заново.
+func (h *Handler) MFAVerify(c *fiber.Ctx) error {
+	var req MFAVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	user, tokens, err := h.service.VerifyMFA(ctx, req.MFAToken, req.Code, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		return writeMFAError(c, err)
+	}
+	return c.JSON(authResponse(c, user, tokens))
+}
+
+// MFASetup — POST /api/auth/mfa/setup. Обязательное подключение TOTP при входе (enrollmentRequired): секрет и QR.
+func (h *Handler) MFASetup(c *fiber.Ctx) error {
+	var req MFASetupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	enrollment, err := h.service.StartMFAEnrollment(ctx, req.MFAToken)
+	if err != nil {
+		return writeMFAError(c, err)
+	}
+	return writeEnrollment(c, enrollment)
+}
+
+// MFASetupConfirm — POST /api/auth/mfa/setup/confirm. Первый код подтверждает подключение и завершает вход;
+// в ответе, кроме токенов, коды восстановления.
+func (h *Handler) MFASetupConfirm(c *fiber.Ctx) error {
+	var req MFAVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	user, tokens, codes, err := h.service.CompleteMFAEnrollment(ctx, req.MFAToken, req.Code, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		return writeMFAError(c, err)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(MFASetupResponse{AuthResponse: authResponse(c, user, tokens), RecoveryCodes: codes})
+}
+
+// MFAStatus — GET /api/auth/mfa. Подключён ли TOTP, обязателен ли он и сколько осталось кодов восстановления.
+func (h *Handler) MFAStatus(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	status, err := h.service.MFAStatus(ctx, claims.UserID)
+	if err != nil {
+		return writeMFAError(c, err)
+	}
+	return c.JSON(MFAStatusResponse{
+		Enabled:           status.Enabled,
+		Required:          status.Required,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	})
+}
+
+// EnrollTOTP — POST /api/auth/mfa/totp. Начинает подключение TOTP: секрет и QR для приложения.
+func (h *Handler) EnrollTOTP(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	enrollment, err := h.service.EnrollTOTP(ctx, claims.UserID)
+	if err != nil {
+		return writeMFAError(c, err)
+	}
+	return writeEnrollment(c, enrollment)
+}
+
+// ConfirmTOTP — POST /api/auth/mfa/totp/confirm. Код из приложения включает TOTP; в ответе коды восстановления.
+func (h *Handler) ConfirmTOTP(c *fiber.Ctx) error {
+	return h.withMFACode(c, func(ctx context.Context, userID, code string) error {
+		codes, err := h.service.ConfirmTOTP(ctx, userID, code)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.JSON(RecoveryCodesResponse{RecoveryCodes: codes})
+	})
+}
+
+// DisableTOTP — POST /api/auth/mfa/totp/disable. Отключает TOTP по коду или коду восстановления;
+// 403, если второй фактор обязателен для роли.
+func (h *Handler) DisableTOTP(c *fiber.Ctx) error {
+	return h.withMFACode(c, func(ctx context.Context, userID, code string) error {
+		if err := h.service.DisableTOTP(ctx, userID, code, c.Get("User-Agent"), c.IP()); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+}
+
+// RegenerateRecoveryCodes — POST /api/auth/mfa/recovery-codes. Новые коды восстановления взамен старых.
+func (h *Handler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	return h.withMFACode(c, func(ctx context.Context, userID, code string) error {
+		codes, err := h.service.RegenerateRecoveryCodes(ctx, userID, code, c.Get("User-Agent"), c.IP())
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.JSON(RecoveryCodesResponse{RecoveryCodes: codes})
+	})
+}
+
+// withMFACode разбирает MFACodeRequest текущего пользователя и маппит ошибки fn через writeMFAError.
+func (h *Handler) withMFACode(c *fiber.Ctx, fn func(ctx context.Context, userID, code string) error) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	var req MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := fn(ctx, claims.UserID, req.Code); err != nil {
+		return writeMFAError(c, err)
+	}
+	return nil
+}
+
+// writeEnrollment отдаёт секрет, otpauth://-ссылку и QR-код (PNG в data URL). Ответ не кешируется: в нём секрет.
+func writeEnrollment(c *fiber.Ctx, enrollment *TOTPEnrollment) error {
+	png, err := qrcode.Encode(enrollment.URI, qrcode.Medium, totpQRSize)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURL: enrollment.URI,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// writeMFAError маппит ошибки двухфакторной аутентификации в HTTP-коды.
+func writeMFAError(c *fiber.Ctx, err error) error {
+	var limited *RateLimitError
+	switch {
+	case errors.As(err, &limited):
+		middleware.SetRetryAfter(c, limited.RetryAfter)
+		return response.WriteError(c, fiber.StatusTooManyRequests, err.Error())
+	case errors.Is(err, ErrMFAUnavailable):
+		return response.WriteError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidMFAChallenge), errors.Is(err, ErrInvalidMFACode), errors.Is(err, ErrInvalidCredentials):
+		return response.WriteError(c, fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, ErrUserInactive), errors.Is(err, ErrMFARequiredByPolicy):
+		return response.WriteError(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, ErrMFAAlreadyEnabled), errors.Is(err, ErrMFANotEnrolled), errors.Is(err, ErrMFANotEnabled):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+// mfaChallengeResponse — ответ первого шага входа, когда нужен второй фактор.
+func mfaChallengeResponse(challenge *MFAChallengeError) MFAChallengeResponse {
+	return MFAChallengeResponse{
+		MFARequired:        true,
+		MFAToken:           challenge.Token,
+		ExpiresAt:          challenge.ExpiresAt,
+		EnrollmentRequired: challenge.Enrollment,
+	}
+}
+
+// authResponse собирает ответ sign-up/sign-in и выставляет refresh-cookie, если токены выданы.
+func authResponse(c *fiber.Ctx, user *User, tokens *AuthTokens) AuthResponse {
+	resp := AuthResponse{
+		UserID:        user.ID,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified(),
+	}
+	if tokens != nil {
+		setRefreshCookie(c, tokens.RefreshToken, tokens.RefreshExpiry)
+		resp.AccessToken = tokens.AccessToken
+		resp.RefreshToken = tokens.RefreshToken
+	}
+	return resp
+}
+
 // setRefreshCookie выставляет HTTP‑only cookie с refresh‑токеном.
 // Secure=true подразумевает использование HTTPS в продакшене.
 func setRefreshCookie(c *fiber.Ctx, token string, expiresAt time.Time) {
@@ -200,3 +654,30 @@ func clearRefreshCookie(c *fiber.Ctx) {
 		Secure:   true,
 	})
 }
+
+// setOIDCStateCookie выставляет HTTP‑only cookie с хешем state на время входа через провайдера.
+// Path ограничен эндпоинтами OIDC; SameSite=Lax — cookie не уходит с запросами, начатыми чужим сайтом.
+func setOIDCStateCookie(c *fiber.Ctx, stateHash string, expiresAt time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    stateHash,
+		Path:     "/api/auth/oidc",
+		Expires:  expiresAt,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// clearOIDCStateCookie очищает cookie state: она одноразовая, как и сам state.
+func clearOIDCStateCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    "",
+		Path:     "/api/auth/oidc",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/ratelimit"
 )
 
 // Интеграционный тест: POST /api/auth/sign-in с мок-сервисом (без БД).
@@ -69,6 +74,185 @@ func TestHandler_SignIn_InvalidCredentials(t *testing.T) {
 	require.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
 }
 
+func TestHandler_PasswordReset(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	h := NewHandler(s)
+	app := fiber.New()
+	app.Post("/api/auth/forgot-password", h.ForgotPassword)
+	app.Post("/api/auth/reset-password", h.ResetPassword)
+
+	_, _, err := s.Register(ctxBackground(), "forgot@example.com", "password123")
+	require.NoError(t, err)
+
+	post := func(path, body string) int {
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+
+	// Ответ одинаковый для существующего и неизвестного email.
+	require.Equal(t, fiber.StatusAccepted, post("/api/auth/forgot-password", `{"email":"forgot@example.com"}`))
+	require.Equal(t, fiber.StatusAccepted, post("/api/auth/forgot-password", `{"email":"nobody@example.com"}`))
+	require.Len(t, deps.mailer.sent, 2) // подтверждение email при регистрации + сброс
+	token := resetTokenFromMail(t, deps.mailer)
+
+	require.Equal(t, fiber.StatusBadRequest, post("/api/auth/reset-password", `{"token":"`+token+`","password":"short"}`))
+	require.Equal(t, fiber.StatusNoContent, post("/api/auth/reset-password", `{"token":"`+token+`","password":"new-password-1"}`))
+	require.Equal(t, fiber.StatusBadRequest, post("/api/auth/reset-password", `{"token":"`+token+`","password":"new-password-2"}`))
+}
+
+func TestHandler_SignUp_UnverifiedIsReadOnly(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	h := NewHandler(s)
+	app := fiber.New()
+	app.Post("/api/auth/sign-up", h.SignUp)
+	app.Post("/api/auth/verify-email", h.VerifyEmail)
+	protected := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Get("/api/protected", middleware.RequireAuth(s.cfg), protected)
+	app.Post("/api/protected", middleware.RequireAuth(s.cfg), protected)
+
+	req := httptest.NewRequest("POST", "/api/auth/sign-up", bytes.NewBufferString(`{"email":"new@example.com","password":"password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var resp AuthResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	require.False(t, resp.EmailVerified)
+	require.NotEmpty(t, resp.AccessToken)
+
+	call := func(method string) int {
+		req := httptest.NewRequest(method, "/api/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+resp.AccessToken)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+	require.Equal(t, fiber.StatusOK, call("GET"))
+	require.Equal(t, fiber.StatusForbidden, call("POST"))
+
+	token := tokenFromMail(t, deps.mailer, "/verify-email")
+	req = httptest.NewRequest("POST", "/api/auth/verify-email", bytes.NewBufferString(`{"token":"`+token+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, res.StatusCode)
+}
+
+func TestRequireAuth_RejectsTokenOfPreviousGeneration(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	user, tokens, err := s.Register(ctxBackground(), "gen@example.com", "password123")
+	require.NoError(t, err)
+
+	app := fiber.New()
+	api := app.Group("/api", middleware.WithTokenValidator(NewTokenValidator(deps.users)))
+	api.Get("/protected", middleware.RequireAuth(s.cfg), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	call := func() int {
+		req := httptest.NewRequest("GET", "/api/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+	require.Equal(t, fiber.StatusOK, call())
+
+	// Смена роли или деактивация увеличивает поколение — выданный токен больше не принимается.
+	user.TokenGeneration++
+	require.Equal(t, fiber.StatusUnauthorized, call())
+}
+
+func TestHandler_Sessions(t *testing.T) {
+	s, _ := newTestServiceDeps(t)
+	h := NewHandler(s)
+	app := fiber.New()
+	requireAuth := middleware.RequireAuth(s.cfg)
+	app.Get("/api/auth/sessions", requireAuth, h.ListSessions)
+	app.Delete("/api/auth/sessions/:id", requireAuth, h.RevokeSession)
+	app.Post("/api/auth/sign-out-all", requireAuth, h.SignOutAll)
+
+	user, _, err := s.Register(ctxBackground(), "devices@example.com", "password123")
+	require.NoError(t, err)
+	user.EmailVerifiedAt = &user.CreatedAt // иначе токен только на чтение
+	const chromeUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	_, tokens, err := s.Login(ctxBackground(), "devices@example.com", "password123", chromeUA, "10.0.0.5")
+	require.NoError(t, err)
+
+	call := func(method, path string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res
+	}
+
+	res := call("GET", "/api/auth/sessions")
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var list []SessionResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Len(t, list, 2)
+	var current *SessionResponse
+	for i := range list {
+		if list[i].Current {
+			current = &list[i]
+		}
+	}
+	require.NotNil(t, current)
+	require.Equal(t, "Chrome", current.Browser)
+	require.Equal(t, "Windows", current.OS)
+	require.Equal(t, "desktop", current.Device)
+
+	require.Equal(t, fiber.StatusNotFound, call("DELETE", "/api/auth/sessions/unknown").StatusCode)
+
+	res = call("POST", "/api/auth/sign-out-all")
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var out SignOutAllResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+	require.Equal(t, 1, out.Revoked)
+
+	require.Equal(t, fiber.StatusNoContent, call("DELETE", "/api/auth/sessions/"+current.ID).StatusCode)
+	res = call("GET", "/api/auth/sessions")
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Empty(t, list)
+}
+
 func ctxBackground() context.Context {
 	return context.Background()
 }
+
+func TestHandler_SignIn_LockedAccountGetsRetryAfter(t *testing.T) {
+	s, _, _ := newTestService(t)
+	s.cfg.LoginLockoutAfter = 1
+	app := fiber.New()
+	app.Post("/api/auth/sign-in", NewHandler(s).SignIn)
+
+	signIn := func() *http.Response {
+		req := httptest.NewRequest("POST", "/api/auth/sign-in", bytes.NewBufferString(`{"email":"lock@example.com","password":"wrong"}`))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res
+	}
+	require.Equal(t, fiber.StatusUnauthorized, signIn().StatusCode)
+	res := signIn()
+	require.Equal(t, fiber.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, "60", res.Header.Get(fiber.HeaderRetryAfter))
+}
+
+func TestRateLimitMiddleware_PerIP(t *testing.T) {
+	app := fiber.New()
+	app.Post("/api/auth/sign-up", middleware.RateLimit(ratelimit.NewMemoryStore(), "sign-up", 2, time.Hour),
+		func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	for range 2 {
+		res, err := app.Test(httptest.NewRequest("POST", "/api/auth/sign-up", nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+	}
+	res, err := app.Test(httptest.NewRequest("POST", "/api/auth/sign-up", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, "3600", res.Header.Get(fiber.HeaderRetryAfter))
+}
@@ -0,0 +1,560 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"wdpl_back/internal/shared/ratelimit"
+	"wdpl_back/internal/shared/totp"
+)
+
+const (
+	// mfaChallengeTTL — сколько ждём код после пароля.
+	mfaChallengeTTL = 5 * time.Minute
+	// mfaEnrollTTL — сколько ждём подключения TOTP при входе, если оно обязательно (нужно поставить приложение).
+	mfaEnrollTTL = 15 * time.Minute
+	// mfaMaxAttempts — неверных кодов на один вход, после чего нужно заново ввести пароль.
+	mfaMaxAttempts = 5
+	// recoveryCodeCount — сколько кодов восстановления выдаётся при подключении.
+	recoveryCodeCount = 10
+	// Отключение TOTP и замена кодов восстановления с действующим access-токеном: после mfaMaxAttempts
+	// неверных кодов подряд — блокировка на mfaCodeLockBase, дальше вдвое дольше, до mfaCodeLockMax.
+	mfaCodeLockBase  = 15 * time.Minute
+	mfaCodeLockMax   = 24 * time.Hour
+	mfaCodeLockReset = 24 * time.Hour
+)
+
+var (
+	ErrMFAUnavailable        = errors.New("two-factor authentication is not configured")
+	ErrMFARequired           = errors.New("second factor required")
+	ErrMFAEnrollmentRequired = errors.New("two-factor authentication must be enabled for this role")
+	ErrMFARequiredByPolicy   = errors.New("two-factor authentication is required for this role")
+	ErrInvalidMFAChallenge   = errors.New("invalid or expired mfa challenge")
+	ErrInvalidMFACode        = errors.New("invalid mfa code")
+	ErrMFAAlreadyEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled        = errors.New("two-factor enrollment is not started")
+	ErrMFANotEnabled         = errors.New("two-factor authentication is not enabled")
+)
+
+// MFAChallengeError — первый шаг входа пройден, токены выдаются после второго: кода (VerifyMFA)
+// или, если Enrollment, подключения TOTP (StartMFAEnrollment, CompleteMFAEnrollment). errors.Is(err, ErrMFARequired).
+type MFAChallengeError struct {
+	Token      string
+	ExpiresAt  time.Time
+	Enrollment bool
+}
+
+func (e *MFAChallengeError) Error() string { return ErrMFARequired.Error() }
+
+func (e *MFAChallengeError) Is(target error) bool { return target == ErrMFARequired }
+
+// TOTPEnrollment — начатое подключение TOTP: секрет и otpauth://-ссылка для приложения-аутентификатора.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// MFAStatus — состояние двухфакторной аутентификации пользователя.
+type MFAStatus struct {
+	Enabled           bool
+	Required          bool
+	RecoveryCodesLeft int
+}
+
+// PublishRights — может ли пользователь публиковать события без глобальной роли из requiredRoles: через
+// команду события или организацию. auth не знает об этих фичах — реализацию передаёт роутер приложения.
+type PublishRights interface {
+	CanPublish(ctx context.Context, userID string) (bool, error)
+}
+
+// WithMFA включает двухфакторную аутентификацию. При MFA_REQUIRED_FOR_EDITORS она обязательна ролям
+// requiredRoles и тем, кому publishers (nil — никому) даёт право публикации. auth не знает, кто публикует, —
+// и список, и publishers передаёт роутер приложения.
+func (s *Service) WithMFA(repo MFARepository, requiredRoles []string, publishers PublishRights) *Service {
+	s.mfaRepo = repo
+	s.mfaRequiredRoles = requiredRoles
+	s.mfaPublishers = publishers
+	return s
+}
+
+// mfaRequiredFor — второй фактор обязателен пользователю: по роли или праву публикации.
+func (s *Service) mfaRequiredFor(ctx context.Context, user *User) (bool, error) {
+	if !s.cfg.MFARequired {
+		return false, nil
+	}
+	if slices.Contains(s.mfaRequiredRoles, user.Role) {
+		return true, nil
+	}
+	if s.mfaPublishers == nil {
+		return false, nil
+	}
+	return s.mfaPublishers.CanPublish(ctx, user.ID)
+}
+
+// secondFactor вызывается после проверки пароля (или OIDC): если у пользователя подключён TOTP
+// или он обязателен для роли, возвращает *MFAChallengeError вместо выдачи токенов.
+func (s *Service) secondFactor(ctx context.Context, user *User) error {
+	if s.mfaRepo == nil {
+		return nil
+	}
+	m, err := s.mfaRepo.GetMFA(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	purpose, ttl := MFAPurposeVerify, mfaChallengeTTL
+	if !m.Enabled() {
+		required, err := s.mfaRequiredFor(ctx, user)
+		if err != nil || !required {
+			return err
+		}
+		purpose, ttl = MFAPurposeEnroll, mfaEnrollTTL
+	}
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	challenge := &MFAChallenge{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.mfaRepo.CreateMFAChallenge(ctx, challenge); err != nil {
+		return err
+	}
+	return &MFAChallengeError{Token: token, ExpiresAt: challenge.ExpiresAt, Enrollment: purpose == MFAPurposeEnroll}
+}
+
+// requireEnrolledMFA не даёт продлевать сессию без второго фактора, если он стал обязателен
+// (включили MFA_REQUIRED_FOR_EDITORS или повысили роль): пользователь входит заново и подключает TOTP.
+func (s *Service) requireEnrolledMFA(ctx context.Context, user *User) error {
+	if s.mfaRepo == nil {
+		return nil
+	}
+	required, err := s.mfaRequiredFor(ctx, user)
+	if err != nil || !required {
+		return err
+	}
+	m, err := s.mfaRepo.GetMFA(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !m.Enabled() {
+		return ErrMFAEnrollmentRequired
+	}
+	return nil
+}
+
+// VerifyMFA — второй шаг входа: код из приложения или код восстановления по токену из *MFAChallengeError.
+func (s *Service) VerifyMFA(ctx context.Context, challengeToken, code, userAgent, ip string) (*User, *AuthTokens, error) {
+	challenge, user, err := s.mfaChallenge(ctx, challengeToken, MFAPurposeVerify)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := s.mfaRepo.GetMFA(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !m.Enabled() {
+		// TOTP отключили, пока шёл вход.
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+	ok, err := s.checkSecondFactor(ctx, m, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, s.failChallenge(ctx, challenge, userAgent, ip)
+	}
+	return s.completeChallenge(ctx, challenge, user, userAgent, ip)
+}
+
+// StartMFAEnrollment начинает обязательное подключение TOTP по токену из *MFAChallengeError (Enrollment).
+func (s *Service) StartMFAEnrollment(ctx context.Context, challengeToken string) (*TOTPEnrollment, error) {
+	_, user, err := s.mfaChallenge(ctx, challengeToken, MFAPurposeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return s.enrollTOTP(ctx, user)
+}
+
+// CompleteMFAEnrollment подтверждает подключение первым кодом, завершает вход и возвращает
+// коды восстановления (показываются один раз).
+func (s *Service) CompleteMFAEnrollment(ctx context.Context, challengeToken, code, userAgent, ip string) (*User, *AuthTokens, []string, error) {
+	challenge, user, err := s.mfaChallenge(ctx, challengeToken, MFAPurposeEnroll)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	codes, err := s.ConfirmTOTP(ctx, user.ID, code)
+	if errors.Is(err, ErrInvalidMFACode) {
+		return nil, nil, nil, s.failChallenge(ctx, challenge, userAgent, ip)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	user, tokens, err := s.completeChallenge(ctx, challenge, user, userAgent, ip)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return user, tokens, codes, nil
+}
+
+// MFAStatus возвращает состояние двухфакторной аутентификации пользователя.
+func (s *Service) MFAStatus(ctx context.Context, userID string) (*MFAStatus, error) {
+	if s.mfaRepo == nil {
+		return nil, ErrMFAUnavailable
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	m, err := s.mfaRepo.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.mfaRequiredFor(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	status := &MFAStatus{Enabled: m.Enabled(), Required: required}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.mfaRepo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// EnrollTOTP начинает подключение TOTP: новый секрет заменяет неподтверждённый. Уже подключённый — ErrMFAAlreadyEnabled.
+func (s *Service) EnrollTOTP(ctx context.Context, userID string) (*TOTPEnrollment, error) {
+	if s.mfaRepo == nil {
+		return nil, ErrMFAUnavailable
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return s.enrollTOTP(ctx, user)
+}
+
+func (s *Service) enrollTOTP(ctx context.Context, user *User) (*TOTPEnrollment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.encryptMFASecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.mfaRepo.SaveMFASecret(ctx, user.ID, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	return &TOTPEnrollment{Secret: secret, URI: totp.URI(secret, s.cfg.MFAIssuer, user.Email)}, nil
+}
+
+// ConfirmTOTP подтверждает подключение кодом из приложения и возвращает коды восстановления.
+func (s *Service) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	if s.mfaRepo == nil {
+		return nil, ErrMFAUnavailable
+	}
+	m, err := s.mfaRepo.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if m.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := s.decryptMFASecret(m.Secret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.mfaRepo.EnableMFA(ctx, userID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	s.log.Info("security: two-factor authentication enabled", "user_id", userID)
+	return codes, nil
+}
+
+// DisableTOTP отключает TOTP после проверки кода (или кода восстановления). Нельзя, если второй фактор обязателен для роли.
+func (s *Service) DisableTOTP(ctx context.Context, userID, code, userAgent, ip string) error {
+	m, user, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	required, err := s.mfaRequiredFor(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredByPolicy
+	}
+	if err := s.checkAccountMFACode(ctx, m, code, "disable", userAgent, ip); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.DisableMFA(ctx, userID); err != nil {
+		return err
+	}
+	s.log.Warn("security: two-factor authentication disabled", "user_id", userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes после проверки кода заменяет коды восстановления новыми; старые перестают действовать.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID, code, userAgent, ip string) ([]string, error) {
+	m, _, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccountMFACode(ctx, m, code, "recovery-codes", userAgent, ip); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkAccountMFACode проверяет код для действия action над 2FA уже вошедшего пользователя. Неверные коды
+// считаются на пользователя (а не на вход, как в failChallenge) и пишутся в лог как события безопасности;
+// после mfaMaxAttempts подряд действие блокируется — *RateLimitError. Верный код сбрасывает счёт.
+func (s *Service) checkAccountMFACode(ctx context.Context, m *UserMFA, code, action, userAgent, ip string) error {
+	key := "mfa-code:" + m.UserID
+	lockedFor, err := s.limits.LockedFor(ctx, key)
+	if err != nil {
+		return err
+	}
+	if lockedFor > 0 {
+		return &RateLimitError{RetryAfter: lockedFor}
+	}
+	ok, err := s.checkSecondFactor(ctx, m, code)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return s.limits.Reset(ctx, key)
+	}
+	s.log.Warn("security: invalid mfa code", "user_id", m.UserID, "action", action, "user_agent", userAgent, "ip", ip)
+	lockedFor, err = s.limits.RecordFailure(ctx, key, ratelimit.LockoutPolicy{
+		Threshold:  mfaMaxAttempts,
+		BaseLock:   mfaCodeLockBase,
+		MaxLock:    mfaCodeLockMax,
+		ResetAfter: mfaCodeLockReset,
+	})
+	if err != nil {
+		return err
+	}
+	if lockedFor > 0 {
+		s.log.Warn("security: mfa attempts exhausted", "user_id", m.UserID, "action", action,
+			"locked_for", lockedFor.String(), "user_agent", userAgent, "ip", ip)
+	}
+	return ErrInvalidMFACode
+}
+
+// enabledMFA возвращает подключённый TOTP пользователя и самого пользователя.
+func (s *Service) enabledMFA(ctx context.Context, userID string) (*UserMFA, *User, error) {
+	if s.mfaRepo == nil {
+		return nil, nil, ErrMFAUnavailable
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+	m, err := s.mfaRepo.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !m.Enabled() {
+		return nil, nil, ErrMFANotEnabled
+	}
+	return m, user, nil
+}
+
+// mfaChallenge находит незавершённый вход по токену: не использован, не истёк, попытки не исчерпаны.
+func (s *Service) mfaChallenge(ctx context.Context, token, purpose string) (*MFAChallenge, *User, error) {
+	if s.mfaRepo == nil {
+		return nil, nil, ErrMFAUnavailable
+	}
+	challenge, err := s.mfaRepo.GetMFAChallenge(ctx, hashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if challenge == nil || challenge.Purpose != purpose || challenge.UsedAt != nil ||
+		challenge.Attempts >= mfaMaxAttempts || time.Now().After(challenge.ExpiresAt) {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+	user, err := s.userRepo.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+	if !user.IsActive {
+		return nil, nil, ErrUserInactive
+	}
+	return challenge, user, nil
+}
+
+// failChallenge учитывает неверный код; последняя попытка пишется в лог как событие безопасности.
+func (s *Service) failChallenge(ctx context.Context, challenge *MFAChallenge, userAgent, ip string) error {
+	if err := s.mfaRepo.FailMFAChallenge(ctx, challenge.ID); err != nil {
+		return err
+	}
+	if challenge.Attempts+1 >= mfaMaxAttempts {
+		s.log.Warn("security: mfa attempts exhausted", "user_id", challenge.UserID, "user_agent", userAgent, "ip", ip)
+	}
+	return ErrInvalidMFACode
+}
+
+// completeChallenge гасит вход и выдаёт токены.
+func (s *Service) completeChallenge(ctx context.Context, challenge *MFAChallenge, user *User, userAgent, ip string) (*User, *AuthTokens, error) {
+	consumed, err := s.mfaRepo.ConsumeMFAChallenge(ctx, challenge.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !consumed {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+	tokens, err := s.issueTokens(ctx, user, SignInMFA, userAgent, ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// checkSecondFactor проверяет код из приложения (6 цифр; каждый принимается один раз) или код восстановления.
+func (s *Service) checkSecondFactor(ctx context.Context, m *UserMFA, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		secret, err := s.decryptMFASecret(m.Secret)
+		if err != nil {
+			return false, err
+		}
+		step, ok := totp.Validate(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.mfaRepo.UseMFAStep(ctx, m.UserID, step)
+	}
+	return s.mfaRepo.UseRecoveryCode(ctx, m.UserID, s.hashRecoveryCode(code))
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// recoveryEncoding — коды восстановления в нижнем регистре base32: без похожих 0/O и 1/l.
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// newRecoveryCodes возвращает коды восстановления вида xxxx-xxxx и их хеши для БД.
+func (s *Service) newRecoveryCodes() (codes, hashes []string, err error) {
+	for range recoveryCodeCount {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := recoveryEncoding.EncodeToString(buf)
+		code := raw[:4] + "-" + raw[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, s.hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode — HMAC кода восстановления (без дефисов, пробелов и регистра). Коды короткие,
+// поэтому, как и refresh-токены, хешируются с секретом: по утёкшей таблице их не перебрать.
+func (s *Service) hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	mac := hmac.New(sha256.New, []byte(s.cfg.MFAEncryptionKey()))
+	mac.Write([]byte("recovery:" + normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// mfaCipher — AES-256-GCM с ключом из MFA_SECRET: TOTP-секрет нужен в открытом виде для проверки кодов,
+// поэтому он шифруется, а не хешируется.
+func (s *Service) mfaCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(s.cfg.MFAEncryptionKey()))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *Service) encryptMFASecret(secret string) (string, error) {
+	aead, err := s.mfaCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func (s *Service) decryptMFASecret(encrypted string) (string, error) {
+	aead, err := s.mfaCipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", errors.New("mfa secret: malformed ciphertext")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("mfa secret: cannot decrypt (MFA_SECRET changed?)")
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,449 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/totp"
+)
+
+// mockMFARepo — in-memory реализация MFARepository.
+type mockMFARepo struct {
+	mfa        map[string]*UserMFA
+	recovery   map[string]map[string]bool // userID -> hash -> использован
+	challenges map[string]*MFAChallenge
+}
+
+func newMockMFARepo() *mockMFARepo {
+	return &mockMFARepo{
+		mfa:        map[string]*UserMFA{},
+		recovery:   map[string]map[string]bool{},
+		challenges: map[string]*MFAChallenge{},
+	}
+}
+
+func (m *mockMFARepo) GetMFA(_ context.Context, userID string) (*UserMFA, error) {
+	if mfa, ok := m.mfa[userID]; ok {
+		copied := *mfa
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockMFARepo) SaveMFASecret(_ context.Context, userID, secret string) (bool, error) {
+	if m.mfa[userID].Enabled() {
+		return false, nil
+	}
+	m.mfa[userID] = &UserMFA{UserID: userID, Secret: secret, CreatedAt: time.Now()}
+	return true, nil
+}
+
+func (m *mockMFARepo) EnableMFA(ctx context.Context, userID string, step int64, hashes []string) (bool, error) {
+	mfa := m.mfa[userID]
+	if mfa == nil || mfa.Enabled() {
+		return false, nil
+	}
+	now := time.Now()
+	mfa.EnabledAt = &now
+	mfa.LastUsedStep = step
+	return true, m.ReplaceRecoveryCodes(ctx, userID, hashes)
+}
+
+func (m *mockMFARepo) DisableMFA(_ context.Context, userID string) error {
+	delete(m.mfa, userID)
+	delete(m.recovery, userID)
+	return nil
+}
+
+func (m *mockMFARepo) UseMFAStep(_ context.Context, userID string, step int64) (bool, error) {
+	mfa := m.mfa[userID]
+	if mfa == nil || step <= mfa.LastUsedStep {
+		return false, nil
+	}
+	mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (m *mockMFARepo) UseRecoveryCode(_ context.Context, userID, codeHash string) (bool, error) {
+	used, ok := m.recovery[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	m.recovery[userID][codeHash] = true
+	return true, nil
+}
+
+func (m *mockMFARepo) ReplaceRecoveryCodes(_ context.Context, userID string, hashes []string) error {
+	m.recovery[userID] = map[string]bool{}
+	for _, hash := range hashes {
+		m.recovery[userID][hash] = false
+	}
+	return nil
+}
+
+func (m *mockMFARepo) CountRecoveryCodes(_ context.Context, userID string) (int, error) {
+	n := 0
+	for _, used := range m.recovery[userID] {
+		if !used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockMFARepo) CreateMFAChallenge(_ context.Context, challenge *MFAChallenge) error {
+	challenge.ID = challenge.TokenHash
+	m.challenges[challenge.TokenHash] = challenge
+	return nil
+}
+
+func (m *mockMFARepo) GetMFAChallenge(_ context.Context, tokenHash string) (*MFAChallenge, error) {
+	if challenge, ok := m.challenges[tokenHash]; ok {
+		copied := *challenge
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockMFARepo) FailMFAChallenge(_ context.Context, id string) error {
+	m.challenges[id].Attempts++
+	return nil
+}
+
+func (m *mockMFARepo) ConsumeMFAChallenge(_ context.Context, id string) (bool, error) {
+	challenge := m.challenges[id]
+	if challenge.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	challenge.UsedAt = &now
+	return true, nil
+}
+
+var testMFARoles = []string{RoleAdmin, RoleOrganizer, RoleEditor}
+
+// newMFATestService — сервис с двухфакторной аутентификацией; required — MFA_REQUIRED_FOR_EDITORS.
+func newMFATestService(t *testing.T, required bool) (*Service, *testDeps, *mockMFARepo) {
+	t.Helper()
+	s, deps := newTestServiceDeps(t)
+	s.cfg.MFARequired = required
+	s.cfg.MFAIssuer = "WDPL"
+	repo := newMockMFARepo()
+	s.WithMFA(repo, testMFARoles, nil)
+	return s, deps, repo
+}
+
+// enableTOTP подключает TOTP пользователю и возвращает секрет и коды восстановления.
+func enableTOTP(t *testing.T, s *Service, userID string) (string, []string) {
+	t.Helper()
+	enrollment, err := s.EnrollTOTP(context.Background(), userID)
+	require.NoError(t, err)
+	codes, err := s.ConfirmTOTP(context.Background(), userID, totpCode(t, enrollment.Secret, 0))
+	require.NoError(t, err)
+	return enrollment.Secret, codes
+}
+
+// totpCode — код на текущий шаг со сдвигом delta (в пределах totp.Skew принимается).
+func totpCode(t *testing.T, secret string, delta int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(time.Now())+delta)
+	require.NoError(t, err)
+	return code
+}
+
+// mfaChallengeOf достаёт *MFAChallengeError из ошибки входа.
+func mfaChallengeOf(t *testing.T, err error) *MFAChallengeError {
+	t.Helper()
+	require.ErrorIs(t, err, ErrMFARequired)
+	var challenge *MFAChallengeError
+	require.True(t, errors.As(err, &challenge))
+	return challenge
+}
+
+func TestMFA_LoginRequiresCodeAfterEnrollment(t *testing.T) {
+	s, _, repo := newMFATestService(t, false)
+	ctx := context.Background()
+	user, _, err := s.Register(ctx, "mfa@example.com", "password123")
+	require.NoError(t, err)
+
+	// До подключения — обычный вход.
+	_, tokens, err := s.Login(ctx, "mfa@example.com", "password123", "", "")
+	require.NoError(t, err)
+	require.NotNil(t, tokens)
+
+	enrollment, err := s.EnrollTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/WDPL:mfa@example.com?")
+	assert.NotContains(t, repo.mfa[user.ID].Secret, enrollment.Secret, "секрет хранится зашифрованным")
+
+	// До подтверждения TOTP при входе не спрашивается.
+	_, tokens, err = s.Login(ctx, "mfa@example.com", "password123", "", "")
+	require.NoError(t, err)
+	require.NotNil(t, tokens)
+
+	_, err = s.ConfirmTOTP(ctx, user.ID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	codes, err := s.ConfirmTOTP(ctx, user.ID, totpCode(t, enrollment.Secret, 0))
+	require.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	_, err = s.EnrollTOTP(ctx, user.ID)
+	assert.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+
+	_, tokens, err = s.Login(ctx, "mfa@example.com", "password123", "", "")
+	assert.Nil(t, tokens)
+	challenge := mfaChallengeOf(t, err)
+	assert.False(t, challenge.Enrollment)
+
+	// Код, которым подтверждали подключение, второй раз не принимается.
+	_, _, err = s.VerifyMFA(ctx, challenge.Token, totpCode(t, enrollment.Secret, 0), "", "")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	verified, tokens, err := s.VerifyMFA(ctx, challenge.Token, totpCode(t, enrollment.Secret, 1), "", "")
+	require.NoError(t, err)
+	require.NotNil(t, tokens)
+	assert.Equal(t, user.ID, verified.ID)
+
+	// Токен второго шага одноразовый.
+	_, _, err = s.VerifyMFA(ctx, challenge.Token, codes[0], "", "")
+	assert.ErrorIs(t, err, ErrInvalidMFAChallenge)
+}
+
+func TestMFA_RecoveryCodesAreOneTime(t *testing.T) {
+	s, _, _ := newMFATestService(t, false)
+	ctx := context.Background()
+	user, _, err := s.Register(ctx, "recovery@example.com", "password123")
+	require.NoError(t, err)
+	_, codes := enableTOTP(t, s, user.ID)
+
+	login := func() string {
+		_, _, err := s.Login(ctx, "recovery@example.com", "password123", "", "")
+		return mfaChallengeOf(t, err).Token
+	}
+
+	// Регистр и дефис не важны.
+	_, tokens, err := s.VerifyMFA(ctx, login(), strings.ToUpper(strings.ReplaceAll(codes[0], "-", "")), "", "")
+	require.NoError(t, err)
+	require.NotNil(t, tokens)
+	_, _, err = s.VerifyMFA(ctx, login(), codes[0], "", "")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+
+	status, err := s.MFAStatus(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesLeft)
+
+	// Новые коды заменяют старые.
+	fresh, err := s.RegenerateRecoveryCodes(ctx, user.ID, codes[1], "", "")
+	require.NoError(t, err)
+	_, _, err = s.VerifyMFA(ctx, login(), codes[2], "", "")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	_, _, err = s.VerifyMFA(ctx, login(), fresh[0], "", "")
+	assert.NoError(t, err)
+}
+
+func TestMFA_ChallengeLockedAfterTooManyAttempts(t *testing.T) {
+	s, _, _ := newMFATestService(t, false)
+	ctx := context.Background()
+	user, _, err := s.Register(ctx, "brute@example.com", "password123")
+	require.NoError(t, err)
+	secret, _ := enableTOTP(t, s, user.ID)
+
+	_, _, err = s.Login(ctx, "brute@example.com", "password123", "", "")
+	challenge := mfaChallengeOf(t, err)
+	for range mfaMaxAttempts {
+		_, _, err = s.VerifyMFA(ctx, challenge.Token, "000000", "", "")
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+	}
+	_, _, err = s.VerifyMFA(ctx, challenge.Token, totpCode(t, secret, 1), "", "")
+	assert.ErrorIs(t, err, ErrInvalidMFAChallenge)
+}
+
+func TestMFA_DisableRequiresCode(t *testing.T) {
+	s, _, _ := newMFATestService(t, false)
+	ctx := context.Background()
+	user, _, err := s.Register(ctx, "disable@example.com", "password123")
+	require.NoError(t, err)
+	secret, _ := enableTOTP(t, s, user.ID)
+
+	assert.ErrorIs(t, s.DisableTOTP(ctx, user.ID, "000000", "", ""), ErrInvalidMFACode)
+	require.NoError(t, s.DisableTOTP(ctx, user.ID, totpCode(t, secret, 1), "", ""))
+	assert.ErrorIs(t, s.DisableTOTP(ctx, user.ID, totpCode(t, secret, 1), "", ""), ErrMFANotEnabled)
+
+	_, tokens, err := s.Login(ctx, "disable@example.com", "password123", "", "")
+	require.NoError(t, err)
+	assert.NotNil(t, tokens)
+}
+
+func TestMFA_DisableLocksAfterFailedCodes(t *testing.T) {
+	s, _, _ := newMFATestService(t, false)
+	ctx := context.Background()
+	user, _, err := s.Register(ctx, "stolen@example.com", "password123")
+	require.NoError(t, err)
+	secret, codes := enableTOTP(t, s, user.ID)
+
+	// Перебор кода с украденным access-токеном: после mfaMaxAttempts неудач не помогает и верный код.
+	for range mfaMaxAttempts {
+		assert.ErrorIs(t, s.DisableTOTP(ctx, user.ID, "000000", "", "203.0.113.7"), ErrInvalidMFACode)
+	}
+	var limited *RateLimitError
+	require.ErrorAs(t, s.DisableTOTP(ctx, user.ID, totpCode(t, secret, 1), "", "203.0.113.7"), &limited)
+	assert.Equal(t, mfaCodeLockBase, limited.RetryAfter.Round(time.Minute))
+	_, err = s.RegenerateRecoveryCodes(ctx, user.ID, codes[0], "", "203.0.113.7")
+	require.ErrorAs(t, err, &limited, "счёт общий для отключения и новых кодов восстановления")
+
+	status, err := s.MFAStatus(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+}
+
+func TestMFA_RequiredForEditorRoles(t *testing.T) {
+	s, deps, _ := newMFATestService(t, true)
+	ctx := context.Background()
+	user, _, err := s.Register(ctx, "editor@example.com", "password123")
+	require.NoError(t, err)
+
+	// Обычному пользователю второй фактор не обязателен.
+	_, tokens, err := s.Login(ctx, "editor@example.com", "password123", "", "")
+	require.NoError(t, err)
+
+	// Роль повысили: продлить старую сессию нельзя, при входе — только подключение TOTP.
+	deps.users.usersByEmail["editor@example.com"].Role = RoleEditor
+	_, err = s.Refresh(ctx, tokens.RefreshToken, "", "")
+	assert.ErrorIs(t, err, ErrMFAEnrollmentRequired)
+
+	_, _, err = s.Login(ctx, "editor@example.com", "password123", "", "")
+	challenge := mfaChallengeOf(t, err)
+	assert.True(t, challenge.Enrollment)
+	_, _, err = s.VerifyMFA(ctx, challenge.Token, "000000", "", "")
+	assert.ErrorIs(t, err, ErrInvalidMFAChallenge)
+
+	enrollment, err := s.StartMFAEnrollment(ctx, challenge.Token)
+	require.NoError(t, err)
+	_, _, _, err = s.CompleteMFAEnrollment(ctx, challenge.Token, "000000", "", "")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	signedIn, tokens, codes, err := s.CompleteMFAEnrollment(ctx, challenge.Token, totpCode(t, enrollment.Secret, 0), "", "")
+	require.NoError(t, err)
+	require.NotNil(t, tokens)
+	assert.Equal(t, user.ID, signedIn.ID)
+	assert.Len(t, codes, recoveryCodeCount)
+
+	_, err = s.Refresh(ctx, tokens.RefreshToken, "", "")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.DisableTOTP(ctx, user.ID, codes[0], "", ""), ErrMFARequiredByPolicy)
+
+	_, _, err = s.Login(ctx, "editor@example.com", "password123", "", "")
+	assert.False(t, mfaChallengeOf(t, err).Enrollment)
+}
+
+// publishersStub — права публикации через команду события или организацию (events.PublishRights).
+type publishersStub map[string]bool
+
+func (p publishersStub) CanPublish(_ context.Context, userID string) (bool, error) {
+	return p[userID], nil
+}
+
+func TestMFA_RequiredForTeamPublishers(t *testing.T) {
+	s, _, repo := newMFATestService(t, true)
+	ctx := context.Background()
+	user, _, err := s.Register(ctx, "team-editor@example.com", "password123")
+	require.NoError(t, err)
+	s.WithMFA(repo, testMFARoles, publishersStub{user.ID: true})
+
+	// Глобальная роль user, но редактор команды события: при входе — только подключение TOTP.
+	_, _, err = s.Login(ctx, "team-editor@example.com", "password123", "", "")
+	assert.True(t, mfaChallengeOf(t, err).Enrollment)
+	status, err := s.MFAStatus(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Required)
+}
+
+func TestHandler_MFA(t *testing.T) {
+	s, deps, _ := newMFATestService(t, false)
+	h := NewHandler(s)
+	app := fiber.New()
+	requireAuth := middleware.RequireAuth(s.cfg)
+	app.Post("/api/auth/sign-in", h.SignIn)
+	app.Post("/api/auth/mfa/verify", h.MFAVerify)
+	app.Get("/api/auth/mfa", requireAuth, h.MFAStatus)
+	app.Post("/api/auth/mfa/totp", requireAuth, h.EnrollTOTP)
+	app.Post("/api/auth/mfa/totp/confirm", requireAuth, h.ConfirmTOTP)
+
+	_, _, err := s.Register(context.Background(), "handler-mfa@example.com", "password123")
+	require.NoError(t, err)
+	// Неподтверждённому email RequireAuth разрешает только чтение.
+	now := time.Now()
+	deps.users.usersByEmail["handler-mfa@example.com"].EmailVerifiedAt = &now
+	_, tokens, err := s.Login(context.Background(), "handler-mfa@example.com", "password123", "", "")
+	require.NoError(t, err)
+
+	do := func(method, path string, body any, token string) *httpResult {
+		var buf bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return &httpResult{status: res.StatusCode, body: res.Body}
+	}
+
+	res := do("POST", "/api/auth/mfa/totp", nil, tokens.AccessToken)
+	require.Equal(t, fiber.StatusOK, res.status)
+	var enrollment TOTPEnrollmentResponse
+	res.decode(t, &enrollment)
+	assert.True(t, strings.HasPrefix(enrollment.QRCode, "data:image/png;base64,"))
+
+	res = do("POST", "/api/auth/mfa/totp/confirm", MFACodeRequest{Code: "000000"}, tokens.AccessToken)
+	assert.Equal(t, fiber.StatusUnauthorized, res.status)
+	res = do("POST", "/api/auth/mfa/totp/confirm", MFACodeRequest{Code: totpCode(t, enrollment.Secret, 0)}, tokens.AccessToken)
+	require.Equal(t, fiber.StatusOK, res.status)
+	var codes RecoveryCodesResponse
+	res.decode(t, &codes)
+	assert.Len(t, codes.RecoveryCodes, recoveryCodeCount)
+	res = do("POST", "/api/auth/mfa/totp", nil, tokens.AccessToken)
+	assert.Equal(t, fiber.StatusConflict, res.status)
+
+	res = do("POST", "/api/auth/sign-in", SignInRequest{Email: "handler-mfa@example.com", Password: "password123"}, "")
+	require.Equal(t, fiber.StatusOK, res.status)
+	var challenge MFAChallengeResponse
+	res.decode(t, &challenge)
+	assert.True(t, challenge.MFARequired)
+	require.NotEmpty(t, challenge.MFAToken)
+
+	res = do("POST", "/api/auth/mfa/verify", MFAVerifyRequest{MFAToken: challenge.MFAToken, Code: codes.RecoveryCodes[0]}, "")
+	require.Equal(t, fiber.StatusOK, res.status)
+	var signedIn AuthResponse
+	res.decode(t, &signedIn)
+	assert.NotEmpty(t, signedIn.AccessToken)
+
+	res = do("GET", "/api/auth/mfa", nil, signedIn.AccessToken)
+	require.Equal(t, fiber.StatusOK, res.status)
+	var status MFAStatusResponse
+	res.decode(t, &status)
+	assert.Equal(t, MFAStatusResponse{Enabled: true, RecoveryCodesLeft: recoveryCodeCount - 1}, status)
+}
+
+type httpResult struct {
+	status int
+	body   io.Reader
+}
+
+func (r *httpResult) decode(t *testing.T, v any) {
+	t.Helper()
+	require.NoError(t, json.NewDecoder(r.body).Decode(v))
+}
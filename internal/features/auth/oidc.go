@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/shared/oidc"
+)
+
+// oidcLoginTTL — сколько ждём возврата пользователя с провайдера.
+const oidcLoginTTL = 10 * time.Minute
+
+var (
+	ErrOIDCDisabled         = errors.New("oidc login is not configured")
+	ErrInvalidOIDCState     = errors.New("invalid or expired oidc login")
+	ErrOIDCLoginFailed      = errors.New("oidc login failed")
+	ErrOIDCEmailNotVerified = errors.New("email is not verified by identity provider")
+	// ErrOIDCAccountNotVerified — пользователь с этим email есть, но свой email не подтвердил: учётную запись
+	// мог завести кто угодно, поэтому привязки нет — сначала вход по паролю и подтверждение email.
+	ErrOIDCAccountNotVerified = errors.New("sign in with your password and verify your email before using single sign-on")
+)
+
+// OIDCProvider — OIDC-провайдер (реализует *oidc.Provider).
+type OIDCProvider interface {
+	Issuer() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier string) (*oidc.IDToken, error)
+}
+
+// WithOIDC включает вход через OIDC-провайдера. Без него StartOIDC и CompleteOIDC возвращают ErrOIDCDisabled.
+func (s *Service) WithOIDC(provider OIDCProvider, repo OIDCRepository) *Service {
+	s.oidc = provider
+	s.oidcRepo = repo
+	return s
+}
+
+// StartOIDC начинает вход: сохраняет state, nonce и code_verifier и возвращает адрес страницы входа провайдера
+// и хеш state — его хендлер кладёт в cookie браузера, начавшего вход (см. CompleteOIDC).
+func (s *Service) StartOIDC(ctx context.Context) (authURL, stateHash string, err error) {
+	if s.oidc == nil {
+		return "", "", ErrOIDCDisabled
+	}
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	stateHash = hashToken(state)
+	if err := s.oidcRepo.CreateOIDCLoginState(ctx, &OIDCLoginState{
+		StateHash:    stateHash,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcLoginTTL),
+	}); err != nil {
+		return "", "", err
+	}
+	authURL, err = s.oidc.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, stateHash, nil
+}
+
+// CompleteOIDC завершает вход по code и state, с которыми провайдер вернул браузер, и выдаёт наши токены.
+// Пользователь ищется по привязанной учётной записи провайдера, затем по email (только подтверждённому
+// провайдером — иначе чужой аккаунт можно занять, указав его email у провайдера); если его нет — создаётся
+// с ролью user и без пароля.
+//
+// boundStateHash — хеш state из cookie браузера, начавшего вход (StartOIDC). Без совпадения state не
+// расходуется: чужие code и state, подсунутые по ссылке, не залогинят жертву в аккаунт атакующего.
+func (s *Service) CompleteOIDC(ctx context.Context, code, state, boundStateHash, userAgent, ip string) (*User, *AuthTokens, error) {
+	if s.oidc == nil {
+		return nil, nil, ErrOIDCDisabled
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(state)), []byte(boundStateHash)) != 1 {
+		s.log.Warn("security: oidc state not bound to browser", "user_agent", userAgent, "ip", ip)
+		return nil, nil, ErrInvalidOIDCState
+	}
+	login, err := s.oidcRepo.ConsumeOIDCLoginState(ctx, hashToken(state))
+	if err != nil {
+		return nil, nil, err
+	}
+	if login == nil || time.Now().After(login.ExpiresAt) {
+		return nil, nil, ErrInvalidOIDCState
+	}
+	idToken, err := s.oidc.Exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		if errors.Is(err, oidc.ErrExchangeFailed) || errors.Is(err, oidc.ErrInvalidIDToken) {
+			s.log.Warn("security: oidc login rejected", "error", err, "user_agent", userAgent, "ip", ip)
+			return nil, nil, ErrOIDCLoginFailed
+		}
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(login.Nonce)) != 1 {
+		s.log.Warn("security: oidc nonce mismatch", "subject", idToken.Subject, "user_agent", userAgent, "ip", ip)
+		return nil, nil, ErrOIDCLoginFailed
+	}
+
+	user, err := s.oidcUser(ctx, idToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.IsActive {
+		return nil, nil, ErrUserInactive
+	}
+	// Вход через провайдера — первый фактор, как пароль: второй спрашивается так же.
+	if err := s.secondFactor(ctx, user); err != nil {
+		return nil, nil, err
+	}
+	tokens, err := s.issueTokens(ctx, user, SignInOIDC, userAgent, ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// oidcUser находит пользователя учётной записи провайдера, привязывает её по email или создаёт пользователя.
+func (s *Service) oidcUser(ctx context.Context, idToken *oidc.IDToken) (*User, error) {
+	issuer := s.oidc.Issuer()
+	userID, err := s.oidcRepo.GetUserIDByIdentity(ctx, issuer, idToken.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" {
+		user, err := s.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return user, nil
+	}
+
+	if idToken.Email == "" || !idToken.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+	identity := &UserIdentity{Issuer: issuer, Subject: idToken.Subject, Email: idToken.Email}
+	user, err := s.userRepo.GetUserByEmail(ctx, idToken.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		// Неподтверждённый email не доказывает владения: привязка отдала бы провайдеру чужую учётную
+		// запись вместе с паролем того, кто её завёл (pre-hijacking).
+		if !user.EmailVerified() {
+			s.log.Warn("oidc link refused: local email not verified", "user_id", user.ID, "issuer", issuer)
+			return nil, ErrOIDCAccountNotVerified
+		}
+		identity.UserID = user.ID
+		if err := s.oidcRepo.LinkIdentity(ctx, identity); err != nil {
+			return nil, err
+		}
+		s.log.Info("oidc identity linked", "user_id", user.ID, "issuer", issuer)
+		return user, nil
+	}
+
+	now := time.Now()
+	user = &User{
+		ID:              uuid.NewString(),
+		Email:           idToken.Email,
+		Role:            RoleUser,
+		IsActive:        true,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.oidcRepo.CreateUserWithIdentity(ctx, user, identity); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/authutils"
+	"wdpl_back/internal/shared/oidc"
+	"wdpl_back/internal/shared/oidc/oidctest"
+)
+
+// mockOIDCRepo — in-memory реализация OIDCRepository поверх mockUserRepo.
+type mockOIDCRepo struct {
+	users      *mockUserRepo
+	states     map[string]*OIDCLoginState
+	identities map[[2]string]*UserIdentity
+}
+
+func (m *mockOIDCRepo) CreateOIDCLoginState(_ context.Context, state *OIDCLoginState) error {
+	m.states[state.StateHash] = state
+	return nil
+}
+
+func (m *mockOIDCRepo) ConsumeOIDCLoginState(_ context.Context, stateHash string) (*OIDCLoginState, error) {
+	state := m.states[stateHash]
+	delete(m.states, stateHash)
+	return state, nil
+}
+
+func (m *mockOIDCRepo) GetUserIDByIdentity(_ context.Context, issuer, subject string) (string, error) {
+	if identity, ok := m.identities[[2]string{issuer, subject}]; ok {
+		return identity.UserID, nil
+	}
+	return "", nil
+}
+
+func (m *mockOIDCRepo) LinkIdentity(_ context.Context, identity *UserIdentity) error {
+	m.identities[[2]string{identity.Issuer, identity.Subject}] = identity
+	return nil
+}
+
+func (m *mockOIDCRepo) CreateUserWithIdentity(ctx context.Context, user *User, identity *UserIdentity) error {
+	if err := m.users.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	identity.UserID = user.ID
+	return m.LinkIdentity(ctx, identity)
+}
+
+// newOIDCTestService — сервис с входом через локальный OIDC-провайдер (без сети).
+func newOIDCTestService(t *testing.T) (*Service, *testDeps, *oidctest.Server) {
+	t.Helper()
+	s, deps := newTestServiceDeps(t)
+	provider := oidctest.NewServer(t)
+	repo := &mockOIDCRepo{users: deps.users, states: map[string]*OIDCLoginState{}, identities: map[[2]string]*UserIdentity{}}
+	s.WithOIDC(oidc.NewProvider(provider.Config("https://app.example.com/auth/oidc/callback"), provider.Client()), repo)
+	return s, deps, provider
+}
+
+// oidcLogin проходит вход целиком: start, вход user у провайдера, callback.
+func oidcLogin(t *testing.T, s *Service, provider *oidctest.Server, user oidctest.User) (*User, *AuthTokens, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, stateHash, err := s.StartOIDC(ctx)
+	require.NoError(t, err)
+	code, state := provider.Authorize(t, authURL, user)
+	return s.CompleteOIDC(ctx, code, state, stateHash, "test-agent", "127.0.0.1")
+}
+
+func TestOIDC_CreatesUserAndIssuesTokens(t *testing.T) {
+	s, deps, provider := newOIDCTestService(t)
+
+	user, tokens, err := oidcLogin(t, s, provider, oidctest.User{Subject: "sub-1", Email: "new@corp.example", EmailVerified: true})
+	require.NoError(t, err)
+	require.NotNil(t, tokens)
+	assert.Equal(t, RoleUser, user.Role)
+	assert.True(t, user.EmailVerified())
+	assert.Empty(t, user.PasswordHash)
+
+	claims, err := authutils.ParseAccessToken(s.cfg, tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+	assert.False(t, claims.EmailUnverified)
+	assert.Len(t, deps.refresh.tokensByValue, 1)
+
+	// Повторный вход — тот же пользователь по привязке, даже если email у провайдера сменился.
+	again, _, err := oidcLogin(t, s, provider, oidctest.User{Subject: "sub-1", Email: "renamed@corp.example", EmailVerified: true})
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+
+	// Входа по паролю у такого пользователя нет.
+	_, _, err = s.Login(context.Background(), "new@corp.example", "", "", "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestOIDC_LinksExistingUserByVerifiedEmail(t *testing.T) {
+	s, _, provider := newOIDCTestService(t)
+	existing, _, err := s.Register(context.Background(), "editor@corp.example", "password123")
+	require.NoError(t, err)
+	verified := time.Now()
+	existing.EmailVerifiedAt = &verified
+
+	_, _, err = oidcLogin(t, s, provider, oidctest.User{Subject: "sub-2", Email: "editor@corp.example", EmailVerified: false})
+	assert.ErrorIs(t, err, ErrOIDCEmailNotVerified)
+
+	user, _, err := oidcLogin(t, s, provider, oidctest.User{Subject: "sub-2", Email: "Editor@Corp.example", EmailVerified: true})
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, user.ID)
+}
+
+func TestOIDC_RefusesToLinkUnverifiedLocalAccount(t *testing.T) {
+	s, deps, provider := newOIDCTestService(t)
+	ctx := context.Background()
+	// Злоумышленник заранее регистрирует чужой email со своим паролем и не подтверждает его.
+	squatter, _, err := s.Register(ctx, "victim@corp.example", "attacker-password")
+	require.NoError(t, err)
+	require.False(t, squatter.EmailVerified())
+
+	_, _, err = oidcLogin(t, s, provider, oidctest.User{Subject: "victim-sub", Email: "victim@corp.example", EmailVerified: true})
+	require.ErrorIs(t, err, ErrOIDCAccountNotVerified)
+
+	// Учётная запись провайдера не привязана, email не стал подтверждённым.
+	stored, err := deps.users.GetUserByEmail(ctx, "victim@corp.example")
+	require.NoError(t, err)
+	assert.False(t, stored.EmailVerified())
+	userID, err := s.oidcRepo.GetUserIDByIdentity(ctx, s.oidc.Issuer(), "victim-sub")
+	require.NoError(t, err)
+	assert.Empty(t, userID)
+}
+
+func TestOIDC_RejectsReusedStateAndForeignCode(t *testing.T) {
+	s, deps, provider := newOIDCTestService(t)
+	ctx := context.Background()
+	alice := oidctest.User{Subject: "sub-3", Email: "alice@corp.example", EmailVerified: true}
+
+	authURL, stateHash, err := s.StartOIDC(ctx)
+	require.NoError(t, err)
+	code, state := provider.Authorize(t, authURL, alice)
+	_, _, err = s.CompleteOIDC(ctx, code, state, stateHash, "", "")
+	require.NoError(t, err)
+	_, _, err = s.CompleteOIDC(ctx, code, state, stateHash, "", "")
+	assert.ErrorIs(t, err, ErrInvalidOIDCState)
+
+	// Code, выданный под чужой code_challenge, с нашим state не обменивается (PKCE).
+	foreignURL, _, err := s.StartOIDC(ctx)
+	require.NoError(t, err)
+	foreignCode, _ := provider.Authorize(t, foreignURL, alice)
+	ownURL, ownHash, err := s.StartOIDC(ctx)
+	require.NoError(t, err)
+	_, ownState := provider.Authorize(t, ownURL, alice)
+	_, _, err = s.CompleteOIDC(ctx, foreignCode, ownState, ownHash, "", "")
+	assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+
+	deps.users.usersByEmail["alice@corp.example"].IsActive = false
+	_, _, err = oidcLogin(t, s, provider, alice)
+	assert.ErrorIs(t, err, ErrUserInactive)
+}
+
+func TestOIDC_RejectsStateFromAnotherBrowser(t *testing.T) {
+	s, _, provider := newOIDCTestService(t)
+	ctx := context.Background()
+
+	// Атакующий начинает вход у себя и подсовывает жертве свои code и state; у жертвы — cookie своего входа.
+	attackerURL, _, err := s.StartOIDC(ctx)
+	require.NoError(t, err)
+	code, state := provider.Authorize(t, attackerURL, oidctest.User{Subject: "sub-5", Email: "mallory@corp.example", EmailVerified: true})
+	_, victimHash, err := s.StartOIDC(ctx)
+	require.NoError(t, err)
+
+	_, _, err = s.CompleteOIDC(ctx, code, state, victimHash, "", "")
+	assert.ErrorIs(t, err, ErrInvalidOIDCState)
+	_, _, err = s.CompleteOIDC(ctx, code, state, "", "", "")
+	assert.ErrorIs(t, err, ErrInvalidOIDCState)
+}
+
+func TestHandler_OIDC(t *testing.T) {
+	s, _, provider := newOIDCTestService(t)
+	h := NewHandler(s)
+	app := fiber.New()
+	app.Post("/api/auth/oidc/start", h.OIDCStart)
+	app.Post("/api/auth/oidc/callback", h.OIDCCallback)
+
+	res, err := app.Test(httptest.NewRequest("POST", "/api/auth/oidc/start", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var start OIDCStartResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&start))
+	var stateCookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == oidcStateCookieName {
+			stateCookie = c
+		}
+	}
+	require.NotNil(t, stateCookie)
+	assert.True(t, stateCookie.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, stateCookie.SameSite)
+
+	code, state := provider.Authorize(t, start.AuthorizationURL, oidctest.User{Subject: "sub-4", Email: "sso@corp.example", EmailVerified: true})
+	body, _ := json.Marshal(OIDCCallbackRequest{Code: code, State: state})
+
+	// Без cookie браузера, начавшего вход, — 401, и state не расходуется.
+	req := httptest.NewRequest("POST", "/api/auth/oidc/callback", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+
+	req = httptest.NewRequest("POST", "/api/auth/oidc/callback", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: stateCookie.Name, Value: stateCookie.Value})
+	res, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var resp AuthResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	assert.Equal(t, "sso@corp.example", resp.Email)
+	assert.NotEmpty(t, resp.AccessToken)
+
+	// Тот же state второй раз — 401.
+	req = httptest.NewRequest("POST", "/api/auth/oidc/callback", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: stateCookie.Name, Value: stateCookie.Value})
+	res, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+}
+
+func TestHandler_OIDCDisabled(t *testing.T) {
+	s, _, _ := newTestService(t)
+	app := fiber.New()
+	app.Post("/api/auth/oidc/start", NewHandler(s).OIDCStart)
+
+	res, err := app.Test(httptest.NewRequest("POST", "/api/auth/oidc/start", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+}
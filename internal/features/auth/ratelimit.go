@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"wdpl_back/internal/shared/ratelimit"
+)
+
+// Защита входа от перебора пароля. Лимиты по IP на sign-in, sign-up и refresh — middleware.RateLimit в router.go.
+const (
+	// signInAccountLimit — попыток входа на один email за signInAccountWindow (с любых адресов).
+	signInAccountLimit  = 10
+	signInAccountWindow = 15 * time.Minute
+	// Блокировка после LOGIN_LOCKOUT_THRESHOLD неверных паролей подряд: 1 мин, дальше вдвое дольше, до часа.
+	// Сутки без неудач — счёт сначала.
+	signInLockBase  = time.Minute
+	signInLockMax   = time.Hour
+	signInLockReset = 24 * time.Hour
+	// Неверных паролей, после которых каждая следующая неудача пишется в лог как всплеск:
+	// с одного IP за 10 минут и со всех адресов за минуту.
+	failureSpikePerIP = 20
+	failureSpikeTotal = 100
+)
+
+// WithRateLimitStore задаёт хранилище лимитов и блокировок (общее для реплик — ratelimit.PostgresStore).
+func (s *Service) WithRateLimitStore(store ratelimit.Store) *Service {
+	s.limits = store
+	return s
+}
+
+// signInAccountKey — ключ лимитов аккаунта. Email, а не id: неизвестный email ограничивается так же,
+// и по ответам нельзя понять, зарегистрирован ли он.
+func signInAccountKey(email string) string {
+	return "sign-in:account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// signInAllowed — аккаунт не заблокирован и не исчерпал лимит попыток; иначе *RateLimitError.
+func (s *Service) signInAllowed(ctx context.Context, account string) error {
+	lockedFor, err := s.limits.LockedFor(ctx, account)
+	if err != nil {
+		return err
+	}
+	if lockedFor > 0 {
+		return &RateLimitError{RetryAfter: lockedFor}
+	}
+	ok, retryAfter, err := s.limits.Allow(ctx, account, signInAccountLimit, signInAccountWindow)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// signInFailed учитывает неверный пароль: блокирует аккаунт по порогу и пишет в лог блокировки и всплески неудач.
+// LOGIN_LOCKOUT_THRESHOLD=0 — без блокировок.
+func (s *Service) signInFailed(ctx context.Context, email, ip string) error {
+	if s.cfg.LoginLockoutAfter > 0 {
+		lockedFor, err := s.limits.RecordFailure(ctx, signInAccountKey(email), ratelimit.LockoutPolicy{
+			Threshold:  s.cfg.LoginLockoutAfter,
+			BaseLock:   signInLockBase,
+			MaxLock:    signInLockMax,
+			ResetAfter: signInLockReset,
+		})
+		if err != nil {
+			return err
+		}
+		if lockedFor > 0 {
+			s.log.Warn("security: account locked after failed sign-ins",
+				"email", strings.ToLower(strings.TrimSpace(email)), "locked_for", lockedFor.String(), "ip", ip)
+		}
+	}
+	if ok, _, err := s.limits.Allow(ctx, "sign-in-failures:ip:"+ip, failureSpikePerIP, 10*time.Minute); err != nil {
+		return err
+	} else if !ok {
+		s.log.Warn("security: sign-in failure spike from ip", "ip", ip, "threshold", failureSpikePerIP)
+	}
+	if ok, _, err := s.limits.Allow(ctx, "sign-in-failures:all", failureSpikeTotal, time.Minute); err != nil {
+		return err
+	} else if !ok {
+		s.log.Warn("security: sign-in failure spike", "threshold_per_minute", failureSpikeTotal, "ip", ip)
+	}
+	return nil
+}
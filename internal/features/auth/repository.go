@@ -1,17 +1,131 @@
 package auth
 
-import "context"
+import (
+	"context"
+
+	"wdpl_back/internal/shared/audit"
+)
+
+// UserFilter — условия списка пользователей. Пустые поля не ограничивают выборку.
+type UserFilter struct {
+	// Search — подстрока email без учёта регистра.
+	Search   string
+	Role     string
+	IsActive *bool
+	Limit    int
+	Offset   int
+}
 
 // UserRepository описывает операции с пользователями.
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *User) error
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByID(ctx context.Context, userID string) (*User, error)
+	// LockUser — как GetUserByID, но в транзакции блокирует строку пользователя (FOR UPDATE) до её конца:
+	// изменение читает актуальное состояние, а не перезаписывает параллельное.
+	LockUser(ctx context.Context, userID string) (*User, error)
+	// LockActiveAdmins блокирует в транзакции строки активных администраторов и возвращает их id:
+	// два параллельных понижения не оставят систему без администраторов.
+	LockActiveAdmins(ctx context.Context) ([]string, error)
+	// ListUsers возвращает пользователей по фильтру (в том числе поиск по email), новые — первыми.
+	ListUsers(ctx context.Context, filter UserFilter) ([]*User, error)
+	// UpdateUser сохраняет роль и активность; TokenGeneration и UpdatedAt берёт из БД
+	// (поколение растёт триггером при их смене).
+	UpdateUser(ctx context.Context, user *User) error
+	// BumpTokenGeneration увеличивает поколение токенов: уже выданные access-токены перестают приниматься.
+	BumpTokenGeneration(ctx context.Context, userID string) error
 }
 
 // RefreshTokenRepository описывает операции с refresh‑токенами.
 type RefreshTokenRepository interface {
 	CreateRefreshToken(ctx context.Context, token *RefreshToken) error
-	GetRefreshToken(ctx context.Context, token string) (*RefreshToken, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
 	RevokeRefreshToken(ctx context.Context, tokenID string) error
+	// RotateRefreshToken в одной транзакции гасит токен oldID (replaced_by = next.ID) и сохраняет next.
+	// false — oldID уже отозван или заменён (например, параллельным refresh).
+	RotateRefreshToken(ctx context.Context, oldID string, next *RefreshToken) (bool, error)
+	// RevokeRefreshTokenFamily отзывает все действующие токены семьи.
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	// ListSessions возвращает активные сессии пользователя (семьи с неотозванным и не истёкшим токеном),
+	// последние использованные — первыми.
+	ListSessions(ctx context.Context, userID string) ([]*Session, error)
+	// RevokeSession отзывает сессию пользователя. false — у пользователя нет такой активной сессии.
+	RevokeSession(ctx context.Context, userID, sessionID string) (bool, error)
+	// RevokeOtherSessions отзывает все сессии пользователя, кроме exceptSessionID; возвращает число отозванных.
+	RevokeOtherSessions(ctx context.Context, userID, exceptSessionID string) (int, error)
+	// RevokeAllSessions отзывает все сессии пользователя; возвращает число отозванных.
+	RevokeAllSessions(ctx context.Context, userID string) (int, error)
+}
+
+// PasswordResetRepository описывает операции с токенами сброса пароля.
+type PasswordResetRepository interface {
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
+	// ResetPassword в одной транзакции гасит токен (и остальные токены сброса пользователя),
+	// меняет хеш пароля, увеличивает поколение токенов и отзывает все refresh-токены. false — токен уже использован.
+	ResetPassword(ctx context.Context, tokenID, userID, passwordHash string) (bool, error)
+}
+
+// EmailVerificationRepository описывает операции с токенами подтверждения email.
+type EmailVerificationRepository interface {
+	CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken) error
+	GetEmailVerificationToken(ctx context.Context, tokenHash string) (*EmailVerificationToken, error)
+	// VerifyEmail в одной транзакции гасит токен (и остальные токены подтверждения пользователя)
+	// и выставляет email_verified_at. false — токен уже использован.
+	VerifyEmail(ctx context.Context, tokenID, userID string) (bool, error)
+}
+
+// TxRepositories — репозитории, привязанные к одной транзакции (см. Transactor).
+// Audit пишет журнал аудита в ту же транзакцию, что и изменение.
+type TxRepositories struct {
+	Users         UserRepository
+	RefreshTokens RefreshTokenRepository
+	Audit         audit.Writer
+}
+
+// Transactor выполняет fn в транзакции: ошибка fn — откат, иначе COMMIT.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(repos *TxRepositories) error) error
+}
+
+// OIDCRepository описывает незавершённые входы через OIDC и привязки учётных записей провайдера.
+type OIDCRepository interface {
+	CreateOIDCLoginState(ctx context.Context, state *OIDCLoginState) error
+	// ConsumeOIDCLoginState забирает (удаляет) вход по хешу state; nil — нет такого или уже забран.
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*OIDCLoginState, error)
+	// GetUserIDByIdentity возвращает пользователя учётной записи провайдера; "" — не привязана.
+	GetUserIDByIdentity(ctx context.Context, issuer, subject string) (string, error)
+	// LinkIdentity привязывает учётную запись к существующему пользователю с подтверждённым email.
+	LinkIdentity(ctx context.Context, identity *UserIdentity) error
+	// CreateUserWithIdentity в одной транзакции создаёт пользователя и привязывает к нему учётную запись.
+	CreateUserWithIdentity(ctx context.Context, user *User, identity *UserIdentity) error
+}
+
+// MFARepository описывает TOTP пользователей, коды восстановления и незавершённые входы со вторым фактором.
+type MFARepository interface {
+	// GetMFA возвращает TOTP пользователя; nil — не подключался.
+	GetMFA(ctx context.Context, userID string) (*UserMFA, error)
+	// SaveMFASecret начинает подключение: сохраняет (или заменяет неподтверждённый) секрет.
+	// false — TOTP уже подключён, секрет не меняется.
+	SaveMFASecret(ctx context.Context, userID, secret string) (bool, error)
+	// EnableMFA в одной транзакции подтверждает подключение (enabled_at, last_used_step)
+	// и заменяет коды восстановления. false — подключение не начато или уже подтверждено.
+	EnableMFA(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) (bool, error)
+	// DisableMFA удаляет TOTP и коды восстановления пользователя.
+	DisableMFA(ctx context.Context, userID string) error
+	// UseMFAStep запоминает шаг принятого кода. false — шаг не новее последнего (код уже использован).
+	UseMFAStep(ctx context.Context, userID string, step int64) (bool, error)
+	// UseRecoveryCode гасит неиспользованный код восстановления. false — нет такого или уже использован.
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	// ReplaceRecoveryCodes заменяет все коды восстановления пользователя новыми.
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// CountRecoveryCodes — сколько неиспользованных кодов восстановления осталось.
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+	CreateMFAChallenge(ctx context.Context, challenge *MFAChallenge) error
+	// GetMFAChallenge возвращает вход по хешу токена; nil — нет такого.
+	GetMFAChallenge(ctx context.Context, tokenHash string) (*MFAChallenge, error)
+	// FailMFAChallenge учитывает неверный код.
+	FailMFAChallenge(ctx context.Context, id string) error
+	// ConsumeMFAChallenge гасит вход. false — уже использован.
+	ConsumeMFAChallenge(ctx context.Context, id string) (bool, error)
 }
@@ -4,19 +4,62 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"wdpl_back/internal/shared/audit"
 	"wdpl_back/internal/shared/postgres"
 )
 
+// querier — общее у *sql.DB и *sql.Tx: одни и те же запросы работают и в транзакции, и без неё.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type postgresRepository struct {
 	db *postgres.DB
+	// q — соединение db или транзакция, если репозиторий получен в InTx.
+	q  querier
+	tx *sql.Tx
 }
 
 func NewPostgresRepository(db *postgres.DB) *postgresRepository {
-	return &postgresRepository{db: db}
+	return &postgresRepository{db: db, q: db}
+}
+
+// InTx выполняет fn в транзакции: ошибка fn — откат, иначе COMMIT. Внутри уже открытой транзакции
+// fn работает в ней же.
+func (r *postgresRepository) InTx(ctx context.Context, fn func(repos *TxRepositories) error) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		txRepo := &postgresRepository{db: r.db, q: tx, tx: tx}
+		return fn(&TxRepositories{
+			Users:         txRepo,
+			RefreshTokens: txRepo,
+			Audit:         audit.NewPostgresWriter(tx),
+		})
+	})
+}
+
+// withTx выполняет fn в текущей транзакции репозитория или в новой.
+func (r *postgresRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *postgresRepository) CreateUser(ctx context.Context, user *User) error {
@@ -30,48 +73,127 @@ func (r *postgresRepository) CreateUser(ctx context.Context, user *User) error {
 	}
 	user.UpdatedAt = now
 
-	_, err := r.db.ExecContext(ctx, `
+	_, err := r.q.ExecContext(ctx, `
 		INSERT INTO auth.users (
-			id, email, password_hash, role, is_active, supabase_user_id, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, user.ID, user.Email, user.PasswordHash, user.Role, user.IsActive, user.SupabaseUserID, user.CreatedAt, user.UpdatedAt)
+			id, email, password_hash, role, is_active, supabase_user_id, email_verified_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, user.ID, user.Email, user.PasswordHash, user.Role, user.IsActive, user.SupabaseUserID, user.EmailVerifiedAt, user.CreatedAt, user.UpdatedAt)
 	return err
 }
 
 func (r *postgresRepository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
-	row := r.db.QueryRowContext(ctx, `
-		SELECT id, email, password_hash, role, is_active, supabase_user_id, created_at, updated_at
+	row := r.q.QueryRowContext(ctx, `
+		SELECT `+userColumns+`
 		FROM auth.users
 		WHERE email = $1
 	`, email)
 
-	var u User
-	err := row.Scan(
-		&u.ID,
-		&u.Email,
-		&u.PasswordHash,
-		&u.Role,
-		&u.IsActive,
-		&u.SupabaseUserID,
-		&u.CreatedAt,
-		&u.UpdatedAt,
-	)
+	u, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return u, err
+}
+
+func (r *postgresRepository) GetUserByID(ctx context.Context, userID string) (*User, error) {
+	row := r.q.QueryRowContext(ctx, `
+		SELECT `+userColumns+`
+		FROM auth.users
+		WHERE id = $1
+	`, userID)
+
+	u, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return u, err
+}
+
+func (r *postgresRepository) LockUser(ctx context.Context, userID string) (*User, error) {
+	row := r.q.QueryRowContext(ctx, `
+		SELECT `+userColumns+`
+		FROM auth.users
+		WHERE id = $1
+		FOR UPDATE
+	`, userID)
+
+	u, err := scanUser(row)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
+	return u, err
+}
+
+func (r *postgresRepository) LockActiveAdmins(ctx context.Context) ([]string, error) {
+	// Порядок по id — одинаковый порядок блокировок у параллельных транзакций, без взаимоблокировок.
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT id
+		FROM auth.users
+		WHERE role = $1 AND is_active
+		ORDER BY id
+		FOR UPDATE
+	`, RoleAdmin)
 	if err != nil {
 		return nil, err
 	}
-	return &u, nil
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
 }
 
-func (r *postgresRepository) GetUserByID(ctx context.Context, userID string) (*User, error) {
-	row := r.db.QueryRowContext(ctx, `
-		SELECT id, email, password_hash, role, is_active, supabase_user_id, created_at, updated_at
+func (r *postgresRepository) ListUsers(ctx context.Context, filter UserFilter) ([]*User, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+userColumns+`
 		FROM auth.users
-		WHERE id = $1
+		WHERE ($1 = '' OR email ILIKE '%' || $1 || '%')
+		  AND ($2 = '' OR role = $2)
+		  AND ($3::boolean IS NULL OR is_active = $3)
+		ORDER BY created_at DESC, id
+		LIMIT $4 OFFSET $5
+	`, escapeLike(filter.Search), filter.Role, filter.IsActive, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, u)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresRepository) UpdateUser(ctx context.Context, user *User) error {
+	return r.q.QueryRowContext(ctx, `
+		UPDATE auth.users SET role = $1, is_active = $2, updated_at = now()
+		WHERE id = $3
+		RETURNING token_generation, updated_at
+	`, user.Role, user.IsActive, user.ID).Scan(&user.TokenGeneration, &user.UpdatedAt)
+}
+
+func (r *postgresRepository) BumpTokenGeneration(ctx context.Context, userID string) error {
+	_, err := r.q.ExecContext(ctx, `
+		UPDATE auth.users SET token_generation = token_generation + 1, updated_at = now() WHERE id = $1
 	`, userID)
+	return err
+}
 
+// userColumns — колонки auth.users в порядке scanUser.
+const userColumns = `id, email, password_hash, role, is_active, supabase_user_id, email_verified_at, token_generation, created_at, updated_at`
+
+// scanUser читает строку с колонками userColumns.
+func scanUser(row interface{ Scan(dest ...any) error }) (*User, error) {
 	var u User
 	err := row.Scan(
 		&u.ID,
@@ -80,50 +202,65 @@ func (r *postgresRepository) GetUserByID(ctx context.Context, userID string) (*U
 		&u.Role,
 		&u.IsActive,
 		&u.SupabaseUserID,
+		&u.EmailVerifiedAt,
+		&u.TokenGeneration,
 		&u.CreatedAt,
 		&u.UpdatedAt,
 	)
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil
-	}
 	if err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
 
+// escapeLike экранирует спецсимволы LIKE, чтобы поиск шёл по подстроке как есть.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 func (r *postgresRepository) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	return insertRefreshToken(ctx, r.q, token)
+}
+
+// insertRefreshToken сохраняет токен через db или транзакцию. Без FamilyID токен открывает новую семью.
+func insertRefreshToken(ctx context.Context, q querier, token *RefreshToken) error {
 	if token.ID == "" {
 		token.ID = uuid.NewString()
 	}
+	if token.FamilyID == "" {
+		token.FamilyID = token.ID
+	}
 	if token.CreatedAt.IsZero() {
 		token.CreatedAt = time.Now()
 	}
 
-	_, err := r.db.ExecContext(ctx, `
+	_, err := q.ExecContext(ctx, `
 		INSERT INTO auth.refresh_tokens (
-			id, user_id, token, expires_at, revoked_at, user_agent, ip, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, token.ID, token.UserID, token.Token, token.ExpiresAt, token.RevokedAt, token.UserAgent, token.IP, token.CreatedAt)
+			id, user_id, family_id, token_hash, expires_at, revoked_at, user_agent, ip, last_used_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.RevokedAt, token.UserAgent, token.IP, token.LastUsedAt, token.CreatedAt)
 	return err
 }
 
-func (r *postgresRepository) GetRefreshToken(ctx context.Context, token string) (*RefreshToken, error) {
-	row := r.db.QueryRowContext(ctx, `
-		SELECT id, user_id, token, expires_at, revoked_at, user_agent, ip, created_at
+func (r *postgresRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	row := r.q.QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by, user_agent, ip, last_used_at, created_at
 		FROM auth.refresh_tokens
-		WHERE token = $1
-	`, token)
+		WHERE token_hash = $1
+	`, tokenHash)
 
 	var rt RefreshToken
 	err := row.Scan(
 		&rt.ID,
 		&rt.UserID,
-		&rt.Token,
+		&rt.FamilyID,
+		&rt.TokenHash,
 		&rt.ExpiresAt,
 		&rt.RevokedAt,
+		&rt.ReplacedBy,
 		&rt.UserAgent,
 		&rt.IP,
+		&rt.LastUsedAt,
 		&rt.CreatedAt,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -137,10 +274,459 @@ func (r *postgresRepository) GetRefreshToken(ctx context.Context, token string)
 
 func (r *postgresRepository) RevokeRefreshToken(ctx context.Context, tokenID string) error {
 	now := time.Now()
-	_, err := r.db.ExecContext(ctx, `
+	_, err := r.q.ExecContext(ctx, `
 		UPDATE auth.refresh_tokens
 		SET revoked_at = $1
 		WHERE id = $2 AND revoked_at IS NULL
 	`, now, tokenID)
 	return err
 }
+
+func (r *postgresRepository) RotateRefreshToken(ctx context.Context, oldID string, next *RefreshToken) (bool, error) {
+	if next.ID == "" {
+		next.ID = uuid.NewString()
+	}
+	var rotated bool
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		// Условие revoked_at IS NULL: из двух параллельных refresh одним токеном проходит только один.
+		res, err := tx.ExecContext(ctx, `
+			UPDATE auth.refresh_tokens SET revoked_at = $1, replaced_by = $2, last_used_at = $1
+			WHERE id = $3 AND revoked_at IS NULL
+		`, time.Now(), next.ID, oldID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		if err := insertRefreshToken(ctx, tx, next); err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated && err == nil, err
+}
+
+func (r *postgresRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.q.ExecContext(ctx, `
+		UPDATE auth.refresh_tokens
+		SET revoked_at = $1
+		WHERE family_id = $2 AND revoked_at IS NULL
+	`, time.Now(), familyID)
+	return err
+}
+
+func (r *postgresRepository) ListSessions(ctx context.Context, userID string) ([]*Session, error) {
+	// В семье действует не больше одного токена (ротация), начало сессии — первый токен семьи.
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT t.family_id, t.user_id, t.user_agent, t.ip,
+		       (SELECT MIN(f.created_at) FROM auth.refresh_tokens f WHERE f.family_id = t.family_id),
+		       COALESCE(t.last_used_at, t.created_at), t.expires_at
+		FROM auth.refresh_tokens t
+		WHERE t.user_id = $1 AND t.revoked_at IS NULL AND t.expires_at > now()
+		ORDER BY COALESCE(t.last_used_at, t.created_at) DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*Session
+	for rows.Next() {
+		var s Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &s)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresRepository) RevokeSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	res, err := r.q.ExecContext(ctx, `
+		UPDATE auth.refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND family_id::text = $3 AND revoked_at IS NULL AND expires_at > $1
+	`, time.Now(), userID, sessionID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *postgresRepository) RevokeOtherSessions(ctx context.Context, userID, exceptSessionID string) (int, error) {
+	res, err := r.q.ExecContext(ctx, `
+		UPDATE auth.refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND family_id::text <> $3 AND revoked_at IS NULL AND expires_at > $1
+	`, time.Now(), userID, exceptSessionID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *postgresRepository) RevokeAllSessions(ctx context.Context, userID string) (int, error) {
+	res, err := r.q.ExecContext(ctx, `
+		UPDATE auth.refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL AND expires_at > $1
+	`, time.Now(), userID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *postgresRepository) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error {
+	if token.ID == "" {
+		token.ID = uuid.NewString()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO auth.password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	return err
+}
+
+func (r *postgresRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error) {
+	var t PasswordResetToken
+	err := r.q.QueryRowContext(ctx, `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM auth.password_reset_tokens
+		WHERE token_hash = $1
+	`, tokenHash).Scan(&t.ID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *postgresRepository) ResetPassword(ctx context.Context, tokenID, userID, passwordHash string) (bool, error) {
+	var reset bool
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		now := time.Now()
+		// Условие used_at IS NULL делает токен одноразовым и при параллельных запросах: второй получит 0 строк.
+		res, err := tx.ExecContext(ctx, `
+			UPDATE auth.password_reset_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL
+		`, now, tokenID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		// Остальные выданные ссылки тоже гасим: после смены пароля они не нужны.
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE auth.password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL
+		`, now, userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE auth.users SET password_hash = $1, token_generation = token_generation + 1, updated_at = $2 WHERE id = $3
+		`, passwordHash, now, userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE auth.refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL
+		`, now, userID); err != nil {
+			return err
+		}
+		reset = true
+		return nil
+	})
+	return reset && err == nil, err
+}
+
+func (r *postgresRepository) CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken) error {
+	if token.ID == "" {
+		token.ID = uuid.NewString()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO auth.email_verification_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	return err
+}
+
+func (r *postgresRepository) GetEmailVerificationToken(ctx context.Context, tokenHash string) (*EmailVerificationToken, error) {
+	var t EmailVerificationToken
+	err := r.q.QueryRowContext(ctx, `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM auth.email_verification_tokens
+		WHERE token_hash = $1
+	`, tokenHash).Scan(&t.ID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *postgresRepository) VerifyEmail(ctx context.Context, tokenID, userID string) (bool, error) {
+	var verified bool
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		now := time.Now()
+		res, err := tx.ExecContext(ctx, `
+			UPDATE auth.email_verification_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL
+		`, now, tokenID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE auth.email_verification_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL
+		`, now, userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE auth.users SET email_verified_at = COALESCE(email_verified_at, $1), updated_at = $1 WHERE id = $2
+		`, now, userID); err != nil {
+			return err
+		}
+		verified = true
+		return nil
+	})
+	return verified && err == nil, err
+}
+
+func (r *postgresRepository) CreateOIDCLoginState(ctx context.Context, state *OIDCLoginState) error {
+	// Брошенные входы (пользователь не вернулся с провайдера) убираем здесь же.
+	if _, err := r.q.ExecContext(ctx, `DELETE FROM auth.oidc_login_states WHERE expires_at < now()`); err != nil {
+		return err
+	}
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO auth.oidc_login_states (state_hash, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, state.StateHash, state.CodeVerifier, state.Nonce, state.ExpiresAt)
+	return err
+}
+
+func (r *postgresRepository) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*OIDCLoginState, error) {
+	var s OIDCLoginState
+	err := r.q.QueryRowContext(ctx, `
+		DELETE FROM auth.oidc_login_states WHERE state_hash = $1
+		RETURNING state_hash, code_verifier, nonce, expires_at
+	`, stateHash).Scan(&s.StateHash, &s.CodeVerifier, &s.Nonce, &s.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *postgresRepository) GetUserIDByIdentity(ctx context.Context, issuer, subject string) (string, error) {
+	var userID string
+	err := r.q.QueryRowContext(ctx, `
+		SELECT user_id FROM auth.user_identities WHERE issuer = $1 AND subject = $2
+	`, issuer, subject).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return userID, err
+}
+
+func (r *postgresRepository) LinkIdentity(ctx context.Context, identity *UserIdentity) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return insertIdentity(ctx, tx, identity)
+	})
+}
+
+func (r *postgresRepository) CreateUserWithIdentity(ctx context.Context, user *User, identity *UserIdentity) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		txRepo := &postgresRepository{db: r.db, q: tx, tx: tx}
+		if err := txRepo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return insertIdentity(ctx, tx, identity)
+	})
+}
+
+func insertIdentity(ctx context.Context, tx *sql.Tx, identity *UserIdentity) error {
+	if identity.CreatedAt.IsZero() {
+		identity.CreatedAt = time.Now()
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO auth.user_identities (issuer, subject, user_id, email, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, identity.Issuer, identity.Subject, identity.UserID, identity.Email, identity.CreatedAt)
+	return err
+}
+
+func (r *postgresRepository) GetMFA(ctx context.Context, userID string) (*UserMFA, error) {
+	var m UserMFA
+	err := r.q.QueryRowContext(ctx, `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at FROM auth.user_mfa WHERE user_id = $1
+	`, userID).Scan(&m.UserID, &m.Secret, &m.EnabledAt, &m.LastUsedStep, &m.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *postgresRepository) SaveMFASecret(ctx context.Context, userID, secret string) (bool, error) {
+	res, err := r.q.ExecContext(ctx, `
+		INSERT INTO auth.user_mfa (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+		WHERE auth.user_mfa.enabled_at IS NULL
+	`, userID, secret)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *postgresRepository) EnableMFA(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) (bool, error) {
+	var enabled bool
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE auth.user_mfa SET enabled_at = now(), last_used_step = $2 WHERE user_id = $1 AND enabled_at IS NULL
+		`, userID, step)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+			return err
+		}
+		enabled = true
+		return nil
+	})
+	return enabled && err == nil, err
+}
+
+func (r *postgresRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, hashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM auth.mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO auth.mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)
+		`, userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *postgresRepository) DisableMFA(ctx context.Context, userID string) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM auth.mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM auth.user_mfa WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+func (r *postgresRepository) UseMFAStep(ctx context.Context, userID string, step int64) (bool, error) {
+	res, err := r.q.ExecContext(ctx, `
+		UPDATE auth.user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *postgresRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	res, err := r.q.ExecContext(ctx, `
+		UPDATE auth.mfa_recovery_codes SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *postgresRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.q.QueryRowContext(ctx, `
+		SELECT count(*) FROM auth.mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL
+	`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *postgresRepository) CreateMFAChallenge(ctx context.Context, challenge *MFAChallenge) error {
+	// Брошенные входы убираем здесь же, как и у OIDC.
+	if _, err := r.q.ExecContext(ctx, `DELETE FROM auth.mfa_challenges WHERE expires_at < now()`); err != nil {
+		return err
+	}
+	if challenge.ID == "" {
+		challenge.ID = uuid.NewString()
+	}
+	if challenge.CreatedAt.IsZero() {
+		challenge.CreatedAt = time.Now()
+	}
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO auth.mfa_challenges (id, token_hash, user_id, purpose, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, challenge.ID, challenge.TokenHash, challenge.UserID, challenge.Purpose, challenge.ExpiresAt, challenge.CreatedAt)
+	return err
+}
+
+func (r *postgresRepository) GetMFAChallenge(ctx context.Context, tokenHash string) (*MFAChallenge, error) {
+	var c MFAChallenge
+	err := r.q.QueryRowContext(ctx, `
+		SELECT id, token_hash, user_id, purpose, attempts, expires_at, used_at, created_at
+		FROM auth.mfa_challenges WHERE token_hash = $1
+	`, tokenHash).Scan(&c.ID, &c.TokenHash, &c.UserID, &c.Purpose, &c.Attempts, &c.ExpiresAt, &c.UsedAt, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *postgresRepository) FailMFAChallenge(ctx context.Context, id string) error {
+	_, err := r.q.ExecContext(ctx, `UPDATE auth.mfa_challenges SET attempts = attempts + 1 WHERE id = $1`, id)
+	return err
+}
+
+func (r *postgresRepository) ConsumeMFAChallenge(ctx context.Context, id string) (bool, error) {
+	res, err := r.q.ExecContext(ctx, `
+		UPDATE auth.mfa_challenges SET used_at = now() WHERE id = $1 AND used_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -1,21 +1,86 @@
 package auth
 
 import (
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 
 	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/logger"
+	"wdpl_back/internal/shared/mail"
+	"wdpl_back/internal/shared/oidc"
 	"wdpl_back/internal/shared/postgres"
+	"wdpl_back/internal/shared/ratelimit"
+)
+
+// Лимиты запросов с одного IP. Рассчитаны на офис за одним NAT: десятки входов подряд — норма,
+// сотни — перебор.
+const (
+	signInIPLimit         = 30
+	signInIPWindow        = 5 * time.Minute
+	signUpIPLimit         = 10 // в час
+	refreshIPLimit        = 60 // в минуту: refresh шлёт каждая вкладка
+	forgotPasswordIPLimit = 10 // в час
 )
 
 // RegisterRoutes вешает эндпоинты авторизации на api (обычно /api).
-func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
+// mailer отправляет письма подтверждения email и сброса пароля. Вход через OIDC — если он настроен (cfg.OIDCEnabled).
+// mfaRequiredRoles — роли, которым двухфакторная аутентификация обязательна при MFA_REQUIRED_FOR_EDITORS,
+// publishers — кому она обязательна из-за права публикации через команду события или организацию.
+func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config, log logger.Logger, mailer mail.Mailer, mfaRequiredRoles []string, publishers PublishRights) {
 	repo := NewPostgresRepository(db)
-	svc := NewService(repo, repo, cfg)
+	limits := newRateLimitStore(db, cfg)
+	svc := NewService(repo, repo, repo, repo, mailer, cfg, log).
+		WithMFA(repo, mfaRequiredRoles, publishers).
+		WithRateLimitStore(limits).
+		WithAudit(repo)
+	if cfg.OIDCEnabled() {
+		svc.WithOIDC(oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirect(),
+			Scopes:       strings.Fields(cfg.OIDCScopes),
+		}, nil), repo)
+	}
 	h := NewHandler(svc)
 
+	// Лимиты по IP; второй шаг входа и коды для отключения 2FA и новых кодов восстановления считаются вместе
+	// с sign-in. Лимиты по аккаунту и email, блокировки — в сервисе.
+	signInLimit := middleware.RateLimit(limits, "sign-in", signInIPLimit, signInIPWindow)
+
 	g := api.Group("/auth")
-	g.Post("/sign-up", h.SignUp)
-	g.Post("/sign-in", h.SignIn)
+	g.Post("/sign-up", middleware.RateLimit(limits, "sign-up", signUpIPLimit, time.Hour), h.SignUp)
+	g.Post("/sign-in", signInLimit, h.SignIn)
 	g.Post("/sign-out", h.SignOut)
-	g.Post("/refresh", h.Refresh)
+	g.Post("/refresh", middleware.RateLimit(limits, "refresh", refreshIPLimit, time.Minute), h.Refresh)
+	g.Post("/forgot-password", middleware.RateLimit(limits, "forgot-password", forgotPasswordIPLimit, time.Hour), h.ForgotPassword)
+	g.Post("/reset-password", h.ResetPassword)
+	g.Post("/verify-email", h.VerifyEmail)
+	g.Post("/resend-verification", h.ResendVerification)
+	g.Post("/oidc/start", h.OIDCStart)
+	g.Post("/oidc/callback", h.OIDCCallback)
+	g.Post("/mfa/verify", signInLimit, h.MFAVerify)
+	g.Post("/mfa/setup", h.MFASetup)
+	g.Post("/mfa/setup/confirm", signInLimit, h.MFASetupConfirm)
+
+	requireAuth := middleware.RequireAuth(cfg)
+	g.Get("/sessions", requireAuth, h.ListSessions)
+	g.Delete("/sessions/:id", requireAuth, h.RevokeSession)
+	g.Post("/sign-out-all", requireAuth, h.SignOutAll)
+	g.Get("/mfa", requireAuth, h.MFAStatus)
+	g.Post("/mfa/totp", requireAuth, h.EnrollTOTP)
+	g.Post("/mfa/totp/confirm", requireAuth, h.ConfirmTOTP)
+	g.Post("/mfa/totp/disable", signInLimit, requireAuth, h.DisableTOTP)
+	g.Post("/mfa/recovery-codes", signInLimit, requireAuth, h.RegenerateRecoveryCodes)
+}
+
+// newRateLimitStore — хранилище лимитов по RATE_LIMIT_STORE: postgres — общее для реплик, иначе в памяти.
+func newRateLimitStore(db *postgres.DB, cfg *config.Config) ratelimit.Store {
+	if cfg.RateLimitStore == config.RateLimitStorePostgres {
+		return ratelimit.NewPostgresStore(db.DB)
+	}
+	return ratelimit.NewMemoryStore()
 }
@@ -3,37 +3,91 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"wdpl_back/internal/shared/authutils"
 	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/logger"
+	"wdpl_back/internal/shared/mail"
+	"wdpl_back/internal/shared/ratelimit"
+)
+
+// Лимиты писем на один email в час: повторная отправка подтверждения и сброс пароля.
+const (
+	verificationResendLimit = 3
+	passwordResetLimit      = 3
 )
 
 // Service инкапсулирует бизнес-логику авторизации.
 type Service struct {
-	userRepo         UserRepository
-	refreshTokenRepo RefreshTokenRepository
-	cfg              *config.Config
+	userRepo          UserRepository
+	refreshTokenRepo  RefreshTokenRepository
+	passwordResetRepo PasswordResetRepository
+	verificationRepo  EmailVerificationRepository
+	mailer            mail.Mailer
+	cfg               *config.Config
+	log               logger.Logger
+	// limits — лимиты частоты и блокировки входа (WithRateLimitStore); по умолчанию в памяти процесса.
+	limits ratelimit.Store
+	// oidc и oidcRepo — вход через OIDC-провайдера (WithOIDC); nil — не настроен.
+	oidc     OIDCProvider
+	oidcRepo OIDCRepository
+	// mfaRepo — двухфакторная аутентификация (WithMFA); nil — не подключена.
+	mfaRepo          MFARepository
+	mfaRequiredRoles []string
+	mfaPublishers    PublishRights
+	// tx — транзакции для журнала аудита входов (WithAudit); nil — входы не журналируются.
+	tx Transactor
 }
 
 // NewService создаёт сервис с зависимостями от интерфейсов (DIP).
-// Одна реализация *postgresRepository реализует оба интерфейса — в роутере передают repo дважды.
-func NewService(userRepo UserRepository, refreshTokenRepo RefreshTokenRepository, cfg *config.Config) *Service {
+// Одна реализация *postgresRepository реализует все репозитории — в роутере передают repo несколько раз.
+func NewService(
+	userRepo UserRepository,
+	refreshTokenRepo RefreshTokenRepository,
+	passwordResetRepo PasswordResetRepository,
+	verificationRepo EmailVerificationRepository,
+	mailer mail.Mailer,
+	cfg *config.Config,
+	log logger.Logger,
+) *Service {
 	return &Service{
-		userRepo:         userRepo,
-		refreshTokenRepo: refreshTokenRepo,
-		cfg:              cfg,
+		userRepo:          userRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		passwordResetRepo: passwordResetRepo,
+		verificationRepo:  verificationRepo,
+		mailer:            mailer,
+		cfg:               cfg,
+		log:               log,
+		limits:            ratelimit.NewMemoryStore(),
 	}
 }
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUserInactive       = errors.New("user is inactive")
-	ErrEmailExists        = errors.New("user with this email already exists")
+	ErrInvalidCredentials       = errors.New("invalid credentials")
+	ErrUserInactive             = errors.New("user is inactive")
+	ErrEmailExists              = errors.New("user with this email already exists")
+	ErrInvalidResetToken        = errors.New("invalid or expired reset token")
+	ErrEmailNotVerified         = errors.New("email not verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrRateLimited              = errors.New("too many requests")
+	ErrSessionNotFound          = errors.New("session not found")
 )
 
+// RateLimitError — превышен лимит; RetryAfter — через сколько можно повторить. errors.Is(err, ErrRateLimited).
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string { return ErrRateLimited.Error() }
+
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
 type AuthTokens struct {
 	AccessToken   string
 	AccessExpiry  time.Time
@@ -41,6 +95,8 @@ type AuthTokens struct {
 	RefreshExpiry time.Time
 }
 
+// Register создаёт пользователя и отправляет письмо подтверждения email. Токены выдаются, только если
+// вход до подтверждения разрешён (cfg.UnverifiedSignInAllowed), иначе tokens == nil.
 func (s *Service) Register(ctx context.Context, email, password string) (*User, *AuthTokens, error) {
 	existing, err := s.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
@@ -59,7 +115,7 @@ func (s *Service) Register(ctx context.Context, email, password string) (*User,
 		ID:           uuid.NewString(),
 		Email:        email,
 		PasswordHash: hash,
-		Role:         "user",
+		Role:         RoleUser,
 		IsActive:     true,
 	}
 
@@ -67,7 +123,15 @@ func (s *Service) Register(ctx context.Context, email, password string) (*User,
 		return nil, nil, err
 	}
 
-	tokens, err := s.issueTokens(ctx, user.ID, user.Role, "", "")
+	// Пользователь уже создан: сбой почты не должен ломать регистрацию — письмо можно запросить повторно.
+	if err := s.sendVerification(ctx, user); err != nil {
+		s.log.Error("send verification email failed", "user_id", user.ID, "error", err)
+	}
+
+	if !s.cfg.UnverifiedSignInAllowed() {
+		return user, nil, nil
+	}
+	tokens, err := s.issueTokens(ctx, user, SignInSignUp, "", "")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -75,19 +139,38 @@ func (s *Service) Register(ctx context.Context, email, password string) (*User,
 	return user, tokens, nil
 }
 
+// Login — вход по email и паролю. Если у пользователя подключён TOTP (или он обязателен для роли),
+// токены не выдаются: возвращается *MFAChallengeError с токеном второго шага (см. VerifyMFA).
+// Заблокированный после неудач или превысивший лимит попыток аккаунт — *RateLimitError, пароль не проверяется.
 func (s *Service) Login(ctx context.Context, email, password, userAgent, ip string) (*User, *AuthTokens, error) {
+	account := signInAccountKey(email)
+	if err := s.signInAllowed(ctx, account); err != nil {
+		return nil, nil, err
+	}
 	user, err := s.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
 		return nil, nil, err
 	}
 	if user == nil || !authutils.CheckPassword(password, user.PasswordHash) {
+		if err := s.signInFailed(ctx, email, ip); err != nil {
+			return nil, nil, err
+		}
 		return nil, nil, ErrInvalidCredentials
 	}
+	if err := s.limits.Reset(ctx, account); err != nil {
+		return nil, nil, err
+	}
 	if !user.IsActive {
 		return nil, nil, ErrUserInactive
 	}
+	if !user.EmailVerified() && !s.cfg.UnverifiedSignInAllowed() {
+		return nil, nil, ErrEmailNotVerified
+	}
+	if err := s.secondFactor(ctx, user); err != nil {
+		return nil, nil, err
+	}
 
-	tokens, err := s.issueTokens(ctx, user.ID, user.Role, userAgent, ip)
+	tokens, err := s.issueTokens(ctx, user, SignInPassword, userAgent, ip)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -96,24 +179,66 @@ func (s *Service) Login(ctx context.Context, email, password, userAgent, ip stri
 }
 
 func (s *Service) Refresh(ctx context.Context, refreshTokenStr string, userAgent, ip string) (*AuthTokens, error) {
-	rt, err := s.refreshTokenRepo.GetRefreshToken(ctx, refreshTokenStr)
+	rt, err := s.refreshTokenRepo.GetRefreshToken(ctx, s.hashRefreshToken(refreshTokenStr))
 	if err != nil {
 		return nil, err
 	}
-	if rt == nil || rt.RevokedAt != nil || time.Now().After(rt.ExpiresAt) {
+	if rt == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if rt.ReplacedBy != nil {
+		// Токен уже обменян на новый: его предъявляет кто-то ещё (украден или скопирован).
+		return nil, s.revokeReusedFamily(ctx, rt, userAgent, ip)
+	}
+	if rt.RevokedAt != nil || time.Now().After(rt.ExpiresAt) {
 		// Для простоты считаем все такие случаи 401 на уровне handler.
 		return nil, ErrInvalidCredentials
 	}
 
-	tokens, err := s.issueTokens(ctx, rt.UserID, "", userAgent, ip)
+	// Пользователь перечитывается: access-токен несёт текущие роль, поколение и подтверждение email.
+	user, err := s.userRepo.GetUserByID(ctx, rt.UserID)
 	if err != nil {
 		return nil, err
 	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	if !user.EmailVerified() && !s.cfg.UnverifiedSignInAllowed() {
+		return nil, ErrEmailNotVerified
+	}
+	if err := s.requireEnrolledMFA(ctx, user); err != nil {
+		return nil, err
+	}
 
-	// Опционально можно отозвать старый refresh и создать новый.
+	tokens, next, err := s.newTokens(user, rt.FamilyID, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.refreshTokenRepo.RotateRefreshToken(ctx, rt.ID, next)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Между чтением и ротацией токен обменял другой запрос — то же повторное использование.
+		return nil, s.revokeReusedFamily(ctx, rt, userAgent, ip)
+	}
 	return tokens, nil
 }
 
+// revokeReusedFamily реагирует на повторное предъявление ротированного refresh-токена:
+// отзывает всю семью (и у злоумышленника, и у владельца) и пишет событие безопасности в лог.
+func (s *Service) revokeReusedFamily(ctx context.Context, rt *RefreshToken, userAgent, ip string) error {
+	s.log.Warn("security: refresh token reuse detected, token family revoked",
+		"user_id", rt.UserID, "family_id", rt.FamilyID, "token_id", rt.ID, "user_agent", userAgent, "ip", ip)
+	if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, rt.FamilyID); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
 func (s *Service) Logout(ctx context.Context, refreshTokenID string) error {
 	return s.refreshTokenRepo.RevokeRefreshToken(ctx, refreshTokenID)
 }
@@ -121,27 +246,214 @@ func (s *Service) Logout(ctx context.Context, refreshTokenID string) error {
 // RevokeByToken отзывает refresh‑токен по его строковому значению (для SignOut).
 // Если токен не найден — возвращает nil (идемпотентность).
 func (s *Service) RevokeByToken(ctx context.Context, tokenStr string) error {
-	rt, err := s.refreshTokenRepo.GetRefreshToken(ctx, tokenStr)
+	rt, err := s.refreshTokenRepo.GetRefreshToken(ctx, s.hashRefreshToken(tokenStr))
 	if err != nil || rt == nil {
 		return nil
 	}
 	return s.refreshTokenRepo.RevokeRefreshToken(ctx, rt.ID)
 }
 
-func (s *Service) issueTokens(ctx context.Context, userID, role, userAgent, ip string) (*AuthTokens, error) {
-	accessToken, accessExp, err := authutils.GenerateAccessToken(s.cfg, userID, role)
+// ListSessions возвращает активные сессии (входы) пользователя, последние использованные — первыми.
+func (s *Service) ListSessions(ctx context.Context, userID string) ([]*Session, error) {
+	return s.refreshTokenRepo.ListSessions(ctx, userID)
+}
+
+// RevokeSession завершает сессию пользователя: её refresh-токен больше не обменивается.
+// Выданный ей access-токен действует до истечения.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	ok, err := s.refreshTokenRepo.RevokeSession(ctx, userID, sessionID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// SignOutOthers завершает все сессии пользователя, кроме текущей (currentSessionID из access-токена;
+// пустой — у токена, выданного до появления сессий, — завершает все). Возвращает число завершённых.
+func (s *Service) SignOutOthers(ctx context.Context, userID, currentSessionID string) (int, error) {
+	return s.refreshTokenRepo.RevokeOtherSessions(ctx, userID, currentSessionID)
+}
+
+// RequestPasswordReset отправляет на email ссылку сброса пароля. Лимит — по email, до поиска пользователя:
+// письма не заваливают чужой ящик, а ответ не выдаёт, есть ли такой пользователь. Для неизвестного
+// или неактивного email молча ничего не делает.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	ok, retryAfter, err := s.limits.Allow(ctx, "password-reset:"+strings.ToLower(email), passwordResetLimit, time.Hour)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return nil
+	}
+
+	token, hash, err := generateToken()
+	if err != nil {
+		return err
+	}
+	ttl := time.Duration(s.cfg.PasswordResetTTLMin) * time.Minute
+	if err := s.passwordResetRepo.CreatePasswordResetToken(ctx, &PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Text: fmt.Sprintf("Чтобы задать новый пароль, откройте ссылку:\n%s\n\n"+
+			"Ссылка действует %d мин. и работает один раз. Если вы не запрашивали сброс, просто проигнорируйте письмо.",
+			s.appLink("/reset-password", token), s.cfg.PasswordResetTTLMin),
+	})
+}
+
+// ResetPassword задаёт новый пароль по токену из письма. Токен одноразовый; после сброса
+// отзываются все refresh-токены пользователя — остальные сессии придётся открыть заново.
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	rt, err := s.passwordResetRepo.GetPasswordResetToken(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if rt == nil || rt.UsedAt != nil || time.Now().After(rt.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	user, err := s.userRepo.GetUserByID(ctx, rt.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return ErrInvalidResetToken
 	}
 
-	refreshExp := time.Now().Add(time.Duration(s.cfg.RefreshTokenTTLMin) * time.Minute)
-	refreshToken := uuid.NewString()
+	hash, err := authutils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	ok, err := s.passwordResetRepo.ResetPassword(ctx, rt.ID, user.ID, hash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidResetToken
+	}
+	return nil
+}
 
+// VerifyEmail подтверждает email по токену из письма. Токен одноразовый. Новый access-токен
+// (без ограничения «только чтение») фронт получает следующим /refresh.
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	vt, err := s.verificationRepo.GetEmailVerificationToken(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if vt == nil || vt.UsedAt != nil || time.Now().After(vt.ExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+	ok, err := s.verificationRepo.VerifyEmail(ctx, vt.ID, vt.UserID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+// ResendVerification повторно отправляет письмо подтверждения. Лимит — по email, до поиска пользователя:
+// ответ не должен выдавать, есть ли такой пользователь. Для неизвестного, неактивного или уже
+// подтверждённого email письмо не отправляется.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	ok, retryAfter, err := s.limits.Allow(ctx, "verification-resend:"+strings.ToLower(email), verificationResendLimit, time.Hour)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive || user.EmailVerified() {
+		return nil
+	}
+	return s.sendVerification(ctx, user)
+}
+
+// sendVerification создаёт токен подтверждения и отправляет письмо со ссылкой.
+func (s *Service) sendVerification(ctx context.Context, user *User) error {
+	token, hash, err := generateToken()
+	if err != nil {
+		return err
+	}
+	if err := s.verificationRepo.CreateEmailVerificationToken(ctx, &EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(time.Duration(s.cfg.EmailVerifyTTLMin) * time.Minute),
+	}); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Подтверждение email",
+		Text: fmt.Sprintf("Чтобы подтвердить email, откройте ссылку:\n%s\n\n"+
+			"Ссылка действует %d мин. Если вы не регистрировались, просто проигнорируйте письмо.",
+			s.appLink("/verify-email", token), s.cfg.EmailVerifyTTLMin),
+	})
+}
+
+// hashRefreshToken — ключ поиска refresh-токена в БД.
+func (s *Service) hashRefreshToken(token string) string {
+	return hashRefreshToken(s.cfg.RefreshSecret, token)
+}
+
+// appLink — ссылка на страницу фронтенда с токеном из письма.
+func (s *Service) appLink(path, token string) string {
+	return strings.TrimRight(s.cfg.AppURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// issueTokens выдаёт пару access/refresh при входе способом method; refresh-токен открывает новую семью.
+func (s *Service) issueTokens(ctx context.Context, user *User, method, userAgent, ip string) (*AuthTokens, error) {
+	tokens, rt, err := s.newTokens(user, "", userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveSession(ctx, user, rt, method, userAgent, ip); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// newTokens готовит пару access/refresh без сохранения. familyID == "" — новая семья (id самого токена).
+// Access-токен несёт роль и поколение токенов пользователя; пока email не подтверждён — только на чтение.
+func (s *Service) newTokens(user *User, familyID, userAgent, ip string) (*AuthTokens, *RefreshToken, error) {
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
 	rt := &RefreshToken{
-		ID:        uuid.NewString(),
-		UserID:    userID,
-		Token:     refreshToken,
-		ExpiresAt: refreshExp,
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		FamilyID:   familyID,
+		TokenHash:  s.hashRefreshToken(refreshToken),
+		ExpiresAt:  now.Add(time.Duration(s.cfg.RefreshTokenTTLMin) * time.Minute),
+		LastUsedAt: &now,
+	}
+	if rt.FamilyID == "" {
+		rt.FamilyID = rt.ID
 	}
 	if userAgent != "" {
 		rt.UserAgent = &userAgent
@@ -150,14 +462,21 @@ func (s *Service) issueTokens(ctx context.Context, userID, role, userAgent, ip s
 		rt.IP = &ip
 	}
 
-	if err := s.refreshTokenRepo.CreateRefreshToken(ctx, rt); err != nil {
-		return nil, err
+	accessToken, accessExp, err := authutils.GenerateAccessTokenWithClaims(s.cfg, &authutils.UserClaims{
+		UserID:          user.ID,
+		Role:            user.Role,
+		EmailUnverified: !user.EmailVerified(),
+		Generation:      user.TokenGeneration,
+		SessionID:       rt.FamilyID,
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
 	return &AuthTokens{
 		AccessToken:   accessToken,
 		AccessExpiry:  accessExp,
 		RefreshToken:  refreshToken,
-		RefreshExpiry: refreshExp,
-	}, nil
+		RefreshExpiry: rt.ExpiresAt,
+	}, rt, nil
 }
@@ -2,14 +2,23 @@ package auth
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"wdpl_back/internal/shared/authutils"
 	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/mail"
 )
 
 // mockUserRepo — простая in-memory реализация UserRepository для тестов.
@@ -48,7 +57,52 @@ func (m *mockUserRepo) GetUserByID(_ context.Context, userID string) (*User, err
 	return nil, nil
 }
 
-// mockRefreshRepo — in-memory реализация RefreshTokenRepository.
+func (m *mockUserRepo) LockUser(ctx context.Context, userID string) (*User, error) {
+	return m.GetUserByID(ctx, userID)
+}
+
+func (m *mockUserRepo) LockActiveAdmins(_ context.Context) ([]string, error) {
+	var ids []string
+	for _, u := range m.usersByEmail {
+		if u.Role == RoleAdmin && u.IsActive {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockUserRepo) ListUsers(_ context.Context, filter UserFilter) ([]*User, error) {
+	var list []*User
+	for _, u := range m.usersByEmail {
+		if strings.Contains(u.Email, strings.ToLower(filter.Search)) && (filter.Role == "" || u.Role == filter.Role) {
+			list = append(list, u)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockUserRepo) UpdateUser(_ context.Context, user *User) error {
+	stored := m.usersByEmail[user.Email]
+	if stored == nil {
+		return nil
+	}
+	if stored.Role != user.Role || stored.IsActive != user.IsActive {
+		user.TokenGeneration = stored.TokenGeneration + 1
+	}
+	*stored = *user
+	return nil
+}
+
+func (m *mockUserRepo) BumpTokenGeneration(_ context.Context, userID string) error {
+	for _, u := range m.usersByEmail {
+		if u.ID == userID {
+			u.TokenGeneration++
+		}
+	}
+	return nil
+}
+
+// mockRefreshRepo — in-memory реализация RefreshTokenRepository (ключ — хеш токена).
 type mockRefreshRepo struct {
 	tokensByValue map[string]*RefreshToken
 	createErr     error
@@ -62,15 +116,18 @@ func (m *mockRefreshRepo) CreateRefreshToken(_ context.Context, token *RefreshTo
 	if m.tokensByValue == nil {
 		m.tokensByValue = make(map[string]*RefreshToken)
 	}
-	m.tokensByValue[token.Token] = token
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	m.tokensByValue[token.TokenHash] = token
 	return nil
 }
 
-func (m *mockRefreshRepo) GetRefreshToken(_ context.Context, token string) (*RefreshToken, error) {
+func (m *mockRefreshRepo) GetRefreshToken(_ context.Context, tokenHash string) (*RefreshToken, error) {
 	if m.tokensByValue == nil {
 		return nil, nil
 	}
-	return m.tokensByValue[token], nil
+	return m.tokensByValue[tokenHash], nil
 }
 
 func (m *mockRefreshRepo) RevokeRefreshToken(_ context.Context, tokenID string) error {
@@ -87,21 +144,251 @@ func (m *mockRefreshRepo) RevokeRefreshToken(_ context.Context, tokenID string)
 	return nil
 }
 
+func (m *mockRefreshRepo) RotateRefreshToken(ctx context.Context, oldID string, next *RefreshToken) (bool, error) {
+	for _, rt := range m.tokensByValue {
+		if rt.ID == oldID {
+			if rt.RevokedAt != nil {
+				return false, nil
+			}
+			now := time.Now()
+			rt.RevokedAt = &now
+			rt.LastUsedAt = &now
+			rt.ReplacedBy = &next.ID
+			return true, m.CreateRefreshToken(ctx, next)
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRefreshRepo) RevokeRefreshTokenFamily(_ context.Context, familyID string) error {
+	now := time.Now()
+	for _, rt := range m.tokensByValue {
+		if rt.FamilyID == familyID && rt.RevokedAt == nil {
+			rt.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *mockRefreshRepo) ListSessions(_ context.Context, userID string) ([]*Session, error) {
+	started := make(map[string]time.Time)
+	for _, rt := range m.tokensByValue {
+		if at, ok := started[rt.FamilyID]; !ok || rt.CreatedAt.Before(at) {
+			started[rt.FamilyID] = rt.CreatedAt
+		}
+	}
+	var list []*Session
+	for _, rt := range m.tokensByValue {
+		if rt.UserID != userID || rt.RevokedAt != nil || time.Now().After(rt.ExpiresAt) {
+			continue
+		}
+		lastUsed := rt.CreatedAt
+		if rt.LastUsedAt != nil {
+			lastUsed = *rt.LastUsedAt
+		}
+		list = append(list, &Session{
+			ID: rt.FamilyID, UserID: rt.UserID, UserAgent: rt.UserAgent, IP: rt.IP,
+			CreatedAt: started[rt.FamilyID], LastUsedAt: lastUsed, ExpiresAt: rt.ExpiresAt,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastUsedAt.After(list[j].LastUsedAt) })
+	return list, nil
+}
+
+func (m *mockRefreshRepo) RevokeSession(_ context.Context, userID, sessionID string) (bool, error) {
+	now := time.Now()
+	found := false
+	for _, rt := range m.tokensByValue {
+		if rt.UserID == userID && rt.FamilyID == sessionID && rt.RevokedAt == nil {
+			rt.RevokedAt = &now
+			found = true
+		}
+	}
+	return found, nil
+}
+
+func (m *mockRefreshRepo) RevokeOtherSessions(_ context.Context, userID, exceptSessionID string) (int, error) {
+	now := time.Now()
+	n := 0
+	for _, rt := range m.tokensByValue {
+		if rt.UserID == userID && rt.FamilyID != exceptSessionID && rt.RevokedAt == nil {
+			rt.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockRefreshRepo) RevokeAllSessions(_ context.Context, userID string) (int, error) {
+	now := time.Now()
+	n := 0
+	for _, rt := range m.tokensByValue {
+		if rt.UserID == userID && rt.RevokedAt == nil {
+			rt.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+// mockResetRepo — in-memory реализация PasswordResetRepository поверх моков пользователей и refresh-токенов.
+type mockResetRepo struct {
+	users    *mockUserRepo
+	refresh  *mockRefreshRepo
+	byHash   map[string]*PasswordResetToken
+	sequence int
+}
+
+func (m *mockResetRepo) CreatePasswordResetToken(_ context.Context, token *PasswordResetToken) error {
+	if m.byHash == nil {
+		m.byHash = make(map[string]*PasswordResetToken)
+	}
+	m.sequence++
+	token.ID = fmt.Sprintf("reset-%d", m.sequence)
+	token.CreatedAt = time.Now()
+	m.byHash[token.TokenHash] = token
+	return nil
+}
+
+func (m *mockResetRepo) GetPasswordResetToken(_ context.Context, tokenHash string) (*PasswordResetToken, error) {
+	if t, ok := m.byHash[tokenHash]; ok {
+		cp := *t
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *mockResetRepo) ResetPassword(ctx context.Context, tokenID, userID, passwordHash string) (bool, error) {
+	var token *PasswordResetToken
+	for _, t := range m.byHash {
+		if t.ID == tokenID {
+			token = t
+		}
+	}
+	if token == nil || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	for _, t := range m.byHash {
+		if t.UserID == userID && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	user, _ := m.users.GetUserByID(ctx, userID)
+	if user != nil {
+		user.PasswordHash = passwordHash
+		user.TokenGeneration++
+	}
+	for _, rt := range m.refresh.tokensByValue {
+		if rt.UserID == userID && rt.RevokedAt == nil {
+			rt.RevokedAt = &now
+		}
+	}
+	return true, nil
+}
+
+// mockVerificationRepo — in-memory реализация EmailVerificationRepository.
+type mockVerificationRepo struct {
+	users    *mockUserRepo
+	byHash   map[string]*EmailVerificationToken
+	sequence int
+}
+
+func (m *mockVerificationRepo) CreateEmailVerificationToken(_ context.Context, token *EmailVerificationToken) error {
+	if m.byHash == nil {
+		m.byHash = make(map[string]*EmailVerificationToken)
+	}
+	m.sequence++
+	token.ID = fmt.Sprintf("verify-%d", m.sequence)
+	token.CreatedAt = time.Now()
+	m.byHash[token.TokenHash] = token
+	return nil
+}
+
+func (m *mockVerificationRepo) GetEmailVerificationToken(_ context.Context, tokenHash string) (*EmailVerificationToken, error) {
+	if t, ok := m.byHash[tokenHash]; ok {
+		cp := *t
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *mockVerificationRepo) VerifyEmail(ctx context.Context, tokenID, userID string) (bool, error) {
+	var token *EmailVerificationToken
+	for _, t := range m.byHash {
+		if t.ID == tokenID {
+			token = t
+		}
+	}
+	if token == nil || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	for _, t := range m.byHash {
+		if t.UserID == userID && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	if user, _ := m.users.GetUserByID(ctx, userID); user != nil && user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &now
+	}
+	return true, nil
+}
+
+// mockMailer запоминает отправленные письма.
+type mockMailer struct {
+	sent []mail.Message
+}
+
+func (m *mockMailer) Send(_ context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// testDeps — моки, на которых собран тестовый Service.
+type testDeps struct {
+	users         *mockUserRepo
+	refresh       *mockRefreshRepo
+	resets        *mockResetRepo
+	verifications *mockVerificationRepo
+	mailer        *mockMailer
+}
+
 // newTestService создаёт Service с замоканными зависимостями.
 func newTestService(t *testing.T) (*Service, *mockUserRepo, *mockRefreshRepo) {
 	t.Helper()
+	s, deps := newTestServiceDeps(t)
+	return s, deps.users, deps.refresh
+}
 
-	cfg := &config.Config{
-		JWTSecret:          "test-secret",
-		RefreshSecret:      "test-refresh-secret",
-		AccessTokenTTLMin:  15,
-		RefreshTokenTTLMin: 30,
-	}
+// newTestServiceDeps — как newTestService, но отдаёт все моки (сброс пароля, подтверждение email, почта).
+func newTestServiceDeps(t *testing.T) (*Service, *testDeps) {
+	t.Helper()
+	return newTestServiceWithConfig(t, &config.Config{
+		JWTSecret:           "test-secret",
+		RefreshSecret:       "test-refresh-secret",
+		AccessTokenTTLMin:   15,
+		RefreshTokenTTLMin:  30,
+		AppURL:              "https://app.example.com",
+		PasswordResetTTLMin: 60,
+		EmailVerifyTTLMin:   1440,
+		UnverifiedAccess:    config.UnverifiedAccessReadOnly,
+	})
+}
+
+func newTestServiceWithConfig(t *testing.T, cfg *config.Config) (*Service, *testDeps) {
+	t.Helper()
 
-	userRepo := &mockUserRepo{}
-	refreshRepo := &mockRefreshRepo{}
-	s := NewService(userRepo, refreshRepo, cfg)
-	return s, userRepo, refreshRepo
+	deps := &testDeps{
+		users:   &mockUserRepo{},
+		refresh: &mockRefreshRepo{},
+		mailer:  &mockMailer{},
+	}
+	deps.resets = &mockResetRepo{users: deps.users, refresh: deps.refresh}
+	deps.verifications = &mockVerificationRepo{users: deps.users}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewService(deps.users, deps.refresh, deps.resets, deps.verifications, deps.mailer, cfg, log)
+	return s, deps
 }
 
 func TestRegister_Success(t *testing.T) {
@@ -183,7 +470,7 @@ func TestRefresh_Success(t *testing.T) {
 	require.NotEmpty(t, tokens.RefreshToken)
 
 	// Проверяем, что refresh‑токен есть в репозитории.
-	storedRT, err := refreshRepo.GetRefreshToken(ctx, tokens.RefreshToken)
+	storedRT, err := refreshRepo.GetRefreshToken(ctx, s.hashRefreshToken(tokens.RefreshToken))
 	require.NoError(t, err)
 	require.NotNil(t, storedRT)
 
@@ -191,7 +478,11 @@ func TestRefresh_Success(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, newTokens)
 
-	assert.NotEqual(t, tokens.AccessToken, newTokens.AccessToken)
+	assert.NotEqual(t, tokens.RefreshToken, newTokens.RefreshToken)
+	claims, err := authutils.ParseAccessToken(s.cfg, newTokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, storedRT.UserID, claims.UserID)
+	assert.Equal(t, "user", claims.Role)
 }
 
 func TestRefresh_InvalidToken(t *testing.T) {
@@ -211,7 +502,7 @@ func TestLogout_RevokesToken(t *testing.T) {
 	rt := &RefreshToken{
 		ID:        "rt-id",
 		UserID:    "user-id",
-		Token:     "token-value",
+		TokenHash: "token-hash",
 		ExpiresAt: time.Now().Add(time.Hour),
 	}
 	err := refreshRepo.CreateRefreshToken(ctx, rt)
@@ -220,7 +511,7 @@ func TestLogout_RevokesToken(t *testing.T) {
 	err = s.Logout(ctx, rt.ID)
 	require.NoError(t, err)
 
-	stored, err := refreshRepo.GetRefreshToken(ctx, rt.Token)
+	stored, err := refreshRepo.GetRefreshToken(ctx, rt.TokenHash)
 	require.NoError(t, err)
 	require.NotNil(t, stored)
 	assert.NotNil(t, stored.RevokedAt)
@@ -237,3 +528,430 @@ func TestRegister_CreateUserError_Propagated(t *testing.T) {
 	assert.Nil(t, user)
 	assert.Nil(t, tokens)
 }
+
+// resetTokenFromMail достаёт токен из ссылки сброса пароля в последнем письме.
+func resetTokenFromMail(t *testing.T, m *mockMailer) string {
+	t.Helper()
+	return tokenFromMail(t, m, "/reset-password")
+}
+
+// tokenFromMail достаёт токен из ссылки на страницу path в последнем письме.
+func tokenFromMail(t *testing.T, m *mockMailer, path string) string {
+	t.Helper()
+	require.NotEmpty(t, m.sent)
+	text := m.sent[len(m.sent)-1].Text
+	i := strings.Index(text, "https://")
+	require.GreaterOrEqual(t, i, 0)
+	link := strings.Fields(text[i:])[0]
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	require.Equal(t, path, u.Path)
+	return u.Query().Get("token")
+}
+
+func TestPasswordReset_Success_RevokesRefreshTokens(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	user, tokens, err := s.Register(ctx, "reset@example.com", "password123")
+	require.NoError(t, err)
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "reset@example.com"))
+	require.Len(t, deps.mailer.sent, 2) // подтверждение email при регистрации + сброс
+	assert.Equal(t, "reset@example.com", deps.mailer.sent[1].To)
+	token := resetTokenFromMail(t, deps.mailer)
+	require.NotEmpty(t, token)
+
+	// В БД хранится только хеш токена.
+	_, stored := deps.resets.byHash[token]
+	assert.False(t, stored)
+
+	require.NoError(t, s.ResetPassword(ctx, token, "new-password-1"))
+
+	assert.True(t, authutils.CheckPassword("new-password-1", user.PasswordHash))
+	rt, err := deps.refresh.GetRefreshToken(ctx, s.hashRefreshToken(tokens.RefreshToken))
+	require.NoError(t, err)
+	assert.NotNil(t, rt.RevokedAt)
+
+	_, err = s.Refresh(ctx, tokens.RefreshToken, "agent", "127.0.0.1")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestPasswordReset_TokenIsSingleUse(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	_, _, err := s.Register(ctx, "reset@example.com", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.RequestPasswordReset(ctx, "reset@example.com"))
+	first := resetTokenFromMail(t, deps.mailer)
+	require.NoError(t, s.RequestPasswordReset(ctx, "reset@example.com"))
+	second := resetTokenFromMail(t, deps.mailer)
+
+	require.NoError(t, s.ResetPassword(ctx, first, "new-password-1"))
+	require.ErrorIs(t, s.ResetPassword(ctx, first, "new-password-2"), ErrInvalidResetToken)
+	// Остальные выданные токены гасятся вместе с использованным.
+	require.ErrorIs(t, s.ResetPassword(ctx, second, "new-password-2"), ErrInvalidResetToken)
+}
+
+func TestPasswordReset_ExpiredToken(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	_, _, err := s.Register(ctx, "reset@example.com", "password123")
+	require.NoError(t, err)
+	require.NoError(t, s.RequestPasswordReset(ctx, "reset@example.com"))
+	token := resetTokenFromMail(t, deps.mailer)
+
+	deps.resets.byHash[hashToken(token)].ExpiresAt = time.Now().Add(-time.Minute)
+
+	require.ErrorIs(t, s.ResetPassword(ctx, token, "new-password-1"), ErrInvalidResetToken)
+	require.ErrorIs(t, s.ResetPassword(ctx, "unknown-token", "new-password-1"), ErrInvalidResetToken)
+}
+
+func TestRequestPasswordReset_UnknownEmail_SendsNothing(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+
+	require.NoError(t, s.RequestPasswordReset(context.Background(), "nobody@example.com"))
+	assert.Empty(t, deps.mailer.sent)
+	assert.Empty(t, deps.resets.byHash)
+}
+
+func TestRequestPasswordReset_ThrottledPerEmail(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+	_, _, err := s.Register(ctx, "reset@example.com", "password123")
+	require.NoError(t, err)
+	sent := len(deps.mailer.sent)
+
+	for i := 0; i < passwordResetLimit; i++ {
+		require.NoError(t, s.RequestPasswordReset(ctx, "reset@example.com"))
+	}
+	assert.Len(t, deps.mailer.sent, sent+passwordResetLimit)
+
+	err = s.RequestPasswordReset(ctx, "RESET@example.com")
+	var limited *RateLimitError
+	require.ErrorAs(t, err, &limited)
+	assert.Positive(t, limited.RetryAfter)
+	assert.Len(t, deps.mailer.sent, sent+passwordResetLimit)
+
+	// Неизвестный email ограничивается так же: по ответу не понять, зарегистрирован ли он.
+	for i := 0; i < passwordResetLimit; i++ {
+		require.NoError(t, s.RequestPasswordReset(ctx, "nobody@example.com"))
+	}
+	require.ErrorAs(t, s.RequestPasswordReset(ctx, "nobody@example.com"), &limited)
+}
+
+func TestRegister_SendsVerificationEmail_ReadOnlyTokens(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	user, tokens, err := s.Register(ctx, "new@example.com", "password123")
+	require.NoError(t, err)
+	assert.False(t, user.EmailVerified())
+	require.NotNil(t, tokens)
+
+	claims, err := authutils.ParseAccessToken(s.cfg, tokens.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.EmailUnverified)
+
+	require.Len(t, deps.mailer.sent, 1)
+	assert.Equal(t, "new@example.com", deps.mailer.sent[0].To)
+	token := tokenFromMail(t, deps.mailer, "/verify-email")
+
+	require.NoError(t, s.VerifyEmail(ctx, token))
+	assert.True(t, user.EmailVerified())
+	require.ErrorIs(t, s.VerifyEmail(ctx, token), ErrInvalidVerificationToken)
+
+	// После подтверждения refresh выдаёт токен без ограничения.
+	refreshed, err := s.Refresh(ctx, tokens.RefreshToken, "agent", "127.0.0.1")
+	require.NoError(t, err)
+	claims, err = authutils.ParseAccessToken(s.cfg, refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.False(t, claims.EmailUnverified)
+	assert.Equal(t, "user", claims.Role)
+}
+
+func TestVerifyEmail_ExpiredToken(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	user, _, err := s.Register(ctx, "new@example.com", "password123")
+	require.NoError(t, err)
+	token := tokenFromMail(t, deps.mailer, "/verify-email")
+	deps.verifications.byHash[hashToken(token)].ExpiresAt = time.Now().Add(-time.Minute)
+
+	require.ErrorIs(t, s.VerifyEmail(ctx, token), ErrInvalidVerificationToken)
+	require.ErrorIs(t, s.VerifyEmail(ctx, "unknown"), ErrInvalidVerificationToken)
+	assert.False(t, user.EmailVerified())
+}
+
+func TestUnverifiedAccessDeny_NoTokensUntilVerified(t *testing.T) {
+	s, deps := newTestServiceWithConfig(t, &config.Config{
+		JWTSecret:          "test-secret",
+		AccessTokenTTLMin:  15,
+		RefreshTokenTTLMin: 30,
+		AppURL:             "https://app.example.com",
+		EmailVerifyTTLMin:  60,
+		UnverifiedAccess:   config.UnverifiedAccessDeny,
+	})
+	ctx := context.Background()
+
+	user, tokens, err := s.Register(ctx, "new@example.com", "password123")
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	assert.Nil(t, tokens)
+
+	_, _, err = s.Login(ctx, "new@example.com", "password123", "", "")
+	require.ErrorIs(t, err, ErrEmailNotVerified)
+
+	require.NoError(t, s.VerifyEmail(ctx, tokenFromMail(t, deps.mailer, "/verify-email")))
+	_, tokens, err = s.Login(ctx, "new@example.com", "password123", "", "")
+	require.NoError(t, err)
+	require.NotNil(t, tokens)
+}
+
+func TestResendVerification_ThrottledAndSilent(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	_, _, err := s.Register(ctx, "new@example.com", "password123")
+	require.NoError(t, err)
+	require.Len(t, deps.mailer.sent, 1)
+
+	for i := 0; i < verificationResendLimit; i++ {
+		require.NoError(t, s.ResendVerification(ctx, "new@example.com"))
+	}
+	assert.Len(t, deps.mailer.sent, 1+verificationResendLimit)
+
+	err = s.ResendVerification(ctx, "NEW@example.com")
+	var limited *RateLimitError
+	require.ErrorAs(t, err, &limited)
+	assert.Positive(t, limited.RetryAfter)
+
+	// Неизвестный email — без ошибки и без письма.
+	require.NoError(t, s.ResendVerification(ctx, "nobody@example.com"))
+	assert.Len(t, deps.mailer.sent, 1+verificationResendLimit)
+}
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	_, tokens, err := s.Register(ctx, "rotate@example.com", "password123")
+	require.NoError(t, err)
+
+	next, err := s.Refresh(ctx, tokens.RefreshToken, "agent", "127.0.0.1")
+	require.NoError(t, err)
+
+	old, _ := deps.refresh.GetRefreshToken(ctx, s.hashRefreshToken(tokens.RefreshToken))
+	rotated, _ := deps.refresh.GetRefreshToken(ctx, s.hashRefreshToken(next.RefreshToken))
+	require.NotNil(t, rotated)
+	assert.NotNil(t, old.RevokedAt)
+	require.NotNil(t, old.ReplacedBy)
+	assert.Equal(t, rotated.ID, *old.ReplacedBy)
+	assert.Equal(t, old.FamilyID, rotated.FamilyID)
+	assert.Nil(t, rotated.RevokedAt)
+
+	// Новый токен снова обменивается.
+	_, err = s.Refresh(ctx, next.RefreshToken, "agent", "127.0.0.1")
+	require.NoError(t, err)
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	_, tokens, err := s.Register(ctx, "reuse@example.com", "password123")
+	require.NoError(t, err)
+	// Вторая сессия того же пользователя — другая семья, её reuse не затрагивает.
+	_, other, err := s.Login(ctx, "reuse@example.com", "password123", "other", "10.0.0.2")
+	require.NoError(t, err)
+
+	next, err := s.Refresh(ctx, tokens.RefreshToken, "agent", "127.0.0.1")
+	require.NoError(t, err)
+
+	// Старый токен предъявлен повторно — вся семья отозвана, включая выданный взамен.
+	_, err = s.Refresh(ctx, tokens.RefreshToken, "attacker", "10.6.6.6")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	rotated, _ := deps.refresh.GetRefreshToken(ctx, s.hashRefreshToken(next.RefreshToken))
+	assert.NotNil(t, rotated.RevokedAt)
+	_, err = s.Refresh(ctx, next.RefreshToken, "agent", "127.0.0.1")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	otherRT, _ := deps.refresh.GetRefreshToken(ctx, s.hashRefreshToken(other.RefreshToken))
+	assert.Nil(t, otherRT.RevokedAt)
+}
+
+func TestRefresh_RevokedByLogout_NoFamilyRevocation(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	_, tokens, err := s.Register(ctx, "logout@example.com", "password123")
+	require.NoError(t, err)
+	next, err := s.Refresh(ctx, tokens.RefreshToken, "agent", "127.0.0.1")
+	require.NoError(t, err)
+	require.NoError(t, s.RevokeByToken(ctx, next.RefreshToken))
+
+	_, err = s.Refresh(ctx, next.RefreshToken, "agent", "127.0.0.1")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	rt, _ := deps.refresh.GetRefreshToken(ctx, s.hashRefreshToken(next.RefreshToken))
+	assert.Nil(t, rt.ReplacedBy)
+}
+
+func TestRefreshToken_StoredAsHMAC(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	_, tokens, err := s.Register(ctx, "hash@example.com", "password123")
+	require.NoError(t, err)
+
+	raw, err := base64.RawURLEncoding.DecodeString(tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.Len(t, raw, 32)
+
+	// В хранилище нет открытого значения, только HMAC с REFRESH_SECRET.
+	_, plain := deps.refresh.tokensByValue[tokens.RefreshToken]
+	assert.False(t, plain)
+	_, hashed := deps.refresh.tokensByValue[hashRefreshToken("test-refresh-secret", tokens.RefreshToken)]
+	assert.True(t, hashed)
+	assert.NotEqual(t, hashToken(tokens.RefreshToken), hashRefreshToken("test-refresh-secret", tokens.RefreshToken))
+}
+
+func TestRefresh_ReloadsRoleAndRefusesInactive(t *testing.T) {
+	s, _ := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	user, tokens, err := s.Register(ctx, "editor@example.com", "password123")
+	require.NoError(t, err)
+
+	// Роль сменили после входа (в БД триггер увеличивает поколение).
+	user.Role = "editor"
+	user.TokenGeneration++
+	next, err := s.Refresh(ctx, tokens.RefreshToken, "agent", "127.0.0.1")
+	require.NoError(t, err)
+	claims, err := authutils.ParseAccessToken(s.cfg, next.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "editor", claims.Role)
+	assert.Equal(t, user.TokenGeneration, claims.Generation)
+
+	user.IsActive = false
+	_, err = s.Refresh(ctx, next.RefreshToken, "agent", "127.0.0.1")
+	require.ErrorIs(t, err, ErrUserInactive)
+}
+
+func TestTokenValidator(t *testing.T) {
+	s, deps := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	user, tokens, err := s.Register(ctx, "gen@example.com", "password123")
+	require.NoError(t, err)
+	claims, err := authutils.ParseAccessToken(s.cfg, tokens.AccessToken)
+	require.NoError(t, err)
+
+	v := NewTokenValidator(deps.users)
+	ok, err := v.ValidateToken(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	user.TokenGeneration++
+	ok, err = v.ValidateToken(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	claims.Generation = user.TokenGeneration
+	user.IsActive = false
+	ok, err = v.ValidateToken(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = v.ValidateToken(ctx, &authutils.UserClaims{UserID: "missing"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSessions_ListRevokeAndSignOutOthers(t *testing.T) {
+	s, _ := newTestServiceDeps(t)
+	ctx := context.Background()
+
+	user, first, err := s.Register(ctx, "sessions@example.com", "password123")
+	require.NoError(t, err)
+	_, second, err := s.Login(ctx, "sessions@example.com", "password123", "phone", "10.0.0.2")
+	require.NoError(t, err)
+	_, third, err := s.Login(ctx, "sessions@example.com", "password123", "laptop", "10.0.0.3")
+	require.NoError(t, err)
+
+	// Refresh не создаёт новую сессию: семья та же.
+	first, err = s.Refresh(ctx, first.RefreshToken, "browser", "10.0.0.1")
+	require.NoError(t, err)
+
+	sessions, err := s.ListSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	current, err := authutils.ParseAccessToken(s.cfg, third.AccessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, current.SessionID)
+
+	secondClaims, err := authutils.ParseAccessToken(s.cfg, second.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, s.RevokeSession(ctx, user.ID, secondClaims.SessionID))
+	require.ErrorIs(t, s.RevokeSession(ctx, user.ID, secondClaims.SessionID), ErrSessionNotFound)
+	require.ErrorIs(t, s.RevokeSession(ctx, "someone-else", current.SessionID), ErrSessionNotFound)
+	_, err = s.Refresh(ctx, second.RefreshToken, "phone", "10.0.0.2")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	n, err := s.SignOutOthers(ctx, user.ID, current.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = s.Refresh(ctx, first.RefreshToken, "browser", "10.0.0.1")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	sessions, err = s.ListSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, current.SessionID, sessions[0].ID)
+	require.NotNil(t, sessions[0].UserAgent)
+	assert.Equal(t, "laptop", *sessions[0].UserAgent)
+}
+
+func TestLogin_LocksAccountAfterFailures(t *testing.T) {
+	s, _ := newTestServiceDeps(t)
+	s.cfg.LoginLockoutAfter = 3
+	ctx := context.Background()
+	_, _, err := s.Register(ctx, "locked@example.com", "password123")
+	require.NoError(t, err)
+
+	for range 3 {
+		_, _, err = s.Login(ctx, "locked@example.com", "wrong-password", "", "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	// Заблокирован: даже верный пароль не проверяется.
+	_, _, err = s.Login(ctx, "Locked@Example.com", "password123", "", "10.0.0.2")
+	var limited *RateLimitError
+	require.ErrorAs(t, err, &limited)
+	assert.Equal(t, signInLockBase, limited.RetryAfter.Round(time.Second))
+
+	// Несуществующий email блокируется так же — ответы не выдают, зарегистрирован ли он.
+	for range 3 {
+		_, _, err = s.Login(ctx, "ghost@example.com", "x", "", "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	_, _, err = s.Login(ctx, "ghost@example.com", "x", "", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestLogin_AccountAttemptLimit(t *testing.T) {
+	s, _ := newTestServiceDeps(t)
+	ctx := context.Background()
+	_, _, err := s.Register(ctx, "busy@example.com", "password123")
+	require.NoError(t, err)
+
+	for range signInAccountLimit {
+		_, _, err = s.Login(ctx, "busy@example.com", "password123", "", "")
+		require.NoError(t, err)
+	}
+	_, _, err = s.Login(ctx, "busy@example.com", "password123", "", "")
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// randomToken возвращает случайный токен: 256 бит в base64url.
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// generateToken возвращает случайный токен для ссылок из писем и его хеш для БД.
+func generateToken() (token, hash string, err error) {
+	token, err = randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, hashToken(token), nil
+}
+
+// hashToken — SHA-256 токена в hex. Соль не нужна: токен случайный и длинный, перебор по хешу бесполезен.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashRefreshToken — HMAC-SHA256 refresh-токена в hex с ключом REFRESH_SECRET. В отличие от hashToken,
+// без секрета по утёкшей таблице нельзя даже проверить кандидата.
+func hashRefreshToken(secret, token string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
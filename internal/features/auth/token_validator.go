@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+
+	"wdpl_back/internal/shared/authutils"
+)
+
+// TokenValidator — проверка access-токенов по текущему состоянию пользователя (middleware.TokenValidator):
+// токен не принимается, если пользователь удалён или деактивирован либо поколение токенов сменилось
+// (смена роли, активности, сброс пароля). Так изменения доходят до уже выданных токенов сразу, а не через refresh.
+type TokenValidator struct {
+	users UserRepository
+}
+
+// NewTokenValidator создаёт проверку токенов поверх репозитория пользователей.
+func NewTokenValidator(users UserRepository) *TokenValidator {
+	return &TokenValidator{users: users}
+}
+
+// ValidateToken реализует middleware.TokenValidator.
+func (v *TokenValidator) ValidateToken(ctx context.Context, claims *authutils.UserClaims) (bool, error) {
+	user, err := v.users.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil || !user.IsActive {
+		return false, nil
+	}
+	return claims.Generation == user.TokenGeneration, nil
+}
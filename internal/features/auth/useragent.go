@@ -0,0 +1,61 @@
+package auth
+
+import "strings"
+
+// DeviceInfo — устройство, ОС и браузер, распознанные по User-Agent для списка сессий.
+// Пустые поля — не распознано.
+type DeviceInfo struct {
+	Device  string // desktop, mobile, tablet
+	OS      string
+	Browser string
+}
+
+// ParseUserAgent грубо распознаёт устройство, ОС и браузер по строке User-Agent.
+// Цель — подсказать пользователю, какой это вход, а не точная статистика, поэтому без внешних библиотек.
+func ParseUserAgent(ua string) DeviceInfo {
+	var info DeviceInfo
+	if ua == "" {
+		return info
+	}
+
+	switch {
+	case strings.Contains(ua, "iPad"):
+		info.OS, info.Device = "iPadOS", "tablet"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPod"):
+		info.OS, info.Device = "iOS", "mobile"
+	case strings.Contains(ua, "Android"):
+		info.OS, info.Device = "Android", "tablet"
+		if strings.Contains(ua, "Mobile") {
+			info.Device = "mobile"
+		}
+	case strings.Contains(ua, "Windows"):
+		info.OS, info.Device = "Windows", "desktop"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		info.OS, info.Device = "macOS", "desktop"
+	case strings.Contains(ua, "CrOS"):
+		info.OS, info.Device = "ChromeOS", "desktop"
+	case strings.Contains(ua, "Linux"):
+		info.OS, info.Device = "Linux", "desktop"
+	}
+
+	// Порядок важен: Edge и Opera содержат «Chrome», Chrome содержит «Safari».
+	switch {
+	case strings.Contains(ua, "Edg/"), strings.Contains(ua, "EdgA/"), strings.Contains(ua, "EdgiOS/"):
+		info.Browser = "Edge"
+	case strings.Contains(ua, "OPR/"), strings.Contains(ua, "Opera"):
+		info.Browser = "Opera"
+	case strings.Contains(ua, "YaBrowser/"):
+		info.Browser = "Yandex Browser"
+	case strings.Contains(ua, "SamsungBrowser/"):
+		info.Browser = "Samsung Internet"
+	case strings.Contains(ua, "Firefox/"), strings.Contains(ua, "FxiOS/"):
+		info.Browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"):
+		info.Browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		info.Browser = "Safari"
+	case strings.HasPrefix(ua, "curl/"):
+		info.Browser = "curl"
+	}
+	return info
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	cases := []struct {
+		ua   string
+		want DeviceInfo
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			DeviceInfo{Device: "desktop", OS: "Windows", Browser: "Chrome"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.80",
+			DeviceInfo{Device: "desktop", OS: "Windows", Browser: "Edge"},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			DeviceInfo{Device: "desktop", OS: "macOS", Browser: "Safari"},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			DeviceInfo{Device: "mobile", OS: "iOS", Browser: "Safari"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+			DeviceInfo{Device: "mobile", OS: "Android", Browser: "Chrome"},
+		},
+		{
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			DeviceInfo{Device: "desktop", OS: "Linux", Browser: "Firefox"},
+		},
+		{"curl/8.5.0", DeviceInfo{Browser: "curl"}},
+		{"", DeviceInfo{}},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, ParseUserAgent(tc.ua), tc.ua)
+	}
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+
+	"wdpl_back/internal/features/organizations"
+)
+
+// Access — проверка прав на событие для других фич (регистрации, оценки): то же правило, что у черновиков
+// и команды, — роль в команде события не ниже need, владельцы и администраторы организации события,
+// глобальный admin. Событие другой организации без членства в нём — ErrForbidden.
+type Access struct {
+	repos *TxRepositories
+	orgs  OrganizationDirectory
+}
+
+// NewAccess создаёт проверку прав поверх репозиториев событий, черновиков (организация ещё не
+// опубликованного события) и команд.
+func NewAccess(eventsRepo EventRepository, draftsRepo EventDraftRepository, membersRepo MemberRepository, orgs OrganizationDirectory) *Access {
+	return &Access{
+		repos: &TxRepositories{Events: eventsRepo, Drafts: draftsRepo, Members: membersRepo},
+		orgs:  orgs,
+	}
+}
+
+// Authorize — nil, если actor может действовать над событием eventID с правами не ниже need
+// (MemberViewer, MemberEditor, MemberOwner); иначе ErrForbidden.
+func (a *Access) Authorize(ctx context.Context, actor Actor, eventID, need string) error {
+	return authorize(ctx, a.repos, a.orgs, actor, eventID, need)
+}
+
+// OrganizationMemberships — организации пользователя с его ролью (реализует organizations.Repository).
+type OrganizationMemberships interface {
+	ListByUserID(ctx context.Context, userID string) ([]*organizations.Membership, error)
+}
+
+// PublishRights — право публиковать события, которое дают не глобальные роли (DraftEditorRoles),
+// а членство: редактор или владелец команды события, владелец или администратор организации.
+// Реализует auth.PublishRights — таким пользователям двухфакторная аутентификация обязательна так же,
+// как редакторам по роли.
+type PublishRights struct {
+	members MemberRepository
+	orgs    OrganizationMemberships
+}
+
+// NewPublishRights создаёт проверку права публикации поверх команд событий и членства в организациях.
+func NewPublishRights(members MemberRepository, orgs OrganizationMemberships) *PublishRights {
+	return &PublishRights{members: members, orgs: orgs}
+}
+
+// CanPublish — userID может публиковать хотя бы одно событие благодаря членству в команде или организации.
+func (p *PublishRights) CanPublish(ctx context.Context, userID string) (bool, error) {
+	eventIDs, err := p.members.ListEventIDsByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, eventID := range eventIDs {
+		m, err := p.members.Get(ctx, eventID, userID)
+		if err != nil {
+			return false, err
+		}
+		if m != nil && m.Can(MemberEditor) {
+			return true, nil
+		}
+	}
+	memberships, err := p.orgs.ListByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, ms := range memberships {
+		if ms.Role == organizations.RoleOwner || ms.Role == organizations.RoleAdmin {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"wdpl_back/internal/shared/audit"
+)
+
+// Действия журнала аудита над черновиками и событиями. Записи пишутся в транзакции самого изменения.
+const (
+	AuditTargetEvent      = "event"
+	AuditTargetEventDraft = "event_draft"
+	AuditTargetDayDraft   = "event_day_draft"
+
+	ActionDraftSaved       = "event_draft.saved"
+	ActionDraftDeleted     = "event_draft.deleted"
+	ActionDayDraftSaved    = "event_day_draft.saved"
+	ActionEventPublished   = "event.published"
+	ActionEventUnpublished = "event.unpublished"
+)
+
+// draftState — снимок черновика события для before/after (nil-черновик — без состояния).
+type draftState struct {
+	EventID        *string `json:"eventId,omitempty"`
+	OrganizationID string  `json:"organizationId,omitempty"`
+	Title          string  `json:"title"`
+	Description    *string `json:"description,omitempty"`
+	StartDate      string  `json:"startDate"`
+	EndDate        string  `json:"endDate"`
+	Timezone       string  `json:"timezone"`
+	Capacity       *int    `json:"capacity,omitempty"`
+}
+
+func draftStateOf(d *EventDraft) any {
+	if d == nil {
+		return nil
+	}
+	return draftState{
+		EventID:        d.EventID,
+		OrganizationID: d.OrganizationID,
+		Title:          d.Title,
+		Description:    d.Description,
+		StartDate:      d.StartDate.Format(dateLayout),
+		EndDate:        d.EndDate.Format(dateLayout),
+		Timezone:       d.Timezone,
+		Capacity:       d.Capacity,
+	}
+}
+
+// dayDraftState — снимок черновика дня: дата и расписание как есть.
+type dayDraftState struct {
+	EventID  string          `json:"eventId"`
+	Date     string          `json:"date"`
+	Schedule json.RawMessage `json:"schedule,omitempty"`
+}
+
+func dayDraftStateOf(d *EventDayDraft) any {
+	if d == nil {
+		return nil
+	}
+	return dayDraftState{EventID: d.EventID, Date: d.Date.Format(dateLayout), Schedule: d.Schedule}
+}
+
+// eventState — снимок опубликованного события; DraftID — из какого черновика (только в after публикации).
+type eventState struct {
+	OrganizationID string  `json:"organizationId,omitempty"`
+	Title          string  `json:"title"`
+	Description    *string `json:"description,omitempty"`
+	StartDate      string  `json:"startDate"`
+	EndDate        string  `json:"endDate"`
+	Timezone       string  `json:"timezone"`
+	Capacity       *int    `json:"capacity,omitempty"`
+	Status         string  `json:"status,omitempty"`
+	DraftID        string  `json:"draftId,omitempty"`
+	Days           int     `json:"days,omitempty"`
+}
+
+func eventStateOf(e *Event) eventState {
+	return eventState{
+		OrganizationID: e.OrganizationID,
+		Title:          e.Title,
+		Description:    e.Description,
+		StartDate:      e.StartDate.Format(dateLayout),
+		EndDate:        e.EndDate.Format(dateLayout),
+		Timezone:       e.Timezone,
+		Capacity:       e.Capacity,
+		Status:         e.Status,
+	}
+}
+
+// appendAudit пишет запись от имени actor (с его IP, User-Agent и API-ключом) в журнал транзакции r.
+func appendAudit(ctx context.Context, r *TxRepositories, actor Actor, action, targetType, targetID string, before, after any) error {
+	entry, err := audit.NewEntry(actor.UserID, action, targetType, targetID, before, after)
+	if err != nil {
+		return err
+	}
+	entry.IP = actor.IP
+	entry.UserAgent = actor.UserAgent
+	entry.APIKeyID = actor.APIKeyID
+	return r.Audit.Append(ctx, entry)
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// ChangeHub будит SSE-потоки события, когда в журнале появились изменения.
+// Сигнал не несёт данных: поток сам читает журнал после своего последнего id, поэтому
+// склеенные или потерянные сигналы ничего не ломают. Источник сигналов — Postgres NOTIFY
+// (postgres.DB.Listen), так что публикация на одной реплике доходит до потоков на всех.
+type ChangeHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+// NewChangeHub создаёт хаб сигналов.
+func NewChangeHub() *ChangeHub {
+	return &ChangeHub{subs: make(map[string]map[chan struct{}]struct{})}
+}
+
+// Subscribe подписывает на сигналы события. unsubscribe обязательно вызвать при отключении клиента.
+func (h *ChangeHub) Subscribe(eventID string) (signals <-chan struct{}, unsubscribe func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.subs[eventID] == nil {
+		h.subs[eventID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[eventID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs[eventID], ch)
+			if len(h.subs[eventID]) == 0 {
+				delete(h.subs, eventID)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// HandleNotification — NOTIFY event_changes: payload — id события.
+func (h *ChangeHub) HandleNotification(eventID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[eventID] {
+		signal(ch)
+	}
+}
+
+// HandleReconnect — после разрыва LISTEN будим все потоки: пропущенное они дочитают из журнала.
+func (h *ChangeHub) HandleReconnect() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			signal(ch)
+		}
+	}
+}
+
+// signal не блокирует: если сигнал уже ждёт обработки, второй не нужен.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// diffPublishedDays возвращает изменения расписания при публикации: day_changed для новых дней
+// и дней с другим набором сессий, session_cancelled для публичных сессий, ставших cancelled.
+func diffPublishedDays(eventID string, previous, published []*EventDay) ([]*Change, error) {
+	before := make(map[string]*EventDay, len(previous))
+	for _, day := range previous {
+		before[day.Date.Format(dateLayout)] = day
+	}
+
+	var changes []*Change
+	for _, day := range published {
+		date := day.Date
+		next, err := day.ParseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		old, existed := before[date.Format(dateLayout)]
+		var prev *DaySchedule
+		if existed {
+			if prev, err = old.ParseSchedule(); err != nil {
+				return nil, err
+			}
+		}
+		if !existed || !sameSessions(prev.Sessions, next.Sessions) {
+			changes = append(changes, &Change{EventID: eventID, Kind: ChangeDayChanged, Date: &date})
+		}
+		if prev == nil {
+			continue
+		}
+		wasActive := make(map[string]bool, len(prev.Sessions))
+		for _, s := range prev.Sessions {
+			wasActive[s.ID] = s.IsPublic && s.Status != SessionStatusCancelled
+		}
+		for _, s := range next.Sessions {
+			if s.IsPublic && s.Status == SessionStatusCancelled && wasActive[s.ID] {
+				changes = append(changes, &Change{EventID: eventID, Kind: ChangeSessionCancelled, Date: &date, SessionID: s.ID})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// sameSessions сравнивает расписания по содержимому: JSONB из БД и свежий JSON отличаются форматированием.
+func sameSessions(a, b []SessionData) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return string(ja) == string(jb)
+}
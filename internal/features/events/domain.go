@@ -6,15 +6,31 @@ import (
 )
 
 // Event — публикованное событие (STEP4: домен публичных данных).
+// Capacity — лимит регистраций на событие (nil — без ограничения). OrganizationID — организация-владелец.
 type Event struct {
-	ID          string
-	Title       string
-	Description *string
-	StartDate   time.Time
-	EndDate     time.Time
-	Status      string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             string
+	OrganizationID string
+	Title          string
+	Description    *string
+	StartDate      time.Time
+	EndDate        time.Time
+	Timezone       string
+	Capacity       *int
+	Status         string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Статусы опубликованного события (events.status). Снятое с публикации (archived) публичный API не показывает;
+// следующая публикация черновика возвращает его.
+const (
+	EventStatusPublished = "published"
+	EventStatusArchived  = "archived"
+)
+
+// IsArchived — событие снято с публикации.
+func (e *Event) IsArchived() bool {
+	return e.Status == EventStatusArchived
 }
 
 // EventDay — опубликованный день события (расписание по дню в JSONB).
@@ -30,22 +46,35 @@ type EventDay struct {
 	UpdatedAt         time.Time
 }
 
-// EventDraft — черновик события (published_at, created_by). Доступ только у организаторов/админов/редакторов.
+// EventDraft — черновик события (published_at, created_by). Доступ — у команды события (Member) и admin.
+// OrganizationID — организация будущего события; у существующего события или черновика не меняется.
 type EventDraft struct {
-	ID          string
-	EventID     *string
-	Title       string
-	Description *string
-	StartDate   time.Time
-	EndDate     time.Time
-	PublishedAt *time.Time
-	CreatedBy   string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             string
+	EventID        *string
+	OrganizationID string
+	Title          string
+	Description    *string
+	StartDate      time.Time
+	EndDate        time.Time
+	Timezone       string
+	Capacity       *int
+	PublishedAt    *time.Time
+	CreatedBy      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TargetEventID — id события, которое опубликует черновик: EventID существующего события
+// или id самого черновика для нового.
+func (d *EventDraft) TargetEventID() string {
+	if d.EventID != nil {
+		return *d.EventID
+	}
+	return d.ID
 }
 
 // EventDayDraft — черновик дня события (расписание по дню в JSONB).
-// Допускаются незавершённые данные; доступ только у организаторов/админов/редакторов.
+// Допускаются незавершённые данные; доступ — у команды события (Member) и admin.
 type EventDayDraft struct {
 	ID          string
 	EventID     string
@@ -56,3 +85,202 @@ type EventDayDraft struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
+
+// DaySchedule — структура JSONB schedule в event_days/event_days_drafts (см. docs/SUPABASE.md).
+type DaySchedule struct {
+	Sessions []SessionData `json:"sessions"`
+}
+
+// SessionData — сессия внутри расписания дня. Поля в snake_case, как в JSONB и на клиентах.
+type SessionData struct {
+	ID            string                 `json:"id"`
+	SessionTypeID string                 `json:"session_type_id"`
+	Title         string                 `json:"title"`
+	Description   *string                `json:"description,omitempty"`
+	StartsAt      time.Time              `json:"starts_at"`
+	EndsAt        time.Time              `json:"ends_at"`
+	IsPublic      bool                   `json:"is_public"`
+	Status        string                 `json:"status"`
+	Capacity      *int                   `json:"capacity,omitempty"`
+	Location      *Location              `json:"location,omitempty"`
+	Participants  []ParticipantReference `json:"participants"`
+	Teams         []TeamReference        `json:"teams"`
+}
+
+// Location — место проведения сессии.
+type Location struct {
+	Type    string  `json:"type"`
+	Address *string `json:"address,omitempty"`
+	URL     *string `json:"url,omitempty"`
+}
+
+// ParticipantReference — ссылка на участника сессии (спикер, модератор, жюри и т.п.).
+type ParticipantReference struct {
+	PersonID   string  `json:"person_id"`
+	Role       string  `json:"role"`
+	PersonName *string `json:"person_name,omitempty"`
+}
+
+// TeamReference — ссылка на команду, участвующую в сессии.
+type TeamReference struct {
+	TeamID   string  `json:"team_id"`
+	Purpose  string  `json:"purpose"`
+	TeamName *string `json:"team_name,omitempty"`
+}
+
+// Статусы сессии в расписании.
+const (
+	SessionStatusPlanned   = "planned"
+	SessionStatusPublished = "published"
+	SessionStatusCancelled = "cancelled"
+)
+
+// IsPubliclyVisible — видна ли сессия анонимам и роли user: только is_public и status=published.
+// planned — внутренняя заготовка, cancelled — снятая с программы; обе видят только редакторы.
+func (s *SessionData) IsPubliclyVisible() bool {
+	return s.IsPublic && s.Status == SessionStatusPublished
+}
+
+// HasParticipant сообщает, участвует ли person_id в сессии (в любой роли).
+func (s *SessionData) HasParticipant(personID string) bool {
+	for _, p := range s.Participants {
+		if p.PersonID == personID {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSchedule разбирает JSONB расписания дня. Пустой schedule трактуется как день без сессий.
+func (d *EventDay) ParseSchedule() (*DaySchedule, error) {
+	var schedule DaySchedule
+	if len(d.Schedule) == 0 {
+		return &schedule, nil
+	}
+	if err := json.Unmarshal(d.Schedule, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// WithSessions возвращает копию дня с расписанием из sessions и пересчитанными
+// session_count, first_session_start и last_session_end — метаданные всегда соответствуют отдаваемому schedule.
+func (d *EventDay) WithSessions(sessions []SessionData) (*EventDay, error) {
+	if sessions == nil {
+		sessions = []SessionData{}
+	}
+	raw, err := json.Marshal(DaySchedule{Sessions: sessions})
+	if err != nil {
+		return nil, err
+	}
+	day := *d
+	day.Schedule = raw
+	day.SessionCount, day.FirstSessionStart, day.LastSessionEnd = scheduleStats(sessions)
+	return &day, nil
+}
+
+// scheduleStats считает метаданные дня. Берём min/max по всем сессиям, а не первую/последнюю в массиве:
+// порядок сессий в JSONB не гарантирован.
+func scheduleStats(sessions []SessionData) (count *int, first, last *time.Time) {
+	n := len(sessions)
+	count = &n
+	for i := range sessions {
+		starts, ends := sessions[i].StartsAt, sessions[i].EndsAt
+		if first == nil || starts.Before(*first) {
+			first = &starts
+		}
+		if last == nil || ends.After(*last) {
+			last = &ends
+		}
+	}
+	return count, first, last
+}
+
+// DaySession — сессия вместе с днём, в который она входит (плоский список сессий события).
+type DaySession struct {
+	Day     *EventDay
+	Session SessionData
+}
+
+// SessionFilter — фильтры публичного списка сессий. Пустые поля не ограничивают выборку.
+type SessionFilter struct {
+	Type     string
+	Date     *time.Time
+	PersonID string
+	// IncludeHidden — показывать ли скрытые от публики сессии (см. SessionData.IsPubliclyVisible).
+	IncludeHidden bool
+}
+
+// Match проверяет, подходит ли сессия дня под фильтр.
+func (f SessionFilter) Match(day *EventDay, s *SessionData) bool {
+	if !f.IncludeHidden && !s.IsPubliclyVisible() {
+		return false
+	}
+	if f.Type != "" && s.SessionTypeID != f.Type {
+		return false
+	}
+	if f.Date != nil && !sameDate(day.Date, *f.Date) {
+		return false
+	}
+	if f.PersonID != "" && !s.HasParticipant(f.PersonID) {
+		return false
+	}
+	return true
+}
+
+// sameDate сравнивает календарные даты (DATE из БД приходит как полночь UTC).
+func sameDate(a, b time.Time) bool {
+	return a.Format(dateLayout) == b.Format(dateLayout)
+}
+
+// dateLayout — формат дат в API и в колонках DATE.
+const dateLayout = "2006-01-02"
+
+// LiveRoom — что идёт сейчас и что будет следующим в одной локации (экраны на площадке).
+type LiveRoom struct {
+	Location *Location
+	Now      []SessionData
+	Next     *SessionData
+}
+
+// LiveSnapshot — состояние «сейчас и далее» по всем локациям события на момент At.
+type LiveSnapshot struct {
+	EventID  string
+	At       time.Time
+	Timezone string
+	Rooms    []LiveRoom
+}
+
+// locationKey — ключ группировки сессий по локации. В Location нет id зала,
+// поэтому различаем по адресу, затем по URL, затем по типу; без локации — общая группа "".
+func locationKey(l *Location) string {
+	switch {
+	case l == nil:
+		return ""
+	case l.Address != nil && *l.Address != "":
+		return "address:" + *l.Address
+	case l.URL != nil && *l.URL != "":
+		return "url:" + *l.URL
+	default:
+		return "type:" + l.Type
+	}
+}
+
+// Типы изменений опубликованного события (журнал event_changes, SSE /events/:id/stream).
+const (
+	ChangeEventPublished   = "event_published"
+	ChangeDayChanged       = "day_changed"
+	ChangeSessionCancelled = "session_cancelled"
+	ChangeEventUnpublished = "event_unpublished"
+)
+
+// Change — запись журнала изменений. ID монотонно растёт и служит SSE id (Last-Event-ID).
+// Date — для day_changed и session_cancelled, SessionID — для session_cancelled.
+type Change struct {
+	ID        int64
+	EventID   string
+	Kind      string
+	Date      *time.Time
+	SessionID string
+	CreatedAt time.Time
+}
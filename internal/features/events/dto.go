@@ -7,14 +7,17 @@ import (
 
 // EventResponse — опубликованное событие в ответе API (STEP4).
 type EventResponse struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description *string   `json:"description,omitempty"`
-	StartDate   time.Time `json:"startDate"`
-	EndDate     time.Time `json:"endDate"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organizationId"`
+	Title          string    `json:"title"`
+	Description    *string   `json:"description,omitempty"`
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	Timezone       string    `json:"timezone"`
+	Capacity       *int      `json:"capacity,omitempty"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
 
 // EventDayResponse — опубликованный день события в ответе API.
@@ -36,28 +39,69 @@ type EventWithDaysResponse struct {
 	Days  []EventDayResponse `json:"days"`
 }
 
+// SessionResponse — сессия из опубликованного расписания (GET /events/:id/sessions[/:sessionId]).
+// Сама сессия отдаётся в том же формате, что и внутри schedule.
+type SessionResponse struct {
+	EventID string      `json:"eventId"`
+	DayID   string      `json:"dayId"`
+	Date    time.Time   `json:"date"`
+	Session SessionData `json:"session"`
+}
+
+// LiveResponse — «сейчас и далее» по локациям (GET /events/:id/live и поток /live/stream).
+type LiveResponse struct {
+	EventID  string             `json:"eventId"`
+	At       time.Time          `json:"at"`
+	Timezone string             `json:"timezone"`
+	Rooms    []LiveRoomResponse `json:"rooms"`
+}
+
+// ChangeResponse — запись журнала изменений в потоке GET /events/:id/stream (data SSE-события).
+type ChangeResponse struct {
+	ID        int64      `json:"id"`
+	EventID   string     `json:"eventId"`
+	Kind      string     `json:"kind"`
+	Date      *time.Time `json:"date,omitempty"`
+	SessionID string     `json:"sessionId,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// LiveRoomResponse — текущие и следующая сессии одной локации.
+type LiveRoomResponse struct {
+	Location *Location     `json:"location,omitempty"`
+	Now      []SessionData `json:"now"`
+	Next     *SessionData  `json:"next,omitempty"`
+}
+
 // DraftResponse — черновик события в ответе API.
 type DraftResponse struct {
-	ID          string     `json:"id"`
-	EventID     *string    `json:"eventId,omitempty"`
-	Title       string     `json:"title"`
-	Description *string    `json:"description,omitempty"`
-	StartDate   time.Time  `json:"startDate"`
-	EndDate     time.Time  `json:"endDate"`
-	PublishedAt *time.Time `json:"publishedAt,omitempty"`
-	CreatedBy   string     `json:"createdBy"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID             string     `json:"id"`
+	EventID        *string    `json:"eventId,omitempty"`
+	OrganizationID string     `json:"organizationId"`
+	Title          string     `json:"title"`
+	Description    *string    `json:"description,omitempty"`
+	StartDate      time.Time  `json:"startDate"`
+	EndDate        time.Time  `json:"endDate"`
+	Timezone       string     `json:"timezone"`
+	Capacity       *int       `json:"capacity,omitempty"`
+	PublishedAt    *time.Time `json:"publishedAt,omitempty"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
 }
 
 // SaveDraftRequest — тело запроса на сохранение черновика (created_by задаёт бэкенд из JWT).
 type SaveDraftRequest struct {
-	ID          string  `json:"id"`
-	EventID     *string `json:"eventId,omitempty"`
-	Title       string  `json:"title"`
-	Description *string `json:"description,omitempty"`
-	StartDate   string  `json:"startDate"`
-	EndDate     string  `json:"endDate"`
+	ID      string  `json:"id"`
+	EventID *string `json:"eventId,omitempty"`
+	// Organization — slug организации нового события; не задан — организация события или по умолчанию.
+	Organization string  `json:"organization,omitempty"`
+	Title        string  `json:"title"`
+	Description  *string `json:"description,omitempty"`
+	StartDate    string  `json:"startDate"`
+	EndDate      string  `json:"endDate"`
+	Timezone     string  `json:"timezone,omitempty"` // IANA-зона, например "Europe/Moscow"; по умолчанию UTC
+	Capacity     *int    `json:"capacity,omitempty"` // лимит регистраций; не задан — без ограничения
 }
 
 // DayDraftResponse — черновик дня события в ответе API.
@@ -78,3 +122,18 @@ type SaveDayDraftRequest struct {
 	Date     string          `json:"date"`
 	Schedule json.RawMessage `json:"schedule"`
 }
+
+// MemberResponse — участник команды события.
+type MemberResponse struct {
+	UserID    string    `json:"userId"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy *string   `json:"invitedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AddMemberRequest — тело POST /api/events/:eventId/members. role: owner, editor или viewer.
+type AddMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
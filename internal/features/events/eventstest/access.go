@@ -0,0 +1,135 @@
+package eventstest
+
+import (
+	"context"
+	"sync"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/organizations"
+)
+
+// MemberRepository — events.MemberRepository в памяти.
+type MemberRepository struct {
+	mu      sync.Mutex
+	members []*events.Member
+}
+
+// Add добавляет userID в команду события eventID с ролью role.
+func (r *MemberRepository) Add(eventID, userID, role string) {
+	_ = r.Upsert(context.Background(), &events.Member{EventID: eventID, UserID: userID, Role: role})
+}
+
+func (r *MemberRepository) Get(_ context.Context, eventID, userID string) (*events.Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.members {
+		if m.EventID == eventID && m.UserID == userID {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemberRepository) ListByEventID(_ context.Context, eventID string) ([]*events.Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*events.Member
+	for _, m := range r.members {
+		if m.EventID == eventID {
+			list = append(list, m)
+		}
+	}
+	return list, nil
+}
+
+func (r *MemberRepository) ListEventIDsByUserID(_ context.Context, userID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []string
+	for _, m := range r.members {
+		if m.UserID == userID {
+			ids = append(ids, m.EventID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *MemberRepository) Upsert(_ context.Context, member *events.Member) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, m := range r.members {
+		if m.EventID == member.EventID && m.UserID == member.UserID {
+			r.members[i] = member
+			return nil
+		}
+	}
+	r.members = append(r.members, member)
+	return nil
+}
+
+func (r *MemberRepository) Delete(_ context.Context, eventID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, m := range r.members {
+		if m.EventID == eventID && m.UserID == userID {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *MemberRepository) LockOwners(_ context.Context, eventID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var owners []string
+	for _, m := range r.members {
+		if m.EventID == eventID && m.Role == events.MemberOwner {
+			owners = append(owners, m.UserID)
+		}
+	}
+	return owners, nil
+}
+
+// OrganizationDirectory — events.OrganizationDirectory в памяти: только членство в организациях.
+type OrganizationDirectory struct {
+	mu      sync.Mutex
+	members []*organizations.Member
+}
+
+// AddMember добавляет userID в организацию orgID с ролью role.
+func (d *OrganizationDirectory) AddMember(orgID, userID, role string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.members = append(d.members, &organizations.Member{OrganizationID: orgID, UserID: userID, Role: role})
+}
+
+func (d *OrganizationDirectory) GetBySlug(context.Context, string) (*organizations.Organization, error) {
+	return nil, nil
+}
+
+func (d *OrganizationDirectory) GetMember(_ context.Context, orgID, userID string) (*organizations.Member, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, m := range d.members {
+		if m.OrganizationID == orgID && m.UserID == userID {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// NewAccess — настоящая проверка прав events.Access поверх опубликованных событий eventsRepo,
+// команд members и организаций orgs (черновиков нет).
+func NewAccess(eventsRepo events.EventRepository, members *MemberRepository, orgs *OrganizationDirectory) *events.Access {
+	return events.NewAccess(eventsRepo, noDrafts{}, members, orgs)
+}
+
+// noDrafts — events.EventDraftRepository без черновиков: организация берётся из опубликованного события.
+type noDrafts struct{}
+
+func (noDrafts) GetByID(context.Context, string) (*events.EventDraft, error)      { return nil, nil }
+func (noDrafts) GetByEventID(context.Context, string) (*events.EventDraft, error) { return nil, nil }
+func (noDrafts) ListDrafts(context.Context, string) ([]*events.EventDraft, error) { return nil, nil }
+func (noDrafts) Upsert(context.Context, *events.EventDraft) error                 { return nil }
+func (noDrafts) DeleteByID(context.Context, string) error                         { return nil }
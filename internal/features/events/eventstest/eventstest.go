@@ -0,0 +1,185 @@
+// Package eventstest — тестовые двойники репозиториев опубликованных событий и сборщик расписания
+// для тестов фич, которые читают события через events (регистрации, оценки, вопросы, личная программа).
+// Фикстура по умолчанию — событие EventID организации OrganizationID с одним днём DayID на дату Date.
+package eventstest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"wdpl_back/internal/features/events"
+)
+
+const (
+	OrganizationID = "org-1"
+	EventID        = "event-1"
+	DayID          = "day-1"
+)
+
+// Date — дата дня фикстуры (UTC).
+var Date = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// EventRepository — events.EventRepository в памяти.
+type EventRepository struct {
+	mu   sync.Mutex
+	byID map[string]*events.Event
+}
+
+// NewEventRepository создаёт репозиторий с событиями list.
+func NewEventRepository(list ...*events.Event) *EventRepository {
+	r := &EventRepository{byID: make(map[string]*events.Event)}
+	for _, e := range list {
+		r.byID[e.ID] = e
+	}
+	return r
+}
+
+func (r *EventRepository) GetByID(_ context.Context, id string) (*events.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.byID[id], nil
+}
+
+func (r *EventRepository) List(_ context.Context, orgID string, _, _ int) ([]*events.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*events.Event
+	for _, e := range r.byID {
+		if e.OrganizationID == orgID && !e.IsArchived() {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+func (r *EventRepository) Upsert(_ context.Context, event *events.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID[event.ID] = event
+	return nil
+}
+
+// DayRepository — events.EventDayRepository в памяти. Days можно менять в тесте (перепубликация).
+type DayRepository struct {
+	mu   sync.Mutex
+	Days []*events.EventDay
+}
+
+func (r *DayRepository) ListByEventID(_ context.Context, eventID string) ([]*events.EventDay, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*events.EventDay
+	for _, d := range r.Days {
+		if d.EventID == eventID {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+func (r *DayRepository) GetByEventIDAndDate(_ context.Context, eventID string, date time.Time) (*events.EventDay, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.Days {
+		if d.EventID == eventID && d.Date.Equal(date) {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *DayRepository) Upsert(_ context.Context, day *events.EventDay) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, d := range r.Days {
+		if d.EventID == day.EventID && d.Date.Equal(day.Date) {
+			r.Days[i] = day
+			return nil
+		}
+	}
+	r.Days = append(r.Days, day)
+	return nil
+}
+
+// SessionOption настраивает сессию, собранную Session.
+type SessionOption func(*events.SessionData)
+
+// Capacity — лимит мест сессии.
+func Capacity(n int) SessionOption {
+	return func(s *events.SessionData) { s.Capacity = &n }
+}
+
+// Hidden — сессия скрыта от публики (is_public = false).
+func Hidden() SessionOption {
+	return func(s *events.SessionData) { s.IsPublic = false }
+}
+
+// Status — статус сессии (events.SessionStatusCancelled и т.п.).
+func Status(status string) SessionOption {
+	return func(s *events.SessionData) { s.Status = status }
+}
+
+// Participant добавляет участника сессии; name == "" — без имени.
+func Participant(personID, role, name string) SessionOption {
+	return func(s *events.SessionData) {
+		p := events.ParticipantReference{PersonID: personID, Role: role}
+		if name != "" {
+			p.PersonName = &name
+		}
+		s.Participants = append(s.Participants, p)
+	}
+}
+
+// Session — публичная опубликованная сессия дня Date с from до to ("15:04", UTC).
+func Session(id, title, from, to string, opts ...SessionOption) events.SessionData {
+	s := events.SessionData{
+		ID:       id,
+		Title:    title,
+		StartsAt: at(from),
+		EndsAt:   at(to),
+		IsPublic: true,
+		Status:   events.SessionStatusPublished,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// JuryBriefing — скрытая служебная сессия 09:00–09:30, которую публика не видит.
+func JuryBriefing(id string) events.SessionData {
+	return Session(id, "Jury briefing", "09:00", "09:30", Hidden())
+}
+
+// Day — день DayID события EventID на дату Date с расписанием из sessions.
+func Day(t testing.TB, sessions ...events.SessionData) *events.EventDay {
+	t.Helper()
+	day, err := (&events.EventDay{ID: DayID, EventID: EventID, Date: Date}).WithSessions(sessions)
+	if err != nil {
+		t.Fatalf("eventstest: build day: %v", err)
+	}
+	return day
+}
+
+// Fixture — опубликованное событие event (пустой ID — EventID, пустая организация — OrganizationID)
+// с одним днём расписания из sessions.
+func Fixture(t testing.TB, event events.Event, sessions ...events.SessionData) (*EventRepository, *DayRepository) {
+	t.Helper()
+	if event.ID == "" {
+		event.ID = EventID
+	}
+	if event.OrganizationID == "" {
+		event.OrganizationID = OrganizationID
+	}
+	return NewEventRepository(&event), &DayRepository{Days: []*events.EventDay{Day(t, sessions...)}}
+}
+
+func at(clock string) time.Time {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		panic("eventstest: invalid time " + clock)
+	}
+	return Date.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+}
@@ -1,15 +1,21 @@
 package events
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 
-	"wdpl_back/internal/shared/authutils"
 	"wdpl_back/internal/shared/http/handler"
 	"wdpl_back/internal/shared/http/middleware"
 	"wdpl_back/internal/shared/http/response"
+	"wdpl_back/internal/shared/http/sse"
 )
 
 // Handler реализует HTTP‑эндпоинты для событий (публичные) и черновиков (защищённые).
@@ -22,13 +28,52 @@ func NewHandler(service *Service) *Handler {
 	return &Handler{service: service}
 }
 
-// ListEvents — GET /api/events (публичный, пагинация: limit, offset).
+// localsKeyOrgScope — ключ orgScope в fiber.Ctx.Locals (см. OrgScope).
+const localsKeyOrgScope = "eventsOrgScope"
+
+// orgScope — организация, событиями которой ограничен публичный запрос.
+type orgScope struct {
+	OrganizationID string
+	// IncludeHidden — вызывающий видит всё расписание событий организации (см. Service.CanSeeHiddenSessions).
+	IncludeHidden bool
+}
+
+// OrgScope — middleware публичных маршрутов: находит организацию (:slug из /api/orgs/:slug/events,
+// без него — организацию по умолчанию) и кладёт orgScope в Locals. Ставится после OptionalAuth.
+func (h *Handler) OrgScope(c *fiber.Ctx) error {
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	org, err := h.service.ResolveOrganization(ctx, c.Params("slug"))
+	if err != nil {
+		if errors.Is(err, ErrOrganizationNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, err.Error())
+		}
+		return response.WriteInternalError(c, err)
+	}
+	scope := orgScope{OrganizationID: org.ID}
+	if actor, ok := actorFromCtx(c); ok {
+		if scope.IncludeHidden, err = h.service.CanSeeHiddenSessions(ctx, actor, org.ID); err != nil {
+			return response.WriteInternalError(c, err)
+		}
+	}
+	c.Locals(localsKeyOrgScope, scope)
+	return c.Next()
+}
+
+// scopeFromCtx возвращает orgScope запроса. Без OrgScope — пустая организация: ни одно событие не подходит.
+func scopeFromCtx(c *fiber.Ctx) orgScope {
+	scope, _ := c.Locals(localsKeyOrgScope).(orgScope)
+	return scope
+}
+
+// ListEvents — GET /api/events, GET /api/orgs/:slug/events (публичный, пагинация: limit, offset).
 func (h *Handler) ListEvents(c *fiber.Ctx) error {
 	limit, offset := handler.LimitOffset(c, 20, 100, 0)
 	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
 	defer cancel()
 
-	list, err := h.service.ListPublishedEvents(ctx, limit, offset)
+	list, err := h.service.ListPublishedEvents(ctx, scopeFromCtx(c).OrganizationID, limit, offset)
 	if err != nil {
 		return response.WriteInternalError(c, err)
 	}
@@ -39,7 +84,7 @@ func (h *Handler) ListEvents(c *fiber.Ctx) error {
 	return c.JSON(resp)
 }
 
-// GetEvent — GET /api/events/:id (публичный, событие + дни).
+// GetEvent — GET /api/events/:id (публичный, событие + дни; скрытые сессии — только редакторам организации).
 func (h *Handler) GetEvent(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
@@ -48,7 +93,8 @@ func (h *Handler) GetEvent(c *fiber.Ctx) error {
 	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
 	defer cancel()
 
-	event, days, err := h.service.GetEventWithDays(ctx, id)
+	scope := scopeFromCtx(c)
+	event, days, err := h.service.GetEventWithDays(ctx, scope.OrganizationID, id, scope.IncludeHidden)
 	if err != nil {
 		return response.WriteInternalError(c, err)
 	}
@@ -62,8 +108,266 @@ func (h *Handler) GetEvent(c *fiber.Ctx) error {
 	return c.JSON(EventWithDaysResponse{Event: eventToResponse(event), Days: daysResp})
 }
 
+// GetEventDay — GET /api/events/:id/days/:date (публичный, один день с расписанием).
+func (h *Handler) GetEventDay(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	date, err := time.Parse(dateLayout, c.Params("date"))
+	if err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid date")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	scope := scopeFromCtx(c)
+	day, err := h.service.GetEventDay(ctx, scope.OrganizationID, id, date, scope.IncludeHidden)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	if day == nil {
+		return response.WriteError(c, fiber.StatusNotFound, "day not found")
+	}
+	return c.JSON(eventDayToResponse(day))
+}
+
+// ListSessions — GET /api/events/:id/sessions (публичный, плоский список; фильтры: type, date, person).
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	scope := scopeFromCtx(c)
+	filter := SessionFilter{
+		Type:          c.Query("type"),
+		PersonID:      c.Query("person"),
+		IncludeHidden: scope.IncludeHidden,
+	}
+	if raw := c.Query("date"); raw != "" {
+		date, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			return response.WriteError(c, fiber.StatusBadRequest, "invalid date")
+		}
+		filter.Date = &date
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListSessions(ctx, scope.OrganizationID, id, filter)
+	if err != nil {
+		if errors.Is(err, ErrEventNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "event not found")
+		}
+		return response.WriteInternalError(c, err)
+	}
+	resp := make([]SessionResponse, 0, len(list))
+	for _, ds := range list {
+		resp = append(resp, daySessionToResponse(ds))
+	}
+	return c.JSON(resp)
+}
+
+// GetSession — GET /api/events/:id/sessions/:sessionId (публичный, одна сессия из JSONB дня).
+func (h *Handler) GetSession(c *fiber.Ctx) error {
+	id := c.Params("id")
+	sessionID := c.Params("sessionId")
+	if id == "" || sessionID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	scope := scopeFromCtx(c)
+	ds, err := h.service.GetSession(ctx, scope.OrganizationID, id, sessionID, scope.IncludeHidden)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	if ds == nil {
+		return response.WriteError(c, fiber.StatusNotFound, "session not found")
+	}
+	return c.JSON(daySessionToResponse(ds))
+}
+
+// liveStreamInterval — как часто поток /live/stream пересчитывает состояние.
+// Точность экранов на площадке — до минуты, чаще опрашивать БД незачем.
+const liveStreamInterval = 15 * time.Second
+
+// GetLive — GET /api/events/:id/live?at=RFC3339 (публичный; по умолчанию at = сейчас).
+func (h *Handler) GetLive(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return response.WriteError(c, fiber.StatusBadRequest, "invalid at")
+		}
+		at = parsed
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	snapshot, err := h.service.GetLive(ctx, scopeFromCtx(c).OrganizationID, id, at)
+	if err != nil {
+		if errors.Is(err, ErrEventNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "event not found")
+		}
+		return response.WriteInternalError(c, err)
+	}
+	return c.JSON(liveToResponse(snapshot))
+}
+
+// StreamLive — GET /api/events/:id/live/stream (SSE). Шлёт событие "live" при подключении
+// и затем каждый раз, когда меняется набор текущих/следующих сессий; в остальное время — keep-alive.
+func (h *Handler) StreamLive(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	orgID := scopeFromCtx(c).OrganizationID
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	snapshot, err := h.service.GetLive(ctx, orgID, id, time.Now())
+	cancel()
+	if err != nil {
+		if errors.Is(err, ErrEventNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "event not found")
+		}
+		return response.WriteInternalError(c, err)
+	}
+
+	sse.SetHeaders(c)
+	// Writer выполняется после выхода из хендлера: fiber.Ctx здесь уже недоступен, контексты — свои.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var lastRooms []byte
+		ticker := time.NewTicker(liveStreamInterval)
+		defer ticker.Stop()
+		for {
+			if snapshot != nil {
+				resp := liveToResponse(snapshot)
+				rooms, _ := json.Marshal(resp.Rooms)
+				if !bytes.Equal(rooms, lastRooms) {
+					data, _ := json.Marshal(resp)
+					if err := sse.WriteEvent(w, sse.Event{Name: "live", Data: data}); err != nil {
+						return
+					}
+					lastRooms = rooms
+				} else if err := sse.WriteComment(w, "ping"); err != nil {
+					return
+				}
+			}
+			<-ticker.C
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			snapshot, err = h.service.GetLive(ctx, orgID, id, time.Now())
+			cancel()
+			if err != nil {
+				// Временная ошибка БД не должна рвать экран: пропускаем тик, клиент видит прежнее состояние.
+				snapshot = nil
+				if err := sse.WriteComment(w, "retry"); err != nil {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// StreamChanges — GET /api/events/:id/stream (SSE). Шлёт записи журнала изменений события:
+// event_published, day_changed, session_cancelled; id SSE-события — id записи. При переподключении
+// браузер присылает Last-Event-ID (или ?lastEventId=) — пропущенные записи досылаются из журнала.
+func (h *Handler) StreamChanges(c *fiber.Ctx) error {
+	// id живёт дольше запроса (writer, ключ подписки), а строки из c.Params Fiber переиспользует.
+	id := utils.CopyString(c.Params("id"))
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	lastEventID := c.Get("Last-Event-ID", c.Query("lastEventId"))
+	var resumeFrom int64
+	if lastEventID != "" {
+		parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || parsed < 0 {
+			return response.WriteError(c, fiber.StatusBadRequest, "invalid Last-Event-ID")
+		}
+		resumeFrom = parsed
+	}
+
+	// Подписываемся до чтения журнала: изменение между чтением и подпиской не потеряется.
+	signals, unsubscribe := h.service.SubscribeChanges(id)
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	lastID, err := h.service.LatestChangeID(ctx, scopeFromCtx(c).OrganizationID, id)
+	cancel()
+	if err != nil {
+		unsubscribe()
+		if errors.Is(err, ErrEventNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "event not found")
+		}
+		return response.WriteInternalError(c, err)
+	}
+	if lastEventID != "" {
+		lastID = resumeFrom
+	}
+
+	sse.SetHeaders(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		ticker := time.NewTicker(liveStreamInterval)
+		defer ticker.Stop()
+
+		// flush дочитывает журнал после lastID. Ошибка БД не рвёт поток: повторим на следующем тике.
+		pending := true
+		flush := func() error {
+			for {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				changes, err := h.service.ChangesSince(ctx, id, lastID)
+				cancel()
+				if err != nil {
+					pending = true
+					return sse.WriteComment(w, "retry")
+				}
+				for _, change := range changes {
+					data, _ := json.Marshal(changeToResponse(change))
+					ev := sse.Event{ID: strconv.FormatInt(change.ID, 10), Name: change.Kind, Data: data}
+					if err := sse.WriteEvent(w, ev); err != nil {
+						return err
+					}
+					lastID = change.ID
+				}
+				if len(changes) < changesPageSize {
+					pending = false
+					return nil
+				}
+			}
+		}
+
+		for {
+			if pending {
+				if err := flush(); err != nil {
+					return
+				}
+			}
+			select {
+			case <-signals:
+				pending = true
+			case <-ticker.C:
+				if !pending {
+					if err := sse.WriteComment(w, "ping"); err != nil {
+						return
+					}
+				}
+			}
+		}
+	})
+	return nil
+}
+
 // PublishDraft — POST /api/events/drafts/:id/publish (черновик → events + event_days).
 func (h *Handler) PublishDraft(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
 	draftID := c.Params("id")
 	if draftID == "" {
 		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
@@ -71,22 +375,85 @@ func (h *Handler) PublishDraft(c *fiber.Ctx) error {
 	ctx, cancel := handler.TimeoutContext(c, 10*time.Second)
 	defer cancel()
 
-	event, err := h.service.PublishDraft(ctx, draftID)
+	event, err := h.service.PublishDraft(ctx, actor, draftID)
 	if err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return response.WriteError(c, fiber.StatusForbidden, err.Error())
+		}
 		if errors.Is(err, ErrDraftNotFound) {
 			return response.WriteError(c, fiber.StatusNotFound, "draft not found")
 		}
+		if errors.Is(err, ErrInvalidSchedule) {
+			return response.WriteError(c, fiber.StatusBadRequest, err.Error())
+		}
 		return response.WriteInternalError(c, err)
 	}
 	return c.Status(fiber.StatusOK).JSON(eventToResponse(event))
 }
 
-// ListDrafts — GET /api/events/drafts
+// UnpublishEvent — POST /api/events/:eventId/unpublish. Снимает событие с публикации (status = archived).
+func (h *Handler) UnpublishEvent(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("eventId")
+	if eventID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing eventId")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 10*time.Second)
+	defer cancel()
+
+	event, err := h.service.UnpublishEvent(ctx, actor, eventID)
+	if err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return response.WriteError(c, fiber.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, ErrEventNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "event not found")
+		}
+		return response.WriteInternalError(c, err)
+	}
+	return c.JSON(eventToResponse(event))
+}
+
+// DeleteDraft — DELETE /api/events/drafts/:id. Удаляет черновик события и черновики его дней.
+func (h *Handler) DeleteDraft(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	draftID := c.Params("id")
+	if draftID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DeleteDraft(ctx, actor, draftID); err != nil {
+		if errors.Is(err, ErrDraftNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "draft not found")
+		}
+		return writeAccessError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListDrafts — GET /api/events/drafts?org=slug. Черновики организации (по умолчанию — организации по умолчанию):
+// событий, в командах которых состоит пользователь; владельцам и администраторам организации и admin — все.
 func (h *Handler) ListDrafts(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
 	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
 	defer cancel()
 
-	list, err := h.service.ListDrafts(ctx)
+	org, err := h.service.ResolveOrganization(ctx, c.Query("org"))
+	if err != nil {
+		return writeAccessError(c, err)
+	}
+	list, err := h.service.ListDrafts(ctx, actor, org.ID)
 	if err != nil {
 		return response.WriteInternalError(c, err)
 	}
@@ -99,6 +466,10 @@ func (h *Handler) ListDrafts(c *fiber.Ctx) error {
 
 // GetDraft — GET /api/events/drafts/:id
 func (h *Handler) GetDraft(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
 	id := c.Params("id")
 	if id == "" {
 		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
@@ -106,9 +477,9 @@ func (h *Handler) GetDraft(c *fiber.Ctx) error {
 	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
 	defer cancel()
 
-	draft, err := h.service.GetDraft(ctx, id)
+	draft, err := h.service.GetDraft(ctx, actor, id)
 	if err != nil {
-		return response.WriteInternalError(c, err)
+		return writeAccessError(c, err)
 	}
 	if draft == nil {
 		return response.WriteError(c, fiber.StatusNotFound, "draft not found")
@@ -117,13 +488,9 @@ func (h *Handler) GetDraft(c *fiber.Ctx) error {
 }
 
 // SaveDraft — POST /api/events/drafts или PUT /api/events/drafts
-// createdBy берётся из JWT (только организаторы/админы/редакторы проходят middleware).
+// createdBy берётся из JWT; права проверяет сервис по команде события.
 func (h *Handler) SaveDraft(c *fiber.Ctx) error {
-	claimsVal := c.Locals(middleware.LocalsKeyClaims)
-	if claimsVal == nil {
-		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
-	}
-	claims, ok := claimsVal.(*authutils.UserClaims)
+	actor, ok := actorFromCtx(c)
 	if !ok {
 		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
 	}
@@ -151,13 +518,27 @@ func (h *Handler) SaveDraft(c *fiber.Ctx) error {
 		Description: req.Description,
 		StartDate:   startDate,
 		EndDate:     endDate,
-		CreatedBy:   claims.UserID,
+		Timezone:    req.Timezone,
+		Capacity:    req.Capacity,
+		CreatedBy:   actor.UserID,
 	}
 	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
 	defer cancel()
 
-	if err := h.service.SaveDraft(ctx, draft); err != nil {
-		if errors.Is(err, ErrInvalidDraftID) || errors.Is(err, ErrInvalidCreatedBy) {
+	if req.Organization != "" {
+		org, err := h.service.ResolveOrganization(ctx, req.Organization)
+		if err != nil {
+			return writeAccessError(c, err)
+		}
+		draft.OrganizationID = org.ID
+	}
+	if err := h.service.SaveDraft(ctx, actor, draft); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return response.WriteError(c, fiber.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, ErrInvalidDraftID) || errors.Is(err, ErrInvalidCreatedBy) ||
+			errors.Is(err, ErrInvalidTimezone) || errors.Is(err, ErrInvalidCapacity) ||
+			errors.Is(err, ErrOrganizationMismatch) {
 			return response.WriteError(c, fiber.StatusBadRequest, err.Error())
 		}
 		return response.WriteInternalError(c, err)
@@ -167,6 +548,10 @@ func (h *Handler) SaveDraft(c *fiber.Ctx) error {
 
 // ListDayDrafts — GET /api/events/:eventId/day-drafts
 func (h *Handler) ListDayDrafts(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
 	eventID := c.Params("eventId")
 	if eventID == "" {
 		return response.WriteError(c, fiber.StatusBadRequest, "missing eventId")
@@ -174,9 +559,9 @@ func (h *Handler) ListDayDrafts(c *fiber.Ctx) error {
 	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
 	defer cancel()
 
-	list, err := h.service.ListDayDraftsByEventID(ctx, eventID)
+	list, err := h.service.ListDayDraftsByEventID(ctx, actor, eventID)
 	if err != nil {
-		return response.WriteInternalError(c, err)
+		return writeAccessError(c, err)
 	}
 	resp := make([]DayDraftResponse, 0, len(list))
 	for _, d := range list {
@@ -187,6 +572,10 @@ func (h *Handler) ListDayDrafts(c *fiber.Ctx) error {
 
 // GetDayDraft — GET /api/events/day-drafts/:id
 func (h *Handler) GetDayDraft(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
 	id := c.Params("id")
 	if id == "" {
 		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
@@ -194,9 +583,9 @@ func (h *Handler) GetDayDraft(c *fiber.Ctx) error {
 	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
 	defer cancel()
 
-	draft, err := h.service.GetDayDraft(ctx, id)
+	draft, err := h.service.GetDayDraft(ctx, actor, id)
 	if err != nil {
-		return response.WriteInternalError(c, err)
+		return writeAccessError(c, err)
 	}
 	if draft == nil {
 		return response.WriteError(c, fiber.StatusNotFound, "day draft not found")
@@ -210,15 +599,10 @@ func (h *Handler) SaveDayDraft(c *fiber.Ctx) error {
 	if eventID == "" {
 		return response.WriteError(c, fiber.StatusBadRequest, "missing eventId")
 	}
-	claimsVal := c.Locals(middleware.LocalsKeyClaims)
-	if claimsVal == nil {
-		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
-	}
-	claims, ok := claimsVal.(*authutils.UserClaims)
+	actor, ok := actorFromCtx(c)
 	if !ok {
 		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
 	}
-	createdBy := claims.UserID
 
 	var req SaveDayDraftRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -246,12 +630,15 @@ func (h *Handler) SaveDayDraft(c *fiber.Ctx) error {
 		EventID:   req.EventID,
 		Date:      date,
 		Schedule:  schedule,
-		CreatedBy: createdBy,
+		CreatedBy: actor.UserID,
 	}
 	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
 	defer cancel()
 
-	if err := h.service.SaveDayDraft(ctx, draft); err != nil {
+	if err := h.service.SaveDayDraft(ctx, actor, draft); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return response.WriteError(c, fiber.StatusForbidden, err.Error())
+		}
 		if errors.Is(err, ErrInvalidEventID) || errors.Is(err, ErrInvalidCreatedBy) {
 			return response.WriteError(c, fiber.StatusBadRequest, err.Error())
 		}
@@ -260,31 +647,131 @@ func (h *Handler) SaveDayDraft(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(dayDraftToResponse(draft))
 }
 
+// ListMembers — GET /api/events/:eventId/members. Команда события (любому её участнику).
+func (h *Handler) ListMembers(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListMembers(ctx, actor, c.Params("eventId"))
+	if err != nil {
+		return writeAccessError(c, err)
+	}
+	resp := make([]MemberResponse, 0, len(list))
+	for _, m := range list {
+		resp = append(resp, memberToResponse(m))
+	}
+	return c.JSON(resp)
+}
+
+// AddMember — POST /api/events/:eventId/members. Приглашение по email или смена роли (владельцы).
+func (h *Handler) AddMember(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	var req AddMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if req.Email == "" || req.Role == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "email, role required")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	// eventID попадает в возвращаемого участника, а строки из c.Params Fiber переиспользует.
+	member, err := h.service.AddMember(ctx, actor, utils.CopyString(c.Params("eventId")), req.Email, req.Role)
+	if err != nil {
+		return writeAccessError(c, err)
+	}
+	return c.JSON(memberToResponse(member))
+}
+
+// RemoveMember — DELETE /api/events/:eventId/members/:userId. Владельцы убирают любого, участник — себя.
+func (h *Handler) RemoveMember(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := h.service.RemoveMember(ctx, actor, c.Params("eventId"), c.Params("userId")); err != nil {
+		return writeAccessError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// actorFromCtx собирает Actor из клеймов JWT или API-ключа (после RequireAuth/RequireAuthOrAPIKey).
+func actorFromCtx(c *fiber.Ctx) (Actor, bool) {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return Actor{}, false
+	}
+	return Actor{UserID: claims.UserID, Role: claims.Role, IP: c.IP(), UserAgent: c.Get("User-Agent"), APIKeyID: claims.APIKeyID}, true
+}
+
+// writeAccessError маппит ошибки доступа к черновикам, команде и организации события в HTTP-коды.
+func writeAccessError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		return response.WriteError(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, ErrInvalidMemberRole):
+		return response.WriteError(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrMemberUserNotFound), errors.Is(err, ErrMemberNotFound), errors.Is(err, ErrOrganizationNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, ErrLastOwner):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+func memberToResponse(m *Member) MemberResponse {
+	return MemberResponse{
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		InvitedBy: m.InvitedBy,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
 func draftToResponse(d *EventDraft) DraftResponse {
 	return DraftResponse{
-		ID:          d.ID,
-		EventID:     d.EventID,
-		Title:       d.Title,
-		Description: d.Description,
-		StartDate:   d.StartDate,
-		EndDate:     d.EndDate,
-		PublishedAt: d.PublishedAt,
-		CreatedBy:   d.CreatedBy,
-		CreatedAt:   d.CreatedAt,
-		UpdatedAt:   d.UpdatedAt,
+		ID:             d.ID,
+		EventID:        d.EventID,
+		OrganizationID: d.OrganizationID,
+		Title:          d.Title,
+		Description:    d.Description,
+		StartDate:      d.StartDate,
+		EndDate:        d.EndDate,
+		Timezone:       d.Timezone,
+		Capacity:       d.Capacity,
+		PublishedAt:    d.PublishedAt,
+		CreatedBy:      d.CreatedBy,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
 	}
 }
 
 func eventToResponse(e *Event) EventResponse {
 	return EventResponse{
-		ID:          e.ID,
-		Title:       e.Title,
-		Description: e.Description,
-		StartDate:   e.StartDate,
-		EndDate:     e.EndDate,
-		Status:      e.Status,
-		CreatedAt:   e.CreatedAt,
-		UpdatedAt:   e.UpdatedAt,
+		ID:             e.ID,
+		OrganizationID: e.OrganizationID,
+		Title:          e.Title,
+		Description:    e.Description,
+		StartDate:      e.StartDate,
+		EndDate:        e.EndDate,
+		Timezone:       e.Timezone,
+		Capacity:       e.Capacity,
+		Status:         e.Status,
+		CreatedAt:      e.CreatedAt,
+		UpdatedAt:      e.UpdatedAt,
 	}
 }
 
@@ -302,6 +789,43 @@ func eventDayToResponse(d *EventDay) EventDayResponse {
 	}
 }
 
+func liveToResponse(snapshot *LiveSnapshot) LiveResponse {
+	rooms := make([]LiveRoomResponse, 0, len(snapshot.Rooms))
+	for _, room := range snapshot.Rooms {
+		now := room.Now
+		if now == nil {
+			now = []SessionData{}
+		}
+		rooms = append(rooms, LiveRoomResponse{Location: room.Location, Now: now, Next: room.Next})
+	}
+	return LiveResponse{
+		EventID:  snapshot.EventID,
+		At:       snapshot.At,
+		Timezone: snapshot.Timezone,
+		Rooms:    rooms,
+	}
+}
+
+func changeToResponse(change *Change) ChangeResponse {
+	return ChangeResponse{
+		ID:        change.ID,
+		EventID:   change.EventID,
+		Kind:      change.Kind,
+		Date:      change.Date,
+		SessionID: change.SessionID,
+		CreatedAt: change.CreatedAt,
+	}
+}
+
+func daySessionToResponse(ds *DaySession) SessionResponse {
+	return SessionResponse{
+		EventID: ds.Day.EventID,
+		DayID:   ds.Day.ID,
+		Date:    ds.Day.Date,
+		Session: ds.Session,
+	}
+}
+
 func dayDraftToResponse(d *EventDayDraft) DayDraftResponse {
 	return DayDraftResponse{
 		ID:          d.ID,
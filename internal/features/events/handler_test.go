@@ -0,0 +1,168 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/authutils"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+)
+
+func testEventsHandlerConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		JWTSecret:         "test-jwt-secret-at-least-32-bytes-for-events",
+		AccessTokenTTLMin: 15,
+	}
+}
+
+// newTestSessionsApp поднимает публичные маршруты событий поверх моков (без БД).
+func newTestSessionsApp(t *testing.T) (*fiber.App, *config.Config) {
+	t.Helper()
+	cfg := testEventsHandlerConfig(t)
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	h := NewHandler(newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{}))
+
+	app := fiber.New()
+	registerPublicRoutes(app.Group("/api/events"), h, cfg)
+	registerPublicRoutes(app.Group("/api/orgs/:slug/events"), h, cfg)
+	return app, cfg
+}
+
+func TestHandler_ListSessions_Anonymous(t *testing.T) {
+	app, _ := newTestSessionsApp(t)
+
+	res, err := app.Test(httptest.NewRequest("GET", "/api/events/event-1/sessions?type=pitch", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var body []SessionResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Len(t, body, 2)
+	assert.Equal(t, "s-2", body[0].Session.ID)
+	assert.Equal(t, "day-1", body[0].DayID)
+}
+
+func TestHandler_GetSession_HiddenForAnonymous(t *testing.T) {
+	app, cfg := newTestSessionsApp(t)
+
+	res, err := app.Test(httptest.NewRequest("GET", "/api/events/event-1/sessions/s-3", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, res.StatusCode)
+
+	token, _, err := authutils.GenerateAccessToken(cfg, "editor-1", "editor")
+	require.NoError(t, err)
+	req := httptest.NewRequest("GET", "/api/events/event-1/sessions/s-3", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+}
+
+func TestHandler_GetEventDay_InvalidDate(t *testing.T) {
+	app, _ := newTestSessionsApp(t)
+
+	res, err := app.Test(httptest.NewRequest("GET", "/api/events/event-1/days/01-05-2026", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+}
+
+func TestHandler_GetSession_HiddenForUserRole(t *testing.T) {
+	app, cfg := newTestSessionsApp(t)
+
+	token, _, err := authutils.GenerateAccessToken(cfg, "user-1", "user")
+	require.NoError(t, err)
+	req := httptest.NewRequest("GET", "/api/events/event-1/sessions/s-3", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, res.StatusCode)
+}
+
+func TestHandler_EventsScopedToOrganization(t *testing.T) {
+	app, cfg := newTestSessionsApp(t)
+	get := func(url, userID, role string) int {
+		req := httptest.NewRequest("GET", url, nil)
+		if userID != "" {
+			token, _, err := authutils.GenerateAccessToken(cfg, userID, role)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, get("/api/orgs/default/events/event-1/sessions", "", ""))
+	assert.Equal(t, fiber.StatusNotFound, get("/api/orgs/other/events/event-1", "", ""))
+	assert.Equal(t, fiber.StatusNotFound, get("/api/orgs/other/events/event-1/sessions", "", ""))
+	assert.Equal(t, fiber.StatusNotFound, get("/api/orgs/missing/events", "", ""))
+
+	res, err := app.Test(httptest.NewRequest("GET", "/api/orgs/other/events", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var list []EventResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	assert.Empty(t, list)
+
+	// Скрытые сессии видят только редакторы организации события.
+	assert.Equal(t, fiber.StatusNotFound, get("/api/events/event-1/sessions/s-3", "outsider-1", "editor"))
+	assert.Equal(t, fiber.StatusOK, get("/api/events/event-1/sessions/s-3", "editor-1", "editor"))
+}
+
+func TestHandler_StreamChanges_Errors(t *testing.T) {
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	h := NewHandler(newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{}))
+	app := fiber.New()
+	app.Get("/api/events/:id/stream", h.OrgScope, h.StreamChanges)
+
+	res, err := app.Test(httptest.NewRequest("GET", "/api/events/missing/stream", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+
+	req := httptest.NewRequest("GET", "/api/events/event-1/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	res, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+}
+
+func TestHandler_DraftsRequireEventMembership(t *testing.T) {
+	cfg := testEventsHandlerConfig(t)
+	svc := newTestService(&mockEventRepo{}, &mockDaysRepo{}, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+	require.NoError(t, svc.SaveDraft(context.Background(), testOwner, newDraft("event-1", testOwner)))
+	h := NewHandler(svc)
+	app := fiber.New()
+	app.Get("/api/events/drafts/:id", middleware.RequireAuth(cfg), h.GetDraft)
+	app.Post("/api/events/:eventId/members", middleware.RequireAuth(cfg), h.AddMember)
+
+	request := func(method, url, userID, role, body string) int {
+		token, _, err := authutils.GenerateAccessToken(cfg, userID, role)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusForbidden, request("GET", "/api/events/drafts/event-1", "organizer-2", "organizer", ""))
+	assert.Equal(t, fiber.StatusOK, request("GET", "/api/events/drafts/event-1", "owner-1", "organizer", ""))
+	assert.Equal(t, fiber.StatusOK, request("GET", "/api/events/drafts/event-1", "admin-1", "admin", ""))
+
+	assert.Equal(t, fiber.StatusOK, request("POST", "/api/events/event-1/members", "owner-1", "organizer", `{"email":"viewer@example.com","role":"viewer"}`))
+	assert.Equal(t, fiber.StatusOK, request("GET", "/api/events/drafts/event-1", "viewer-1", "user", ""))
+	assert.Equal(t, fiber.StatusNotFound, request("POST", "/api/events/event-1/members", "owner-1", "organizer", `{"email":"nobody@example.com","role":"viewer"}`))
+	assert.Equal(t, fiber.StatusBadRequest, request("POST", "/api/events/event-1/members", "owner-1", "organizer", `{"email":"viewer@example.com"}`))
+}
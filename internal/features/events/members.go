@@ -0,0 +1,298 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/organizations"
+)
+
+// Роли участника команды события (event_members.role): owner — всё, включая состав команды;
+// editor — черновики и публикация; viewer — только чтение черновиков.
+const (
+	MemberOwner  = "owner"
+	MemberEditor = "editor"
+	MemberViewer = "viewer"
+)
+
+// memberRoles — роли команды по возрастанию прав.
+var memberRoles = []string{MemberViewer, MemberEditor, MemberOwner}
+
+// IsValidMemberRole — role есть среди ролей команды.
+func IsValidMemberRole(role string) bool {
+	return slices.Contains(memberRoles, role)
+}
+
+// Member — участник команды события. Событие может быть ещё не опубликовано: EventID — id будущего
+// события из черновика (EventDraft.TargetEventID).
+type Member struct {
+	EventID   string
+	UserID    string
+	Email     string
+	Role      string
+	InvitedBy *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Can — роль участника даёт права не ниже need.
+func (m *Member) Can(need string) bool {
+	return slices.Index(memberRoles, m.Role) >= slices.Index(memberRoles, need)
+}
+
+// Actor — кто выполняет операцию с черновиками: пользователь и его глобальная роль из JWT.
+// IP, UserAgent и APIKeyID (запрос с API-ключом, UserID — его владелец) попадают в журнал аудита.
+type Actor struct {
+	UserID    string
+	Role      string
+	IP        string
+	UserAgent string
+	APIKeyID  string
+}
+
+// IsAdmin — глобальный admin: доступ ко всем событиям без членства.
+func (a Actor) IsAdmin() bool {
+	return a.Role == auth.RoleAdmin
+}
+
+// CanCreateEvents — глобальная роль позволяет заводить новые события (DraftEditorRoles).
+// Создатель нового события становится его владельцем.
+func (a Actor) CanCreateEvents() bool {
+	return slices.Contains(DraftEditorRoles, a.Role)
+}
+
+var (
+	ErrForbidden          = errors.New("no access to this event")
+	ErrInvalidMemberRole  = errors.New("invalid member role")
+	ErrMemberUserNotFound = errors.New("user not found")
+	ErrMemberNotFound     = errors.New("member not found")
+	ErrLastOwner          = errors.New("event must keep at least one owner")
+	// ErrOrganizationMismatch — организацию существующего события или черновика сменить нельзя.
+	ErrOrganizationMismatch = errors.New("event belongs to another organization")
+)
+
+// authorize проверяет, что actor может действовать над событием eventID с правами не ниже need:
+// по роли в команде события, а владельцы и администраторы организации события — над любым её событием.
+func (s *Service) authorize(ctx context.Context, r *TxRepositories, actor Actor, eventID, need string) error {
+	return authorize(ctx, r, s.orgs, actor, eventID, need)
+}
+
+func authorize(ctx context.Context, r *TxRepositories, orgs OrganizationDirectory, actor Actor, eventID, need string) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	m, err := r.Members.Get(ctx, eventID, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if m != nil && m.Can(need) {
+		return nil
+	}
+	orgID, err := eventOrganizationID(ctx, r, eventID)
+	if err != nil {
+		return err
+	}
+	if orgID == "" {
+		return ErrForbidden
+	}
+	orgMember, err := orgs.GetMember(ctx, orgID, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if orgMember == nil || !orgMember.Can(organizations.RoleAdmin) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// authorizeDraftSave закрепляет за черновиком организацию и проверяет право его сохранить. Черновик нового
+// события (события ещё нет, черновиков и команды у него тоже) может завести участник организации
+// с CanCreateEvents — он становится владельцем. Возвращает прежнюю версию черновика (nil — черновик новый).
+func (s *Service) authorizeDraftSave(ctx context.Context, r *TxRepositories, actor Actor, draft *EventDraft) (*EventDraft, error) {
+	existing, err := r.Drafts.GetByID(ctx, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.pinDraftOrganization(ctx, r, existing, draft); err != nil {
+		return nil, err
+	}
+	// Перенос черновика на другое событие требует прав и на прежнее.
+	if existing != nil && existing.TargetEventID() != draft.TargetEventID() {
+		if err := s.authorize(ctx, r, actor, existing.TargetEventID(), MemberEditor); err != nil {
+			return nil, err
+		}
+	}
+
+	eventID := draft.TargetEventID()
+	err = s.authorize(ctx, r, actor, eventID, MemberEditor)
+	if !errors.Is(err, ErrForbidden) {
+		return existing, err
+	}
+	if existing != nil || !actor.CanCreateEvents() {
+		return nil, ErrForbidden
+	}
+	isNew, err := isNewEvent(ctx, r, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !isNew {
+		return nil, ErrForbidden
+	}
+	orgMember, err := s.orgs.GetMember(ctx, draft.OrganizationID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if orgMember == nil {
+		return nil, ErrForbidden
+	}
+	now := time.Now()
+	return nil, r.Members.Upsert(ctx, &Member{EventID: eventID, UserID: actor.UserID, Role: MemberOwner, CreatedAt: now, UpdatedAt: now})
+}
+
+// pinDraftOrganization: организация существующего черновика и события не меняется (другая — ErrOrganizationMismatch),
+// новое событие без указанной организации попадает в организацию по умолчанию.
+func (s *Service) pinDraftOrganization(ctx context.Context, r *TxRepositories, existing, draft *EventDraft) error {
+	pinned := ""
+	if existing != nil {
+		pinned = existing.OrganizationID
+	}
+	eventOrgID, err := eventOrganizationID(ctx, r, draft.TargetEventID())
+	if err != nil {
+		return err
+	}
+	if eventOrgID != "" {
+		if pinned != "" && pinned != eventOrgID {
+			return ErrOrganizationMismatch
+		}
+		pinned = eventOrgID
+	}
+	switch {
+	case pinned == "" && draft.OrganizationID == "":
+		org, err := s.ResolveOrganization(ctx, "")
+		if err != nil {
+			return err
+		}
+		draft.OrganizationID = org.ID
+	case pinned == "":
+	case draft.OrganizationID == "" || draft.OrganizationID == pinned:
+		draft.OrganizationID = pinned
+	default:
+		return ErrOrganizationMismatch
+	}
+	return nil
+}
+
+// isNewEvent — у события нет ни публикации, ни черновика, ни команды.
+func isNewEvent(ctx context.Context, r *TxRepositories, eventID string) (bool, error) {
+	members, err := r.Members.ListByEventID(ctx, eventID)
+	if err != nil || len(members) > 0 {
+		return false, err
+	}
+	event, err := r.Events.GetByID(ctx, eventID)
+	if err != nil || event != nil {
+		return false, err
+	}
+	draft, err := r.Drafts.GetByEventID(ctx, eventID)
+	if err != nil || draft != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListMembers возвращает команду события. Доступно любому участнику команды.
+func (s *Service) ListMembers(ctx context.Context, actor Actor, eventID string) ([]*Member, error) {
+	if err := s.authorize(ctx, s.repos(), actor, eventID, MemberViewer); err != nil {
+		return nil, err
+	}
+	return s.membersRepo.ListByEventID(ctx, eventID)
+}
+
+// AddMember приглашает пользователя (по email) в команду события или меняет его роль. Только владельцы.
+func (s *Service) AddMember(ctx context.Context, actor Actor, eventID, email, role string) (*Member, error) {
+	if !IsValidMemberRole(role) {
+		return nil, ErrInvalidMemberRole
+	}
+	user, err := s.users.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrMemberUserNotFound
+	}
+	var member *Member
+	err = s.tx.InTx(ctx, func(r *TxRepositories) error {
+		owners, err := r.Members.LockOwners(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, r, actor, eventID, MemberOwner); err != nil {
+			return err
+		}
+		existing, err := r.Members.Get(ctx, eventID, user.ID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if existing != nil {
+			if err := keepOwner(owners, existing, role); err != nil {
+				return err
+			}
+			existing.Role = role
+			existing.UpdatedAt = now
+			member = existing
+		} else {
+			invitedBy := actor.UserID
+			member = &Member{EventID: eventID, UserID: user.ID, Role: role, InvitedBy: &invitedBy, CreatedAt: now, UpdatedAt: now}
+		}
+		member.Email = user.Email
+		return r.Members.Upsert(ctx, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember убирает пользователя из команды события. Владельцы убирают любого, участник — себя.
+func (s *Service) RemoveMember(ctx context.Context, actor Actor, eventID, userID string) error {
+	return s.tx.InTx(ctx, func(r *TxRepositories) error {
+		owners, err := r.Members.LockOwners(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		need := MemberOwner
+		if userID == actor.UserID {
+			need = MemberViewer
+		}
+		if err := s.authorize(ctx, r, actor, eventID, need); err != nil {
+			return err
+		}
+		existing, err := r.Members.Get(ctx, eventID, userID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return ErrMemberNotFound
+		}
+		if err := keepOwner(owners, existing, ""); err != nil {
+			return err
+		}
+		return r.Members.Delete(ctx, eventID, userID)
+	})
+}
+
+// keepOwner не даёт снять роль owner с последнего владельца (newRole == "" — удаление из команды).
+// owners — владельцы, заблокированные LockOwners в той же транзакции, что и изменение.
+func keepOwner(owners []string, m *Member, newRole string) error {
+	if m.Role != MemberOwner || newRole == MemberOwner {
+		return nil
+	}
+	if len(owners) <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/organizations"
+)
+
+var (
+	testOwner      = Actor{UserID: "owner-1", Role: auth.RoleOrganizer}
+	testEditor     = Actor{UserID: "editor-1", Role: auth.RoleUser}
+	testViewer     = Actor{UserID: "viewer-1", Role: auth.RoleUser}
+	otherOrganizer = Actor{UserID: "organizer-2", Role: auth.RoleOrganizer}
+)
+
+func newMembersTestService(t *testing.T) (*Service, *mockDraftRepo, *mockEventRepo) {
+	t.Helper()
+	eventsRepo := &mockEventRepo{events: map[string]*Event{}}
+	draftsRepo := &mockDraftRepo{}
+	svc := newTestService(eventsRepo, &mockDaysRepo{}, draftsRepo, &mockDayDraftRepo{}, &mockChangeRepo{})
+	return svc, draftsRepo, eventsRepo
+}
+
+func newDraft(id string, createdBy Actor) *EventDraft {
+	return &EventDraft{
+		ID: id, Title: "Conf", StartDate: time.Now(), EndDate: time.Now().Add(24 * time.Hour), CreatedBy: createdBy.UserID,
+	}
+}
+
+func TestSaveDraft_NewEventMakesCreatorOwner(t *testing.T) {
+	svc, _, _ := newMembersTestService(t)
+	ctx := context.Background()
+
+	require.NoError(t, svc.SaveDraft(ctx, testOwner, newDraft("event-1", testOwner)))
+	members, err := svc.ListMembers(ctx, testOwner, "event-1")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, MemberOwner, members[0].Role)
+	assert.Equal(t, testOwner.UserID, members[0].UserID)
+
+	// Чужой организатор не может ни читать, ни менять, ни публиковать черновик.
+	assert.ErrorIs(t, svc.SaveDraft(ctx, otherOrganizer, newDraft("event-1", otherOrganizer)), ErrForbidden)
+	_, err = svc.GetDraft(ctx, otherOrganizer, "event-1")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.PublishDraft(ctx, otherOrganizer, "event-1")
+	assert.ErrorIs(t, err, ErrForbidden)
+	err = svc.SaveDayDraft(ctx, otherOrganizer, &EventDayDraft{EventID: "event-1", Date: time.Now(), CreatedBy: otherOrganizer.UserID})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestSaveDraft_UserRoleCannotCreateEvents(t *testing.T) {
+	svc, _, _ := newMembersTestService(t)
+
+	err := svc.SaveDraft(context.Background(), testViewer, newDraft("event-1", testViewer))
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestSaveDraft_ExistingEventWithoutTeamOnlyForAdmin(t *testing.T) {
+	svc, _, eventsRepo := newMembersTestService(t)
+	ctx := context.Background()
+	eventsRepo.events["event-1"] = &Event{ID: "event-1", Title: "Legacy"}
+	eventID := "event-1"
+
+	draft := newDraft("draft-1", otherOrganizer)
+	draft.EventID = &eventID
+	assert.ErrorIs(t, svc.SaveDraft(ctx, otherOrganizer, draft), ErrForbidden)
+
+	draft.CreatedBy = testAdmin.UserID
+	require.NoError(t, svc.SaveDraft(ctx, testAdmin, draft))
+	members, err := svc.ListMembers(ctx, testAdmin, "event-1")
+	require.NoError(t, err)
+	assert.Empty(t, members, "admin не становится владельцем существующего события")
+}
+
+func TestMembers_RolesGrantAccess(t *testing.T) {
+	svc, _, _ := newMembersTestService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.SaveDraft(ctx, testOwner, newDraft("event-1", testOwner)))
+
+	_, err := svc.AddMember(ctx, testOwner, "event-1", "Editor@Example.com", MemberEditor)
+	require.NoError(t, err)
+	m, err := svc.AddMember(ctx, testOwner, "event-1", "viewer@example.com", MemberViewer)
+	require.NoError(t, err)
+	require.NotNil(t, m.InvitedBy)
+	assert.Equal(t, testOwner.UserID, *m.InvitedBy)
+
+	// Редактор меняет черновик и дни и публикует.
+	require.NoError(t, svc.SaveDraft(ctx, testEditor, newDraft("event-1", testEditor)))
+	require.NoError(t, svc.SaveDayDraft(ctx, testEditor, &EventDayDraft{
+		EventID: "event-1", Date: time.Now(), Schedule: []byte(`{"sessions":[]}`), CreatedBy: testEditor.UserID,
+	}))
+
+	// Наблюдатель только читает.
+	draft, err := svc.GetDraft(ctx, testViewer, "event-1")
+	require.NoError(t, err)
+	require.NotNil(t, draft)
+	_, err = svc.ListDayDraftsByEventID(ctx, testViewer, "event-1")
+	require.NoError(t, err)
+	assert.ErrorIs(t, svc.SaveDraft(ctx, testViewer, newDraft("event-1", testViewer)), ErrForbidden)
+	_, err = svc.PublishDraft(ctx, testViewer, "event-1")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	// Команду меняет только владелец.
+	_, err = svc.AddMember(ctx, testEditor, "event-1", "viewer@example.com", MemberEditor)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = svc.PublishDraft(ctx, testEditor, "event-1")
+	require.NoError(t, err)
+}
+
+func TestListDrafts_OnlyOwnEvents(t *testing.T) {
+	svc, _, _ := newMembersTestService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.SaveDraft(ctx, testOwner, newDraft("event-1", testOwner)))
+	require.NoError(t, svc.SaveDraft(ctx, otherOrganizer, newDraft("event-2", otherOrganizer)))
+
+	list, err := svc.ListDrafts(ctx, testOwner, testOrgID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "event-1", list[0].ID)
+
+	list, err = svc.ListDrafts(ctx, testAdmin, testOrgID)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func TestMembers_KeepLastOwner(t *testing.T) {
+	svc, _, _ := newMembersTestService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.SaveDraft(ctx, testOwner, newDraft("event-1", testOwner)))
+
+	assert.ErrorIs(t, svc.RemoveMember(ctx, testOwner, "event-1", testOwner.UserID), ErrLastOwner)
+	_, err := svc.AddMember(ctx, testOwner, "event-1", "owner@example.com", MemberEditor)
+	assert.ErrorIs(t, err, ErrLastOwner)
+
+	_, err = svc.AddMember(ctx, testOwner, "event-1", "editor@example.com", MemberOwner)
+	require.NoError(t, err)
+	require.NoError(t, svc.RemoveMember(ctx, testOwner, "event-1", testOwner.UserID))
+
+	_, err = svc.GetDraft(ctx, testOwner, "event-1")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestMembers_ConcurrentDemotionsKeepOneOwner(t *testing.T) {
+	svc, _, _ := newMembersTestService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.SaveDraft(ctx, testOwner, newDraft("event-1", testOwner)))
+	_, err := svc.AddMember(ctx, testOwner, "event-1", "editor@example.com", MemberOwner)
+	require.NoError(t, err)
+
+	// Два владельца одновременно понижают друг друга: пройти может только одно изменение.
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, c := range []struct {
+		actor Actor
+		email string
+	}{{testOwner, "editor@example.com"}, {testEditor, "owner@example.com"}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = svc.AddMember(ctx, c.actor, "event-1", c.email, MemberEditor)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed, "одно из понижений должно получить отказ")
+
+	owners := 0
+	list, err := svc.ListMembers(ctx, testAdmin, "event-1")
+	require.NoError(t, err)
+	for _, m := range list {
+		if m.Role == MemberOwner {
+			owners++
+		}
+	}
+	assert.Equal(t, 1, owners)
+}
+
+func TestPublishRights_TeamEditorsAndOrgAdmins(t *testing.T) {
+	members := &mockMemberRepo{}
+	ctx := context.Background()
+	require.NoError(t, members.Upsert(ctx, &Member{EventID: "event-1", UserID: "editor-1", Role: MemberEditor}))
+	require.NoError(t, members.Upsert(ctx, &Member{EventID: "event-1", UserID: "viewer-1", Role: MemberViewer}))
+	orgs := &mockOrgDirectory{members: map[[2]string]string{
+		{"org-1", "org-admin"}:  organizations.RoleAdmin,
+		{"org-1", "org-member"}: organizations.RoleMember,
+	}}
+	rights := NewPublishRights(members, orgs)
+
+	for userID, want := range map[string]bool{
+		"editor-1": true, "org-admin": true, "viewer-1": false, "org-member": false, "stranger": false,
+	} {
+		got, err := rights.CanPublish(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, userID)
+	}
+}
+
+func TestMembers_SelfRemovalAndErrors(t *testing.T) {
+	svc, _, _ := newMembersTestService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.SaveDraft(ctx, testOwner, newDraft("event-1", testOwner)))
+	_, err := svc.AddMember(ctx, testOwner, "event-1", "viewer@example.com", MemberViewer)
+	require.NoError(t, err)
+
+	_, err = svc.AddMember(ctx, testOwner, "event-1", "viewer@example.com", "admin")
+	assert.ErrorIs(t, err, ErrInvalidMemberRole)
+	_, err = svc.AddMember(ctx, testOwner, "event-1", "nobody@example.com", MemberViewer)
+	assert.ErrorIs(t, err, ErrMemberUserNotFound)
+	assert.ErrorIs(t, svc.RemoveMember(ctx, testViewer, "event-1", testOwner.UserID), ErrForbidden)
+	assert.ErrorIs(t, svc.RemoveMember(ctx, testOwner, "event-1", "editor-1"), ErrMemberNotFound)
+
+	require.NoError(t, svc.RemoveMember(ctx, testViewer, "event-1", testViewer.UserID))
+	_, err = svc.ListMembers(ctx, testViewer, "event-1")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"time"
+
+	"wdpl_back/internal/shared/outbox"
+)
+
+// Доменные события фичи (outbox). Подписчики регистрируются в outbox.Dispatcher.
+// Агрегат — событие: сообщения одного события доставляются по порядку.
+const (
+	AggregateEvent = "event"
+
+	DomainEventPublished   = "event.published"
+	DomainEventUnpublished = "event.unpublished"
+	DomainEventDayChanged  = "event.day.changed"
+	DomainDraftSaved       = "draft.saved"
+)
+
+// EventPublishedPayload — payload event.published.
+type EventPublishedPayload struct {
+	EventID        string    `json:"eventId"`
+	OrganizationID string    `json:"organizationId"`
+	DraftID        string    `json:"draftId"`
+	Title          string    `json:"title"`
+	PublishedAt    time.Time `json:"publishedAt"`
+}
+
+// EventUnpublishedPayload — payload event.unpublished: событие снято с публикации.
+type EventUnpublishedPayload struct {
+	EventID        string    `json:"eventId"`
+	OrganizationID string    `json:"organizationId"`
+	UnpublishedAt  time.Time `json:"unpublishedAt"`
+}
+
+// EventDayChangedPayload — payload event.day.changed: день добавлен или изменено его расписание.
+type EventDayChangedPayload struct {
+	EventID string `json:"eventId"`
+	Date    string `json:"date"`
+	// CancelledSessionIDs — публичные сессии, отменённые этой публикацией.
+	CancelledSessionIDs []string `json:"cancelledSessionIds,omitempty"`
+}
+
+// Виды черновиков в draft.saved.
+const (
+	DraftKindEvent = "event"
+	DraftKindDay   = "day"
+)
+
+// DraftSavedPayload — payload draft.saved (черновик события или дня).
+type DraftSavedPayload struct {
+	DraftID string `json:"draftId"`
+	EventID string `json:"eventId"`
+	Kind    string `json:"kind"`
+	// Date — только для черновика дня.
+	Date    string    `json:"date,omitempty"`
+	SavedBy string    `json:"savedBy"`
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// eventDraftSavedMessage — draft.saved для черновика события. До первой публикации id события равен id черновика.
+func eventDraftSavedMessage(draft *EventDraft) (*outbox.Message, error) {
+	eventID := draft.ID
+	if draft.EventID != nil {
+		eventID = *draft.EventID
+	}
+	return outbox.NewMessage(AggregateEvent, eventID, DomainDraftSaved, DraftSavedPayload{
+		DraftID: draft.ID,
+		EventID: eventID,
+		Kind:    DraftKindEvent,
+		SavedBy: draft.CreatedBy,
+		SavedAt: draft.UpdatedAt,
+	})
+}
+
+// dayDraftSavedMessage — draft.saved для черновика дня.
+func dayDraftSavedMessage(draft *EventDayDraft) (*outbox.Message, error) {
+	return outbox.NewMessage(AggregateEvent, draft.EventID, DomainDraftSaved, DraftSavedPayload{
+		DraftID: draft.ID,
+		EventID: draft.EventID,
+		Kind:    DraftKindDay,
+		Date:    draft.Date.Format(dateLayout),
+		SavedBy: draft.CreatedBy,
+		SavedAt: draft.UpdatedAt,
+	})
+}
+
+// publishMessages — event.published и event.day.changed по журналу изменений публикации.
+func publishMessages(event *Event, draftID string, changes []*Change) ([]*outbox.Message, error) {
+	published, err := outbox.NewMessage(AggregateEvent, event.ID, DomainEventPublished, EventPublishedPayload{
+		EventID:        event.ID,
+		OrganizationID: event.OrganizationID,
+		DraftID:        draftID,
+		Title:          event.Title,
+		PublishedAt:    event.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	msgs := []*outbox.Message{published}
+
+	// day_changed и session_cancelled одного дня сводим в одно event.day.changed.
+	days := make(map[string]*EventDayChangedPayload)
+	var order []string
+	for _, c := range changes {
+		if c.Date == nil {
+			continue
+		}
+		date := c.Date.Format(dateLayout)
+		payload, ok := days[date]
+		if !ok {
+			payload = &EventDayChangedPayload{EventID: event.ID, Date: date}
+			days[date] = payload
+			order = append(order, date)
+		}
+		if c.Kind == ChangeSessionCancelled {
+			payload.CancelledSessionIDs = append(payload.CancelledSessionIDs, c.SessionID)
+		}
+	}
+	for _, date := range order {
+		msg, err := outbox.NewMessage(AggregateEvent, event.ID, DomainEventDayChanged, days[date])
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// unpublishedMessage — event.unpublished для снятого с публикации события.
+func unpublishedMessage(event *Event) (*outbox.Message, error) {
+	return outbox.NewMessage(AggregateEvent, event.ID, DomainEventUnpublished, EventUnpublishedPayload{
+		EventID:        event.ID,
+		OrganizationID: event.OrganizationID,
+		UnpublishedAt:  event.UpdatedAt,
+	})
+}
@@ -3,18 +3,25 @@ package events
 import (
 	"context"
 	"time"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/organizations"
+	"wdpl_back/internal/shared/audit"
+	"wdpl_back/internal/shared/outbox"
 )
 
 // EventRepository описывает операции с публикованными событиями.
 type EventRepository interface {
 	GetByID(ctx context.Context, id string) (*Event, error)
-	List(ctx context.Context, limit, offset int) ([]*Event, error)
+	// List возвращает опубликованные (не архивные) события организации orgID (новые по дате начала первыми).
+	List(ctx context.Context, orgID string, limit, offset int) ([]*Event, error)
 	Upsert(ctx context.Context, event *Event) error
 }
 
 // EventDayRepository описывает операции с опубликованными днями событий.
 type EventDayRepository interface {
 	ListByEventID(ctx context.Context, eventID string) ([]*EventDay, error)
+	GetByEventIDAndDate(ctx context.Context, eventID string, date time.Time) (*EventDay, error)
 	Upsert(ctx context.Context, day *EventDay) error
 }
 
@@ -22,7 +29,8 @@ type EventDayRepository interface {
 type EventDraftRepository interface {
 	GetByID(ctx context.Context, id string) (*EventDraft, error)
 	GetByEventID(ctx context.Context, eventID string) (*EventDraft, error)
-	ListDrafts(ctx context.Context) ([]*EventDraft, error)
+	// ListDrafts возвращает черновики организации orgID (недавно изменённые первыми).
+	ListDrafts(ctx context.Context, orgID string) ([]*EventDraft, error)
 	Upsert(ctx context.Context, draft *EventDraft) error
 	DeleteByID(ctx context.Context, id string) error
 }
@@ -35,3 +43,59 @@ type EventDayDraftRepository interface {
 	Upsert(ctx context.Context, draft *EventDayDraft) error
 	DeleteByID(ctx context.Context, id string) error
 }
+
+// ChangeRepository описывает журнал изменений опубликованных событий.
+type ChangeRepository interface {
+	// Append сохраняет изменения и уведомляет подписчиков всех реплик (NOTIFY).
+	// Вызывать внутри Transactor.InTx: запись и уведомление уходят вместе с изменением при COMMIT.
+	Append(ctx context.Context, changes []*Change) error
+	// ListSince возвращает изменения события с id > afterID по возрастанию (не больше limit).
+	ListSince(ctx context.Context, eventID string, afterID int64, limit int) ([]*Change, error)
+	// LatestID — id последнего изменения события (0, если изменений нет).
+	LatestID(ctx context.Context, eventID string) (int64, error)
+}
+
+// MemberRepository описывает команды событий (public.event_members).
+type MemberRepository interface {
+	// Get возвращает участника команды (nil, если пользователь не в команде).
+	Get(ctx context.Context, eventID, userID string) (*Member, error)
+	// ListByEventID возвращает команду события: владельцы, затем редакторы и наблюдатели.
+	ListByEventID(ctx context.Context, eventID string) ([]*Member, error)
+	// ListEventIDsByUserID возвращает id событий, в командах которых состоит пользователь.
+	ListEventIDsByUserID(ctx context.Context, userID string) ([]string, error)
+	// Upsert добавляет участника или меняет его роль.
+	Upsert(ctx context.Context, member *Member) error
+	Delete(ctx context.Context, eventID, userID string) error
+	// LockOwners блокирует строки владельцев события (SELECT … FOR UPDATE) до конца транзакции
+	// и возвращает их user_id. Вызывать внутри Transactor.InTx: параллельные снятия владельцев идут по очереди.
+	LockOwners(ctx context.Context, eventID string) ([]string, error)
+}
+
+// UserDirectory ищет пользователя, приглашаемого в команду (реализует auth.UserRepository).
+type UserDirectory interface {
+	GetUserByEmail(ctx context.Context, email string) (*auth.User, error)
+}
+
+// OrganizationDirectory — организации событий и членство в них (реализует organizations.Repository).
+type OrganizationDirectory interface {
+	GetBySlug(ctx context.Context, slug string) (*organizations.Organization, error)
+	GetMember(ctx context.Context, orgID, userID string) (*organizations.Member, error)
+}
+
+// TxRepositories — репозитории, привязанные к одной транзакции (см. Transactor).
+// Outbox и Audit пишут доменные события и журнал аудита в ту же транзакцию, что и изменение.
+type TxRepositories struct {
+	Events    EventRepository
+	Days      EventDayRepository
+	Drafts    EventDraftRepository
+	DayDrafts EventDayDraftRepository
+	Changes   ChangeRepository
+	Members   MemberRepository
+	Outbox    outbox.Writer
+	Audit     audit.Writer
+}
+
+// Transactor выполняет fn в транзакции: ошибка fn — откат, иначе COMMIT.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(repos *TxRepositories) error) error
+}
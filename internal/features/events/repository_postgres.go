@@ -8,15 +8,27 @@ import (
 
 	"github.com/google/uuid"
 
+	"wdpl_back/internal/shared/audit"
+	"wdpl_back/internal/shared/outbox"
 	"wdpl_back/internal/shared/postgres"
 )
 
+// querier — общее у *sql.DB и *sql.Tx: одни и те же репозитории работают и в транзакции, и без неё.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 // PostgresRepositories объединяет Postgres-реализации репозиториев событий и черновиков.
 type PostgresRepositories struct {
 	Events    EventRepository
 	Days      EventDayRepository
 	Drafts    EventDraftRepository
 	DayDrafts EventDayDraftRepository
+	Changes   ChangeRepository
+	Members   MemberRepository
+	Tx        Transactor
 }
 
 // NewPostgresRepository возвращает реализации репозиториев событий и черновиков.
@@ -26,20 +38,55 @@ func NewPostgresRepository(db *postgres.DB) *PostgresRepositories {
 		Days:      &eventDayRepoImpl{db: db},
 		Drafts:    &eventDraftRepoImpl{db: db},
 		DayDrafts: &eventDayDraftRepoImpl{db: db},
+		Changes:   &changeRepoImpl{db: db},
+		Members:   &memberRepoImpl{db: db},
+		Tx:        &transactorImpl{db: db},
+	}
+}
+
+// — Transactor
+
+type transactorImpl struct{ db *postgres.DB }
+
+func (t *transactorImpl) InTx(ctx context.Context, fn func(repos *TxRepositories) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	repos := &TxRepositories{
+		Events:    &eventRepoImpl{db: tx},
+		Days:      &eventDayRepoImpl{db: tx},
+		Drafts:    &eventDraftRepoImpl{db: tx},
+		DayDrafts: &eventDayDraftRepoImpl{db: tx},
+		Changes:   &changeRepoImpl{db: tx},
+		Members:   &memberRepoImpl{db: tx},
+		Outbox:    outbox.NewPostgresWriter(tx),
+		Audit:     audit.NewPostgresWriter(tx),
+	}
+	if err := fn(repos); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // — EventRepository
 
-type eventRepoImpl struct{ db *postgres.DB }
+type eventRepoImpl struct{ db querier }
 
 func (r *eventRepoImpl) GetByID(ctx context.Context, id string) (*Event, error) {
+	if !isUUID(id) {
+		return nil, nil
+	}
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, title, description, start_date, end_date, status, created_at, updated_at
+		SELECT id, organization_id, title, description, start_date, end_date, timezone, capacity, status, created_at, updated_at
 		FROM public.events WHERE id = $1
 	`, id)
 	var e Event
-	err := row.Scan(&e.ID, &e.Title, &e.Description, &e.StartDate, &e.EndDate, &e.Status, &e.CreatedAt, &e.UpdatedAt)
+	err := row.Scan(&e.ID, &e.OrganizationID, &e.Title, &e.Description, &e.StartDate, &e.EndDate, &e.Timezone, &e.Capacity, &e.Status, &e.CreatedAt, &e.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
@@ -49,7 +96,10 @@ func (r *eventRepoImpl) GetByID(ctx context.Context, id string) (*Event, error)
 	return &e, nil
 }
 
-func (r *eventRepoImpl) List(ctx context.Context, limit, offset int) ([]*Event, error) {
+func (r *eventRepoImpl) List(ctx context.Context, orgID string, limit, offset int) ([]*Event, error) {
+	if !isUUID(orgID) {
+		return nil, nil
+	}
 	if limit <= 0 {
 		limit = 20
 	}
@@ -57,9 +107,9 @@ func (r *eventRepoImpl) List(ctx context.Context, limit, offset int) ([]*Event,
 		offset = 0
 	}
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, title, description, start_date, end_date, status, created_at, updated_at
-		FROM public.events ORDER BY start_date DESC LIMIT $1 OFFSET $2
-	`, limit, offset)
+		SELECT id, organization_id, title, description, start_date, end_date, timezone, capacity, status, created_at, updated_at
+		FROM public.events WHERE organization_id = $1 AND status <> 'archived' ORDER BY start_date DESC LIMIT $2 OFFSET $3
+	`, orgID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +117,7 @@ func (r *eventRepoImpl) List(ctx context.Context, limit, offset int) ([]*Event,
 	var list []*Event
 	for rows.Next() {
 		var e Event
-		if err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.StartDate, &e.EndDate, &e.Status, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.OrganizationID, &e.Title, &e.Description, &e.StartDate, &e.EndDate, &e.Timezone, &e.Capacity, &e.Status, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			return nil, err
 		}
 		list = append(list, &e)
@@ -77,22 +127,24 @@ func (r *eventRepoImpl) List(ctx context.Context, limit, offset int) ([]*Event,
 
 func (r *eventRepoImpl) Upsert(ctx context.Context, event *Event) error {
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO public.events (id, title, description, start_date, end_date, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO public.events (id, organization_id, title, description, start_date, end_date, timezone, capacity, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (id) DO UPDATE SET
 			title = EXCLUDED.title,
 			description = EXCLUDED.description,
 			start_date = EXCLUDED.start_date,
 			end_date = EXCLUDED.end_date,
+			timezone = EXCLUDED.timezone,
+			capacity = EXCLUDED.capacity,
 			status = EXCLUDED.status,
 			updated_at = EXCLUDED.updated_at
-	`, event.ID, event.Title, event.Description, event.StartDate, event.EndDate, event.Status, event.CreatedAt, event.UpdatedAt)
+	`, event.ID, event.OrganizationID, event.Title, event.Description, event.StartDate, event.EndDate, event.Timezone, event.Capacity, event.Status, event.CreatedAt, event.UpdatedAt)
 	return err
 }
 
 // — EventDayRepository (опубликованные дни)
 
-type eventDayRepoImpl struct{ db *postgres.DB }
+type eventDayRepoImpl struct{ db querier }
 
 func (r *eventDayRepoImpl) ListByEventID(ctx context.Context, eventID string) ([]*EventDay, error) {
 	rows, err := r.db.QueryContext(ctx, `
@@ -114,6 +166,23 @@ func (r *eventDayRepoImpl) ListByEventID(ctx context.Context, eventID string) ([
 	return list, rows.Err()
 }
 
+func (r *eventDayRepoImpl) GetByEventIDAndDate(ctx context.Context, eventID string, date time.Time) (*EventDay, error) {
+	dateOnly := date.Truncate(24 * time.Hour)
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, event_id, date, schedule, session_count, first_session_start, last_session_end, created_at, updated_at
+		FROM public.event_days WHERE event_id = $1 AND date = $2
+	`, eventID, dateOnly)
+	var d EventDay
+	err := row.Scan(&d.ID, &d.EventID, &d.Date, &d.Schedule, &d.SessionCount, &d.FirstSessionStart, &d.LastSessionEnd, &d.CreatedAt, &d.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
 func (r *eventDayRepoImpl) Upsert(ctx context.Context, day *EventDay) error {
 	if day.ID == "" {
 		day.ID = uuid.NewString()
@@ -138,15 +207,18 @@ func (r *eventDayRepoImpl) Upsert(ctx context.Context, day *EventDay) error {
 
 // — EventDraftRepository
 
-type eventDraftRepoImpl struct{ db *postgres.DB }
+type eventDraftRepoImpl struct{ db querier }
 
 func (r *eventDraftRepoImpl) GetByID(ctx context.Context, id string) (*EventDraft, error) {
+	if !isUUID(id) {
+		return nil, nil
+	}
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, event_id, title, description, start_date, end_date, published_at, created_by, created_at, updated_at
+		SELECT id, event_id, organization_id, title, description, start_date, end_date, timezone, capacity, published_at, created_by, created_at, updated_at
 		FROM public.event_drafts WHERE id = $1
 	`, id)
 	var d EventDraft
-	err := row.Scan(&d.ID, &d.EventID, &d.Title, &d.Description, &d.StartDate, &d.EndDate, &d.PublishedAt, &d.CreatedBy, &d.CreatedAt, &d.UpdatedAt)
+	err := row.Scan(&d.ID, &d.EventID, &d.OrganizationID, &d.Title, &d.Description, &d.StartDate, &d.EndDate, &d.Timezone, &d.Capacity, &d.PublishedAt, &d.CreatedBy, &d.CreatedAt, &d.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
@@ -157,12 +229,15 @@ func (r *eventDraftRepoImpl) GetByID(ctx context.Context, id string) (*EventDraf
 }
 
 func (r *eventDraftRepoImpl) GetByEventID(ctx context.Context, eventID string) (*EventDraft, error) {
+	if !isUUID(eventID) {
+		return nil, nil
+	}
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, event_id, title, description, start_date, end_date, published_at, created_by, created_at, updated_at
+		SELECT id, event_id, organization_id, title, description, start_date, end_date, timezone, capacity, published_at, created_by, created_at, updated_at
 		FROM public.event_drafts WHERE event_id = $1
 	`, eventID)
 	var d EventDraft
-	err := row.Scan(&d.ID, &d.EventID, &d.Title, &d.Description, &d.StartDate, &d.EndDate, &d.PublishedAt, &d.CreatedBy, &d.CreatedAt, &d.UpdatedAt)
+	err := row.Scan(&d.ID, &d.EventID, &d.OrganizationID, &d.Title, &d.Description, &d.StartDate, &d.EndDate, &d.Timezone, &d.Capacity, &d.PublishedAt, &d.CreatedBy, &d.CreatedAt, &d.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
@@ -172,11 +247,14 @@ func (r *eventDraftRepoImpl) GetByEventID(ctx context.Context, eventID string) (
 	return &d, nil
 }
 
-func (r *eventDraftRepoImpl) ListDrafts(ctx context.Context) ([]*EventDraft, error) {
+func (r *eventDraftRepoImpl) ListDrafts(ctx context.Context, orgID string) ([]*EventDraft, error) {
+	if !isUUID(orgID) {
+		return nil, nil
+	}
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, event_id, title, description, start_date, end_date, published_at, created_by, created_at, updated_at
-		FROM public.event_drafts ORDER BY updated_at DESC
-	`)
+		SELECT id, event_id, organization_id, title, description, start_date, end_date, timezone, capacity, published_at, created_by, created_at, updated_at
+		FROM public.event_drafts WHERE organization_id = $1 ORDER BY updated_at DESC
+	`, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -184,7 +262,7 @@ func (r *eventDraftRepoImpl) ListDrafts(ctx context.Context) ([]*EventDraft, err
 	var list []*EventDraft
 	for rows.Next() {
 		var d EventDraft
-		if err := rows.Scan(&d.ID, &d.EventID, &d.Title, &d.Description, &d.StartDate, &d.EndDate, &d.PublishedAt, &d.CreatedBy, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		if err := rows.Scan(&d.ID, &d.EventID, &d.OrganizationID, &d.Title, &d.Description, &d.StartDate, &d.EndDate, &d.Timezone, &d.Capacity, &d.PublishedAt, &d.CreatedBy, &d.CreatedAt, &d.UpdatedAt); err != nil {
 			return nil, err
 		}
 		list = append(list, &d)
@@ -194,17 +272,19 @@ func (r *eventDraftRepoImpl) ListDrafts(ctx context.Context) ([]*EventDraft, err
 
 func (r *eventDraftRepoImpl) Upsert(ctx context.Context, draft *EventDraft) error {
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO public.event_drafts (id, event_id, title, description, start_date, end_date, published_at, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO public.event_drafts (id, event_id, organization_id, title, description, start_date, end_date, timezone, capacity, published_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (id) DO UPDATE SET
 			event_id = EXCLUDED.event_id,
 			title = EXCLUDED.title,
 			description = EXCLUDED.description,
 			start_date = EXCLUDED.start_date,
 			end_date = EXCLUDED.end_date,
+			timezone = EXCLUDED.timezone,
+			capacity = EXCLUDED.capacity,
 			published_at = EXCLUDED.published_at,
 			updated_at = EXCLUDED.updated_at
-	`, draft.ID, draft.EventID, draft.Title, draft.Description, draft.StartDate, draft.EndDate, draft.PublishedAt, draft.CreatedBy, draft.CreatedAt, draft.UpdatedAt)
+	`, draft.ID, draft.EventID, draft.OrganizationID, draft.Title, draft.Description, draft.StartDate, draft.EndDate, draft.Timezone, draft.Capacity, draft.PublishedAt, draft.CreatedBy, draft.CreatedAt, draft.UpdatedAt)
 	return err
 }
 
@@ -215,7 +295,7 @@ func (r *eventDraftRepoImpl) DeleteByID(ctx context.Context, id string) error {
 
 // — EventDayDraftRepository
 
-type eventDayDraftRepoImpl struct{ db *postgres.DB }
+type eventDayDraftRepoImpl struct{ db querier }
 
 func (r *eventDayDraftRepoImpl) GetByID(ctx context.Context, id string) (*EventDayDraft, error) {
 	row := r.db.QueryRowContext(ctx, `
@@ -295,3 +375,193 @@ func (r *eventDayDraftRepoImpl) DeleteByID(ctx context.Context, id string) error
 	_, err := r.db.ExecContext(ctx, `DELETE FROM public.event_days_drafts WHERE id = $1`, id)
 	return err
 }
+
+// — ChangeRepository
+
+// ChangesChannel — канал Postgres NOTIFY для журнала изменений; payload — id события.
+const ChangesChannel = "event_changes"
+
+type changeRepoImpl struct{ db querier }
+
+func (r *changeRepoImpl) Append(ctx context.Context, changes []*Change) error {
+	notified := make(map[string]bool)
+	for _, c := range changes {
+		var sessionID *string
+		if c.SessionID != "" {
+			sessionID = &c.SessionID
+		}
+		if err := r.db.QueryRowContext(ctx, `
+			INSERT INTO public.event_changes (event_id, kind, date, session_id)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`, c.EventID, c.Kind, c.Date, sessionID).Scan(&c.ID, &c.CreatedAt); err != nil {
+			return err
+		}
+		if notified[c.EventID] {
+			continue
+		}
+		// NOTIFY внутри транзакции доставляется только после COMMIT — слушатели не увидят незакоммиченное.
+		if _, err := r.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, ChangesChannel, c.EventID); err != nil {
+			return err
+		}
+		notified[c.EventID] = true
+	}
+	return nil
+}
+
+func (r *changeRepoImpl) ListSince(ctx context.Context, eventID string, afterID int64, limit int) ([]*Change, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, event_id, kind, date, session_id, created_at
+		FROM public.event_changes
+		WHERE event_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`, eventID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Change
+	for rows.Next() {
+		var c Change
+		var sessionID sql.NullString
+		if err := rows.Scan(&c.ID, &c.EventID, &c.Kind, &c.Date, &sessionID, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.SessionID = sessionID.String
+		list = append(list, &c)
+	}
+	return list, rows.Err()
+}
+
+func (r *changeRepoImpl) LatestID(ctx context.Context, eventID string) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(max(id), 0) FROM public.event_changes WHERE event_id = $1
+	`, eventID).Scan(&id)
+	return id, err
+}
+
+// — MemberRepository
+
+type memberRepoImpl struct{ db querier }
+
+// isUUID — id из пути запроса может быть не UUID: такой записи просто нет (а не ошибка приведения типа в БД).
+func isUUID(ids ...string) bool {
+	for _, id := range ids {
+		if uuid.Validate(id) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *memberRepoImpl) Get(ctx context.Context, eventID, userID string) (*Member, error) {
+	if !isUUID(eventID, userID) {
+		return nil, nil
+	}
+	row := r.db.QueryRowContext(ctx, `
+		SELECT m.event_id, m.user_id, u.email, m.role, m.invited_by, m.created_at, m.updated_at
+		FROM public.event_members m JOIN auth.users u ON u.id = m.user_id
+		WHERE m.event_id = $1 AND m.user_id = $2
+	`, eventID, userID)
+	var m Member
+	err := row.Scan(&m.EventID, &m.UserID, &m.Email, &m.Role, &m.InvitedBy, &m.CreatedAt, &m.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *memberRepoImpl) ListByEventID(ctx context.Context, eventID string) ([]*Member, error) {
+	if !isUUID(eventID) {
+		return nil, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.event_id, m.user_id, u.email, m.role, m.invited_by, m.created_at, m.updated_at
+		FROM public.event_members m JOIN auth.users u ON u.id = m.user_id
+		WHERE m.event_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, m.created_at
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Member
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.EventID, &m.UserID, &m.Email, &m.Role, &m.InvitedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &m)
+	}
+	return list, rows.Err()
+}
+
+func (r *memberRepoImpl) ListEventIDsByUserID(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT event_id FROM public.event_members WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *memberRepoImpl) Upsert(ctx context.Context, member *Member) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO public.event_members (event_id, user_id, role, invited_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (event_id, user_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			updated_at = EXCLUDED.updated_at
+	`, member.EventID, member.UserID, member.Role, member.InvitedBy, member.CreatedAt, member.UpdatedAt)
+	return err
+}
+
+func (r *memberRepoImpl) Delete(ctx context.Context, eventID, userID string) error {
+	if !isUUID(eventID, userID) {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM public.event_members WHERE event_id = $1 AND user_id = $2
+	`, eventID, userID)
+	return err
+}
+
+func (r *memberRepoImpl) LockOwners(ctx context.Context, eventID string) ([]string, error) {
+	if !isUUID(eventID) {
+		return nil, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id FROM public.event_members
+		WHERE event_id = $1 AND role = 'owner'
+		ORDER BY user_id
+		FOR UPDATE
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var owners []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		owners = append(owners, userID)
+	}
+	return owners, rows.Err()
+}
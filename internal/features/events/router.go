@@ -1,36 +1,78 @@
 package events
 
 import (
+	"context"
+
 	"github.com/gofiber/fiber/v2"
 
+	"wdpl_back/internal/features/apikeys"
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/organizations"
 	"wdpl_back/internal/shared/config"
 	"wdpl_back/internal/shared/http/middleware"
 	"wdpl_back/internal/shared/postgres"
 )
 
-// Роли, допущенные к редактированию черновиков (STEP3).
-var DraftEditorRoles = []string{"admin", "organizer", "editor"}
+// DraftEditorRoles — глобальные роли редакторов: заводят новые события в своих организациях и видят
+// скрытые сессии их событий, в других фичах — сводки и модерация. Черновики существующего события —
+// только его команде (Member) и владельцам и администраторам его организации.
+var DraftEditorRoles = []string{auth.RoleAdmin, auth.RoleOrganizer, auth.RoleEditor}
 
 // RegisterRoutes вешает эндпоинты событий на api (обычно /api).
-// Публичные: GET /events, GET /events/:id. Защищённые (редакторы): черновики и POST drafts/:id/publish.
-func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
+// Публичные: GET /events, GET /events/:id, дни, сессии, live-экран и поток изменений события — по организации
+// по умолчанию, те же маршруты под /orgs/:slug/events — по организации slug.
+// Защищённые (команда события): черновики, POST drafts/:id/publish, POST /:eventId/unpublish и состав команды.
+// Сохранение, публикация и удаление черновиков и снятие с публикации пишутся в журнал аудита.
+// Подписка на изменения (LISTEN) живёт до отмены ctx — времени жизни приложения.
+func RegisterRoutes(ctx context.Context, api fiber.Router, db *postgres.DB, cfg *config.Config) {
 	repos := NewPostgresRepository(db)
-	svc := NewService(repos.Events, repos.Days, repos.Drafts, repos.DayDrafts)
+	svc := NewService(repos.Events, repos.Days, repos.Drafts, repos.DayDrafts, repos.Changes, repos.Members, auth.NewPostgresRepository(db), organizations.NewPostgresRepository(db), repos.Tx)
 	h := NewHandler(svc)
 
+	// Журнал изменений пишет любая реплика; NOTIFY будит потоки /:id/stream на всех.
+	go db.Listen(ctx, ChangesChannel, svc.ChangeHub())
+
+	// Middleware вешаем на маршруты, а не на группу: Group(prefix, handlers...) в Fiber работает как Use
+	// и закрыл бы авторизацией все /events/*, включая публичные.
+	// Права на черновики, дни и публикацию проверяет сервис по команде события (admin — без ограничений).
+	// API-ключ принимается там, где есть его scope, и действует с правами своего пользователя;
+	// состав команды меняют только люди.
+	requireAuth := middleware.RequireAuth(cfg)
+	canRead := middleware.RequireAuthOrAPIKey(cfg, apikeys.ScopeEventsRead)
+	canWrite := middleware.RequireAuthOrAPIKey(cfg, apikeys.ScopeDraftsWrite)
+	canPublish := middleware.RequireAuthOrAPIKey(cfg, apikeys.ScopePublish)
+
 	// Сначала защищённые маршруты, чтобы /drafts и т.д. не попали в /:id.
-	g := api.Group("/events", middleware.RequireAuth(cfg), middleware.RequireRole(DraftEditorRoles...))
-	g.Get("/drafts", h.ListDrafts)
-	g.Get("/drafts/:id", h.GetDraft)
-	g.Post("/drafts", h.SaveDraft)
-	g.Put("/drafts", h.SaveDraft)
-	g.Post("/drafts/:id/publish", h.PublishDraft)
-	g.Get("/day-drafts/:id", h.GetDayDraft)
-	g.Get("/:eventId/day-drafts", h.ListDayDrafts)
-	g.Post("/:eventId/day-drafts", h.SaveDayDraft)
-
-	// Публичные: список и детали (без auth). "" — путь группы без суффикса (GET /api/events).
-	public := api.Group("/events")
-	public.Get("", h.ListEvents)
-	public.Get("/:id", h.GetEvent)
+	g := api.Group("/events")
+	g.Get("/drafts", canRead, h.ListDrafts)
+	g.Get("/drafts/:id", canRead, h.GetDraft)
+	g.Post("/drafts", canWrite, h.SaveDraft)
+	g.Put("/drafts", canWrite, h.SaveDraft)
+	g.Delete("/drafts/:id", canWrite, h.DeleteDraft)
+	g.Post("/drafts/:id/publish", canPublish, h.PublishDraft)
+	g.Post("/:eventId/unpublish", canPublish, h.UnpublishEvent)
+	g.Get("/day-drafts/:id", canRead, h.GetDayDraft)
+	g.Get("/:eventId/day-drafts", canRead, h.ListDayDrafts)
+	g.Post("/:eventId/day-drafts", canWrite, h.SaveDayDraft)
+	g.Get("/:eventId/members", canRead, h.ListMembers)
+	g.Post("/:eventId/members", requireAuth, h.AddMember)
+	g.Delete("/:eventId/members/:userId", requireAuth, h.RemoveMember)
+
+	registerPublicRoutes(api.Group("/events"), h, cfg)
+	registerPublicRoutes(api.Group("/orgs/:slug/events"), h, cfg)
+}
+
+// registerPublicRoutes вешает публичные маршруты (без auth) на g. "" — путь группы без суффикса (GET /api/events).
+// OrgScope ограничивает запрос событиями организации. OptionalAuth: редакторы организации с валидным JWT
+// (или API-ключом с events:read) видят всё расписание, остальные — только публичные сессии.
+func registerPublicRoutes(g fiber.Router, h *Handler, cfg *config.Config) {
+	optionalAuth := middleware.OptionalAuthOrAPIKey(cfg, apikeys.ScopeEventsRead)
+	g.Get("", h.OrgScope, h.ListEvents)
+	g.Get("/:id", optionalAuth, h.OrgScope, h.GetEvent)
+	g.Get("/:id/live", h.OrgScope, h.GetLive)
+	g.Get("/:id/live/stream", h.OrgScope, h.StreamLive)
+	g.Get("/:id/stream", h.OrgScope, h.StreamChanges)
+	g.Get("/:id/days/:date", optionalAuth, h.OrgScope, h.GetEventDay)
+	g.Get("/:id/sessions", optionalAuth, h.OrgScope, h.ListSessions)
+	g.Get("/:id/sessions/:sessionId", optionalAuth, h.OrgScope, h.GetSession)
 }
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"errors"
+
+	"wdpl_back/internal/features/organizations"
+)
+
+// ErrOrganizationNotFound — организации с таким slug нет.
+var ErrOrganizationNotFound = errors.New("organization not found")
+
+// ResolveOrganization находит организацию по slug; пустой slug — организация по умолчанию.
+func (s *Service) ResolveOrganization(ctx context.Context, slug string) (*organizations.Organization, error) {
+	if slug == "" {
+		slug = organizations.DefaultSlug
+	}
+	org, err := s.orgs.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+// CanSeeHiddenSessions — видит ли actor всё расписание событий организации orgID (is_public=false,
+// planned, cancelled): admin, а также редакторы (DraftEditorRoles), состоящие в этой организации.
+func (s *Service) CanSeeHiddenSessions(ctx context.Context, actor Actor, orgID string) (bool, error) {
+	if actor.IsAdmin() {
+		return true, nil
+	}
+	if !actor.CanCreateEvents() {
+		return false, nil
+	}
+	m, err := s.orgs.GetMember(ctx, orgID, actor.UserID)
+	if err != nil {
+		return false, err
+	}
+	return m != nil, nil
+}
+
+// publishedEvent возвращает опубликованное событие организации orgID (nil, если его нет, оно чужое или снято
+// с публикации): события других организаций и архивные публичный API не показывает.
+func (s *Service) publishedEvent(ctx context.Context, orgID, eventID string) (*Event, error) {
+	event, err := s.eventsRepo.GetByID(ctx, eventID)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	if event.OrganizationID != orgID || event.IsArchived() {
+		return nil, nil
+	}
+	return event, nil
+}
+
+// eventOrganizationID — организация события: опубликованного или пока только черновика ("" — события нет).
+func eventOrganizationID(ctx context.Context, r *TxRepositories, eventID string) (string, error) {
+	event, err := r.Events.GetByID(ctx, eventID)
+	if err != nil {
+		return "", err
+	}
+	if event != nil {
+		return event.OrganizationID, nil
+	}
+	draft, err := r.Drafts.GetByEventID(ctx, eventID)
+	if err != nil {
+		return "", err
+	}
+	if draft == nil {
+		// Черновик нового события: его id и есть id будущего события.
+		if draft, err = r.Drafts.GetByID(ctx, eventID); err != nil {
+			return "", err
+		}
+	}
+	if draft == nil || draft.TargetEventID() != eventID {
+		return "", nil
+	}
+	return draft.OrganizationID, nil
+}
+
+// repos — репозитории сервиса вне транзакции: проверки прав при чтении.
+func (s *Service) repos() *TxRepositories {
+	return &TxRepositories{
+		Events:    s.eventsRepo,
+		Days:      s.daysRepo,
+		Drafts:    s.draftsRepo,
+		DayDrafts: s.dayDraftsRepo,
+		Changes:   s.changesRepo,
+		Members:   s.membersRepo,
+	}
+}
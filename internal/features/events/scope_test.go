@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+)
+
+var (
+	testOrgAdmin = Actor{UserID: "org-admin-1", Role: auth.RoleUser}
+	testOutsider = Actor{UserID: "outsider-1", Role: auth.RoleOrganizer}
+)
+
+func TestSaveDraft_PinsOrganization(t *testing.T) {
+	svc, draftsRepo, _ := newMembersTestService(t)
+	ctx := context.Background()
+
+	// Без организации — организация по умолчанию.
+	require.NoError(t, svc.SaveDraft(ctx, testOwner, newDraft("event-1", testOwner)))
+	assert.Equal(t, testOrgID, draftsRepo.drafts["event-1"].OrganizationID)
+
+	// Событие заводят только в своей организации.
+	draft := newDraft("event-2", testOutsider)
+	draft.OrganizationID = testOrgID
+	assert.ErrorIs(t, svc.SaveDraft(ctx, testOutsider, draft), ErrForbidden)
+	draft.OrganizationID = testOtherOrgID
+	require.NoError(t, svc.SaveDraft(ctx, testOutsider, draft))
+
+	// Организацию существующего черновика сменить нельзя, без неё — сохраняется прежняя.
+	draft = newDraft("event-2", testOutsider)
+	draft.OrganizationID = testOrgID
+	assert.ErrorIs(t, svc.SaveDraft(ctx, testOutsider, draft), ErrOrganizationMismatch)
+	draft.OrganizationID = ""
+	require.NoError(t, svc.SaveDraft(ctx, testOutsider, draft))
+	assert.Equal(t, testOtherOrgID, draftsRepo.drafts["event-2"].OrganizationID)
+
+	event, err := svc.PublishDraft(ctx, testOutsider, "event-2")
+	require.NoError(t, err)
+	assert.Equal(t, testOtherOrgID, event.OrganizationID)
+}
+
+func TestOrgAdmin_ManagesAllEventsOfOrganization(t *testing.T) {
+	svc, _, _ := newMembersTestService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.SaveDraft(ctx, testOwner, newDraft("event-1", testOwner)))
+	outsiderDraft := newDraft("event-2", testOutsider)
+	outsiderDraft.OrganizationID = testOtherOrgID
+	require.NoError(t, svc.SaveDraft(ctx, testOutsider, outsiderDraft))
+
+	// Администратор организации не в команде, но видит и меняет её события.
+	_, err := svc.GetDraft(ctx, testOrgAdmin, "event-1")
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveDraft(ctx, testOrgAdmin, newDraft("event-1", testOrgAdmin)))
+	_, err = svc.AddMember(ctx, testOrgAdmin, "event-1", "viewer@example.com", MemberViewer)
+	require.NoError(t, err)
+	list, err := svc.ListDrafts(ctx, testOrgAdmin, testOrgID)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	// Чужие организации ему недоступны.
+	_, err = svc.GetDraft(ctx, testOrgAdmin, "event-2")
+	assert.ErrorIs(t, err, ErrForbidden)
+	list, err = svc.ListDrafts(ctx, testOrgAdmin, testOtherOrgID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// Рядовой участник организации без команды события доступа не получает.
+	_, err = svc.GetDraft(ctx, otherOrganizer, "event-1")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestPublicEvents_ScopedToOrganization(t *testing.T) {
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	eventsRepo.events["event-2"] = &Event{ID: "event-2", OrganizationID: testOtherOrgID, Title: "Other"}
+	svc := newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+	ctx := context.Background()
+
+	list, err := svc.ListPublishedEvents(ctx, testOtherOrgID, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "event-2", list[0].ID)
+
+	event, _, err := svc.GetEventWithDays(ctx, testOtherOrgID, "event-1", true)
+	require.NoError(t, err)
+	assert.Nil(t, event)
+	_, err = svc.ListSessions(ctx, testOtherOrgID, "event-1", SessionFilter{})
+	assert.ErrorIs(t, err, ErrEventNotFound)
+	_, err = svc.LatestChangeID(ctx, testOtherOrgID, "event-1")
+	assert.ErrorIs(t, err, ErrEventNotFound)
+
+	visible, err := svc.CanSeeHiddenSessions(ctx, Actor{UserID: "editor-1", Role: auth.RoleEditor}, testOrgID)
+	require.NoError(t, err)
+	assert.True(t, visible)
+	visible, err = svc.CanSeeHiddenSessions(ctx, Actor{UserID: "editor-1", Role: auth.RoleEditor}, testOtherOrgID)
+	require.NoError(t, err)
+	assert.False(t, visible)
+}
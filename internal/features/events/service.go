@@ -3,7 +3,11 @@ package events
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
+
+	"wdpl_back/internal/features/organizations"
 )
 
 // Service инкапсулирует бизнес-логику работы с событиями и черновиками.
@@ -12,19 +16,34 @@ type Service struct {
 	daysRepo      EventDayRepository
 	draftsRepo    EventDraftRepository
 	dayDraftsRepo EventDayDraftRepository
+	changesRepo   ChangeRepository
+	membersRepo   MemberRepository
+	users         UserDirectory
+	orgs          OrganizationDirectory
+	tx            Transactor
+	hub           *ChangeHub
 }
 
-func NewService(eventsRepo EventRepository, daysRepo EventDayRepository, draftsRepo EventDraftRepository, dayDraftsRepo EventDayDraftRepository) *Service {
+func NewService(eventsRepo EventRepository, daysRepo EventDayRepository, draftsRepo EventDraftRepository, dayDraftsRepo EventDayDraftRepository, changesRepo ChangeRepository, membersRepo MemberRepository, users UserDirectory, orgs OrganizationDirectory, tx Transactor) *Service {
 	return &Service{
 		eventsRepo:    eventsRepo,
 		daysRepo:      daysRepo,
 		draftsRepo:    draftsRepo,
 		dayDraftsRepo: dayDraftsRepo,
+		changesRepo:   changesRepo,
+		membersRepo:   membersRepo,
+		users:         users,
+		orgs:          orgs,
+		tx:            tx,
+		hub:           NewChangeHub(),
 	}
 }
 
 // SaveDraft сохраняет черновик события (создание или обновление). created_by обязателен.
-func (s *Service) SaveDraft(ctx context.Context, draft *EventDraft) error {
+// Нужна роль editor в команде события; черновик нового события делает actor его владельцем.
+// Организация черновика без OrganizationID — организация события или организация по умолчанию.
+// Сохранение пишется в журнал аудита с прежней и новой версией черновика.
+func (s *Service) SaveDraft(ctx context.Context, actor Actor, draft *EventDraft) error {
 	now := time.Now()
 	if draft.ID == "" {
 		return ErrInvalidDraftID
@@ -32,93 +51,258 @@ func (s *Service) SaveDraft(ctx context.Context, draft *EventDraft) error {
 	if draft.CreatedBy == "" {
 		return ErrInvalidCreatedBy
 	}
+	if draft.Timezone == "" {
+		draft.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(draft.Timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	if draft.Capacity != nil && *draft.Capacity < 0 {
+		return ErrInvalidCapacity
+	}
 	if draft.CreatedAt.IsZero() {
 		draft.CreatedAt = now
 	}
 	draft.UpdatedAt = now
-	return s.draftsRepo.Upsert(ctx, draft)
+	msg, err := eventDraftSavedMessage(draft)
+	if err != nil {
+		return err
+	}
+	return s.tx.InTx(ctx, func(r *TxRepositories) error {
+		existing, err := s.authorizeDraftSave(ctx, r, actor, draft)
+		if err != nil {
+			return err
+		}
+		if err := r.Drafts.Upsert(ctx, draft); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, r, actor, ActionDraftSaved, AuditTargetEventDraft, draft.ID, draftStateOf(existing), draftStateOf(draft)); err != nil {
+			return err
+		}
+		return r.Outbox.Append(ctx, msg)
+	})
 }
 
 // PublishDraft публикует черновик (STEP4): event_draft → events, event_day_drafts → event_days, затем удаляет черновики.
-func (s *Service) PublishDraft(ctx context.Context, draftID string) (*Event, error) {
-	draft, err := s.draftsRepo.GetByID(ctx, draftID)
+// Всё — одной транзакцией вместе с журналом изменений, доменными событиями (outbox) и записью аудита.
+// Нужна роль editor в команде события.
+func (s *Service) PublishDraft(ctx context.Context, actor Actor, draftID string) (*Event, error) {
+	var event *Event
+	err := s.tx.InTx(ctx, func(r *TxRepositories) error {
+		var err error
+		event, err = s.publishDraft(ctx, r, actor, draftID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *Service) publishDraft(ctx context.Context, r *TxRepositories, actor Actor, draftID string) (*Event, error) {
+	draft, err := r.Drafts.GetByID(ctx, draftID)
 	if err != nil {
 		return nil, err
 	}
 	if draft == nil {
 		return nil, ErrDraftNotFound
 	}
+	eventID := draft.TargetEventID()
+	if err := s.authorize(ctx, r, actor, eventID, MemberEditor); err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
-	eventID := draft.ID
-	if draft.EventID != nil {
-		eventID = *draft.EventID
-	}
 
 	var event *Event
 	if draft.EventID != nil {
-		event, err = s.eventsRepo.GetByID(ctx, *draft.EventID)
+		event, err = r.Events.GetByID(ctx, *draft.EventID)
 		if err != nil {
 			return nil, err
 		}
 	}
-	if event == nil {
+	// before — опубликованная версия для журнала аудита (nil — событие публикуется впервые).
+	var before any
+	if event != nil {
+		before = eventStateOf(event)
+	} else {
 		event = &Event{
-			ID:        eventID,
-			CreatedAt: now,
-			Status:    "published",
+			ID:             eventID,
+			OrganizationID: draft.OrganizationID,
+			CreatedAt:      now,
 		}
 	}
+	// Публикация возвращает и снятое с публикации событие.
+	event.Status = EventStatusPublished
 
 	event.Title = draft.Title
 	event.Description = draft.Description
 	event.StartDate = draft.StartDate
 	event.EndDate = draft.EndDate
+	event.Timezone = draft.Timezone
+	event.Capacity = draft.Capacity
 	event.UpdatedAt = now
 
-	if err := s.eventsRepo.Upsert(ctx, event); err != nil {
-		return nil, err
-	}
-
-	// Публикуем черновики дней в event_days.
-	dayDrafts, err := s.dayDraftsRepo.ListByEventID(ctx, eventID)
+	// Черновики дней разбираем до любых записей: черновик может быть «грязным»,
+	// а в event_days попадает только валидное расписание с посчитанными метаданными.
+	dayDrafts, err := r.DayDrafts.ListByEventID(ctx, eventID)
 	if err != nil {
 		return nil, err
 	}
+	days := make([]*EventDay, 0, len(dayDrafts))
 	for _, dd := range dayDrafts {
-		day := &EventDay{
+		schedule, err := (&EventDay{Schedule: dd.Schedule}).ParseSchedule()
+		if err != nil {
+			return nil, fmt.Errorf("%w: day %s: %v", ErrInvalidSchedule, dd.Date.Format(dateLayout), err)
+		}
+		day, err := (&EventDay{
 			EventID:   eventID,
 			Date:      dd.Date,
-			Schedule:  dd.Schedule,
 			CreatedAt: dd.CreatedAt,
 			UpdatedAt: now,
+		}).WithSessions(schedule.Sessions)
+		if err != nil {
+			return nil, err
 		}
-		if day.Schedule == nil {
-			day.Schedule = []byte(`{"sessions":[]}`)
-		}
-		if err := s.daysRepo.Upsert(ctx, day); err != nil {
+		days = append(days, day)
+	}
+
+	// Прежние дни нужны, чтобы записать в журнал и outbox, что именно изменилось.
+	previous, err := r.Days.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := diffPublishedDays(eventID, previous, days)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := publishMessages(event, draft.ID, changes)
+	if err != nil {
+		return nil, err
+	}
+	changes = append([]*Change{{EventID: eventID, Kind: ChangeEventPublished}}, changes...)
+
+	if err := r.Events.Upsert(ctx, event); err != nil {
+		return nil, err
+	}
+
+	// Публикуем черновики дней в event_days.
+	for _, day := range days {
+		if err := r.Days.Upsert(ctx, day); err != nil {
 			return nil, err
 		}
-		if err := s.dayDraftsRepo.DeleteByID(ctx, dd.ID); err != nil {
+	}
+
+	if err := r.Changes.Append(ctx, changes); err != nil {
+		return nil, err
+	}
+	if err := r.Outbox.Append(ctx, msgs...); err != nil {
+		return nil, err
+	}
+
+	for _, dd := range dayDrafts {
+		if err := r.DayDrafts.DeleteByID(ctx, dd.ID); err != nil {
 			return nil, err
 		}
 	}
+	if err := r.Drafts.DeleteByID(ctx, draft.ID); err != nil {
+		return nil, err
+	}
 
-	if err := s.draftsRepo.DeleteByID(ctx, draft.ID); err != nil {
+	after := eventStateOf(event)
+	after.DraftID = draft.ID
+	after.Days = len(days)
+	if err := appendAudit(ctx, r, actor, ActionEventPublished, AuditTargetEvent, event.ID, before, after); err != nil {
 		return nil, err
 	}
+	return event, nil
+}
 
+// UnpublishEvent снимает опубликованное событие с публикации (status = archived): публичный API его больше
+// не показывает, дни и регистрации сохраняются, следующая публикация черновика возвращает событие.
+// Нужна роль editor в команде события. Журнал изменений, event.unpublished в outbox и запись аудита —
+// в той же транзакции; повторное снятие ничего не пишет.
+func (s *Service) UnpublishEvent(ctx context.Context, actor Actor, eventID string) (*Event, error) {
+	var event *Event
+	err := s.tx.InTx(ctx, func(r *TxRepositories) error {
+		var err error
+		event, err = r.Events.GetByID(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			return ErrEventNotFound
+		}
+		if err := s.authorize(ctx, r, actor, eventID, MemberEditor); err != nil {
+			return err
+		}
+		if event.IsArchived() {
+			return nil
+		}
+		before := eventStateOf(event)
+		event.Status = EventStatusArchived
+		event.UpdatedAt = time.Now()
+		if err := r.Events.Upsert(ctx, event); err != nil {
+			return err
+		}
+		if err := r.Changes.Append(ctx, []*Change{{EventID: eventID, Kind: ChangeEventUnpublished}}); err != nil {
+			return err
+		}
+		msg, err := unpublishedMessage(event)
+		if err != nil {
+			return err
+		}
+		if err := r.Outbox.Append(ctx, msg); err != nil {
+			return err
+		}
+		return appendAudit(ctx, r, actor, ActionEventUnpublished, AuditTargetEvent, eventID, before, eventStateOf(event))
+	})
+	if err != nil {
+		return nil, err
+	}
 	return event, nil
 }
 
-// ListPublishedEvents возвращает список опубликованных событий (пагинация).
-func (s *Service) ListPublishedEvents(ctx context.Context, limit, offset int) ([]*Event, error) {
-	return s.eventsRepo.List(ctx, limit, offset)
+// DeleteDraft удаляет черновик события вместе с черновиками его дней; опубликованное событие не меняется.
+// Нужна роль editor в команде события. Удаление пишется в журнал аудита с удалённой версией черновика.
+func (s *Service) DeleteDraft(ctx context.Context, actor Actor, draftID string) error {
+	return s.tx.InTx(ctx, func(r *TxRepositories) error {
+		draft, err := r.Drafts.GetByID(ctx, draftID)
+		if err != nil {
+			return err
+		}
+		if draft == nil {
+			return ErrDraftNotFound
+		}
+		eventID := draft.TargetEventID()
+		if err := s.authorize(ctx, r, actor, eventID, MemberEditor); err != nil {
+			return err
+		}
+		dayDrafts, err := r.DayDrafts.ListByEventID(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		for _, dd := range dayDrafts {
+			if err := r.DayDrafts.DeleteByID(ctx, dd.ID); err != nil {
+				return err
+			}
+		}
+		if err := r.Drafts.DeleteByID(ctx, draft.ID); err != nil {
+			return err
+		}
+		return appendAudit(ctx, r, actor, ActionDraftDeleted, AuditTargetEventDraft, draft.ID, draftStateOf(draft), nil)
+	})
+}
+
+// ListPublishedEvents возвращает список опубликованных событий организации orgID (пагинация).
+func (s *Service) ListPublishedEvents(ctx context.Context, orgID string, limit, offset int) ([]*Event, error) {
+	return s.eventsRepo.List(ctx, orgID, limit, offset)
 }
 
-// GetEventWithDays возвращает событие и его опубликованные дни (для публичного API).
-func (s *Service) GetEventWithDays(ctx context.Context, eventID string) (*Event, []*EventDay, error) {
-	event, err := s.eventsRepo.GetByID(ctx, eventID)
+// GetEventWithDays возвращает событие организации orgID и его опубликованные дни (для публичного API).
+// Без includeHidden из расписаний вырезаются скрытые от публики сессии, метаданные дней пересчитываются.
+func (s *Service) GetEventWithDays(ctx context.Context, orgID, eventID string, includeHidden bool) (*Event, []*EventDay, error) {
+	event, err := s.publishedEvent(ctx, orgID, eventID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -129,21 +313,227 @@ func (s *Service) GetEventWithDays(ctx context.Context, eventID string) (*Event,
 	if err != nil {
 		return nil, nil, err
 	}
-	return event, days, nil
+	if includeHidden {
+		return event, days, nil
+	}
+	visible := make([]*EventDay, 0, len(days))
+	for _, day := range days {
+		filtered, err := publicDayView(day)
+		if err != nil {
+			return nil, nil, err
+		}
+		visible = append(visible, filtered)
+	}
+	return event, visible, nil
 }
 
-// GetDraft возвращает черновик события по ID.
-func (s *Service) GetDraft(ctx context.Context, id string) (*EventDraft, error) {
-	return s.draftsRepo.GetByID(ctx, id)
+// GetEventDay возвращает опубликованный день события организации orgID по дате (nil, если дня нет).
+// Без includeHidden из schedule вырезаются скрытые от публики сессии.
+func (s *Service) GetEventDay(ctx context.Context, orgID, eventID string, date time.Time, includeHidden bool) (*EventDay, error) {
+	event, err := s.publishedEvent(ctx, orgID, eventID)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	day, err := s.daysRepo.GetByEventIDAndDate(ctx, eventID, date)
+	if err != nil || day == nil {
+		return nil, err
+	}
+	if includeHidden {
+		return day, nil
+	}
+	return publicDayView(day)
 }
 
-// ListDrafts возвращает список черновиков (для админки).
-func (s *Service) ListDrafts(ctx context.Context) ([]*EventDraft, error) {
-	return s.draftsRepo.ListDrafts(ctx)
+// publicDayView — копия дня только с публично видимыми сессиями. Исходный день не меняется.
+func publicDayView(day *EventDay) (*EventDay, error) {
+	schedule, err := day.ParseSchedule()
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]SessionData, 0, len(schedule.Sessions))
+	for i := range schedule.Sessions {
+		if schedule.Sessions[i].IsPubliclyVisible() {
+			visible = append(visible, schedule.Sessions[i])
+		}
+	}
+	return day.WithSessions(visible)
+}
+
+// ListSessions возвращает плоский список сессий опубликованного события организации orgID, отсортированный по началу.
+// Если события нет — ErrEventNotFound (чтобы отличить от пустого расписания).
+func (s *Service) ListSessions(ctx context.Context, orgID, eventID string, filter SessionFilter) ([]*DaySession, error) {
+	event, err := s.publishedEvent(ctx, orgID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEventNotFound
+	}
+	days, err := s.daysRepo.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*DaySession, 0)
+	for _, day := range days {
+		schedule, err := day.ParseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		for i := range schedule.Sessions {
+			if filter.Match(day, &schedule.Sessions[i]) {
+				list = append(list, &DaySession{Day: day, Session: schedule.Sessions[i]})
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Session.StartsAt.Before(list[j].Session.StartsAt)
+	})
+	return list, nil
+}
+
+// GetSession ищет сессию по id внутри JSONB расписаний всех дней события организации orgID
+// (nil, если не найдена или скрыта). Из фильтра учитывается только видимость: поиск идёт по всем дням.
+func (s *Service) GetSession(ctx context.Context, orgID, eventID, sessionID string, includeHidden bool) (*DaySession, error) {
+	event, err := s.publishedEvent(ctx, orgID, eventID)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	days, err := s.daysRepo.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	filter := SessionFilter{IncludeHidden: includeHidden}
+	for _, day := range days {
+		schedule, err := day.ParseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		for i := range schedule.Sessions {
+			if schedule.Sessions[i].ID != sessionID {
+				continue
+			}
+			if !filter.Match(day, &schedule.Sessions[i]) {
+				return nil, nil
+			}
+			return &DaySession{Day: day, Session: schedule.Sessions[i]}, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetLive возвращает текущие и ближайшие публичные сессии события организации orgID по локациям на момент at.
+// Рассматриваются дни, чья дата совпадает с локальной датой at в часовом поясе события,
+// и дни, окно first_session_start..last_session_end которых содержит at (сессии через полночь).
+func (s *Service) GetLive(ctx context.Context, orgID, eventID string, at time.Time) (*LiveSnapshot, error) {
+	event, days, err := s.GetEventWithDays(ctx, orgID, eventID, false)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEventNotFound
+	}
+	loc, err := time.LoadLocation(event.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	localAt := at.In(loc)
+
+	rooms := make(map[string]*LiveRoom)
+	for _, day := range days {
+		// Метаданные дня позволяют не разбирать JSONB уже закончившихся дней.
+		if day.LastSessionEnd != nil && !at.Before(*day.LastSessionEnd) {
+			continue
+		}
+		inWindow := day.FirstSessionStart != nil && day.LastSessionEnd != nil &&
+			!at.Before(*day.FirstSessionStart) && at.Before(*day.LastSessionEnd)
+		if !sameDate(day.Date, localAt) && !inWindow {
+			continue
+		}
+		schedule, err := day.ParseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range schedule.Sessions {
+			if !at.Before(session.EndsAt) {
+				continue
+			}
+			key := locationKey(session.Location)
+			room, ok := rooms[key]
+			if !ok {
+				room = &LiveRoom{Location: session.Location}
+				rooms[key] = room
+			}
+			if !at.Before(session.StartsAt) {
+				room.Now = append(room.Now, session)
+				continue
+			}
+			if room.Next == nil || session.StartsAt.Before(room.Next.StartsAt) {
+				next := session
+				room.Next = &next
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(rooms))
+	for key := range rooms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	snapshot := &LiveSnapshot{EventID: event.ID, At: at, Timezone: loc.String(), Rooms: make([]LiveRoom, 0, len(keys))}
+	for _, key := range keys {
+		room := rooms[key]
+		sort.SliceStable(room.Now, func(i, j int) bool { return room.Now[i].StartsAt.Before(room.Now[j].StartsAt) })
+		snapshot.Rooms = append(snapshot.Rooms, *room)
+	}
+	return snapshot, nil
+}
+
+// GetDraft возвращает черновик события по ID (nil, если нет). Нужно членство в команде события.
+func (s *Service) GetDraft(ctx context.Context, actor Actor, id string) (*EventDraft, error) {
+	draft, err := s.draftsRepo.GetByID(ctx, id)
+	if err != nil || draft == nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, s.repos(), actor, draft.TargetEventID(), MemberViewer); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// ListDrafts возвращает черновики организации orgID: событий, в командах которых состоит actor,
+// а владельцам и администраторам организации и admin — все.
+func (s *Service) ListDrafts(ctx context.Context, actor Actor, orgID string) ([]*EventDraft, error) {
+	list, err := s.draftsRepo.ListDrafts(ctx, orgID)
+	if err != nil || actor.IsAdmin() {
+		return list, err
+	}
+	orgMember, err := s.orgs.GetMember(ctx, orgID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if orgMember != nil && orgMember.Can(organizations.RoleAdmin) {
+		return list, nil
+	}
+	eventIDs, err := s.membersRepo.ListEventIDsByUserID(ctx, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	own := make(map[string]bool, len(eventIDs))
+	for _, id := range eventIDs {
+		own[id] = true
+	}
+	visible := make([]*EventDraft, 0, len(list))
+	for _, d := range list {
+		if own[d.TargetEventID()] {
+			visible = append(visible, d)
+		}
+	}
+	return visible, nil
 }
 
 // SaveDayDraft сохраняет черновик дня события (создание или обновление по event_id + date).
-func (s *Service) SaveDayDraft(ctx context.Context, draft *EventDayDraft) error {
+// Нужна роль editor в команде события.
+func (s *Service) SaveDayDraft(ctx context.Context, actor Actor, draft *EventDayDraft) error {
 	now := time.Now()
 	if draft.EventID == "" {
 		return ErrInvalidEventID
@@ -155,16 +545,46 @@ func (s *Service) SaveDayDraft(ctx context.Context, draft *EventDayDraft) error
 		draft.CreatedAt = now
 	}
 	draft.UpdatedAt = now
-	return s.dayDraftsRepo.Upsert(ctx, draft)
+	return s.tx.InTx(ctx, func(r *TxRepositories) error {
+		if err := s.authorize(ctx, r, actor, draft.EventID, MemberEditor); err != nil {
+			return err
+		}
+		existing, err := r.DayDrafts.GetByEventIDAndDate(ctx, draft.EventID, draft.Date)
+		if err != nil {
+			return err
+		}
+		// ID черновика дня известен только после Upsert — сообщение и запись аудита собираем после него.
+		if err := r.DayDrafts.Upsert(ctx, draft); err != nil {
+			return err
+		}
+		if err := appendAudit(ctx, r, actor, ActionDayDraftSaved, AuditTargetDayDraft, draft.ID, dayDraftStateOf(existing), dayDraftStateOf(draft)); err != nil {
+			return err
+		}
+		msg, err := dayDraftSavedMessage(draft)
+		if err != nil {
+			return err
+		}
+		return r.Outbox.Append(ctx, msg)
+	})
 }
 
-// GetDayDraft возвращает черновик дня по ID.
-func (s *Service) GetDayDraft(ctx context.Context, id string) (*EventDayDraft, error) {
-	return s.dayDraftsRepo.GetByID(ctx, id)
+// GetDayDraft возвращает черновик дня по ID (nil, если нет). Нужно членство в команде события.
+func (s *Service) GetDayDraft(ctx context.Context, actor Actor, id string) (*EventDayDraft, error) {
+	draft, err := s.dayDraftsRepo.GetByID(ctx, id)
+	if err != nil || draft == nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, s.repos(), actor, draft.EventID, MemberViewer); err != nil {
+		return nil, err
+	}
+	return draft, nil
 }
 
-// ListDayDraftsByEventID возвращает черновики дней события для админки.
-func (s *Service) ListDayDraftsByEventID(ctx context.Context, eventID string) ([]*EventDayDraft, error) {
+// ListDayDraftsByEventID возвращает черновики дней события. Нужно членство в команде события.
+func (s *Service) ListDayDraftsByEventID(ctx context.Context, actor Actor, eventID string) ([]*EventDayDraft, error) {
+	if err := s.authorize(ctx, s.repos(), actor, eventID, MemberViewer); err != nil {
+		return nil, err
+	}
 	return s.dayDraftsRepo.ListByEventID(ctx, eventID)
 }
 
@@ -172,5 +592,40 @@ var (
 	ErrInvalidDraftID   = errors.New("invalid draft id")
 	ErrDraftNotFound    = errors.New("draft not found")
 	ErrInvalidEventID   = errors.New("invalid event id")
+	ErrEventNotFound    = errors.New("event not found")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+	ErrInvalidTimezone  = errors.New("invalid timezone")
+	ErrInvalidCapacity  = errors.New("invalid capacity")
 	ErrInvalidCreatedBy = errors.New("invalid created_by")
 )
+
+// changesPageSize — сколько записей журнала поток дочитывает за один запрос.
+const changesPageSize = 100
+
+// ChangesSince возвращает изменения события после afterID (по возрастанию id, не больше changesPageSize).
+func (s *Service) ChangesSince(ctx context.Context, eventID string, afterID int64) ([]*Change, error) {
+	return s.changesRepo.ListSince(ctx, eventID, afterID, changesPageSize)
+}
+
+// LatestChangeID — id последнего изменения события организации orgID: с него начинает поток без Last-Event-ID.
+// Если события нет — ErrEventNotFound.
+func (s *Service) LatestChangeID(ctx context.Context, orgID, eventID string) (int64, error) {
+	event, err := s.publishedEvent(ctx, orgID, eventID)
+	if err != nil {
+		return 0, err
+	}
+	if event == nil {
+		return 0, ErrEventNotFound
+	}
+	return s.changesRepo.LatestID(ctx, eventID)
+}
+
+// SubscribeChanges подписывает на сигналы о новых записях журнала события.
+func (s *Service) SubscribeChanges(eventID string) (<-chan struct{}, func()) {
+	return s.hub.Subscribe(eventID)
+}
+
+// ChangeHub возвращает хаб сигналов: его слушатель NOTIFY подключается в RegisterRoutes.
+func (s *Service) ChangeHub() *ChangeHub {
+	return s.hub
+}
@@ -2,11 +2,18 @@ package events
 
 import (
 	"context"
+	"encoding/json"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/organizations"
+	"wdpl_back/internal/shared/audit"
+	"wdpl_back/internal/shared/outbox"
 )
 
 // mockEventRepo — in-memory реализация EventRepository.
@@ -21,13 +28,12 @@ func (m *mockEventRepo) GetByID(_ context.Context, id string) (*Event, error) {
 	return m.events[id], nil
 }
 
-func (m *mockEventRepo) List(_ context.Context, limit, offset int) ([]*Event, error) {
-	if m.events == nil {
-		return nil, nil
-	}
+func (m *mockEventRepo) List(_ context.Context, orgID string, limit, offset int) ([]*Event, error) {
 	list := make([]*Event, 0, len(m.events))
 	for _, e := range m.events {
-		list = append(list, e)
+		if e.OrganizationID == orgID && !e.IsArchived() {
+			list = append(list, e)
+		}
 	}
 	return list, nil
 }
@@ -41,13 +47,37 @@ func (m *mockEventRepo) Upsert(_ context.Context, event *Event) error {
 }
 
 // mockDaysRepo — in-memory реализация EventDayRepository для тестов.
-type mockDaysRepo struct{}
+type mockDaysRepo struct {
+	days []*EventDay
+}
+
+func (m *mockDaysRepo) ListByEventID(_ context.Context, eventID string) ([]*EventDay, error) {
+	var list []*EventDay
+	for _, d := range m.days {
+		if d.EventID == eventID {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
 
-func (m *mockDaysRepo) ListByEventID(_ context.Context, _ string) ([]*EventDay, error) {
+func (m *mockDaysRepo) GetByEventIDAndDate(_ context.Context, eventID string, date time.Time) (*EventDay, error) {
+	for _, d := range m.days {
+		if d.EventID == eventID && sameDate(d.Date, date) {
+			return d, nil
+		}
+	}
 	return nil, nil
 }
 
-func (m *mockDaysRepo) Upsert(_ context.Context, _ *EventDay) error {
+func (m *mockDaysRepo) Upsert(_ context.Context, day *EventDay) error {
+	for i, d := range m.days {
+		if d.EventID == day.EventID && sameDate(d.Date, day.Date) {
+			m.days[i] = day
+			return nil
+		}
+	}
+	m.days = append(m.days, day)
 	return nil
 }
 
@@ -75,10 +105,12 @@ func (m *mockDraftRepo) GetByEventID(_ context.Context, eventID string) (*EventD
 	return nil, nil
 }
 
-func (m *mockDraftRepo) ListDrafts(_ context.Context) ([]*EventDraft, error) {
+func (m *mockDraftRepo) ListDrafts(_ context.Context, orgID string) ([]*EventDraft, error) {
 	result := make([]*EventDraft, 0, len(m.drafts))
 	for _, d := range m.drafts {
-		result = append(result, d)
+		if d.OrganizationID == orgID {
+			result = append(result, d)
+		}
 	}
 	return result, nil
 }
@@ -115,8 +147,14 @@ func (m *mockDayDraftRepo) GetByEventIDAndDate(_ context.Context, _ string, _ ti
 	return nil, nil
 }
 
-func (m *mockDayDraftRepo) ListByEventID(_ context.Context, _ string) ([]*EventDayDraft, error) {
-	return nil, nil
+func (m *mockDayDraftRepo) ListByEventID(_ context.Context, eventID string) ([]*EventDayDraft, error) {
+	var list []*EventDayDraft
+	for _, d := range m.dayDrafts {
+		if d.EventID == eventID {
+			list = append(list, d)
+		}
+	}
+	return list, nil
 }
 
 func (m *mockDayDraftRepo) Upsert(_ context.Context, draft *EventDayDraft) error {
@@ -137,14 +175,238 @@ func (m *mockDayDraftRepo) DeleteByID(_ context.Context, id string) error {
 	return nil
 }
 
+// mockChangeRepo — in-memory журнал изменений (ChangeRepository).
+type mockChangeRepo struct {
+	changes []*Change
+}
+
+func (m *mockChangeRepo) Append(_ context.Context, changes []*Change) error {
+	for _, c := range changes {
+		c.ID = int64(len(m.changes) + 1)
+		c.CreatedAt = time.Now()
+		m.changes = append(m.changes, c)
+	}
+	return nil
+}
+
+func (m *mockChangeRepo) ListSince(_ context.Context, eventID string, afterID int64, limit int) ([]*Change, error) {
+	var list []*Change
+	for _, c := range m.changes {
+		if c.EventID == eventID && c.ID > afterID && len(list) < limit {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockChangeRepo) LatestID(_ context.Context, eventID string) (int64, error) {
+	var id int64
+	for _, c := range m.changes {
+		if c.EventID == eventID {
+			id = c.ID
+		}
+	}
+	return id, nil
+}
+
+// mockOutbox — in-memory outbox.Writer.
+type mockOutbox struct {
+	msgs []*outbox.Message
+}
+
+func (m *mockOutbox) Append(_ context.Context, msgs ...*outbox.Message) error {
+	m.msgs = append(m.msgs, msgs...)
+	return nil
+}
+
+// mockAudit копит записи журнала аудита.
+type mockAudit struct {
+	entries []*audit.Entry
+}
+
+func (m *mockAudit) Append(_ context.Context, entries ...*audit.Entry) error {
+	m.entries = append(m.entries, entries...)
+	return nil
+}
+
+// auditOf — записи аудита сервиса из newTestService.
+func auditOf(svc *Service) []*audit.Entry {
+	return svc.tx.(*mockTransactor).repos.Audit.(*mockAudit).entries
+}
+
+// mockTransactor выполняет fn поверх тех же моков без отката. Транзакции идут по одной —
+// как при блокировке строк (LockOwners) в PostgreSQL.
+type mockTransactor struct {
+	mu    sync.Mutex
+	repos *TxRepositories
+}
+
+func (m *mockTransactor) InTx(_ context.Context, fn func(repos *TxRepositories) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(m.repos)
+}
+
+// mockMemberRepo — in-memory реализация MemberRepository (ключ — eventID + userID).
+type mockMemberRepo struct {
+	members map[[2]string]*Member
+}
+
+func (m *mockMemberRepo) Get(_ context.Context, eventID, userID string) (*Member, error) {
+	if member, ok := m.members[[2]string{eventID, userID}]; ok {
+		cp := *member
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *mockMemberRepo) ListByEventID(_ context.Context, eventID string) ([]*Member, error) {
+	var list []*Member
+	for _, member := range m.members {
+		if member.EventID == eventID {
+			list = append(list, member)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockMemberRepo) ListEventIDsByUserID(_ context.Context, userID string) ([]string, error) {
+	var ids []string
+	for _, member := range m.members {
+		if member.UserID == userID {
+			ids = append(ids, member.EventID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockMemberRepo) Upsert(_ context.Context, member *Member) error {
+	if m.members == nil {
+		m.members = make(map[[2]string]*Member)
+	}
+	cp := *member
+	m.members[[2]string{member.EventID, member.UserID}] = &cp
+	return nil
+}
+
+func (m *mockMemberRepo) Delete(_ context.Context, eventID, userID string) error {
+	delete(m.members, [2]string{eventID, userID})
+	return nil
+}
+
+func (m *mockMemberRepo) LockOwners(_ context.Context, eventID string) ([]string, error) {
+	var owners []string
+	for _, member := range m.members {
+		if member.EventID == eventID && member.Role == MemberOwner {
+			owners = append(owners, member.UserID)
+		}
+	}
+	return owners, nil
+}
+
+// mockUserDirectory — пользователи для приглашений по email.
+type mockUserDirectory struct {
+	users []*auth.User
+}
+
+func (m *mockUserDirectory) GetUserByEmail(_ context.Context, email string) (*auth.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+// mockOrgDirectory — организации и их участники (ключ участника — orgID + userID).
+type mockOrgDirectory struct {
+	orgs    []*organizations.Organization
+	members map[[2]string]string
+}
+
+func (m *mockOrgDirectory) GetBySlug(_ context.Context, slug string) (*organizations.Organization, error) {
+	for _, org := range m.orgs {
+		if org.Slug == slug {
+			return org, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockOrgDirectory) GetMember(_ context.Context, orgID, userID string) (*organizations.Member, error) {
+	role, ok := m.members[[2]string{orgID, userID}]
+	if !ok {
+		return nil, nil
+	}
+	return &organizations.Member{OrganizationID: orgID, UserID: userID, Role: role}, nil
+}
+
+func (m *mockOrgDirectory) ListByUserID(_ context.Context, userID string) ([]*organizations.Membership, error) {
+	var list []*organizations.Membership
+	for key, role := range m.members {
+		if key[1] == userID {
+			list = append(list, &organizations.Membership{Organization: &organizations.Organization{ID: key[0]}, Role: role})
+		}
+	}
+	return list, nil
+}
+
+// testOrgID — организация по умолчанию в тестах; testOtherOrgID — вторая организация (slug "other").
+const (
+	testOrgID      = "org-default"
+	testOtherOrgID = "org-other"
+)
+
+// newTestOrgDirectory: в организации по умолчанию состоят owner-1, editor-1 и organizer-2 (member)
+// и org-admin-1 (admin); во второй — только outsider-1.
+func newTestOrgDirectory() *mockOrgDirectory {
+	return &mockOrgDirectory{
+		orgs: []*organizations.Organization{
+			{ID: testOrgID, Slug: organizations.DefaultSlug},
+			{ID: testOtherOrgID, Slug: "other"},
+		},
+		members: map[[2]string]string{
+			{testOrgID, "owner-1"}:         organizations.RoleMember,
+			{testOrgID, "editor-1"}:        organizations.RoleMember,
+			{testOrgID, "organizer-2"}:     organizations.RoleMember,
+			{testOrgID, "org-admin-1"}:     organizations.RoleAdmin,
+			{testOtherOrgID, "outsider-1"}: organizations.RoleMember,
+		},
+	}
+}
+
+// testAdmin — глобальный admin: проходит проверки команды события.
+var testAdmin = Actor{UserID: "admin-1", Role: auth.RoleAdmin}
+
+// newTestService собирает сервис, у которого транзакция работает поверх тех же моков.
+func newTestService(eventsRepo EventRepository, daysRepo EventDayRepository, draftsRepo EventDraftRepository, dayDraftsRepo EventDayDraftRepository, changesRepo ChangeRepository) *Service {
+	members := &mockMemberRepo{}
+	tx := &mockTransactor{repos: &TxRepositories{
+		Events:    eventsRepo,
+		Days:      daysRepo,
+		Drafts:    draftsRepo,
+		DayDrafts: dayDraftsRepo,
+		Changes:   changesRepo,
+		Members:   members,
+		Outbox:    &mockOutbox{},
+		Audit:     &mockAudit{},
+	}}
+	users := &mockUserDirectory{users: []*auth.User{
+		{ID: "owner-1", Email: "owner@example.com"},
+		{ID: "editor-1", Email: "editor@example.com"},
+		{ID: "viewer-1", Email: "viewer@example.com"},
+	}}
+	return NewService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, changesRepo, members, users, newTestOrgDirectory(), tx)
+}
+
 func TestSaveDraft_InvalidID(t *testing.T) {
 	eventsRepo := &mockEventRepo{}
 	daysRepo := &mockDaysRepo{}
 	draftsRepo := &mockDraftRepo{}
 	dayDraftsRepo := &mockDayDraftRepo{}
-	svc := NewService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo)
+	svc := newTestService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
 
-	err := svc.SaveDraft(context.Background(), &EventDraft{})
+	err := svc.SaveDraft(context.Background(), testAdmin, &EventDraft{})
 	require.ErrorIs(t, err, ErrInvalidDraftID)
 }
 
@@ -153,9 +415,9 @@ func TestSaveDraft_InvalidCreatedBy(t *testing.T) {
 	daysRepo := &mockDaysRepo{}
 	draftsRepo := &mockDraftRepo{}
 	dayDraftsRepo := &mockDayDraftRepo{}
-	svc := NewService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo)
+	svc := newTestService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
 
-	err := svc.SaveDraft(context.Background(), &EventDraft{
+	err := svc.SaveDraft(context.Background(), testAdmin, &EventDraft{
 		ID: "draft-1", Title: "T", StartDate: time.Now(), EndDate: time.Now().Add(24 * time.Hour),
 	})
 	require.ErrorIs(t, err, ErrInvalidCreatedBy)
@@ -166,7 +428,7 @@ func TestSaveDraft_CreatesOrUpdates(t *testing.T) {
 	daysRepo := &mockDaysRepo{}
 	draftsRepo := &mockDraftRepo{}
 	dayDraftsRepo := &mockDayDraftRepo{}
-	svc := NewService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo)
+	svc := newTestService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
 
 	ctx := context.Background()
 	draft := &EventDraft{
@@ -177,13 +439,36 @@ func TestSaveDraft_CreatesOrUpdates(t *testing.T) {
 		CreatedBy: "user-1",
 	}
 
-	err := svc.SaveDraft(ctx, draft)
+	err := svc.SaveDraft(ctx, testAdmin, draft)
 	require.NoError(t, err)
 
 	stored, err := draftsRepo.GetByID(ctx, "draft-1")
 	require.NoError(t, err)
 	require.NotNil(t, stored)
 	assert.Equal(t, "Title", stored.Title)
+
+	entries := auditOf(svc)
+	require.Len(t, entries, 1)
+	assert.Equal(t, ActionDraftSaved, entries[0].Action)
+	assert.Equal(t, AuditTargetEventDraft, entries[0].TargetType)
+	assert.Equal(t, "draft-1", entries[0].TargetID)
+	assert.Equal(t, testAdmin.UserID, entries[0].ActorID)
+	assert.Nil(t, entries[0].Before, "новый черновик — без прежнего состояния")
+	assert.Contains(t, string(entries[0].After), `"title":"Title"`)
+}
+
+func TestSaveDraft_AuditRecordsAPIKey(t *testing.T) {
+	svc := newTestService(&mockEventRepo{}, &mockDaysRepo{}, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+	actor := testAdmin
+	actor.APIKeyID = "key-1"
+
+	draft := &EventDraft{ID: "draft-1", Title: "Title", StartDate: time.Now(), EndDate: time.Now(), CreatedBy: actor.UserID}
+	require.NoError(t, svc.SaveDraft(context.Background(), actor, draft))
+
+	entries := auditOf(svc)
+	require.Len(t, entries, 1)
+	assert.Equal(t, actor.UserID, entries[0].ActorID, "актор — владелец ключа")
+	assert.Equal(t, "key-1", entries[0].APIKeyID)
 }
 
 func TestPublishDraft_CreatesNewEventAndDeletesDraft(t *testing.T) {
@@ -200,11 +485,11 @@ func TestPublishDraft_CreatesNewEventAndDeletesDraft(t *testing.T) {
 	}
 	daysRepo := &mockDaysRepo{}
 	dayDraftsRepo := &mockDayDraftRepo{}
-	svc := NewService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo)
+	svc := newTestService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
 
 	ctx := context.Background()
 
-	event, err := svc.PublishDraft(ctx, "draft-1")
+	event, err := svc.PublishDraft(ctx, testAdmin, "draft-1")
 	require.NoError(t, err)
 	require.NotNil(t, event)
 
@@ -240,16 +525,54 @@ func TestPublishDraft_UpdatesExistingEvent(t *testing.T) {
 	}
 	daysRepo := &mockDaysRepo{}
 	dayDraftsRepo := &mockDayDraftRepo{}
-	svc := NewService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo)
+	svc := newTestService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
 
 	ctx := context.Background()
 
-	event, err := svc.PublishDraft(ctx, "draft-1")
+	event, err := svc.PublishDraft(ctx, testAdmin, "draft-1")
 	require.NoError(t, err)
 	require.NotNil(t, event)
 
 	assert.Equal(t, "event-1", event.ID)
 	assert.Equal(t, "New Title", event.Title)
+
+	entries := auditOf(svc)
+	require.Len(t, entries, 1)
+	assert.Equal(t, ActionEventPublished, entries[0].Action)
+	assert.Equal(t, AuditTargetEvent, entries[0].TargetType)
+	assert.Equal(t, "event-1", entries[0].TargetID)
+	assert.Contains(t, string(entries[0].Before), `"title":"Old Title"`)
+	assert.Contains(t, string(entries[0].After), `"title":"New Title"`)
+	assert.Contains(t, string(entries[0].After), `"draftId":"draft-1"`)
+}
+
+func TestDeleteDraft(t *testing.T) {
+	eventID := "event-1"
+	draftsRepo := &mockDraftRepo{drafts: map[string]*EventDraft{
+		"draft-1": {ID: "draft-1", EventID: &eventID, Title: "Draft", StartDate: time.Now(), EndDate: time.Now()},
+	}}
+	dayDraftsRepo := &mockDayDraftRepo{dayDrafts: map[string]*EventDayDraft{
+		"day-1": {ID: "day-1", EventID: eventID, Date: time.Now()},
+	}}
+	svc := newTestService(&mockEventRepo{}, &mockDaysRepo{}, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
+	ctx := context.Background()
+
+	err := svc.DeleteDraft(ctx, Actor{UserID: "viewer-1", Role: auth.RoleUser}, "draft-1")
+	require.ErrorIs(t, err, ErrForbidden)
+	assert.Empty(t, auditOf(svc))
+
+	require.NoError(t, svc.DeleteDraft(ctx, testAdmin, "draft-1"))
+	draft, _ := draftsRepo.GetByID(ctx, "draft-1")
+	assert.Nil(t, draft)
+	assert.Empty(t, dayDraftsRepo.dayDrafts)
+
+	entries := auditOf(svc)
+	require.Len(t, entries, 1)
+	assert.Equal(t, ActionDraftDeleted, entries[0].Action)
+	assert.Contains(t, string(entries[0].Before), `"title":"Draft"`)
+	assert.Nil(t, entries[0].After)
+
+	require.ErrorIs(t, svc.DeleteDraft(ctx, testAdmin, "draft-1"), ErrDraftNotFound)
 }
 
 func TestPublishDraft_NotFound(t *testing.T) {
@@ -257,24 +580,447 @@ func TestPublishDraft_NotFound(t *testing.T) {
 	daysRepo := &mockDaysRepo{}
 	draftsRepo := &mockDraftRepo{}
 	dayDraftsRepo := &mockDayDraftRepo{}
-	svc := NewService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo)
+	svc := newTestService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
 
-	_, err := svc.PublishDraft(context.Background(), "missing")
+	_, err := svc.PublishDraft(context.Background(), testAdmin, "missing")
 	require.ErrorIs(t, err, ErrDraftNotFound)
 }
 
 func TestListDrafts(t *testing.T) {
 	draftsRepo := &mockDraftRepo{
 		drafts: map[string]*EventDraft{
-			"d1": {ID: "d1", Title: "Draft 1"},
-			"d2": {ID: "d2", Title: "Draft 2"},
+			"d1": {ID: "d1", OrganizationID: testOrgID, Title: "Draft 1"},
+			"d2": {ID: "d2", OrganizationID: testOrgID, Title: "Draft 2"},
+			"d3": {ID: "d3", OrganizationID: testOtherOrgID, Title: "Draft 3"},
 		},
 	}
 	daysRepo := &mockDaysRepo{}
 	dayDraftsRepo := &mockDayDraftRepo{}
-	svc := NewService(&mockEventRepo{}, daysRepo, draftsRepo, dayDraftsRepo)
+	svc := newTestService(&mockEventRepo{}, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
 
-	list, err := svc.ListDrafts(context.Background())
+	list, err := svc.ListDrafts(context.Background(), testAdmin, testOrgID)
 	require.NoError(t, err)
 	assert.Len(t, list, 2)
 }
+
+// newScheduleFixture — событие с двумя днями: публичный доклад, закрытый брифинг жюри и питч.
+func newScheduleFixture(t *testing.T) (*mockEventRepo, *mockDaysRepo) {
+	t.Helper()
+	eventsRepo := &mockEventRepo{events: map[string]*Event{"event-1": {ID: "event-1", OrganizationID: testOrgID, Title: "Hackathon"}}}
+	daysRepo := &mockDaysRepo{days: []*EventDay{
+		{
+			ID:      "day-1",
+			EventID: "event-1",
+			Date:    time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+			Schedule: []byte(`{"sessions":[
+				{"id":"s-2","session_type_id":"pitch","title":"Pitch","starts_at":"2026-05-01T14:00:00Z","ends_at":"2026-05-01T15:00:00Z","is_public":true,"status":"published","participants":[{"person_id":"p-1","role":"jury"}],"teams":[]},
+				{"id":"s-1","session_type_id":"intro","title":"Intro","starts_at":"2026-05-01T10:00:00Z","ends_at":"2026-05-01T11:00:00Z","is_public":true,"status":"published","participants":[{"person_id":"p-2","role":"speaker"}],"teams":[]},
+				{"id":"s-3","session_type_id":"briefing","title":"Jury briefing","starts_at":"2026-05-01T09:00:00Z","ends_at":"2026-05-01T09:30:00Z","is_public":false,"status":"published","participants":[{"person_id":"p-1","role":"jury"}],"teams":[]}
+			]}`),
+		},
+		{
+			ID:      "day-2",
+			EventID: "event-1",
+			Date:    time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC),
+			Schedule: []byte(`{"sessions":[
+				{"id":"s-4","session_type_id":"pitch","title":"Final pitch","starts_at":"2026-05-02T12:00:00Z","ends_at":"2026-05-02T13:00:00Z","is_public":true,"status":"published","participants":[{"person_id":"p-1","role":"jury"}],"teams":[]}
+			]}`),
+		},
+	}}
+	return eventsRepo, daysRepo
+}
+
+func sessionIDs(list []*DaySession) []string {
+	ids := make([]string, 0, len(list))
+	for _, ds := range list {
+		ids = append(ids, ds.Session.ID)
+	}
+	return ids
+}
+
+func TestListSessions_SortedAndHidesNonPublic(t *testing.T) {
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	svc := newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+
+	list, err := svc.ListSessions(context.Background(), testOrgID, "event-1", SessionFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s-1", "s-2", "s-4"}, sessionIDs(list))
+
+	list, err = svc.ListSessions(context.Background(), testOrgID, "event-1", SessionFilter{IncludeHidden: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s-3", "s-1", "s-2", "s-4"}, sessionIDs(list))
+}
+
+func TestListSessions_Filters(t *testing.T) {
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	svc := newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+	ctx := context.Background()
+
+	list, err := svc.ListSessions(ctx, testOrgID, "event-1", SessionFilter{Type: "pitch"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s-2", "s-4"}, sessionIDs(list))
+
+	date := time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)
+	list, err = svc.ListSessions(ctx, testOrgID, "event-1", SessionFilter{Date: &date})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s-4"}, sessionIDs(list))
+
+	list, err = svc.ListSessions(ctx, testOrgID, "event-1", SessionFilter{PersonID: "p-1", IncludeHidden: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s-3", "s-2", "s-4"}, sessionIDs(list))
+}
+
+func TestListSessions_EventNotFound(t *testing.T) {
+	svc := newTestService(&mockEventRepo{}, &mockDaysRepo{}, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+
+	_, err := svc.ListSessions(context.Background(), testOrgID, "missing", SessionFilter{})
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestGetSession_FindsInsideDayAndRespectsVisibility(t *testing.T) {
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	svc := newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+	ctx := context.Background()
+
+	ds, err := svc.GetSession(ctx, testOrgID, "event-1", "s-4", false)
+	require.NoError(t, err)
+	require.NotNil(t, ds)
+	assert.Equal(t, "day-2", ds.Day.ID)
+	assert.Equal(t, "Final pitch", ds.Session.Title)
+
+	ds, err = svc.GetSession(ctx, testOrgID, "event-1", "s-3", false)
+	require.NoError(t, err)
+	assert.Nil(t, ds)
+
+	ds, err = svc.GetSession(ctx, testOrgID, "event-1", "s-3", true)
+	require.NoError(t, err)
+	require.NotNil(t, ds)
+}
+
+func TestGetEventDay_FiltersSchedule(t *testing.T) {
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	svc := newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+	date := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	day, err := svc.GetEventDay(context.Background(), testOrgID, "event-1", date, false)
+	require.NoError(t, err)
+	require.NotNil(t, day)
+	schedule, err := day.ParseSchedule()
+	require.NoError(t, err)
+	assert.Len(t, schedule.Sessions, 2)
+
+	// Исходный день в репозитории не должен измениться.
+	original, err := daysRepo.days[0].ParseSchedule()
+	require.NoError(t, err)
+	assert.Len(t, original.Sessions, 3)
+}
+
+func TestGetEventWithDays_PublicViewHidesPlannedAndCancelled(t *testing.T) {
+	eventsRepo := &mockEventRepo{events: map[string]*Event{"event-1": {ID: "event-1", OrganizationID: testOrgID}}}
+	count := 3
+	daysRepo := &mockDaysRepo{days: []*EventDay{{
+		ID:           "day-1",
+		EventID:      "event-1",
+		Date:         time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		SessionCount: &count,
+		Schedule: []byte(`{"sessions":[
+			{"id":"s-1","title":"Keynote","starts_at":"2026-05-01T10:00:00Z","ends_at":"2026-05-01T11:00:00Z","is_public":true,"status":"published"},
+			{"id":"s-2","title":"Draft slot","starts_at":"2026-05-01T08:00:00Z","ends_at":"2026-05-01T09:00:00Z","is_public":true,"status":"planned"},
+			{"id":"s-3","title":"Dropped talk","starts_at":"2026-05-01T17:00:00Z","ends_at":"2026-05-01T18:00:00Z","is_public":true,"status":"cancelled"}
+		]}`),
+	}}}
+	svc := newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+	ctx := context.Background()
+
+	_, days, err := svc.GetEventWithDays(ctx, testOrgID, "event-1", false)
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	schedule, err := days[0].ParseSchedule()
+	require.NoError(t, err)
+	require.Len(t, schedule.Sessions, 1)
+	assert.Equal(t, "s-1", schedule.Sessions[0].ID)
+	// Метаданные соответствуют отфильтрованному расписанию.
+	require.NotNil(t, days[0].SessionCount)
+	assert.Equal(t, 1, *days[0].SessionCount)
+	assert.Equal(t, time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC), days[0].FirstSessionStart.UTC())
+	assert.Equal(t, time.Date(2026, 5, 1, 11, 0, 0, 0, time.UTC), days[0].LastSessionEnd.UTC())
+
+	_, days, err = svc.GetEventWithDays(ctx, testOrgID, "event-1", true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, *days[0].SessionCount)
+}
+
+func TestPublishDraft_ComputesDayMetadata(t *testing.T) {
+	draftsRepo := &mockDraftRepo{drafts: map[string]*EventDraft{
+		"event-1": {ID: "event-1", Title: "Event", StartDate: time.Now(), EndDate: time.Now()},
+	}}
+	dayDraftsRepo := &mockDayDraftRepo{dayDrafts: map[string]*EventDayDraft{
+		"dd-1": {
+			ID:      "dd-1",
+			EventID: "event-1",
+			Date:    time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+			Schedule: []byte(`{"sessions":[
+				{"id":"s-2","title":"Late","starts_at":"2026-05-01T15:00:00Z","ends_at":"2026-05-01T16:00:00Z","is_public":true,"status":"published"},
+				{"id":"s-1","title":"Early","starts_at":"2026-05-01T09:00:00Z","ends_at":"2026-05-01T10:00:00Z","is_public":true,"status":"published"}
+			]}`),
+		},
+	}}
+	daysRepo := &mockDaysRepo{}
+	svc := newTestService(&mockEventRepo{}, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
+
+	_, err := svc.PublishDraft(context.Background(), testAdmin, "event-1")
+	require.NoError(t, err)
+	require.Len(t, daysRepo.days, 1)
+	day := daysRepo.days[0]
+	assert.Equal(t, 2, *day.SessionCount)
+	assert.Equal(t, time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC), day.FirstSessionStart.UTC())
+	assert.Equal(t, time.Date(2026, 5, 1, 16, 0, 0, 0, time.UTC), day.LastSessionEnd.UTC())
+	assert.Empty(t, dayDraftsRepo.dayDrafts)
+}
+
+func TestPublishDraft_InvalidScheduleWritesNothing(t *testing.T) {
+	draftsRepo := &mockDraftRepo{drafts: map[string]*EventDraft{
+		"event-1": {ID: "event-1", Title: "Event", StartDate: time.Now(), EndDate: time.Now()},
+	}}
+	dayDraftsRepo := &mockDayDraftRepo{dayDrafts: map[string]*EventDayDraft{
+		"dd-1": {ID: "dd-1", EventID: "event-1", Schedule: []byte(`{"sessions":[{"id":"s-1","starts_at":"soon"}]}`)},
+	}}
+	eventsRepo := &mockEventRepo{}
+	svc := newTestService(eventsRepo, &mockDaysRepo{}, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
+
+	_, err := svc.PublishDraft(context.Background(), testAdmin, "event-1")
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+	assert.Empty(t, eventsRepo.events)
+	assert.Len(t, draftsRepo.drafts, 1)
+}
+
+func TestGetLive_GroupsNowAndNextByLocation(t *testing.T) {
+	eventsRepo := &mockEventRepo{events: map[string]*Event{
+		"event-1": {ID: "event-1", OrganizationID: testOrgID, Timezone: "Europe/Moscow"},
+	}}
+	daysRepo := &mockDaysRepo{days: []*EventDay{
+		{
+			ID:      "day-1",
+			EventID: "event-1",
+			Date:    time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+			Schedule: []byte(`{"sessions":[
+				{"id":"a-1","title":"Hall A talk","starts_at":"2026-05-01T07:00:00Z","ends_at":"2026-05-01T08:00:00Z","is_public":true,"status":"published","location":{"type":"physical","address":"Hall A"}},
+				{"id":"a-2","title":"Hall A next","starts_at":"2026-05-01T08:30:00Z","ends_at":"2026-05-01T09:00:00Z","is_public":true,"status":"published","location":{"type":"physical","address":"Hall A"}},
+				{"id":"a-3","title":"Hall A later","starts_at":"2026-05-01T10:00:00Z","ends_at":"2026-05-01T11:00:00Z","is_public":true,"status":"published","location":{"type":"physical","address":"Hall A"}},
+				{"id":"b-1","title":"Hall B done","starts_at":"2026-05-01T06:00:00Z","ends_at":"2026-05-01T07:00:00Z","is_public":true,"status":"published","location":{"type":"physical","address":"Hall B"}},
+				{"id":"b-2","title":"Hall B hidden","starts_at":"2026-05-01T07:00:00Z","ends_at":"2026-05-01T09:00:00Z","is_public":false,"status":"published","location":{"type":"physical","address":"Hall B"}}
+			]}`),
+		},
+		{
+			ID:      "day-2",
+			EventID: "event-1",
+			Date:    time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC),
+			Schedule: []byte(`{"sessions":[
+				{"id":"c-1","title":"Tomorrow","starts_at":"2026-05-02T07:00:00Z","ends_at":"2026-05-02T08:00:00Z","is_public":true,"status":"published","location":{"type":"online","url":"https://stream"}}
+			]}`),
+		},
+	}}
+	svc := newTestService(eventsRepo, daysRepo, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+
+	// 10:30 по Москве = 07:30 UTC, 1 мая.
+	at := time.Date(2026, 5, 1, 7, 30, 0, 0, time.UTC)
+	snapshot, err := svc.GetLive(context.Background(), testOrgID, "event-1", at)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Moscow", snapshot.Timezone)
+	require.Len(t, snapshot.Rooms, 1)
+
+	room := snapshot.Rooms[0]
+	assert.Equal(t, "Hall A", *room.Location.Address)
+	require.Len(t, room.Now, 1)
+	assert.Equal(t, "a-1", room.Now[0].ID)
+	require.NotNil(t, room.Next)
+	assert.Equal(t, "a-2", room.Next.ID)
+}
+
+func TestGetLive_EventNotFound(t *testing.T) {
+	svc := newTestService(&mockEventRepo{}, &mockDaysRepo{}, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+
+	_, err := svc.GetLive(context.Background(), testOrgID, "missing", time.Now())
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestSaveDraft_InvalidTimezone(t *testing.T) {
+	svc := newTestService(&mockEventRepo{}, &mockDaysRepo{}, &mockDraftRepo{}, &mockDayDraftRepo{}, &mockChangeRepo{})
+
+	err := svc.SaveDraft(context.Background(), testAdmin, &EventDraft{ID: "draft-1", CreatedBy: "user-1", Timezone: "Mars/Olympus"})
+	require.ErrorIs(t, err, ErrInvalidTimezone)
+}
+
+func TestPublishDraft_RecordsScheduleChanges(t *testing.T) {
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	eventID := "event-1"
+	draftsRepo := &mockDraftRepo{drafts: map[string]*EventDraft{
+		"draft-1": {ID: "draft-1", EventID: &eventID, Title: "Hackathon", Timezone: "UTC"},
+	}}
+	// День 1: s-1 отменена, закрытый брифинг s-3 тоже отменён (не публичный — без session_cancelled).
+	// День 2 без изменений, день 3 — новый.
+	dayDraftsRepo := &mockDayDraftRepo{dayDrafts: map[string]*EventDayDraft{
+		"dd-1": {ID: "dd-1", EventID: "event-1", Date: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), Schedule: []byte(`{"sessions":[
+			{"id":"s-2","session_type_id":"pitch","title":"Pitch","starts_at":"2026-05-01T14:00:00Z","ends_at":"2026-05-01T15:00:00Z","is_public":true,"status":"published","participants":[{"person_id":"p-1","role":"jury"}],"teams":[]},
+			{"id":"s-1","session_type_id":"intro","title":"Intro","starts_at":"2026-05-01T10:00:00Z","ends_at":"2026-05-01T11:00:00Z","is_public":true,"status":"cancelled","participants":[{"person_id":"p-2","role":"speaker"}],"teams":[]},
+			{"id":"s-3","session_type_id":"briefing","title":"Jury briefing","starts_at":"2026-05-01T09:00:00Z","ends_at":"2026-05-01T09:30:00Z","is_public":false,"status":"cancelled","participants":[{"person_id":"p-1","role":"jury"}],"teams":[]}
+		]}`)},
+		"dd-2": {ID: "dd-2", EventID: "event-1", Date: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC), Schedule: []byte(`{"sessions":[
+			{"id":"s-4","session_type_id":"pitch","title":"Final pitch","starts_at":"2026-05-02T12:00:00Z","ends_at":"2026-05-02T13:00:00Z","is_public":true,"status":"published","participants":[{"person_id":"p-1","role":"jury"}],"teams":[]}
+		]}`)},
+		"dd-3": {ID: "dd-3", EventID: "event-1", Date: time.Date(2026, 5, 3, 0, 0, 0, 0, time.UTC), Schedule: []byte(`{"sessions":[]}`)},
+	}}
+	changesRepo := &mockChangeRepo{}
+	svc := newTestService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, changesRepo)
+
+	_, err := svc.PublishDraft(context.Background(), testAdmin, "draft-1")
+	require.NoError(t, err)
+
+	type change struct{ kind, date, sessionID string }
+	var got []change
+	for _, c := range changesRepo.changes {
+		date := ""
+		if c.Date != nil {
+			date = c.Date.Format(dateLayout)
+		}
+		got = append(got, change{c.Kind, date, c.SessionID})
+	}
+	assert.ElementsMatch(t, []change{
+		{ChangeEventPublished, "", ""},
+		{ChangeDayChanged, "2026-05-01", ""},
+		{ChangeSessionCancelled, "2026-05-01", "s-1"},
+		{ChangeDayChanged, "2026-05-03", ""},
+	}, got)
+	assert.Equal(t, ChangeEventPublished, changesRepo.changes[0].Kind)
+}
+
+func TestChangeHub_SignalsSubscribersOfEvent(t *testing.T) {
+	hub := NewChangeHub()
+	first, unsubscribeFirst := hub.Subscribe("event-1")
+	other, unsubscribeOther := hub.Subscribe("event-2")
+	defer unsubscribeOther()
+
+	// Сигналы склеиваются: два NOTIFY подряд — один сигнал, канал не блокирует отправителя.
+	hub.HandleNotification("event-1")
+	hub.HandleNotification("event-1")
+	assert.Len(t, first, 1)
+	assert.Len(t, other, 0)
+
+	hub.HandleReconnect()
+	assert.Len(t, other, 1)
+
+	<-first
+	unsubscribeFirst()
+	unsubscribeFirst()
+	hub.HandleNotification("event-1")
+	assert.Len(t, first, 0)
+}
+
+func TestOutbox_PublishAndSaveDraftEmitDomainEvents(t *testing.T) {
+	eventsRepo, daysRepo := newScheduleFixture(t)
+	eventID := "event-1"
+	draftsRepo := &mockDraftRepo{}
+	dayDraftsRepo := &mockDayDraftRepo{}
+	svc := newTestService(eventsRepo, daysRepo, draftsRepo, dayDraftsRepo, &mockChangeRepo{})
+	box := svc.tx.(*mockTransactor).repos.Outbox.(*mockOutbox)
+	ctx := context.Background()
+
+	require.NoError(t, svc.SaveDraft(ctx, testAdmin, &EventDraft{ID: "draft-1", EventID: &eventID, Title: "Hackathon", CreatedBy: "editor-1"}))
+	require.NoError(t, svc.SaveDayDraft(ctx, testAdmin, &EventDayDraft{
+		EventID: "event-1", Date: time.Date(2026, 5, 3, 0, 0, 0, 0, time.UTC), Schedule: []byte(`{"sessions":[]}`), CreatedBy: "editor-1",
+	}))
+	_, err := svc.PublishDraft(ctx, testAdmin, "draft-1")
+	require.NoError(t, err)
+
+	var types []string
+	for _, msg := range box.msgs {
+		assert.Equal(t, AggregateEvent, msg.AggregateType)
+		assert.Equal(t, "event-1", msg.AggregateID)
+		types = append(types, msg.Type)
+	}
+	assert.Equal(t, []string{DomainDraftSaved, DomainDraftSaved, DomainEventPublished, DomainEventDayChanged}, types)
+
+	var saved DraftSavedPayload
+	require.NoError(t, json.Unmarshal(box.msgs[1].Payload, &saved))
+	assert.Equal(t, DraftSavedPayload{DraftID: "day-draft-1", EventID: "event-1", Kind: DraftKindDay, Date: "2026-05-03", SavedBy: "editor-1", SavedAt: saved.SavedAt}, saved)
+
+	var day EventDayChangedPayload
+	require.NoError(t, json.Unmarshal(box.msgs[3].Payload, &day))
+	assert.Equal(t, EventDayChangedPayload{EventID: "event-1", Date: "2026-05-03"}, day)
+}
+
+func TestUnpublishEvent_ArchivesAndEmitsDomainEvent(t *testing.T) {
+	eventsRepo := &mockEventRepo{events: map[string]*Event{
+		"event-1": {ID: "event-1", OrganizationID: testOrgID, Title: "Hackathon", Status: EventStatusPublished},
+	}}
+	eventID := "event-1"
+	draftsRepo := &mockDraftRepo{}
+	changesRepo := &mockChangeRepo{}
+	svc := newTestService(eventsRepo, &mockDaysRepo{}, draftsRepo, &mockDayDraftRepo{}, changesRepo)
+	box := svc.tx.(*mockTransactor).repos.Outbox.(*mockOutbox)
+	ctx := context.Background()
+
+	_, err := svc.UnpublishEvent(ctx, Actor{UserID: "outsider-1", Role: auth.RoleEditor}, "event-1")
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.UnpublishEvent(ctx, testAdmin, "missing")
+	require.ErrorIs(t, err, ErrEventNotFound)
+	assert.Empty(t, box.msgs)
+
+	event, err := svc.UnpublishEvent(ctx, testAdmin, "event-1")
+	require.NoError(t, err)
+	assert.Equal(t, EventStatusArchived, event.Status)
+
+	require.Len(t, box.msgs, 1)
+	assert.Equal(t, DomainEventUnpublished, box.msgs[0].Type)
+	assert.Equal(t, "event-1", box.msgs[0].AggregateID)
+	var payload EventUnpublishedPayload
+	require.NoError(t, json.Unmarshal(box.msgs[0].Payload, &payload))
+	assert.Equal(t, "event-1", payload.EventID)
+	assert.Equal(t, testOrgID, payload.OrganizationID)
+	assert.True(t, event.UpdatedAt.Equal(payload.UnpublishedAt))
+
+	require.Len(t, changesRepo.changes, 1)
+	assert.Equal(t, ChangeEventUnpublished, changesRepo.changes[0].Kind)
+	entries := auditOf(svc)
+	require.Len(t, entries, 1)
+	assert.Equal(t, ActionEventUnpublished, entries[0].Action)
+	assert.Contains(t, string(entries[0].Before), `"status":"published"`)
+	assert.Contains(t, string(entries[0].After), `"status":"archived"`)
+
+	// Публичный API снятое событие не показывает.
+	list, err := svc.ListPublishedEvents(ctx, testOrgID, 20, 0)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	got, _, err := svc.GetEventWithDays(ctx, testOrgID, "event-1", false)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	// Повторное снятие ничего не пишет; публикация черновика возвращает событие.
+	_, err = svc.UnpublishEvent(ctx, testAdmin, "event-1")
+	require.NoError(t, err)
+	assert.Len(t, box.msgs, 1)
+	assert.Len(t, auditOf(svc), 1)
+
+	require.NoError(t, svc.SaveDraft(ctx, testAdmin, &EventDraft{ID: "draft-1", EventID: &eventID, Title: "Hackathon", CreatedBy: "editor-1"}))
+	event, err = svc.PublishDraft(ctx, testAdmin, "draft-1")
+	require.NoError(t, err)
+	assert.Equal(t, EventStatusPublished, event.Status)
+	got, _, err = svc.GetEventWithDays(ctx, testOrgID, "event-1", false)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+}
+
+func TestPublishMessages_GroupsCancellationsByDay(t *testing.T) {
+	date := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	event := &Event{ID: "event-1", OrganizationID: testOrgID, Title: "Hackathon"}
+	msgs, err := publishMessages(event, "draft-1", []*Change{
+		{EventID: "event-1", Kind: ChangeDayChanged, Date: &date},
+		{EventID: "event-1", Kind: ChangeSessionCancelled, Date: &date, SessionID: "s-1"},
+		{EventID: "event-1", Kind: ChangeSessionCancelled, Date: &date, SessionID: "s-2"},
+	})
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	var day EventDayChangedPayload
+	require.NoError(t, json.Unmarshal(msgs[1].Payload, &day))
+	assert.Equal(t, []string{"s-1", "s-2"}, day.CancelledSessionIDs)
+}
@@ -0,0 +1,60 @@
+package feedback
+
+import "time"
+
+// Границы оценки сессии.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Feedback — оценка сессии участником. Сессия живёт в JSONB-расписании опубликованного дня,
+// поэтому название и время начала сохраняются на момент оценки.
+type Feedback struct {
+	ID              string
+	EventID         string
+	SessionID       string
+	UserID          string
+	Rating          int
+	Comment         *string
+	SessionTitle    string
+	SessionStartsAt time.Time
+	CreatedAt       time.Time
+}
+
+// SessionResult — сводка оценок по сессии.
+type SessionResult struct {
+	SessionID string
+	Title     string
+	StartsAt  time.Time
+	// Removed — сессии больше нет в опубликованном расписании (название — на момент оценки).
+	Removed bool
+	Count   int
+	Average float64
+	// Distribution[i] — число оценок i+1.
+	Distribution [MaxRating]int
+	Comments     int
+}
+
+// RoleSpeaker — роль участника сессии (participants[].role), по которой считается сводка по спикерам;
+// модераторы и другие участники в неё не попадают.
+const RoleSpeaker = "speaker"
+
+// SpeakerResult — сводка оценок по спикеру (person_id участника сессии с ролью speaker) за все его сессии.
+type SpeakerResult struct {
+	PersonID   string
+	PersonName *string
+	SessionIDs []string
+	Count      int
+	Average    float64
+}
+
+// add учитывает одну оценку в сводке сессии.
+func (r *SessionResult) add(f *Feedback) {
+	r.Average = (r.Average*float64(r.Count) + float64(f.Rating)) / float64(r.Count+1)
+	r.Count++
+	r.Distribution[f.Rating-MinRating]++
+	if f.Comment != nil {
+		r.Comments++
+	}
+}
@@ -0,0 +1,40 @@
+package feedback
+
+import "time"
+
+// SubmitFeedbackRequest — тело POST /api/events/:id/sessions/:sessionId/feedback.
+type SubmitFeedbackRequest struct {
+	Rating  int     `json:"rating" validate:"required,min=1,max=5"`
+	Comment *string `json:"comment" validate:"omitempty,max=2000"`
+}
+
+// FeedbackResponse — сохранённая оценка.
+type FeedbackResponse struct {
+	ID        string    `json:"id"`
+	EventID   string    `json:"eventId"`
+	SessionID string    `json:"sessionId"`
+	Rating    int       `json:"rating"`
+	Comment   *string   `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SessionResultResponse — сводка оценок по сессии (для редакторов).
+type SessionResultResponse struct {
+	SessionID    string    `json:"sessionId"`
+	Title        string    `json:"title"`
+	StartsAt     time.Time `json:"startsAt"`
+	Removed      bool      `json:"removed"`
+	Count        int       `json:"count"`
+	Average      float64   `json:"average"`
+	Distribution []int     `json:"distribution"`
+	Comments     int       `json:"comments"`
+}
+
+// SpeakerResultResponse — сводка оценок по спикеру (для редакторов).
+type SpeakerResultResponse struct {
+	PersonID   string   `json:"personId"`
+	PersonName *string  `json:"personName,omitempty"`
+	SessionIDs []string `json:"sessionIds"`
+	Count      int      `json:"count"`
+	Average    float64  `json:"average"`
+}
@@ -0,0 +1,193 @@
+package feedback
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/shared/csvexport"
+	"wdpl_back/internal/shared/http/handler"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/http/response"
+)
+
+// Handler реализует HTTP-эндпоинты оценок сессий.
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+// NewHandler создаёт handler оценок.
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Submit — POST /api/events/:id/sessions/:sessionId/feedback. Оценка 1–5 и комментарий после окончания сессии.
+func (h *Handler) Submit(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("id")
+	sessionID := c.Params("sessionId")
+	if eventID == "" || sessionID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	var req SubmitFeedbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	f, err := h.service.Submit(ctx, claims.UserID, eventID, sessionID, req.Rating, req.Comment)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(FeedbackResponse{
+		ID:        f.ID,
+		EventID:   f.EventID,
+		SessionID: f.SessionID,
+		Rating:    f.Rating,
+		Comment:   f.Comment,
+		CreatedAt: f.CreatedAt,
+	})
+}
+
+// SessionResults — GET /api/events/:id/feedback (команда события, viewer). Сводка по сессиям; ?format=csv — все оценки файлом.
+func (h *Handler) SessionResults(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 10*time.Second)
+	defer cancel()
+
+	if c.Query("format") == "csv" {
+		list, err := h.service.List(ctx, actor, eventID)
+		if err != nil {
+			return writeServiceError(c, err)
+		}
+		return writeFeedbackCSV(c, eventID, list)
+	}
+
+	results, err := h.service.SessionResults(ctx, actor, eventID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := make([]SessionResultResponse, 0, len(results))
+	for _, r := range results {
+		resp = append(resp, SessionResultResponse{
+			SessionID:    r.SessionID,
+			Title:        r.Title,
+			StartsAt:     r.StartsAt,
+			Removed:      r.Removed,
+			Count:        r.Count,
+			Average:      roundAverage(r.Average),
+			Distribution: r.Distribution[:],
+			Comments:     r.Comments,
+		})
+	}
+	return c.JSON(resp)
+}
+
+// SpeakerResults — GET /api/events/:id/feedback/speakers (команда события, viewer). Сводка по спикерам (person_id).
+func (h *Handler) SpeakerResults(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 10*time.Second)
+	defer cancel()
+
+	results, err := h.service.SpeakerResults(ctx, actor, eventID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := make([]SpeakerResultResponse, 0, len(results))
+	for _, r := range results {
+		resp = append(resp, SpeakerResultResponse{
+			PersonID:   r.PersonID,
+			PersonName: r.PersonName,
+			SessionIDs: r.SessionIDs,
+			Count:      r.Count,
+			Average:    roundAverage(r.Average),
+		})
+	}
+	return c.JSON(resp)
+}
+
+// writeFeedbackCSV отдаёт все оценки события файлом CSV. Комментарии и названия сессий экранируются
+// от CSV-инъекции.
+func writeFeedbackCSV(c *fiber.Ctx, eventID string, list []*Feedback) error {
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="feedback-`+eventID+`.csv"`)
+
+	w := csvexport.NewWriter(c.Response().BodyWriter())
+	_ = w.Write([]string{"session_id", "session_title", "session_starts_at", "user_id", "rating", "comment", "created_at"})
+	for _, f := range list {
+		comment := ""
+		if f.Comment != nil {
+			comment = *f.Comment
+		}
+		_ = w.Write([]string{
+			f.SessionID, f.SessionTitle, f.SessionStartsAt.UTC().Format(time.RFC3339), f.UserID,
+			strconv.Itoa(f.Rating), comment, f.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// actorFromCtx собирает events.Actor из клеймов JWT (после RequireAuth).
+func actorFromCtx(c *fiber.Ctx) (events.Actor, bool) {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return events.Actor{}, false
+	}
+	return events.Actor{UserID: claims.UserID, Role: claims.Role, IP: c.IP(), UserAgent: c.Get("User-Agent"), APIKeyID: claims.APIKeyID}, true
+}
+
+// writeServiceError маппит ошибки сервиса в HTTP-коды.
+func writeServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, events.ErrForbidden):
+		return response.WriteError(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, ErrEventNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "event not found")
+	case errors.Is(err, ErrSessionNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "session not found")
+	case errors.Is(err, ErrInvalidRating):
+		return response.WriteError(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrSessionNotEnded):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, ErrAlreadySubmitted):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+// roundAverage округляет среднюю оценку до сотых для ответа.
+func roundAverage(v float64) float64 {
+	return math.Round(v*100) / 100
+}
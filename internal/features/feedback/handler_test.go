@@ -0,0 +1,97 @@
+package feedback
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/authutils/authtest"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+)
+
+// newTestFeedbackApp поднимает маршруты оценок поверх моков (без БД).
+func newTestFeedbackApp(t *testing.T) (*fiber.App, *config.Config) {
+	t.Helper()
+	cfg := &config.Config{
+		JWTSecret:         "test-jwt-secret-at-least-32-bytes-for-feedback",
+		AccessTokenTTLMin: 15,
+	}
+	svc, _, _ := newFeedbackFixture(t)
+	h := NewHandler(svc)
+
+	requireAuth := middleware.RequireAuth(cfg)
+	app := fiber.New()
+	app.Post("/api/events/:id/sessions/:sessionId/feedback", requireAuth, h.Submit)
+	app.Get("/api/events/:id/feedback", requireAuth, h.SessionResults)
+	app.Get("/api/events/:id/feedback/speakers", requireAuth, h.SpeakerResults)
+	return app, cfg
+}
+
+func TestHandler_Submit(t *testing.T) {
+	app, cfg := newTestFeedbackApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-1/feedback", "user-1", "user", `{"rating":5,"comment":"Great"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-1/feedback", "user-1", "user", `{"rating":4}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusConflict, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-3/feedback", "user-1", "user", `{"rating":4}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusConflict, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-2/feedback", "user-1", "user", `{"rating":7}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+}
+
+func TestHandler_ResultsForEventTeamOnly(t *testing.T) {
+	app, cfg := newTestFeedbackApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-1/feedback", "user-1", "user", `{"rating":5,"comment":"=HYPERLINK(\"http://evil\"), thanks"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/feedback", "user-1", "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/feedback", "editor-2", "editor", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/feedback", "organizer-2", "organizer", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/feedback", "editor-1", "editor", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var sessions []SessionResultResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&sessions))
+	require.Len(t, sessions, 1)
+	assert.Equal(t, []int{0, 0, 0, 0, 1}, sessions[0].Distribution)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/feedback/speakers", "observer-1", "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var speakers []SpeakerResultResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&speakers))
+	require.Len(t, speakers, 1)
+	assert.Equal(t, "p-1", speakers[0].PersonID)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/feedback?format=csv", "editor-1", "editor", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	rows, err := csv.NewReader(res.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, `'=HYPERLINK("http://evil"), thanks`, rows[1][5])
+}
@@ -0,0 +1,21 @@
+package feedback
+
+import (
+	"context"
+
+	"wdpl_back/internal/features/events"
+)
+
+// FeedbackRepository описывает операции с таблицей session_feedback.
+type FeedbackRepository interface {
+	// Create сохраняет оценку. Если пользователь уже оценил сессию — ErrAlreadySubmitted.
+	Create(ctx context.Context, f *Feedback) error
+	// ListByEventID возвращает все оценки сессий события в порядке создания.
+	ListByEventID(ctx context.Context, eventID string) ([]*Feedback, error)
+}
+
+// EventAccess — проверка прав на событие по правилу фичи events (events.Access): команда события,
+// владельцы и администраторы его организации, глобальный admin.
+type EventAccess interface {
+	Authorize(ctx context.Context, actor events.Actor, eventID, need string) error
+}
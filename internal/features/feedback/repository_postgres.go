@@ -0,0 +1,71 @@
+package feedback
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/shared/postgres"
+)
+
+type postgresFeedbackRepository struct {
+	db *postgres.DB
+}
+
+// NewPostgresRepository возвращает реализацию FeedbackRepository для PostgreSQL.
+func NewPostgresRepository(db *postgres.DB) FeedbackRepository {
+	return &postgresFeedbackRepository{db: db}
+}
+
+func (r *postgresFeedbackRepository) Create(ctx context.Context, f *Feedback) error {
+	if f.ID == "" {
+		f.ID = uuid.NewString()
+	}
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now()
+	}
+	// ON CONFLICT вместо разбора кода ошибки: повторная оценка — штатный сценарий, а не сбой.
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO public.session_feedback
+			(id, event_id, session_id, user_id, rating, comment, session_title, session_starts_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (event_id, session_id, user_id) DO NOTHING
+	`, f.ID, f.EventID, f.SessionID, f.UserID, f.Rating, f.Comment, f.SessionTitle, f.SessionStartsAt, f.CreatedAt)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAlreadySubmitted
+	}
+	return nil
+}
+
+func (r *postgresFeedbackRepository) ListByEventID(ctx context.Context, eventID string) ([]*Feedback, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, event_id, session_id, user_id, rating, comment, session_title, session_starts_at, created_at
+		FROM public.session_feedback
+		WHERE event_id = $1
+		ORDER BY created_at, id
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Feedback
+	for rows.Next() {
+		var f Feedback
+		if err := rows.Scan(
+			&f.ID, &f.EventID, &f.SessionID, &f.UserID, &f.Rating, &f.Comment,
+			&f.SessionTitle, &f.SessionStartsAt, &f.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, &f)
+	}
+	return list, rows.Err()
+}
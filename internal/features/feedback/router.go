@@ -0,0 +1,27 @@
+package feedback
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/organizations"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/postgres"
+)
+
+// RegisterRoutes вешает эндпоинты оценок на api (обычно /api).
+// Участники (любой JWT): оценка сессии. Команда события (viewer и выше) и администраторы его организации
+// (events.Access): сводки и CSV.
+func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
+	eventsRepos := events.NewPostgresRepository(db)
+	access := events.NewAccess(eventsRepos.Events, eventsRepos.Drafts, eventsRepos.Members, organizations.NewPostgresRepository(db))
+	svc := NewService(NewPostgresRepository(db), eventsRepos.Events, eventsRepos.Days, access)
+	h := NewHandler(svc)
+
+	requireAuth := middleware.RequireAuth(cfg)
+
+	api.Post("/events/:id/sessions/:sessionId/feedback", requireAuth, h.Submit)
+	api.Get("/events/:id/feedback", requireAuth, h.SessionResults)
+	api.Get("/events/:id/feedback/speakers", requireAuth, h.SpeakerResults)
+}
@@ -0,0 +1,256 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"wdpl_back/internal/features/events"
+)
+
+// Service инкапсулирует оценки сессий: приём оценок после окончания сессии и сводки для редакторов.
+// Сессии читаются из опубликованных расписаний через репозитории фичи events.
+type Service struct {
+	repo       FeedbackRepository
+	eventsRepo events.EventRepository
+	daysRepo   events.EventDayRepository
+	access     EventAccess
+	now        func() time.Time
+}
+
+// NewService создаёт сервис оценок. access проверяет права редакторов на сводки события.
+func NewService(repo FeedbackRepository, eventsRepo events.EventRepository, daysRepo events.EventDayRepository, access EventAccess) *Service {
+	return &Service{
+		repo:       repo,
+		eventsRepo: eventsRepo,
+		daysRepo:   daysRepo,
+		access:     access,
+		now:        time.Now,
+	}
+}
+
+var (
+	ErrEventNotFound    = errors.New("event not found")
+	ErrSessionNotFound  = errors.New("session not found")
+	ErrSessionNotEnded  = errors.New("session has not ended yet")
+	ErrAlreadySubmitted = errors.New("feedback already submitted")
+	ErrInvalidRating    = errors.New("rating must be between 1 and 5")
+)
+
+// Submit сохраняет оценку сессии. Оценить можно только публично видимую сессию и только после её ends_at;
+// одна оценка на пользователя и сессию. Пустой комментарий не сохраняется.
+func (s *Service) Submit(ctx context.Context, userID, eventID, sessionID string, rating int, comment *string) (*Feedback, error) {
+	if rating < MinRating || rating > MaxRating {
+		return nil, ErrInvalidRating
+	}
+	sessions, err := s.publicSessions(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	session, ok := sessions[sessionID]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if s.now().Before(session.EndsAt) {
+		return nil, ErrSessionNotEnded
+	}
+	if comment != nil {
+		trimmed := strings.TrimSpace(*comment)
+		comment = &trimmed
+		if trimmed == "" {
+			comment = nil
+		}
+	}
+	f := &Feedback{
+		EventID:         eventID,
+		SessionID:       sessionID,
+		UserID:          userID,
+		Rating:          rating,
+		Comment:         comment,
+		SessionTitle:    session.Title,
+		SessionStartsAt: session.StartsAt,
+		CreatedAt:       s.now(),
+	}
+	if err := s.repo.Create(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// List возвращает все оценки события (для выгрузки).
+func (s *Service) List(ctx context.Context, actor events.Actor, eventID string) ([]*Feedback, error) {
+	if err := s.authorize(ctx, actor, eventID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByEventID(ctx, eventID)
+}
+
+// SessionResults возвращает сводку по каждой оценённой сессии, по времени начала.
+// Название берётся из текущего расписания; у снятых с программы сессий — на момент оценки.
+func (s *Service) SessionResults(ctx context.Context, actor events.Actor, eventID string) ([]*SessionResult, error) {
+	if err := s.authorize(ctx, actor, eventID); err != nil {
+		return nil, err
+	}
+	sessions, err := s.publishedSessions(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*SessionResult)
+	var results []*SessionResult
+	for _, f := range list {
+		r, ok := byID[f.SessionID]
+		if !ok {
+			r = &SessionResult{SessionID: f.SessionID, Title: f.SessionTitle, StartsAt: f.SessionStartsAt, Removed: true}
+			if session, found := sessions[f.SessionID]; found {
+				r.Title, r.StartsAt, r.Removed = session.Title, session.StartsAt, false
+			}
+			byID[f.SessionID] = r
+			results = append(results, r)
+		}
+		r.add(f)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if !results[i].StartsAt.Equal(results[j].StartsAt) {
+			return results[i].StartsAt.Before(results[j].StartsAt)
+		}
+		return results[i].SessionID < results[j].SessionID
+	})
+	return results, nil
+}
+
+// SpeakerResults возвращает сводку по спикерам: все оценки сессий, где person_id указан среди участников
+// с ролью speaker.
+// Учитываются только сессии текущего опубликованного расписания. Сортировка — по средней оценке (убывание).
+func (s *Service) SpeakerResults(ctx context.Context, actor events.Actor, eventID string) ([]*SpeakerResult, error) {
+	if err := s.authorize(ctx, actor, eventID); err != nil {
+		return nil, err
+	}
+	sessions, err := s.publishedSessions(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	bySession := make(map[string][]*Feedback)
+	for _, f := range list {
+		bySession[f.SessionID] = append(bySession[f.SessionID], f)
+	}
+
+	byPerson := make(map[string]*SpeakerResult)
+	var results []*SpeakerResult
+	for sessionID, feedback := range bySession {
+		session, ok := sessions[sessionID]
+		if !ok {
+			continue
+		}
+		for _, p := range session.Participants {
+			if p.PersonID == "" || p.Role != RoleSpeaker {
+				continue
+			}
+			r, ok := byPerson[p.PersonID]
+			if !ok {
+				r = &SpeakerResult{PersonID: p.PersonID}
+				byPerson[p.PersonID] = r
+				results = append(results, r)
+			}
+			if r.PersonName == nil {
+				r.PersonName = p.PersonName
+			}
+			if containsString(r.SessionIDs, sessionID) {
+				// Один спикер указан в сессии дважды — оценки не удваиваем.
+				continue
+			}
+			r.SessionIDs = append(r.SessionIDs, sessionID)
+			for _, f := range feedback {
+				r.Average = (r.Average*float64(r.Count) + float64(f.Rating)) / float64(r.Count+1)
+				r.Count++
+			}
+		}
+	}
+	for _, r := range results {
+		sort.Strings(r.SessionIDs)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Average != results[j].Average {
+			return results[i].Average > results[j].Average
+		}
+		return results[i].PersonID < results[j].PersonID
+	})
+	return results, nil
+}
+
+// publicSessions возвращает публично видимые сессии события по id.
+func (s *Service) publicSessions(ctx context.Context, eventID string) (map[string]events.SessionData, error) {
+	sessions, err := s.publishedSessions(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	for id, session := range sessions {
+		if !session.IsPubliclyVisible() {
+			delete(sessions, id)
+		}
+	}
+	return sessions, nil
+}
+
+// publishedSessions возвращает все сессии опубликованного расписания события по id.
+func (s *Service) publishedSessions(ctx context.Context, eventID string) (map[string]events.SessionData, error) {
+	if err := s.ensureEvent(ctx, eventID); err != nil {
+		return nil, err
+	}
+	days, err := s.daysRepo.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make(map[string]events.SessionData)
+	for _, day := range days {
+		schedule, err := day.ParseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range schedule.Sessions {
+			sessions[session.ID] = session
+		}
+	}
+	return sessions, nil
+}
+
+// ensureEvent — событие опубликовано; снятое с публикации (archived) — как несуществующее.
+func (s *Service) ensureEvent(ctx context.Context, eventID string) error {
+	event, err := s.eventsRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if event == nil || event.IsArchived() {
+		return ErrEventNotFound
+	}
+	return nil
+}
+
+// authorize проверяет, что событие eventID опубликовано (иначе ErrEventNotFound) и actor может
+// просматривать его (иначе events.ErrForbidden).
+func (s *Service) authorize(ctx context.Context, actor events.Actor, eventID string) error {
+	if err := s.ensureEvent(ctx, eventID); err != nil {
+		return err
+	}
+	return s.access.Authorize(ctx, actor, eventID, events.MemberViewer)
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package feedback
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/events/eventstest"
+	"wdpl_back/internal/features/organizations"
+)
+
+// mockFeedbackRepo — in-memory реализация FeedbackRepository.
+type mockFeedbackRepo struct {
+	list []*Feedback
+}
+
+func (m *mockFeedbackRepo) Create(_ context.Context, f *Feedback) error {
+	for _, existing := range m.list {
+		if existing.EventID == f.EventID && existing.SessionID == f.SessionID && existing.UserID == f.UserID {
+			return ErrAlreadySubmitted
+		}
+	}
+	// Строки из c.Params живут в буфере запроса Fiber — храним копии, как это делает БД.
+	f.EventID, f.SessionID = strings.Clone(f.EventID), strings.Clone(f.SessionID)
+	f.ID = "fb-" + f.UserID + "-" + f.SessionID
+	m.list = append(m.list, f)
+	return nil
+}
+
+func (m *mockFeedbackRepo) ListByEventID(_ context.Context, eventID string) ([]*Feedback, error) {
+	var result []*Feedback
+	for _, f := range m.list {
+		if f.EventID == eventID {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+// editor — редактор команды события фикстуры, observer — наблюдатель команды с глобальной ролью user,
+// outsider — администратор другой организации, stranger — глобальный editor без команды и организации.
+var (
+	editor   = events.Actor{UserID: "editor-1", Role: auth.RoleEditor}
+	observer = events.Actor{UserID: "observer-1", Role: auth.RoleUser}
+	outsider = events.Actor{UserID: "organizer-2", Role: auth.RoleOrganizer}
+	stranger = events.Actor{UserID: "editor-2", Role: auth.RoleEditor}
+)
+
+// newFeedbackFixture: s-1 и s-2 — доклады p-1 (s-2 вместе с p-2, ведёт модератор p-3), s-3 — позже, s-4 скрыта.
+// Часы сервиса — 12:30, после окончания s-1 и s-2, но до окончания s-3.
+func newFeedbackFixture(t *testing.T) (*Service, *mockFeedbackRepo, *eventstest.DayRepository) {
+	t.Helper()
+	eventsRepo, daysRepo := eventstest.Fixture(t, events.Event{},
+		eventstest.Session("s-2", "Panel", "11:00", "12:00",
+			eventstest.Participant("p-1", "speaker", "Alice"),
+			eventstest.Participant("p-2", "speaker", ""),
+			eventstest.Participant("p-3", "moderator", "Mod")),
+		eventstest.Session("s-1", "Keynote", "10:00", "11:00", eventstest.Participant("p-1", "speaker", "Alice")),
+		eventstest.Session("s-3", "Closing", "12:00", "13:00"),
+		eventstest.JuryBriefing("s-4"),
+	)
+	members := &eventstest.MemberRepository{}
+	members.Add(eventstest.EventID, editor.UserID, events.MemberEditor)
+	members.Add(eventstest.EventID, observer.UserID, events.MemberViewer)
+	orgs := &eventstest.OrganizationDirectory{}
+	orgs.AddMember("org-2", outsider.UserID, organizations.RoleAdmin)
+
+	repo := &mockFeedbackRepo{}
+	svc := NewService(repo, eventsRepo, daysRepo, eventstest.NewAccess(eventsRepo, members, orgs))
+	svc.now = func() time.Time { return time.Date(2026, 5, 1, 12, 30, 0, 0, time.UTC) }
+	return svc, repo, daysRepo
+}
+
+func TestSubmit_Validation(t *testing.T) {
+	svc, repo, _ := newFeedbackFixture(t)
+	ctx := context.Background()
+
+	blank := "   "
+	f, err := svc.Submit(ctx, "user-1", "event-1", "s-1", 5, &blank)
+	require.NoError(t, err)
+	assert.Nil(t, f.Comment)
+	assert.Equal(t, "Keynote", f.SessionTitle)
+
+	_, err = svc.Submit(ctx, "user-1", "event-1", "s-1", 4, nil)
+	require.ErrorIs(t, err, ErrAlreadySubmitted)
+
+	_, err = svc.Submit(ctx, "user-1", "event-1", "s-3", 4, nil)
+	require.ErrorIs(t, err, ErrSessionNotEnded)
+
+	_, err = svc.Submit(ctx, "user-1", "event-1", "s-4", 4, nil)
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	_, err = svc.Submit(ctx, "user-1", "missing", "s-1", 4, nil)
+	require.ErrorIs(t, err, ErrEventNotFound)
+
+	for _, rating := range []int{0, 6} {
+		_, err = svc.Submit(ctx, "user-2", "event-1", "s-1", rating, nil)
+		require.ErrorIs(t, err, ErrInvalidRating)
+	}
+	assert.Len(t, repo.list, 1)
+}
+
+func TestSessionResults_AggregatesAndKeepsRemoved(t *testing.T) {
+	svc, _, daysRepo := newFeedbackFixture(t)
+	ctx := context.Background()
+
+	comment := "Great"
+	for _, in := range []struct {
+		userID, sessionID string
+		rating            int
+		comment           *string
+	}{
+		{"user-1", "s-1", 5, &comment},
+		{"user-2", "s-1", 4, nil},
+		{"user-3", "s-1", 4, nil},
+		{"user-1", "s-2", 2, nil},
+	} {
+		_, err := svc.Submit(ctx, in.userID, "event-1", in.sessionID, in.rating, in.comment)
+		require.NoError(t, err)
+	}
+
+	results, err := svc.SessionResults(ctx, editor, "event-1")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "s-1", results[0].SessionID)
+	assert.Equal(t, 3, results[0].Count)
+	assert.InDelta(t, 4.333, results[0].Average, 0.001)
+	assert.Equal(t, [MaxRating]int{0, 0, 0, 2, 1}, results[0].Distribution)
+	assert.Equal(t, 1, results[0].Comments)
+	assert.False(t, results[0].Removed)
+
+	// Перепубликация без s-2: сводка остаётся с названием на момент оценки.
+	daysRepo.Days[0] = eventstest.Day(t, eventstest.Session("s-1", "Keynote (updated)", "10:00", "11:00"))
+	results, err = svc.SessionResults(ctx, editor, "event-1")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Keynote (updated)", results[0].Title)
+	assert.True(t, results[1].Removed)
+	assert.Equal(t, "Panel", results[1].Title)
+}
+
+func TestSpeakerResults_PerPerson(t *testing.T) {
+	svc, _, _ := newFeedbackFixture(t)
+	ctx := context.Background()
+
+	for _, in := range []struct {
+		userID, sessionID string
+		rating            int
+	}{
+		{"user-1", "s-1", 5},
+		{"user-2", "s-1", 5},
+		{"user-1", "s-2", 2},
+	} {
+		_, err := svc.Submit(ctx, in.userID, "event-1", in.sessionID, in.rating, nil)
+		require.NoError(t, err)
+	}
+
+	results, err := svc.SpeakerResults(ctx, editor, "event-1")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "p-1", results[0].PersonID)
+	require.NotNil(t, results[0].PersonName)
+	assert.Equal(t, "Alice", *results[0].PersonName)
+	assert.Equal(t, []string{"s-1", "s-2"}, results[0].SessionIDs)
+	assert.Equal(t, 3, results[0].Count)
+	assert.InDelta(t, 4.0, results[0].Average, 0.001)
+
+	// Модератор p-3 — не спикер: в сводку не попадает.
+	assert.Equal(t, "p-2", results[1].PersonID)
+	assert.Equal(t, 1, results[1].Count)
+	assert.InDelta(t, 2.0, results[1].Average, 0.001)
+}
+
+func TestResults_OtherOrganizationForbidden(t *testing.T) {
+	svc, _, _ := newFeedbackFixture(t)
+	ctx := context.Background()
+	_, err := svc.Submit(ctx, "user-1", "event-1", "s-1", 5, nil)
+	require.NoError(t, err)
+
+	_, err = svc.List(ctx, outsider, "event-1")
+	require.ErrorIs(t, err, events.ErrForbidden)
+	_, err = svc.SessionResults(ctx, outsider, "event-1")
+	require.ErrorIs(t, err, events.ErrForbidden)
+	_, err = svc.SpeakerResults(ctx, outsider, "event-1")
+	require.ErrorIs(t, err, events.ErrForbidden)
+
+	_, err = svc.SessionResults(ctx, outsider, "missing")
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestResults_EventTeamRolesNotGlobalRoles(t *testing.T) {
+	svc, _, _ := newFeedbackFixture(t)
+	ctx := context.Background()
+	_, err := svc.Submit(ctx, "user-1", "event-1", "s-1", 5, nil)
+	require.NoError(t, err)
+
+	_, err = svc.SessionResults(ctx, stranger, "event-1")
+	require.ErrorIs(t, err, events.ErrForbidden)
+
+	results, err := svc.SessionResults(ctx, observer, "event-1")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	_, err = svc.SpeakerResults(ctx, observer, "event-1")
+	require.NoError(t, err)
+	_, err = svc.List(ctx, observer, "event-1")
+	require.NoError(t, err)
+}
+
+func TestSubmit_UnpublishedEventNotFound(t *testing.T) {
+	svc, _, _ := newFeedbackFixture(t)
+	ctx := context.Background()
+	event, err := svc.eventsRepo.GetByID(ctx, eventstest.EventID)
+	require.NoError(t, err)
+	event.Status = events.EventStatusArchived
+
+	_, err = svc.Submit(ctx, "user-1", eventstest.EventID, "s-1", 5, nil)
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
@@ -0,0 +1,73 @@
+package organizations
+
+import (
+	"regexp"
+	"slices"
+	"time"
+
+	"wdpl_back/internal/features/auth"
+)
+
+// DefaultSlug — организация по умолчанию (миграция 022_organizations.sql): ей принадлежат события,
+// заведённые до появления организаций, её события отдаёт публичный /api/events.
+const DefaultSlug = "default"
+
+// Роли участника организации (organization_members.role): owner — всё, включая владельцев;
+// admin — состав организации и полные права на все её события; member — заводит события организации.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// roles — роли организации по возрастанию прав.
+var roles = []string{RoleMember, RoleAdmin, RoleOwner}
+
+// IsValidRole — role есть среди ролей организации.
+func IsValidRole(role string) bool {
+	return slices.Contains(roles, role)
+}
+
+// slugPattern — slug попадает в URL (/api/orgs/:slug): строчные латинские буквы, цифры и дефис.
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// Organization — организация, которой принадлежат события.
+type Organization struct {
+	ID        string
+	Slug      string
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Member — участник организации.
+type Member struct {
+	OrganizationID string
+	UserID         string
+	Email          string
+	Role           string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Can — роль участника даёт права не ниже need.
+func (m *Member) Can(need string) bool {
+	return slices.Index(roles, m.Role) >= slices.Index(roles, need)
+}
+
+// Membership — организация пользователя и его роль в ней.
+type Membership struct {
+	Organization *Organization
+	Role         string
+}
+
+// Actor — кто выполняет операцию: пользователь и его глобальная роль из JWT.
+type Actor struct {
+	UserID string
+	Role   string
+}
+
+// IsAdmin — глобальный admin: управляет любой организацией без членства.
+func (a Actor) IsAdmin() bool {
+	return a.Role == auth.RoleAdmin
+}
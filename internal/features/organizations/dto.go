@@ -0,0 +1,37 @@
+package organizations
+
+import "time"
+
+// OrganizationResponse — организация в ответах API.
+type OrganizationResponse struct {
+	ID        string    `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// MembershipResponse — организация пользователя и его роль (GET /api/orgs).
+type MembershipResponse struct {
+	OrganizationResponse
+	Role string `json:"role"`
+}
+
+// MemberResponse — участник организации.
+type MemberResponse struct {
+	UserID    string    `json:"userId"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateOrganizationRequest — тело POST /api/orgs.
+type CreateOrganizationRequest struct {
+	Slug string `json:"slug" validate:"required"`
+	Name string `json:"name" validate:"required"`
+}
+
+// AddMemberRequest — тело POST /api/orgs/:slug/members.
+type AddMemberRequest struct {
+	Email string `json:"email" validate:"required"`
+	Role  string `json:"role" validate:"required"`
+}
@@ -0,0 +1,183 @@
+package organizations
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/shared/http/handler"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/http/response"
+)
+
+// Handler реализует HTTP-эндпоинты организаций.
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+// NewHandler создаёт handler организаций.
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Create — POST /api/orgs. Создатель становится владельцем организации.
+func (h *Handler) Create(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	var req CreateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	org, err := h.service.Create(ctx, actor, req.Slug, req.Name)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(organizationToResponse(org))
+}
+
+// ListMine — GET /api/orgs. Организации текущего пользователя с его ролью.
+func (h *Handler) ListMine(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListMine(ctx, actor.UserID)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	resp := make([]MembershipResponse, 0, len(list))
+	for _, m := range list {
+		resp = append(resp, MembershipResponse{OrganizationResponse: organizationToResponse(m.Organization), Role: m.Role})
+	}
+	return c.JSON(resp)
+}
+
+// Get — GET /api/orgs/:slug (публичный).
+func (h *Handler) Get(c *fiber.Ctx) error {
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	org, err := h.service.Get(ctx, c.Params("slug"))
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(organizationToResponse(org))
+}
+
+// ListMembers — GET /api/orgs/:slug/members. Участники организации (любому её участнику).
+func (h *Handler) ListMembers(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListMembers(ctx, actor, c.Params("slug"))
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := make([]MemberResponse, 0, len(list))
+	for _, m := range list {
+		resp = append(resp, memberToResponse(m))
+	}
+	return c.JSON(resp)
+}
+
+// AddMember — POST /api/orgs/:slug/members. Приглашение по email или смена роли.
+func (h *Handler) AddMember(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	var req AddMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	member, err := h.service.AddMember(ctx, actor, c.Params("slug"), req.Email, req.Role)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(memberToResponse(member))
+}
+
+// RemoveMember — DELETE /api/orgs/:slug/members/:userId.
+func (h *Handler) RemoveMember(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := h.service.RemoveMember(ctx, actor, c.Params("slug"), c.Params("userId")); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// actorFromCtx собирает Actor из клеймов JWT (после RequireAuth).
+func actorFromCtx(c *fiber.Ctx) (Actor, bool) {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return Actor{}, false
+	}
+	return Actor{UserID: claims.UserID, Role: claims.Role}, true
+}
+
+// writeServiceError маппит ошибки сервиса в HTTP-коды.
+func writeServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrOrganizationNotFound), errors.Is(err, ErrUserNotFound), errors.Is(err, ErrMemberNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidSlug), errors.Is(err, ErrInvalidName), errors.Is(err, ErrInvalidRole):
+		return response.WriteError(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrForbidden):
+		return response.WriteError(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, ErrSlugTaken), errors.Is(err, ErrLastOwner):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+func organizationToResponse(o *Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        o.ID,
+		Slug:      o.Slug,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt,
+	}
+}
+
+func memberToResponse(m *Member) MemberResponse {
+	return MemberResponse{
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+	}
+}
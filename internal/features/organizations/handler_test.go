@@ -0,0 +1,80 @@
+package organizations
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/authutils"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+)
+
+// newTestOrgsApp поднимает маршруты организаций поверх моков (без БД).
+func newTestOrgsApp(t *testing.T) (*fiber.App, func(method, url string, actor Actor, body string) int) {
+	t.Helper()
+	cfg := &config.Config{
+		JWTSecret:         "test-jwt-secret-at-least-32-bytes-for-orgs",
+		AccessTokenTTLMin: 15,
+	}
+	svc, _ := newTestService(t)
+	h := NewHandler(svc)
+
+	app := fiber.New()
+	requireAuth := middleware.RequireAuth(cfg)
+	app.Post("/api/orgs", requireAuth, middleware.RequireRole(CreatorRoles...), h.Create)
+	app.Get("/api/orgs", requireAuth, h.ListMine)
+	app.Get("/api/orgs/:slug", h.Get)
+	app.Post("/api/orgs/:slug/members", requireAuth, h.AddMember)
+	app.Delete("/api/orgs/:slug/members/:userId", requireAuth, h.RemoveMember)
+
+	request := func(method, url string, actor Actor, body string) int {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if actor.UserID != "" {
+			token, _, err := authutils.GenerateAccessToken(cfg, actor.UserID, actor.Role)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+	return app, request
+}
+
+func TestHandler_CreateAndGet(t *testing.T) {
+	app, request := newTestOrgsApp(t)
+
+	assert.Equal(t, fiber.StatusForbidden, request("POST", "/api/orgs", testMember, `{"slug":"beta","name":"Beta"}`))
+	assert.Equal(t, fiber.StatusBadRequest, request("POST", "/api/orgs", testOwner, `{"slug":"beta"}`))
+	assert.Equal(t, fiber.StatusConflict, request("POST", "/api/orgs", testOwner, `{"slug":"acme","name":"Acme 2"}`))
+	assert.Equal(t, fiber.StatusCreated, request("POST", "/api/orgs", testOwner, `{"slug":"beta","name":"Beta"}`))
+
+	res, err := app.Test(httptest.NewRequest("GET", "/api/orgs/beta", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var org OrganizationResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&org))
+	assert.Equal(t, "Beta", org.Name)
+
+	assert.Equal(t, fiber.StatusNotFound, request("GET", "/api/orgs/missing", Actor{}, ""))
+	assert.Equal(t, fiber.StatusUnauthorized, request("GET", "/api/orgs", Actor{}, ""))
+}
+
+func TestHandler_Members(t *testing.T) {
+	_, request := newTestOrgsApp(t)
+
+	assert.Equal(t, fiber.StatusForbidden, request("POST", "/api/orgs/acme/members", testMember, `{"email":"admin2@example.com","role":"admin"}`))
+	assert.Equal(t, fiber.StatusOK, request("POST", "/api/orgs/acme/members", testOwner, `{"email":"admin2@example.com","role":"admin"}`))
+	assert.Equal(t, fiber.StatusNotFound, request("POST", "/api/orgs/acme/members", testOwner, `{"email":"nobody@example.com","role":"admin"}`))
+	assert.Equal(t, fiber.StatusConflict, request("DELETE", "/api/orgs/acme/members/owner-1", testOwner, ""))
+	assert.Equal(t, fiber.StatusNoContent, request("DELETE", "/api/orgs/acme/members/member-1", testMember, ""))
+	assert.Equal(t, fiber.StatusNotFound, request("DELETE", "/api/orgs/acme/members/member-1", Actor{UserID: "admin-1", Role: auth.RoleAdmin}, ""))
+}
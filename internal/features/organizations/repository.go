@@ -0,0 +1,37 @@
+package organizations
+
+import (
+	"context"
+
+	"wdpl_back/internal/features/auth"
+)
+
+// Repository описывает организации (public.organizations) и их участников (public.organization_members).
+type Repository interface {
+	// GetBySlug возвращает организацию (nil, если нет).
+	GetBySlug(ctx context.Context, slug string) (*Organization, error)
+	// ListByUserID возвращает организации пользователя с его ролью, по имени.
+	ListByUserID(ctx context.Context, userID string) ([]*Membership, error)
+	// Create создаёт организацию вместе с первым владельцем (одной транзакцией).
+	Create(ctx context.Context, org *Organization, owner *Member) error
+
+	// GetMember возвращает участника (nil, если пользователь не в организации).
+	GetMember(ctx context.Context, orgID, userID string) (*Member, error)
+	// ListMembers возвращает участников: владельцы, затем администраторы и участники.
+	ListMembers(ctx context.Context, orgID string) ([]*Member, error)
+	// UpsertMember добавляет участника или меняет его роль.
+	UpsertMember(ctx context.Context, member *Member) error
+	DeleteMember(ctx context.Context, orgID, userID string) error
+	// LockOwners блокирует строки владельцев организации (SELECT … FOR UPDATE) до конца транзакции
+	// и возвращает их user_id. Вызывать внутри InTx: параллельные снятия владельцев идут по очереди.
+	LockOwners(ctx context.Context, orgID string) ([]string, error)
+
+	// InTx выполняет fn с репозиторием, привязанным к одной транзакции: ошибка fn — откат, иначе COMMIT.
+	// Внутри уже открытой транзакции fn работает в ней же.
+	InTx(ctx context.Context, fn func(repo Repository) error) error
+}
+
+// UserDirectory ищет пользователя, приглашаемого в организацию (реализует auth.UserRepository).
+type UserDirectory interface {
+	GetUserByEmail(ctx context.Context, email string) (*auth.User, error)
+}
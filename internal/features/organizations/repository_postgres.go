@@ -0,0 +1,217 @@
+package organizations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/shared/postgres"
+)
+
+// querier — общее у *sql.DB и *sql.Tx: одни и те же запросы работают и в транзакции, и без неё.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type postgresRepository struct {
+	db *postgres.DB
+	// q — соединение db или транзакция, если репозиторий получен в InTx.
+	q  querier
+	tx *sql.Tx
+}
+
+// NewPostgresRepository возвращает реализацию Repository для PostgreSQL.
+func NewPostgresRepository(db *postgres.DB) Repository {
+	return &postgresRepository{db: db, q: db}
+}
+
+func (r *postgresRepository) InTx(ctx context.Context, fn func(repo Repository) error) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return fn(&postgresRepository{db: r.db, q: tx, tx: tx})
+	})
+}
+
+// withTx выполняет fn в текущей транзакции репозитория или в новой.
+func (r *postgresRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isUUID — id из пути запроса может быть не UUID: такого участника просто нет (а не ошибка приведения типа в БД).
+func isUUID(ids ...string) bool {
+	for _, id := range ids {
+		if uuid.Validate(id) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *postgresRepository) GetBySlug(ctx context.Context, slug string) (*Organization, error) {
+	row := r.q.QueryRowContext(ctx, `
+		SELECT id, slug, name, created_at, updated_at FROM public.organizations WHERE slug = $1
+	`, slug)
+	var o Organization
+	err := row.Scan(&o.ID, &o.Slug, &o.Name, &o.CreatedAt, &o.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *postgresRepository) ListByUserID(ctx context.Context, userID string) ([]*Membership, error) {
+	if !isUUID(userID) {
+		return nil, nil
+	}
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT o.id, o.slug, o.name, o.created_at, o.updated_at, m.role
+		FROM public.organization_members m JOIN public.organizations o ON o.id = m.organization_id
+		WHERE m.user_id = $1
+		ORDER BY o.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Membership
+	for rows.Next() {
+		var o Organization
+		var role string
+		if err := rows.Scan(&o.ID, &o.Slug, &o.Name, &o.CreatedAt, &o.UpdatedAt, &role); err != nil {
+			return nil, err
+		}
+		list = append(list, &Membership{Organization: &o, Role: role})
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresRepository) Create(ctx context.Context, org *Organization, owner *Member) error {
+	if org.ID == "" {
+		org.ID = uuid.NewString()
+	}
+	owner.OrganizationID = org.ID
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO public.organizations (id, slug, name, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, org.ID, org.Slug, org.Name, org.CreatedAt, org.UpdatedAt); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO public.organization_members (organization_id, user_id, role, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, owner.OrganizationID, owner.UserID, owner.Role, owner.CreatedAt, owner.UpdatedAt)
+		return err
+	})
+}
+
+func (r *postgresRepository) GetMember(ctx context.Context, orgID, userID string) (*Member, error) {
+	if !isUUID(orgID, userID) {
+		return nil, nil
+	}
+	row := r.q.QueryRowContext(ctx, `
+		SELECT m.organization_id, m.user_id, u.email, m.role, m.created_at, m.updated_at
+		FROM public.organization_members m JOIN auth.users u ON u.id = m.user_id
+		WHERE m.organization_id = $1 AND m.user_id = $2
+	`, orgID, userID)
+	var m Member
+	err := row.Scan(&m.OrganizationID, &m.UserID, &m.Email, &m.Role, &m.CreatedAt, &m.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *postgresRepository) ListMembers(ctx context.Context, orgID string) ([]*Member, error) {
+	if !isUUID(orgID) {
+		return nil, nil
+	}
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT m.organization_id, m.user_id, u.email, m.role, m.created_at, m.updated_at
+		FROM public.organization_members m JOIN auth.users u ON u.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, m.created_at
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Member
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Email, &m.Role, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &m)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresRepository) UpsertMember(ctx context.Context, member *Member) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO public.organization_members (organization_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			updated_at = EXCLUDED.updated_at
+	`, member.OrganizationID, member.UserID, member.Role, member.CreatedAt, member.UpdatedAt)
+	return err
+}
+
+func (r *postgresRepository) DeleteMember(ctx context.Context, orgID, userID string) error {
+	if !isUUID(orgID, userID) {
+		return nil
+	}
+	_, err := r.q.ExecContext(ctx, `
+		DELETE FROM public.organization_members WHERE organization_id = $1 AND user_id = $2
+	`, orgID, userID)
+	return err
+}
+
+func (r *postgresRepository) LockOwners(ctx context.Context, orgID string) ([]string, error) {
+	if !isUUID(orgID) {
+		return nil, nil
+	}
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT user_id FROM public.organization_members
+		WHERE organization_id = $1 AND role = 'owner'
+		ORDER BY user_id
+		FOR UPDATE
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var owners []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		owners = append(owners, userID)
+	}
+	return owners, rows.Err()
+}
@@ -0,0 +1,29 @@
+package organizations
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/postgres"
+)
+
+// CreatorRoles — глобальные роли, которым можно заводить организации.
+var CreatorRoles = []string{auth.RoleAdmin, auth.RoleOrganizer}
+
+// RegisterRoutes вешает эндпоинты организаций на api (под /api/orgs).
+// Публичный — только GET /orgs/:slug; события организации (/orgs/:slug/events) регистрирует фича events.
+func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
+	svc := NewService(NewPostgresRepository(db), auth.NewPostgresRepository(db))
+	h := NewHandler(svc)
+
+	requireAuth := middleware.RequireAuth(cfg)
+
+	api.Post("/orgs", requireAuth, middleware.RequireRole(CreatorRoles...), h.Create)
+	api.Get("/orgs", requireAuth, h.ListMine)
+	api.Get("/orgs/:slug", h.Get)
+	api.Get("/orgs/:slug/members", requireAuth, h.ListMembers)
+	api.Post("/orgs/:slug/members", requireAuth, h.AddMember)
+	api.Delete("/orgs/:slug/members/:userId", requireAuth, h.RemoveMember)
+}
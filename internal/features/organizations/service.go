@@ -0,0 +1,217 @@
+package organizations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrInvalidSlug          = errors.New("invalid slug")
+	ErrInvalidName          = errors.New("invalid name")
+	ErrSlugTaken            = errors.New("slug already taken")
+	ErrForbidden            = errors.New("no access to this organization")
+	ErrInvalidRole          = errors.New("invalid organization role")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrMemberNotFound       = errors.New("member not found")
+	ErrLastOwner            = errors.New("organization must keep at least one owner")
+)
+
+// Service — организации и их участники.
+type Service struct {
+	repo  Repository
+	users UserDirectory
+}
+
+// NewService создаёт сервис организаций.
+func NewService(repo Repository, users UserDirectory) *Service {
+	return &Service{repo: repo, users: users}
+}
+
+// Create заводит организацию; actor становится её владельцем.
+func (s *Service) Create(ctx context.Context, actor Actor, slug, name string) (*Organization, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !slugPattern.MatchString(slug) {
+		return nil, ErrInvalidSlug
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+	existing, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrSlugTaken
+	}
+	now := time.Now()
+	org := &Organization{Slug: slug, Name: name, CreatedAt: now, UpdatedAt: now}
+	owner := &Member{UserID: actor.UserID, Role: RoleOwner, CreatedAt: now, UpdatedAt: now}
+	if err := s.repo.Create(ctx, org, owner); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// Get возвращает организацию по slug (публично).
+func (s *Service) Get(ctx context.Context, slug string) (*Organization, error) {
+	org, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+// ListMine возвращает организации пользователя с его ролью в каждой.
+func (s *Service) ListMine(ctx context.Context, userID string) ([]*Membership, error) {
+	return s.repo.ListByUserID(ctx, userID)
+}
+
+// ListMembers возвращает участников организации. Доступно её участникам и admin.
+func (s *Service) ListMembers(ctx context.Context, actor Actor, slug string) ([]*Member, error) {
+	org, err := s.authorize(ctx, actor, slug, RoleMember)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, org.ID)
+}
+
+// AddMember приглашает пользователя (по email) в организацию или меняет его роль.
+// Администраторы организации управляют участниками, владельцев назначают и снимают только владельцы.
+func (s *Service) AddMember(ctx context.Context, actor Actor, slug, email, role string) (*Member, error) {
+	if !IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	need := RoleAdmin
+	if role == RoleOwner {
+		need = RoleOwner
+	}
+	org, err := s.Get(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	var member *Member
+	err = s.repo.InTx(ctx, func(r Repository) error {
+		owners, err := r.LockOwners(ctx, org.ID)
+		if err != nil {
+			return err
+		}
+		if err := authorize(ctx, r, actor, org, need); err != nil {
+			return err
+		}
+		user, err := s.users.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		existing, err := r.GetMember(ctx, org.ID, user.ID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		member = &Member{OrganizationID: org.ID, UserID: user.ID, Role: role, CreatedAt: now}
+		if existing != nil {
+			if existing.Role == RoleOwner {
+				if err := authorize(ctx, r, actor, org, RoleOwner); err != nil {
+					return err
+				}
+				if err := keepOwner(owners, role); err != nil {
+					return err
+				}
+			}
+			member.CreatedAt = existing.CreatedAt
+		}
+		member.UpdatedAt = now
+		member.Email = user.Email
+		return r.UpsertMember(ctx, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember убирает пользователя из организации. Администраторы убирают участников, владельцев —
+// только владельцы; любой участник может выйти сам.
+func (s *Service) RemoveMember(ctx context.Context, actor Actor, slug, userID string) error {
+	need := RoleAdmin
+	if userID == actor.UserID {
+		need = RoleMember
+	}
+	org, err := s.Get(ctx, slug)
+	if err != nil {
+		return err
+	}
+	return s.repo.InTx(ctx, func(r Repository) error {
+		owners, err := r.LockOwners(ctx, org.ID)
+		if err != nil {
+			return err
+		}
+		if err := authorize(ctx, r, actor, org, need); err != nil {
+			return err
+		}
+		existing, err := r.GetMember(ctx, org.ID, userID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return ErrMemberNotFound
+		}
+		if existing.Role == RoleOwner {
+			if err := authorize(ctx, r, actor, org, RoleOwner); err != nil {
+				return err
+			}
+			if err := keepOwner(owners, ""); err != nil {
+				return err
+			}
+		}
+		return r.DeleteMember(ctx, org.ID, userID)
+	})
+}
+
+// authorize находит организацию и проверяет, что роль actor в ней не ниже need (admin — без членства).
+func (s *Service) authorize(ctx context.Context, actor Actor, slug, need string) (*Organization, error) {
+	org, err := s.Get(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, s.repo, actor, org, need); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// authorize проверяет роль actor в организации org через repo — в транзакции или без неё.
+func authorize(ctx context.Context, repo Repository, actor Actor, org *Organization, need string) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	m, err := repo.GetMember(ctx, org.ID, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if m == nil || !m.Can(need) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// keepOwner не даёт снять роль owner с последнего владельца (newRole == "" — удаление из организации).
+// owners — владельцы, заблокированные LockOwners в той же транзакции, что и изменение.
+func keepOwner(owners []string, newRole string) error {
+	if newRole == RoleOwner {
+		return nil
+	}
+	if len(owners) <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package organizations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+)
+
+// mockRepo — in-memory реализация Repository (ключ участника — orgID + userID).
+type mockRepo struct {
+	orgs    map[string]*Organization
+	members map[[2]string]*Member
+	txs     int
+}
+
+func (m *mockRepo) GetBySlug(_ context.Context, slug string) (*Organization, error) {
+	for _, org := range m.orgs {
+		if org.Slug == slug {
+			return org, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) ListByUserID(_ context.Context, userID string) ([]*Membership, error) {
+	var list []*Membership
+	for key, member := range m.members {
+		if key[1] == userID {
+			list = append(list, &Membership{Organization: m.orgs[key[0]], Role: member.Role})
+		}
+	}
+	return list, nil
+}
+
+func (m *mockRepo) Create(_ context.Context, org *Organization, owner *Member) error {
+	org.ID = "org-" + org.Slug
+	owner.OrganizationID = org.ID
+	m.orgs[org.ID] = org
+	return m.UpsertMember(context.Background(), owner)
+}
+
+func (m *mockRepo) GetMember(_ context.Context, orgID, userID string) (*Member, error) {
+	if member, ok := m.members[[2]string{orgID, userID}]; ok {
+		cp := *member
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) ListMembers(_ context.Context, orgID string) ([]*Member, error) {
+	var list []*Member
+	for _, member := range m.members {
+		if member.OrganizationID == orgID {
+			list = append(list, member)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockRepo) UpsertMember(_ context.Context, member *Member) error {
+	cp := *member
+	m.members[[2]string{member.OrganizationID, member.UserID}] = &cp
+	return nil
+}
+
+func (m *mockRepo) DeleteMember(_ context.Context, orgID, userID string) error {
+	delete(m.members, [2]string{orgID, userID})
+	return nil
+}
+
+func (m *mockRepo) LockOwners(_ context.Context, orgID string) ([]string, error) {
+	var owners []string
+	for _, member := range m.members {
+		if member.OrganizationID == orgID && member.Role == RoleOwner {
+			owners = append(owners, member.UserID)
+		}
+	}
+	return owners, nil
+}
+
+// InTx — без отката: fn работает с тем же репозиторием; txs считает открытые транзакции.
+func (m *mockRepo) InTx(_ context.Context, fn func(repo Repository) error) error {
+	m.txs++
+	return fn(m)
+}
+
+// mockUsers — пользователи для приглашений по email.
+type mockUsers struct {
+	users []*auth.User
+}
+
+func (m *mockUsers) GetUserByEmail(_ context.Context, email string) (*auth.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+var (
+	testOwner  = Actor{UserID: "owner-1", Role: auth.RoleOrganizer}
+	testMember = Actor{UserID: "member-1", Role: auth.RoleUser}
+	testAdmin  = Actor{UserID: "admin-1", Role: auth.RoleAdmin}
+)
+
+// newTestService — сервис с организацией acme: owner-1 владелец, member-1 участник.
+func newTestService(t *testing.T) (*Service, *mockRepo) {
+	t.Helper()
+	repo := &mockRepo{orgs: map[string]*Organization{}, members: map[[2]string]*Member{}}
+	users := &mockUsers{users: []*auth.User{
+		{ID: "owner-1", Email: "owner@example.com"},
+		{ID: "member-1", Email: "member@example.com"},
+		{ID: "admin-2", Email: "admin2@example.com"},
+	}}
+	svc := NewService(repo, users)
+	_, err := svc.Create(context.Background(), testOwner, "acme", "Acme")
+	require.NoError(t, err)
+	_, err = svc.AddMember(context.Background(), testOwner, "acme", "member@example.com", RoleMember)
+	require.NoError(t, err)
+	return svc, repo
+}
+
+func TestCreate_ValidatesSlugAndMakesCreatorOwner(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, testOwner, "Bad Slug", "X")
+	assert.ErrorIs(t, err, ErrInvalidSlug)
+	_, err = svc.Create(ctx, testOwner, "ok-slug", "  ")
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = svc.Create(ctx, testOwner, "ACME", "Another")
+	assert.ErrorIs(t, err, ErrSlugTaken)
+
+	owner, err := repo.GetMember(ctx, "org-acme", testOwner.UserID)
+	require.NoError(t, err)
+	require.NotNil(t, owner)
+	assert.Equal(t, RoleOwner, owner.Role)
+
+	mine, err := svc.ListMine(ctx, testMember.UserID)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, "acme", mine[0].Organization.Slug)
+	assert.Equal(t, RoleMember, mine[0].Role)
+}
+
+func TestMembers_RolesGuardManagement(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	// Участник видит состав, но не меняет его.
+	list, err := svc.ListMembers(ctx, testMember, "acme")
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	_, err = svc.AddMember(ctx, testMember, "acme", "admin2@example.com", RoleAdmin)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.ListMembers(ctx, Actor{UserID: "stranger"}, "acme")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	// Администратор организации управляет участниками, но не владельцами.
+	_, err = svc.AddMember(ctx, testOwner, "acme", "admin2@example.com", RoleAdmin)
+	require.NoError(t, err)
+	orgAdmin := Actor{UserID: "admin-2", Role: auth.RoleUser}
+	_, err = svc.AddMember(ctx, orgAdmin, "acme", "member@example.com", RoleOwner)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.AddMember(ctx, orgAdmin, "acme", "owner@example.com", RoleMember)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, svc.RemoveMember(ctx, orgAdmin, "acme", testOwner.UserID), ErrForbidden)
+	require.NoError(t, svc.RemoveMember(ctx, orgAdmin, "acme", testMember.UserID))
+
+	_, err = svc.AddMember(ctx, testOwner, "acme", "nobody@example.com", RoleMember)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	_, err = svc.AddMember(ctx, testOwner, "acme", "member@example.com", "superuser")
+	assert.ErrorIs(t, err, ErrInvalidRole)
+	_, err = svc.ListMembers(ctx, testAdmin, "missing")
+	assert.ErrorIs(t, err, ErrOrganizationNotFound)
+}
+
+func TestMembers_KeepLastOwner(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, svc.RemoveMember(ctx, testOwner, "acme", testOwner.UserID), ErrLastOwner)
+	_, err := svc.AddMember(ctx, testOwner, "acme", "owner@example.com", RoleAdmin)
+	assert.ErrorIs(t, err, ErrLastOwner)
+
+	// Глобальный admin управляет организацией без членства.
+	_, err = svc.AddMember(ctx, testAdmin, "acme", "member@example.com", RoleOwner)
+	require.NoError(t, err)
+	require.NoError(t, svc.RemoveMember(ctx, testOwner, "acme", testOwner.UserID))
+	_, err = svc.ListMembers(ctx, testOwner, "acme")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestMembers_OwnerChecksRunInTransaction(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.AddMember(ctx, testOwner, "acme", "member@example.com", RoleOwner)
+	require.NoError(t, err)
+	txs := repo.txs
+
+	// Два владельца: один снимает другого — проверка последнего владельца и удаление в одной транзакции.
+	require.NoError(t, svc.RemoveMember(ctx, testOwner, "acme", testMember.UserID))
+	assert.Equal(t, txs+1, repo.txs)
+	assert.ErrorIs(t, svc.RemoveMember(ctx, testOwner, "acme", testOwner.UserID), ErrLastOwner)
+	assert.Equal(t, txs+2, repo.txs)
+
+	// Посторонний не узнаёт, зарегистрирован ли email: отказ в доступе раньше поиска пользователя.
+	_, err = svc.AddMember(ctx, Actor{UserID: "stranger"}, "acme", "nobody@example.com", RoleMember)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,60 @@
+package qa
+
+import "sync"
+
+// subscriberBuffer — очередь обновлений одного подписчика. Медленный клиент теряет обновления,
+// а не тормозит рассылку: при переполнении сообщение ему не доставляется.
+const subscriberBuffer = 32
+
+// Broker рассылает обновления вопросов подписчикам сессии внутри процесса.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan Update]struct{}
+}
+
+// NewBroker создаёт брокер обновлений.
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[string]map[chan Update]struct{})}
+}
+
+// Subscribe подписывает на обновления сессии. unsubscribe обязательно вызвать при отключении клиента.
+func (b *Broker) Subscribe(eventID, sessionID string) (updates <-chan Update, unsubscribe func()) {
+	key := sessionKey(eventID, sessionID)
+	ch := make(chan Update, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[key] == nil {
+		b.subs[key] = make(map[chan Update]struct{})
+	}
+	b.subs[key][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs[key], ch)
+			if len(b.subs[key]) == 0 {
+				delete(b.subs, key)
+			}
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Publish отправляет обновление всем подписчикам сессии вопроса (без блокировки).
+func (b *Broker) Publish(u Update) {
+	key := sessionKey(u.Question.EventID, u.Question.SessionID)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[key] {
+		select {
+		case ch <- u:
+		default:
+		}
+	}
+}
+
+func sessionKey(eventID, sessionID string) string {
+	return eventID + "/" + sessionID
+}
@@ -0,0 +1,67 @@
+package qa
+
+import (
+	"sort"
+	"time"
+)
+
+// Question — вопрос участника к сессии. Votes считается по таблице голосов при чтении.
+type Question struct {
+	ID         string
+	EventID    string
+	SessionID  string
+	UserID     string
+	Text       string
+	Votes      int
+	Hidden     bool
+	Pinned     bool
+	AnsweredAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ModerationInput — изменения вопроса модератором; nil — поле не меняется.
+type ModerationInput struct {
+	Hidden   *bool
+	Answered *bool
+	Pinned   *bool
+}
+
+// Viewer — кто обращается к вопросам: пустой UserID — аноним.
+type Viewer struct {
+	UserID string
+	Role   string
+}
+
+// Типы обновлений, которые рассылаются подписчикам сессии.
+const (
+	UpdateQuestion = "question" // новый или изменённый видимый вопрос
+	UpdateHidden   = "hidden"   // вопрос скрыт модератором
+	UpdateVotes    = "votes"    // изменилось число голосов
+)
+
+// Update — изменение вопросов сессии для рассылки (SSE).
+type Update struct {
+	Kind     string
+	Question Question
+}
+
+// RoleModerator — роль участника сессии (participants[].role), дающая права модерации вопросов.
+const RoleModerator = "moderator"
+
+// SortQuestions упорядочивает вопросы: закреплённые, затем по голосам (убывание), затем по времени.
+func SortQuestions(list []*Question) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		if a.Votes != b.Votes {
+			return a.Votes > b.Votes
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,41 @@
+package qa
+
+import "time"
+
+// AskRequest — тело POST /api/events/:id/sessions/:sessionId/questions.
+type AskRequest struct {
+	Text string `json:"text" validate:"required"`
+}
+
+// ModerateRequest — тело PATCH /api/questions/:id; отсутствующее поле не меняется.
+type ModerateRequest struct {
+	Hidden   *bool `json:"hidden"`
+	Answered *bool `json:"answered"`
+	Pinned   *bool `json:"pinned"`
+}
+
+// QuestionResponse — вопрос в ответе API и в SSE-событии question.
+type QuestionResponse struct {
+	ID         string     `json:"id"`
+	EventID    string     `json:"eventId"`
+	SessionID  string     `json:"sessionId"`
+	Text       string     `json:"text"`
+	Votes      int        `json:"votes"`
+	Hidden     bool       `json:"hidden,omitempty"`
+	Pinned     bool       `json:"pinned"`
+	AnsweredAt *time.Time `json:"answeredAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	// VotedByMe — голосовал ли текущий пользователь (только в списке для авторизованных).
+	VotedByMe bool `json:"votedByMe,omitempty"`
+}
+
+// VotesResponse — SSE-событие votes: новое число голосов вопроса.
+type VotesResponse struct {
+	ID    string `json:"id"`
+	Votes int    `json:"votes"`
+}
+
+// HiddenResponse — SSE-событие hidden: вопрос скрыт модератором, клиенту нужно убрать его из списка.
+type HiddenResponse struct {
+	ID string `json:"id"`
+}
@@ -0,0 +1,256 @@
+package qa
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"wdpl_back/internal/shared/http/handler"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/http/response"
+	"wdpl_back/internal/shared/http/sse"
+)
+
+// streamPingInterval — как часто слать комментарий-пинг в SSE, чтобы прокси не рвали простаивающее соединение.
+const streamPingInterval = 15 * time.Second
+
+// Handler реализует HTTP-эндпоинты вопросов к сессиям.
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+// NewHandler создаёт handler вопросов.
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// List — GET /api/events/:id/sessions/:sessionId/questions. Вопросы по голосам (закреплённые первыми).
+// Модераторы (с JWT) видят и скрытые.
+func (h *Handler) List(c *fiber.Ctx) error {
+	eventID := c.Params("id")
+	sessionID := c.Params("sessionId")
+	if eventID == "" || sessionID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, voted, err := h.service.List(ctx, viewerFromCtx(c), eventID, sessionID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := make([]QuestionResponse, 0, len(list))
+	for _, q := range list {
+		item := questionToResponse(q)
+		item.VotedByMe = voted[q.ID]
+		resp = append(resp, item)
+	}
+	return c.JSON(resp)
+}
+
+// Ask — POST /api/events/:id/sessions/:sessionId/questions. Новый вопрос (с лимитом частоты).
+func (h *Handler) Ask(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	// Копии: вопрос уходит в брокер и читается SSE-потоками после завершения запроса,
+	// а строки c.Params указывают на буфер Fiber, который переиспользуется.
+	eventID := utils.CopyString(c.Params("id"))
+	sessionID := utils.CopyString(c.Params("sessionId"))
+	if eventID == "" || sessionID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	var req AskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	q, err := h.service.Ask(ctx, claims.UserID, eventID, sessionID, req.Text)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(questionToResponse(q))
+}
+
+// Vote — POST /api/questions/:id/vote. Голос за вопрос (повторный — без изменений).
+func (h *Handler) Vote(c *fiber.Ctx) error {
+	return h.vote(c, true)
+}
+
+// Unvote — DELETE /api/questions/:id/vote. Снять голос.
+func (h *Handler) Unvote(c *fiber.Ctx) error {
+	return h.vote(c, false)
+}
+
+func (h *Handler) vote(c *fiber.Ctx, up bool) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	q, err := h.service.Vote(ctx, claims.UserID, id, up)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(VotesResponse{ID: q.ID, Votes: q.Votes})
+}
+
+// Moderate — PATCH /api/questions/:id. Скрыть, отметить отвеченным, закрепить (модераторы и редакторы).
+func (h *Handler) Moderate(c *fiber.Ctx) error {
+	if _, ok := middleware.ClaimsFromCtx(c); !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	var req ModerateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	q, err := h.service.Moderate(ctx, viewerFromCtx(c), id, ModerationInput{
+		Hidden:   req.Hidden,
+		Answered: req.Answered,
+		Pinned:   req.Pinned,
+	})
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(questionToResponse(q))
+}
+
+// Stream — GET /api/events/:id/sessions/:sessionId/questions/stream (SSE).
+// Сразу после подключения — событие questions со списком, затем question / votes / hidden по мере изменений.
+func (h *Handler) Stream(c *fiber.Ctx) error {
+	eventID := c.Params("id")
+	sessionID := c.Params("sessionId")
+	if eventID == "" || sessionID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	// Подписка до чтения списка: изменения между снимком и циклом не теряются (в худшем случае придут дважды).
+	updates, unsubscribe, err := h.service.Subscribe(ctx, eventID, sessionID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	list, _, err := h.service.List(ctx, Viewer{}, eventID, sessionID)
+	if err != nil {
+		unsubscribe()
+		return writeServiceError(c, err)
+	}
+	snapshot := make([]QuestionResponse, 0, len(list))
+	for _, q := range list {
+		snapshot = append(snapshot, questionToResponse(q))
+	}
+
+	sse.SetHeaders(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		data, _ := json.Marshal(snapshot)
+		if err := sse.WriteEvent(w, sse.Event{Name: "questions", Data: data}); err != nil {
+			return
+		}
+		ticker := time.NewTicker(streamPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case u := <-updates:
+				if err := sse.WriteEvent(w, updateToEvent(u)); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if err := sse.WriteComment(w, "ping"); err != nil {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// viewerFromCtx — текущий пользователь из JWT (если есть) для проверок модерации.
+func viewerFromCtx(c *fiber.Ctx) Viewer {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return Viewer{}
+	}
+	return Viewer{UserID: claims.UserID, Role: claims.Role}
+}
+
+func updateToEvent(u Update) sse.Event {
+	var payload any
+	switch u.Kind {
+	case UpdateVotes:
+		payload = VotesResponse{ID: u.Question.ID, Votes: u.Question.Votes}
+	case UpdateHidden:
+		payload = HiddenResponse{ID: u.Question.ID}
+	default:
+		payload = questionToResponse(&u.Question)
+	}
+	data, _ := json.Marshal(payload)
+	return sse.Event{Name: u.Kind, Data: data}
+}
+
+// writeServiceError маппит ошибки сервиса в HTTP-коды. Для 429 выставляется Retry-After (секунды).
+func writeServiceError(c *fiber.Ctx, err error) error {
+	var limited *RateLimitError
+	switch {
+	case errors.As(err, &limited):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		return response.WriteError(c, fiber.StatusTooManyRequests, err.Error())
+	case errors.Is(err, ErrEventNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "event not found")
+	case errors.Is(err, ErrSessionNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "session not found")
+	case errors.Is(err, ErrQuestionNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "question not found")
+	case errors.Is(err, ErrInvalidQuestion):
+		return response.WriteError(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrForbidden):
+		return response.WriteError(c, fiber.StatusForbidden, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+func questionToResponse(q *Question) QuestionResponse {
+	return QuestionResponse{
+		ID:         q.ID,
+		EventID:    q.EventID,
+		SessionID:  q.SessionID,
+		Text:       q.Text,
+		Votes:      q.Votes,
+		Hidden:     q.Hidden,
+		Pinned:     q.Pinned,
+		AnsweredAt: q.AnsweredAt,
+		CreatedAt:  q.CreatedAt,
+	}
+}
@@ -0,0 +1,99 @@
+package qa
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/authutils/authtest"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/ratelimit"
+)
+
+// newTestQAApp поднимает маршруты вопросов поверх моков (без БД).
+func newTestQAApp(t *testing.T) (*fiber.App, *config.Config, *Service) {
+	t.Helper()
+	cfg := &config.Config{
+		JWTSecret:         "test-jwt-secret-at-least-32-bytes-for-qa",
+		AccessTokenTTLMin: 15,
+	}
+	svc, _, _ := newQAFixture(t)
+	h := NewHandler(svc)
+
+	requireAuth := middleware.RequireAuth(cfg)
+	app := fiber.New()
+	app.Get("/api/events/:id/sessions/:sessionId/questions", middleware.OptionalAuth(cfg), h.List)
+	app.Post("/api/events/:id/sessions/:sessionId/questions", requireAuth, h.Ask)
+	app.Post("/api/questions/:id/vote", requireAuth, h.Vote)
+	app.Patch("/api/questions/:id", requireAuth, h.Moderate)
+	return app, cfg, svc
+}
+
+func TestHandler_AskVoteAndList(t *testing.T) {
+	app, cfg, _ := newTestQAApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-1/questions", "user-1", "user", `{"text":"What is next?"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, res.StatusCode)
+	var q QuestionResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&q))
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/questions/"+q.ID+"/vote", "user-2", "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	res, err = app.Test(httptest.NewRequest("GET", "/api/events/event-1/sessions/s-1/questions", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var list []QuestionResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Len(t, list, 1)
+	assert.Equal(t, 1, list[0].Votes)
+	assert.False(t, list[0].VotedByMe)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/sessions/s-1/questions", "user-2", "user", ""))
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Len(t, list, 1)
+	assert.True(t, list[0].VotedByMe)
+}
+
+func TestHandler_AskRateLimited(t *testing.T) {
+	app, cfg, svc := newTestQAApp(t)
+	svc.askLimiter = ratelimit.New(1, time.Minute)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-1/questions", "user-1", "user", `{"text":"First question"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-1/questions", "user-1", "user", `{"text":"Second question"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "60", res.Header.Get(fiber.HeaderRetryAfter))
+}
+
+func TestHandler_ModerateForbiddenForUsers(t *testing.T) {
+	app, cfg, _ := newTestQAApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/sessions/s-1/questions", "user-1", "user", `{"text":"Please hide me"}`))
+	require.NoError(t, err)
+	var q QuestionResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&q))
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "PATCH", "/api/questions/"+q.ID, "user-1", "user", `{"hidden":true}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "PATCH", "/api/questions/"+q.ID, "mod-1", "user", `{"pinned":true}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var moderated QuestionResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&moderated))
+	assert.True(t, moderated.Pinned)
+}
@@ -0,0 +1,79 @@
+package qa
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// WordFilter маскирует слова из локального списка (без учёта регистра, целыми словами).
+// Пустой фильтр ничего не меняет.
+type WordFilter struct {
+	words map[string]struct{}
+}
+
+// NewWordFilter создаёт фильтр. Пустые строки и строки, начинающиеся с '#', пропускаются.
+func NewWordFilter(words []string) *WordFilter {
+	f := &WordFilter{words: make(map[string]struct{}, len(words))}
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		f.words[w] = struct{}{}
+	}
+	return f
+}
+
+// LoadWordFilter читает список слов из файла (одно слово в строке). Пустой path — пустой фильтр.
+func LoadWordFilter(path string) (*WordFilter, error) {
+	if path == "" {
+		return NewWordFilter(nil), nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		words = append(words, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewWordFilter(words), nil
+}
+
+// Mask заменяет буквы запрещённых слов на '*'. masked — было ли что-то заменено.
+func (f *WordFilter) Mask(text string) (result string, masked bool) {
+	if len(f.words) == 0 {
+		return text, false
+	}
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if _, ok := f.words[strings.ToLower(string(runes[start:end]))]; ok {
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+			masked = true
+		}
+		start = end
+	}
+	return string(runes), masked
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
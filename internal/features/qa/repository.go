@@ -0,0 +1,34 @@
+package qa
+
+import (
+	"context"
+
+	"wdpl_back/internal/features/events"
+)
+
+// QuestionRepository описывает операции с вопросами и голосами.
+type QuestionRepository interface {
+	Create(ctx context.Context, q *Question) error
+	GetByID(ctx context.Context, id string) (*Question, error)
+	// ListBySession возвращает вопросы сессии; скрытые — только при includeHidden.
+	ListBySession(ctx context.Context, eventID, sessionID string, includeHidden bool) ([]*Question, error)
+	// Update сохраняет поля модерации (hidden, pinned, answered_at).
+	Update(ctx context.Context, q *Question) error
+	// AddVote и RemoveVote идемпотентны и возвращают текущее число голосов.
+	AddVote(ctx context.Context, questionID, userID string) (int, error)
+	RemoveVote(ctx context.Context, questionID, userID string) (int, error)
+	// VotedQuestionIDs — вопросы сессии, за которые голосовал пользователь.
+	VotedQuestionIDs(ctx context.Context, userID, eventID, sessionID string) (map[string]bool, error)
+}
+
+// PersonRepository связывает аккаунт с персоной из расписания (person_id).
+type PersonRepository interface {
+	// PersonIDByUserID возвращает "" если аккаунт не связан с персоной.
+	PersonIDByUserID(ctx context.Context, userID string) (string, error)
+}
+
+// EventAccess — проверка прав на событие по правилу фичи events (events.Access): команда события,
+// владельцы и администраторы его организации, глобальный admin.
+type EventAccess interface {
+	Authorize(ctx context.Context, actor events.Actor, eventID, need string) error
+}
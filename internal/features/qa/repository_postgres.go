@@ -0,0 +1,160 @@
+package qa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/shared/postgres"
+)
+
+type postgresQuestionRepository struct {
+	db *postgres.DB
+}
+
+// NewPostgresRepository возвращает реализацию QuestionRepository для PostgreSQL.
+func NewPostgresRepository(db *postgres.DB) QuestionRepository {
+	return &postgresQuestionRepository{db: db}
+}
+
+const questionColumns = `q.id, q.event_id, q.session_id, q.user_id, q.text, q.hidden, q.pinned, q.answered_at, q.created_at, q.updated_at,
+	(SELECT count(*) FROM public.question_votes v WHERE v.question_id = q.id)`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanQuestion(row rowScanner) (*Question, error) {
+	var q Question
+	err := row.Scan(&q.ID, &q.EventID, &q.SessionID, &q.UserID, &q.Text, &q.Hidden, &q.Pinned,
+		&q.AnsweredAt, &q.CreatedAt, &q.UpdatedAt, &q.Votes)
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (r *postgresQuestionRepository) Create(ctx context.Context, q *Question) error {
+	if q.ID == "" {
+		q.ID = uuid.NewString()
+	}
+	now := time.Now()
+	q.CreatedAt, q.UpdatedAt = now, now
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO public.session_questions (id, event_id, session_id, user_id, text, hidden, pinned, answered_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, q.ID, q.EventID, q.SessionID, q.UserID, q.Text, q.Hidden, q.Pinned, q.AnsweredAt, q.CreatedAt, q.UpdatedAt)
+	return err
+}
+
+func (r *postgresQuestionRepository) GetByID(ctx context.Context, id string) (*Question, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+questionColumns+` FROM public.session_questions q WHERE q.id = $1`, id)
+	q, err := scanQuestion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return q, err
+}
+
+func (r *postgresQuestionRepository) ListBySession(ctx context.Context, eventID, sessionID string, includeHidden bool) ([]*Question, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM public.session_questions q
+		WHERE q.event_id = $1 AND q.session_id = $2 AND ($3 OR NOT q.hidden)
+		ORDER BY q.created_at, q.id
+	`, eventID, sessionID, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Question
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, q)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresQuestionRepository) Update(ctx context.Context, q *Question) error {
+	q.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE public.session_questions
+		SET hidden = $1, pinned = $2, answered_at = $3, updated_at = $4
+		WHERE id = $5
+	`, q.Hidden, q.Pinned, q.AnsweredAt, q.UpdatedAt, q.ID)
+	return err
+}
+
+func (r *postgresQuestionRepository) AddVote(ctx context.Context, questionID, userID string) (int, error) {
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO public.question_votes (question_id, user_id) VALUES ($1, $2)
+		ON CONFLICT (question_id, user_id) DO NOTHING
+	`, questionID, userID); err != nil {
+		return 0, err
+	}
+	return r.countVotes(ctx, questionID)
+}
+
+func (r *postgresQuestionRepository) RemoveVote(ctx context.Context, questionID, userID string) (int, error) {
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM public.question_votes WHERE question_id = $1 AND user_id = $2
+	`, questionID, userID); err != nil {
+		return 0, err
+	}
+	return r.countVotes(ctx, questionID)
+}
+
+func (r *postgresQuestionRepository) countVotes(ctx context.Context, questionID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM public.question_votes WHERE question_id = $1`, questionID).Scan(&n)
+	return n, err
+}
+
+func (r *postgresQuestionRepository) VotedQuestionIDs(ctx context.Context, userID, eventID, sessionID string) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT v.question_id
+		FROM public.question_votes v
+		JOIN public.session_questions q ON q.id = v.question_id
+		WHERE v.user_id = $1 AND q.event_id = $2 AND q.session_id = $3
+	`, userID, eventID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	voted := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		voted[id] = true
+	}
+	return voted, rows.Err()
+}
+
+type postgresPersonRepository struct {
+	db *postgres.DB
+}
+
+// NewPostgresPersonRepository возвращает PersonRepository поверх user_profiles.person_id.
+func NewPostgresPersonRepository(db *postgres.DB) PersonRepository {
+	return &postgresPersonRepository{db: db}
+}
+
+func (r *postgresPersonRepository) PersonIDByUserID(ctx context.Context, userID string) (string, error) {
+	var personID sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT person_id FROM public.user_profiles WHERE user_id = $1`, userID).Scan(&personID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return personID.String, nil
+}
@@ -0,0 +1,48 @@
+package qa
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/organizations"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/postgres"
+)
+
+// RegisterRoutes вешает эндпоинты вопросов на api (обычно /api).
+// Публичные: список и SSE-поток вопросов сессии. JWT: вопрос, голос. Модераторы сессии, редакторы команды события
+// и администраторы его организации (events.Access): PATCH вопроса.
+// Стоп-лист слов читается из PROFANITY_WORDS_FILE при старте; ошибка чтения — panic (как config.MustLoad).
+func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
+	filter, err := LoadWordFilter(cfg.ProfanityWordsFile)
+	if err != nil {
+		panic(fmt.Errorf("load profanity words: %w", err))
+	}
+	eventsRepos := events.NewPostgresRepository(db)
+	access := events.NewAccess(eventsRepos.Events, eventsRepos.Drafts, eventsRepos.Members, organizations.NewPostgresRepository(db))
+	svc := NewService(
+		NewPostgresRepository(db),
+		NewPostgresPersonRepository(db),
+		eventsRepos.Events,
+		eventsRepos.Days,
+		access,
+		filter,
+		NewBroker(),
+	)
+	h := NewHandler(svc)
+
+	requireAuth := middleware.RequireAuth(cfg)
+	optionalAuth := middleware.OptionalAuth(cfg)
+
+	api.Get("/events/:id/sessions/:sessionId/questions", optionalAuth, h.List)
+	api.Post("/events/:id/sessions/:sessionId/questions", requireAuth, h.Ask)
+	api.Get("/events/:id/sessions/:sessionId/questions/stream", h.Stream)
+
+	g := api.Group("/questions")
+	g.Post("/:id/vote", requireAuth, h.Vote)
+	g.Delete("/:id/vote", requireAuth, h.Unvote)
+	g.Patch("/:id", requireAuth, h.Moderate)
+}
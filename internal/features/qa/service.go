@@ -0,0 +1,289 @@
+package qa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/shared/ratelimit"
+)
+
+// Лимиты на пользователя: вопросы и голоса за минуту.
+const (
+	askLimitPerMinute  = 5
+	voteLimitPerMinute = 60
+)
+
+// Границы длины вопроса (в символах).
+const (
+	minQuestionLength = 3
+	maxQuestionLength = 500
+)
+
+// Service инкапсулирует вопросы к сессиям: публикацию, голосование, модерацию и рассылку обновлений.
+// Сессии читаются из опубликованных расписаний через репозитории фичи events.
+type Service struct {
+	repo        QuestionRepository
+	persons     PersonRepository
+	eventsRepo  events.EventRepository
+	daysRepo    events.EventDayRepository
+	access      EventAccess
+	filter      *WordFilter
+	broker      *Broker
+	askLimiter  *ratelimit.Limiter
+	voteLimiter *ratelimit.Limiter
+}
+
+// NewService создаёт сервис вопросов.
+func NewService(
+	repo QuestionRepository,
+	persons PersonRepository,
+	eventsRepo events.EventRepository,
+	daysRepo events.EventDayRepository,
+	access EventAccess,
+	filter *WordFilter,
+	broker *Broker,
+) *Service {
+	return &Service{
+		repo:        repo,
+		persons:     persons,
+		eventsRepo:  eventsRepo,
+		daysRepo:    daysRepo,
+		access:      access,
+		filter:      filter,
+		broker:      broker,
+		askLimiter:  ratelimit.New(askLimitPerMinute, time.Minute),
+		voteLimiter: ratelimit.New(voteLimitPerMinute, time.Minute),
+	}
+}
+
+var (
+	ErrEventNotFound    = errors.New("event not found")
+	ErrSessionNotFound  = errors.New("session not found")
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrInvalidQuestion  = fmt.Errorf("question must be %d to %d characters", minQuestionLength, maxQuestionLength)
+	ErrForbidden        = errors.New("only moderators can change questions")
+	ErrRateLimited      = errors.New("too many requests")
+)
+
+// RateLimitError — превышен лимит; RetryAfter — через сколько можно повторить. errors.Is(err, ErrRateLimited).
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string { return ErrRateLimited.Error() }
+
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+// List возвращает вопросы сессии в порядке показа. Модераторы видят и скрытые.
+// voted — вопросы, за которые голосовал viewer (пусто для анонима).
+func (s *Service) List(ctx context.Context, viewer Viewer, eventID, sessionID string) (list []*Question, voted map[string]bool, err error) {
+	session, err := s.findSession(ctx, eventID, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	moderator, err := s.isModerator(ctx, viewer, eventID, session)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !moderator && !session.IsPubliclyVisible() {
+		return nil, nil, ErrSessionNotFound
+	}
+	list, err = s.repo.ListBySession(ctx, eventID, sessionID, moderator)
+	if err != nil {
+		return nil, nil, err
+	}
+	SortQuestions(list)
+	voted = map[string]bool{}
+	if viewer.UserID != "" {
+		if voted, err = s.repo.VotedQuestionIDs(ctx, viewer.UserID, eventID, sessionID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return list, voted, nil
+}
+
+// Ask публикует вопрос к публично видимой сессии. Слова из стоп-листа маскируются.
+func (s *Service) Ask(ctx context.Context, userID, eventID, sessionID, text string) (*Question, error) {
+	text = strings.TrimSpace(text)
+	if n := utf8.RuneCountInString(text); n < minQuestionLength || n > maxQuestionLength {
+		return nil, ErrInvalidQuestion
+	}
+	session, err := s.findSession(ctx, eventID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !session.IsPubliclyVisible() {
+		return nil, ErrSessionNotFound
+	}
+	if ok, retryAfter := s.askLimiter.Allow(userID); !ok {
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+	text, _ = s.filter.Mask(text)
+
+	q := &Question{
+		EventID:   eventID,
+		SessionID: sessionID,
+		UserID:    userID,
+		Text:      text,
+	}
+	if err := s.repo.Create(ctx, q); err != nil {
+		return nil, err
+	}
+	s.broker.Publish(Update{Kind: UpdateQuestion, Question: *q})
+	return q, nil
+}
+
+// Vote ставит (up) или снимает голос за видимый вопрос. Повторный голос не ошибка.
+// Вопросы события, снятого с публикации, — как несуществующие.
+func (s *Service) Vote(ctx context.Context, userID, questionID string, up bool) (*Question, error) {
+	q, err := s.repo.GetByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if q == nil || q.Hidden {
+		return nil, ErrQuestionNotFound
+	}
+	event, err := s.eventsRepo.GetByID(ctx, q.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil || event.IsArchived() {
+		return nil, ErrQuestionNotFound
+	}
+	if ok, retryAfter := s.voteLimiter.Allow(userID); !ok {
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+	if up {
+		q.Votes, err = s.repo.AddVote(ctx, questionID, userID)
+	} else {
+		q.Votes, err = s.repo.RemoveVote(ctx, questionID, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.broker.Publish(Update{Kind: UpdateVotes, Question: *q})
+	return q, nil
+}
+
+// Moderate скрывает/возвращает, отмечает отвеченным или закрепляет вопрос.
+// Доступно редакторам команды события и администраторам его организации (events.Access, MemberEditor)
+// и модераторам сессии (participants[].role = moderator).
+func (s *Service) Moderate(ctx context.Context, viewer Viewer, questionID string, in ModerationInput) (*Question, error) {
+	q, err := s.repo.GetByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if q == nil {
+		return nil, ErrQuestionNotFound
+	}
+	session, err := s.findSession(ctx, q.EventID, q.SessionID)
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return nil, err
+	}
+	moderator, err := s.isModerator(ctx, viewer, q.EventID, session)
+	if err != nil {
+		return nil, err
+	}
+	if !moderator {
+		return nil, ErrForbidden
+	}
+
+	if in.Hidden != nil {
+		q.Hidden = *in.Hidden
+	}
+	if in.Pinned != nil {
+		q.Pinned = *in.Pinned
+	}
+	if in.Answered != nil {
+		switch {
+		case *in.Answered && q.AnsweredAt == nil:
+			now := time.Now()
+			q.AnsweredAt = &now
+		case !*in.Answered:
+			q.AnsweredAt = nil
+		}
+	}
+	if err := s.repo.Update(ctx, q); err != nil {
+		return nil, err
+	}
+	kind := UpdateQuestion
+	if q.Hidden {
+		kind = UpdateHidden
+	}
+	s.broker.Publish(Update{Kind: kind, Question: *q})
+	return q, nil
+}
+
+// Subscribe подписывает на обновления вопросов публично видимой сессии (для SSE).
+func (s *Service) Subscribe(ctx context.Context, eventID, sessionID string) (<-chan Update, func(), error) {
+	session, err := s.findSession(ctx, eventID, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !session.IsPubliclyVisible() {
+		return nil, nil, ErrSessionNotFound
+	}
+	updates, unsubscribe := s.broker.Subscribe(eventID, sessionID)
+	return updates, unsubscribe, nil
+}
+
+// isModerator — редактор события eventID (команда, администраторы организации, admin) или персона-модератор
+// сессии. session == nil — сессию сняли с программы, тогда модерировать могут только редакторы.
+func (s *Service) isModerator(ctx context.Context, viewer Viewer, eventID string, session *events.SessionData) (bool, error) {
+	if viewer.UserID == "" {
+		return false, nil
+	}
+	err := s.access.Authorize(ctx, events.Actor{UserID: viewer.UserID, Role: viewer.Role}, eventID, events.MemberEditor)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, events.ErrForbidden) {
+		return false, err
+	}
+	if session == nil {
+		return false, nil
+	}
+	personID, err := s.persons.PersonIDByUserID(ctx, viewer.UserID)
+	if err != nil || personID == "" {
+		return false, err
+	}
+	for _, p := range session.Participants {
+		if p.PersonID == personID && p.Role == RoleModerator {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findSession ищет сессию в опубликованном расписании события (любой видимости). Событие, снятое
+// с публикации (archived), — как несуществующее.
+func (s *Service) findSession(ctx context.Context, eventID, sessionID string) (*events.SessionData, error) {
+	event, err := s.eventsRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil || event.IsArchived() {
+		return nil, ErrEventNotFound
+	}
+	days, err := s.daysRepo.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		schedule, err := day.ParseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		for i := range schedule.Sessions {
+			if schedule.Sessions[i].ID == sessionID {
+				return &schedule.Sessions[i], nil
+			}
+		}
+	}
+	return nil, ErrSessionNotFound
+}
@@ -0,0 +1,313 @@
+package qa
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/events/eventstest"
+	"wdpl_back/internal/features/organizations"
+	"wdpl_back/internal/shared/ratelimit"
+)
+
+// mockQuestionRepo — in-memory реализация QuestionRepository.
+type mockQuestionRepo struct {
+	list  []*Question
+	votes map[string]map[string]bool // questionID → userID
+	seq   int
+}
+
+func (m *mockQuestionRepo) Create(_ context.Context, q *Question) error {
+	m.seq++
+	q.ID = fmt.Sprintf("q-%d", m.seq)
+	q.CreatedAt = time.Date(2026, 5, 1, 10, 0, m.seq, 0, time.UTC)
+	copied := *q
+	m.list = append(m.list, &copied)
+	return nil
+}
+
+func (m *mockQuestionRepo) GetByID(_ context.Context, id string) (*Question, error) {
+	for _, q := range m.list {
+		if q.ID == id {
+			copied := *q
+			copied.Votes = len(m.votes[id])
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockQuestionRepo) ListBySession(_ context.Context, eventID, sessionID string, includeHidden bool) ([]*Question, error) {
+	var result []*Question
+	for _, q := range m.list {
+		if q.EventID == eventID && q.SessionID == sessionID && (includeHidden || !q.Hidden) {
+			copied := *q
+			copied.Votes = len(m.votes[q.ID])
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockQuestionRepo) Update(_ context.Context, q *Question) error {
+	for i, existing := range m.list {
+		if existing.ID == q.ID {
+			copied := *q
+			m.list[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *mockQuestionRepo) AddVote(_ context.Context, questionID, userID string) (int, error) {
+	// questionID из c.Params живёт в буфере запроса Fiber — ключ карты копируем, как это делает БД.
+	questionID = strings.Clone(questionID)
+	if m.votes == nil {
+		m.votes = make(map[string]map[string]bool)
+	}
+	if m.votes[questionID] == nil {
+		m.votes[questionID] = make(map[string]bool)
+	}
+	m.votes[questionID][userID] = true
+	return len(m.votes[questionID]), nil
+}
+
+func (m *mockQuestionRepo) RemoveVote(_ context.Context, questionID, userID string) (int, error) {
+	delete(m.votes[questionID], userID)
+	return len(m.votes[questionID]), nil
+}
+
+func (m *mockQuestionRepo) VotedQuestionIDs(_ context.Context, userID, _, _ string) (map[string]bool, error) {
+	voted := make(map[string]bool)
+	for questionID, users := range m.votes {
+		if users[userID] {
+			voted[questionID] = true
+		}
+	}
+	return voted, nil
+}
+
+// mockPersonRepo — связь аккаунтов с персонами расписания.
+type mockPersonRepo struct {
+	byUserID map[string]string
+}
+
+func (m *mockPersonRepo) PersonIDByUserID(_ context.Context, userID string) (string, error) {
+	return m.byUserID[userID], nil
+}
+
+// newQAFixture: s-1 — публичная сессия с модератором p-mod (аккаунт mod-1), s-2 — скрытая.
+// editor-1 — редактор команды события, outsider — администратор другой организации.
+func newQAFixture(t *testing.T) (*Service, *mockQuestionRepo, *Broker) {
+	t.Helper()
+	eventsRepo, daysRepo := eventstest.Fixture(t, events.Event{},
+		eventstest.Session("s-1", "Pitches", "10:00", "11:00",
+			eventstest.Participant("p-mod", "moderator", ""),
+			eventstest.Participant("p-speaker", "speaker", "")),
+		eventstest.JuryBriefing("s-2"),
+	)
+	persons := &mockPersonRepo{byUserID: map[string]string{"mod-1": "p-mod", "speaker-1": "p-speaker"}}
+	members := &eventstest.MemberRepository{}
+	members.Add(eventstest.EventID, "editor-1", events.MemberEditor)
+	members.Add(eventstest.EventID, "viewer-1", events.MemberViewer)
+	orgs := &eventstest.OrganizationDirectory{}
+	orgs.AddMember("org-2", "outsider", organizations.RoleAdmin)
+	repo := &mockQuestionRepo{}
+	broker := NewBroker()
+	svc := NewService(repo, persons, eventsRepo, daysRepo, eventstest.NewAccess(eventsRepo, members, orgs), NewWordFilter([]string{"darn", "# комментарий", "блин"}), broker)
+	return svc, repo, broker
+}
+
+func TestAsk_ValidatesAndMasksProfanity(t *testing.T) {
+	svc, _, _ := newQAFixture(t)
+	ctx := context.Background()
+
+	q, err := svc.Ask(ctx, "user-1", "event-1", "s-1", "  Why is this so DARN slow, блин?  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Why is this so **** slow, ****?", q.Text)
+
+	_, err = svc.Ask(ctx, "user-1", "event-1", "s-1", "no")
+	require.ErrorIs(t, err, ErrInvalidQuestion)
+
+	_, err = svc.Ask(ctx, "user-1", "event-1", "s-2", "Hidden session?")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	_, err = svc.Ask(ctx, "user-1", "missing", "s-1", "Missing event?")
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestAsk_RateLimitedPerUser(t *testing.T) {
+	svc, _, _ := newQAFixture(t)
+	svc.askLimiter = ratelimit.New(2, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.Ask(ctx, "user-1", "event-1", "s-1", "Question number "+fmt.Sprint(i))
+		require.NoError(t, err)
+	}
+	_, err := svc.Ask(ctx, "user-1", "event-1", "s-1", "One too many")
+	require.ErrorIs(t, err, ErrRateLimited)
+	var limited *RateLimitError
+	require.ErrorAs(t, err, &limited)
+	assert.Positive(t, limited.RetryAfter)
+
+	_, err = svc.Ask(ctx, "user-2", "event-1", "s-1", "Another user")
+	require.NoError(t, err)
+}
+
+func TestList_SortedByPinAndVotes(t *testing.T) {
+	svc, _, _ := newQAFixture(t)
+	ctx := context.Background()
+
+	var ids []string
+	for _, text := range []string{"First question", "Second question", "Third question"} {
+		q, err := svc.Ask(ctx, "user-1", "event-1", "s-1", text)
+		require.NoError(t, err)
+		ids = append(ids, q.ID)
+	}
+	for _, userID := range []string{"user-2", "user-3"} {
+		_, err := svc.Vote(ctx, userID, ids[2], true)
+		require.NoError(t, err)
+	}
+	_, err := svc.Vote(ctx, "user-2", ids[1], true)
+	require.NoError(t, err)
+	// Повторный голос не считается.
+	q, err := svc.Vote(ctx, "user-2", ids[1], true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, q.Votes)
+
+	pinned := true
+	_, err = svc.Moderate(ctx, Viewer{UserID: "editor-1", Role: "editor"}, ids[0], ModerationInput{Pinned: &pinned})
+	require.NoError(t, err)
+
+	list, voted, err := svc.List(ctx, Viewer{UserID: "user-2", Role: "user"}, "event-1", "s-1")
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, []string{ids[0], ids[2], ids[1]}, []string{list[0].ID, list[1].ID, list[2].ID})
+	assert.True(t, voted[ids[1]])
+	assert.False(t, voted[ids[0]])
+}
+
+func TestModerate_Permissions(t *testing.T) {
+	svc, _, _ := newQAFixture(t)
+	ctx := context.Background()
+
+	q, err := svc.Ask(ctx, "user-1", "event-1", "s-1", "Can you repeat?")
+	require.NoError(t, err)
+
+	hidden, answered := true, true
+	// Автор вопроса, спикер, наблюдатель команды, глобальный editor без членства в событии
+	// и администратор другой организации модерировать не могут.
+	for _, viewer := range []Viewer{
+		{UserID: "user-1", Role: "user"},
+		{UserID: "speaker-1", Role: "user"},
+		{UserID: "viewer-1", Role: "user"},
+		{UserID: "stranger", Role: "editor"},
+		{UserID: "outsider", Role: "organizer"},
+		{},
+	} {
+		_, err = svc.Moderate(ctx, viewer, q.ID, ModerationInput{Hidden: &hidden})
+		require.ErrorIs(t, err, ErrForbidden)
+	}
+
+	// Редактор команды события — независимо от глобальной роли.
+	_, err = svc.Moderate(ctx, Viewer{UserID: "editor-1", Role: "user"}, q.ID, ModerationInput{Pinned: &answered})
+	require.NoError(t, err)
+
+	// Модератор сессии по person_id.
+	moderated, err := svc.Moderate(ctx, Viewer{UserID: "mod-1", Role: "user"}, q.ID, ModerationInput{Answered: &answered})
+	require.NoError(t, err)
+	assert.NotNil(t, moderated.AnsweredAt)
+
+	moderated, err = svc.Moderate(ctx, Viewer{UserID: "mod-1", Role: "user"}, q.ID, ModerationInput{Hidden: &hidden})
+	require.NoError(t, err)
+	assert.True(t, moderated.Hidden)
+	assert.NotNil(t, moderated.AnsweredAt)
+
+	// Скрытый вопрос не виден публично и за него нельзя голосовать; модератор его видит.
+	list, _, err := svc.List(ctx, Viewer{}, "event-1", "s-1")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	list, _, err = svc.List(ctx, Viewer{UserID: "mod-1", Role: "user"}, "event-1", "s-1")
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	_, err = svc.Vote(ctx, "user-2", q.ID, true)
+	require.ErrorIs(t, err, ErrQuestionNotFound)
+}
+
+func TestBroker_PublishesUpdates(t *testing.T) {
+	svc, _, _ := newQAFixture(t)
+	ctx := context.Background()
+
+	updates, unsubscribe, err := svc.Subscribe(ctx, "event-1", "s-1")
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	q, err := svc.Ask(ctx, "user-1", "event-1", "s-1", "Live question")
+	require.NoError(t, err)
+	_, err = svc.Vote(ctx, "user-2", q.ID, true)
+	require.NoError(t, err)
+	hidden := true
+	_, err = svc.Moderate(ctx, Viewer{UserID: "editor-1", Role: "admin"}, q.ID, ModerationInput{Hidden: &hidden})
+	require.NoError(t, err)
+
+	var kinds []string
+	for i := 0; i < 3; i++ {
+		u := <-updates
+		assert.Equal(t, q.ID, u.Question.ID)
+		kinds = append(kinds, u.Kind)
+	}
+	assert.Equal(t, []string{UpdateQuestion, UpdateVotes, UpdateHidden}, kinds)
+
+	_, _, err = svc.Subscribe(ctx, "event-1", "s-2")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestLoadWordFilter(t *testing.T) {
+	empty, err := LoadWordFilter("")
+	require.NoError(t, err)
+	text, masked := empty.Mask("anything goes")
+	assert.False(t, masked)
+	assert.Equal(t, "anything goes", text)
+
+	path := filepath.Join(t.TempDir(), "words.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# local list\nheck\n\n  Dang \n"), 0o600))
+	filter, err := LoadWordFilter(path)
+	require.NoError(t, err)
+	text, masked = filter.Mask("Heck, dang! Checkpoint stays.")
+	assert.True(t, masked)
+	assert.Equal(t, "****, ****! Checkpoint stays.", text)
+
+	_, err = LoadWordFilter(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
+
+func TestAsk_UnpublishedEventNotFound(t *testing.T) {
+	svc, _, _ := newQAFixture(t)
+	ctx := context.Background()
+	q, err := svc.Ask(ctx, "user-1", eventstest.EventID, "s-1", "Before unpublish")
+	require.NoError(t, err)
+	event, err := svc.eventsRepo.GetByID(ctx, eventstest.EventID)
+	require.NoError(t, err)
+	event.Status = events.EventStatusArchived
+
+	_, err = svc.Ask(ctx, "user-1", eventstest.EventID, "s-1", "After unpublish")
+	require.ErrorIs(t, err, ErrEventNotFound)
+	_, _, err = svc.List(ctx, Viewer{}, eventstest.EventID, "s-1")
+	require.ErrorIs(t, err, ErrEventNotFound)
+	_, _, err = svc.Subscribe(ctx, eventstest.EventID, "s-1")
+	require.ErrorIs(t, err, ErrEventNotFound)
+	_, err = svc.Vote(ctx, "user-2", q.ID, true)
+	require.ErrorIs(t, err, ErrQuestionNotFound)
+	hidden := true
+	_, err = svc.Moderate(ctx, Viewer{UserID: "mod-1", Role: "user"}, q.ID, ModerationInput{Hidden: &hidden})
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
@@ -0,0 +1,53 @@
+package registrations
+
+import "time"
+
+// Статусы регистрации.
+const (
+	StatusConfirmed  = "confirmed"
+	StatusWaitlisted = "waitlisted"
+	StatusCancelled  = "cancelled"
+)
+
+// Registration — регистрация пользователя на опубликованное событие или отдельную сессию.
+// SessionID == "" — регистрация на событие целиком.
+type Registration struct {
+	ID          string
+	EventID     string
+	SessionID   string
+	UserID      string
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PromotedAt  *time.Time
+	CancelledAt *time.Time
+	// CheckedInAt / CheckedInBy — отметка прохода по билету (кто из персонала отметил).
+	CheckedInAt *time.Time
+	CheckedInBy *string
+	// WaitlistPosition — место в очереди (с 1) для waitlisted; считается при чтении, в БД не хранится.
+	WaitlistPosition *int
+}
+
+// Registrant — строка списка зарегистрированных для организаторов (регистрация + контакт пользователя).
+type Registrant struct {
+	Registration
+	Email       string
+	DisplayName *string
+}
+
+// CheckInStats — счётчики прохода по билетам для события (или сессии).
+// Confirmed — подтверждённые регистрации, CheckedIn — из них отмеченные на входе.
+type CheckInStats struct {
+	EventID   string
+	SessionID string
+	Confirmed int
+	CheckedIn int
+	// Days — отметки по дням (дата в часовом поясе события), по возрастанию даты.
+	Days []DayCheckIns
+}
+
+// DayCheckIns — число отметок за день.
+type DayCheckIns struct {
+	Date      time.Time
+	CheckedIn int
+}
@@ -0,0 +1,56 @@
+package registrations
+
+import "time"
+
+// RegisterRequest — тело POST /api/events/:eventId/registrations. Без sessionId — регистрация на событие.
+type RegisterRequest struct {
+	SessionID string `json:"sessionId"`
+}
+
+// RegistrationResponse — регистрация в ответе API.
+type RegistrationResponse struct {
+	ID               string     `json:"id"`
+	EventID          string     `json:"eventId"`
+	SessionID        string     `json:"sessionId,omitempty"`
+	Status           string     `json:"status"`
+	WaitlistPosition *int       `json:"waitlistPosition,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	PromotedAt       *time.Time `json:"promotedAt,omitempty"`
+	CancelledAt      *time.Time `json:"cancelledAt,omitempty"`
+	CheckedInAt      *time.Time `json:"checkedInAt,omitempty"`
+}
+
+// RegistrantResponse — строка списка зарегистрированных (для организаторов).
+type RegistrantResponse struct {
+	RegistrationResponse
+	UserID      string  `json:"userID"`
+	Email       string  `json:"email"`
+	DisplayName *string `json:"displayName,omitempty"`
+}
+
+// CheckInRequest — тело POST /api/events/:id/check-in: содержимое отсканированного QR-кода.
+type CheckInRequest struct {
+	Token string `json:"token"`
+}
+
+// CheckInResponse — результат отметки прохода.
+type CheckInResponse struct {
+	Registration     RegistrationResponse `json:"registration"`
+	UserID           string               `json:"userID"`
+	AlreadyCheckedIn bool                 `json:"alreadyCheckedIn"`
+}
+
+// CheckInStatsResponse — счётчики прохода по событию (или сессии) и по дням.
+type CheckInStatsResponse struct {
+	EventID   string                `json:"eventId"`
+	SessionID string                `json:"sessionId,omitempty"`
+	Confirmed int                   `json:"confirmed"`
+	CheckedIn int                   `json:"checkedIn"`
+	Days      []DayCheckInsResponse `json:"days"`
+}
+
+// DayCheckInsResponse — отметки за день (YYYY-MM-DD в часовом поясе события).
+type DayCheckInsResponse struct {
+	Date      string `json:"date"`
+	CheckedIn int    `json:"checkedIn"`
+}
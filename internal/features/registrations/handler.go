@@ -0,0 +1,308 @@
+package registrations
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/shared/csvexport"
+	"wdpl_back/internal/shared/http/handler"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/http/response"
+)
+
+// Handler реализует HTTP-эндпоинты регистраций.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler создаёт handler регистраций.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Register — POST /api/events/:eventId/registrations. Регистрация текущего пользователя.
+// 201 — место подтверждено, 202 — в очереди ожидания. Повторный вызов возвращает ту же регистрацию.
+func (h *Handler) Register(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("eventId")
+	if eventID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing eventId")
+	}
+	var req RegisterRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+		}
+	}
+	ctx, cancel := handler.TimeoutContext(c, 10*time.Second)
+	defer cancel()
+
+	reg, err := h.service.Register(ctx, claims.UserID, eventID, req.SessionID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	status := fiber.StatusCreated
+	if reg.Status == StatusWaitlisted {
+		status = fiber.StatusAccepted
+	}
+	return c.Status(status).JSON(registrationToResponse(reg))
+}
+
+// ListMine — GET /api/registrations/me. Регистрации текущего пользователя.
+func (h *Handler) ListMine(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListMine(ctx, claims.UserID)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	resp := make([]RegistrationResponse, 0, len(list))
+	for _, reg := range list {
+		resp = append(resp, registrationToResponse(reg))
+	}
+	return c.JSON(resp)
+}
+
+// Cancel — DELETE /api/registrations/:id. Отмена своей регистрации; очередь продвигается автоматически.
+func (h *Handler) Cancel(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 10*time.Second)
+	defer cancel()
+
+	reg, err := h.service.Cancel(ctx, claims.UserID, id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(registrationToResponse(reg))
+}
+
+// ListRegistrants — GET /api/events/:eventId/registrations?sessionId=&format=csv (команда события, viewer).
+func (h *Handler) ListRegistrants(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("eventId")
+	if eventID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing eventId")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 10*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListRegistrants(ctx, actor, eventID, c.Query("sessionId"))
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	if c.Query("format") == "csv" {
+		return writeRegistrantsCSV(c, eventID, list)
+	}
+	resp := make([]RegistrantResponse, 0, len(list))
+	for _, reg := range list {
+		resp = append(resp, RegistrantResponse{
+			RegistrationResponse: registrationToResponse(&reg.Registration),
+			UserID:               reg.UserID,
+			Email:                reg.Email,
+			DisplayName:          reg.DisplayName,
+		})
+	}
+	return c.JSON(resp)
+}
+
+// ticketQRSize — сторона PNG с QR-кодом билета по умолчанию (px); ?size= в пределах [128, 1024].
+const (
+	ticketQRSize    = 320
+	ticketQRSizeMin = 128
+	ticketQRSizeMax = 1024
+)
+
+// TicketQR — GET /api/users/me/tickets/:id/qr. PNG с QR-кодом билета своей подтверждённой регистрации.
+func (h *Handler) TicketQR(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	size := c.QueryInt("size", ticketQRSize)
+	if size < ticketQRSizeMin || size > ticketQRSizeMax {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid size")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	token, err := h.service.Ticket(ctx, claims.UserID, id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	png, err := qrcode.Encode(token, qrcode.Medium, size)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	// Билет личный: не кешировать на общих прокси.
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Send(png)
+}
+
+// CheckIn — POST /api/events/:id/check-in (команда события, editor). Проверяет билет и отмечает проход.
+// Повторное сканирование — 200 с alreadyCheckedIn = true.
+func (h *Handler) CheckIn(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	var req CheckInRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if req.Token == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing token")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	reg, already, err := h.service.CheckIn(ctx, actor, eventID, req.Token)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(CheckInResponse{
+		Registration:     registrationToResponse(reg),
+		UserID:           reg.UserID,
+		AlreadyCheckedIn: already,
+	})
+}
+
+// CheckInStats — GET /api/events/:id/check-in/stats?sessionId= (команда события, viewer). Счётчики прохода.
+func (h *Handler) CheckInStats(c *fiber.Ctx) error {
+	actor, ok := actorFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	stats, err := h.service.CheckInStats(ctx, actor, eventID, c.Query("sessionId"))
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := CheckInStatsResponse{
+		EventID:   stats.EventID,
+		SessionID: stats.SessionID,
+		Confirmed: stats.Confirmed,
+		CheckedIn: stats.CheckedIn,
+		Days:      make([]DayCheckInsResponse, 0, len(stats.Days)),
+	}
+	for _, day := range stats.Days {
+		resp.Days = append(resp.Days, DayCheckInsResponse{
+			Date:      day.Date.Format("2006-01-02"),
+			CheckedIn: day.CheckedIn,
+		})
+	}
+	return c.JSON(resp)
+}
+
+// writeRegistrantsCSV отдаёт список зарегистрированных файлом CSV (для выгрузки в таблицы).
+// Email и имя вводят сами участники — ячейки экранируются от CSV-инъекции.
+func writeRegistrantsCSV(c *fiber.Ctx, eventID string, list []*Registrant) error {
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="registrations-`+eventID+`.csv"`)
+
+	w := csvexport.NewWriter(c.Response().BodyWriter())
+	_ = w.Write([]string{"registration_id", "user_id", "email", "display_name", "session_id", "status", "waitlist_position", "registered_at", "promoted_at", "checked_in_at"})
+	for _, reg := range list {
+		displayName, position, promotedAt, checkedInAt := "", "", "", ""
+		if reg.DisplayName != nil {
+			displayName = *reg.DisplayName
+		}
+		if reg.WaitlistPosition != nil {
+			position = strconv.Itoa(*reg.WaitlistPosition)
+		}
+		if reg.PromotedAt != nil {
+			promotedAt = reg.PromotedAt.UTC().Format(time.RFC3339)
+		}
+		if reg.CheckedInAt != nil {
+			checkedInAt = reg.CheckedInAt.UTC().Format(time.RFC3339)
+		}
+		_ = w.Write([]string{
+			reg.ID, reg.UserID, reg.Email, displayName, reg.SessionID, reg.Status, position,
+			reg.CreatedAt.UTC().Format(time.RFC3339), promotedAt, checkedInAt,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// actorFromCtx собирает events.Actor из клеймов JWT (после RequireAuth).
+func actorFromCtx(c *fiber.Ctx) (events.Actor, bool) {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return events.Actor{}, false
+	}
+	return events.Actor{UserID: claims.UserID, Role: claims.Role, IP: c.IP(), UserAgent: c.Get("User-Agent"), APIKeyID: claims.APIKeyID}, true
+}
+
+// writeServiceError маппит ошибки сервиса в HTTP-коды.
+func writeServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, events.ErrForbidden):
+		return response.WriteError(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, ErrEventNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "event not found")
+	case errors.Is(err, ErrSessionNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "session not found")
+	case errors.Is(err, ErrRegistrationNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "registration not found")
+	case errors.Is(err, ErrInvalidTicket):
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid ticket")
+	case errors.Is(err, ErrTicketOtherEvent):
+		return response.WriteError(c, fiber.StatusConflict, "ticket is for another event")
+	case errors.Is(err, ErrNotConfirmed):
+		return response.WriteError(c, fiber.StatusConflict, "registration is not confirmed")
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+func registrationToResponse(reg *Registration) RegistrationResponse {
+	return RegistrationResponse{
+		ID:               reg.ID,
+		EventID:          reg.EventID,
+		SessionID:        reg.SessionID,
+		Status:           reg.Status,
+		WaitlistPosition: reg.WaitlistPosition,
+		CreatedAt:        reg.CreatedAt,
+		PromotedAt:       reg.PromotedAt,
+		CancelledAt:      reg.CancelledAt,
+		CheckedInAt:      reg.CheckedInAt,
+	}
+}
@@ -0,0 +1,144 @@
+package registrations
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/authutils/authtest"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+)
+
+// newTestRegistrationsApp поднимает маршруты регистраций поверх моков (без БД).
+func newTestRegistrationsApp(t *testing.T) (*fiber.App, *config.Config) {
+	t.Helper()
+	cfg := &config.Config{
+		JWTSecret:         "test-jwt-secret-at-least-32-bytes-for-registrations",
+		AccessTokenTTLMin: 15,
+	}
+	svc, _, _ := newRegistrationsFixture(t)
+	h := NewHandler(svc)
+
+	requireAuth := middleware.RequireAuth(cfg)
+	app := fiber.New()
+	app.Post("/api/events/:eventId/registrations", requireAuth, h.Register)
+	app.Get("/api/events/:eventId/registrations", requireAuth, h.ListRegistrants)
+	app.Post("/api/events/:id/check-in", requireAuth, h.CheckIn)
+	app.Get("/api/events/:id/check-in/stats", requireAuth, h.CheckInStats)
+	app.Get("/api/users/me/tickets/:id/qr", requireAuth, h.TicketQR)
+	return app, cfg
+}
+
+func TestHandler_Register_ConfirmedThenWaitlisted(t *testing.T) {
+	app, cfg := newTestRegistrationsApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/registrations", "user-1", "user", `{"sessionId":"s-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/registrations", "user-2", "user", `{"sessionId":"s-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusAccepted, res.StatusCode)
+
+	var body RegistrationResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	assert.Equal(t, StatusWaitlisted, body.Status)
+	require.NotNil(t, body.WaitlistPosition)
+	assert.Equal(t, 1, *body.WaitlistPosition)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/registrations", "user-1", "user", `{"sessionId":"missing"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, res.StatusCode)
+}
+
+func TestHandler_ListRegistrants_CSVForEditors(t *testing.T) {
+	app, cfg := newTestRegistrationsApp(t)
+
+	// Третий участник — с «формулой» в email: в CSV она экранируется апострофом.
+	for _, userID := range []string{"user-1", "user-2", "=user-3"} {
+		res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/registrations", userID, "user", ""))
+		require.NoError(t, err)
+		require.Less(t, res.StatusCode, 300)
+	}
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/registrations?format=csv", "user-1", "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/registrations?format=csv", "organizer-2", "organizer", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/missing/registrations", "organizer-1", "organizer", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/registrations?format=csv", "organizer-1", "organizer", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Contains(t, res.Header.Get(fiber.HeaderContentDisposition), "attachment")
+
+	rows, err := csv.NewReader(res.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "email", rows[0][2])
+	assert.Equal(t, "'=user-3@example.com", rows[3][2])
+	assert.Equal(t, StatusWaitlisted, rows[3][5])
+	assert.Equal(t, "1", rows[3][6])
+}
+
+func TestHandler_TicketQRAndCheckIn(t *testing.T) {
+	app, cfg := newTestRegistrationsApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/registrations", "user-1", "user", ""))
+	require.NoError(t, err)
+	var reg RegistrationResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&reg))
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/users/me/tickets/"+reg.ID+"/qr", "user-1", "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Equal(t, "image/png", res.Header.Get(fiber.HeaderContentType))
+	png, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(png, []byte("\x89PNG")))
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/users/me/tickets/"+reg.ID+"/qr", "user-2", "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, res.StatusCode)
+
+	// Участник без команды события не может отмечать проход, редактор команды с ролью user — может.
+	token := NewTicketSigner("test-ticket-secret").Sign(&Registration{ID: reg.ID, EventID: "event-1"})
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/check-in", "user-1", "user", `{"token":"`+token+`"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/check-in", "volunteer-1", "user", `{"token":"`+token+`"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	var checkIn CheckInResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&checkIn))
+	assert.False(t, checkIn.AlreadyCheckedIn)
+	assert.Equal(t, "user-1", checkIn.UserID)
+	assert.NotNil(t, checkIn.Registration.CheckedInAt)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/events/event-1/check-in/stats", "observer-1", "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	// Организатор чужой организации отмечать проход не может; несуществующее событие — 404.
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-1/check-in", "organizer-2", "organizer", `{"token":"`+token+`"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "POST", "/api/events/event-2/check-in", "staff-1", "staff", `{"token":"`+token+`"}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, res.StatusCode)
+}
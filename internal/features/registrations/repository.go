@@ -0,0 +1,41 @@
+package registrations
+
+import (
+	"context"
+
+	"wdpl_back/internal/features/events"
+)
+
+// RegistrationRepository описывает операции с таблицей registrations.
+type RegistrationRepository interface {
+	// InEventLock выполняет fn в транзакции, удерживая блокировку строки события (SELECT ... FOR UPDATE).
+	// Все изменения регистраций одного события идут через неё — проверка лимита и запись атомарны
+	// при конкурентных регистрациях. Ошибка fn откатывает транзакцию.
+	InEventLock(ctx context.Context, eventID string, fn func(store RegistrationStore) error) error
+	GetByID(ctx context.Context, id string) (*Registration, error)
+	ListByUserID(ctx context.Context, userID string) ([]*Registration, error)
+	// ListRegistrants возвращает активные регистрации события/сессии (без cancelled) в порядке записи.
+	ListRegistrants(ctx context.Context, eventID, sessionID string) ([]*Registrant, error)
+	// WaitlistPosition — место регистрации в очереди ожидания (с 1).
+	WaitlistPosition(ctx context.Context, reg *Registration) (int, error)
+	// CheckInStats считает подтверждённые регистрации и отметки прохода; дни — в часовом поясе timezone.
+	CheckInStats(ctx context.Context, eventID, sessionID, timezone string) (*CheckInStats, error)
+}
+
+// RegistrationStore — операции внутри транзакции InEventLock.
+type RegistrationStore interface {
+	GetByID(ctx context.Context, id string) (*Registration, error)
+	// GetActive возвращает не отменённую регистрацию пользователя на событие/сессию.
+	GetActive(ctx context.Context, userID, eventID, sessionID string) (*Registration, error)
+	CountConfirmed(ctx context.Context, eventID, sessionID string) (int, error)
+	// NextWaitlisted — первая в очереди ожидания (FIFO по created_at).
+	NextWaitlisted(ctx context.Context, eventID, sessionID string) (*Registration, error)
+	Create(ctx context.Context, reg *Registration) error
+	Update(ctx context.Context, reg *Registration) error
+}
+
+// EventAccess — проверка прав на событие по правилу фичи events (events.Access): команда события,
+// владельцы и администраторы его организации, глобальный admin.
+type EventAccess interface {
+	Authorize(ctx context.Context, actor events.Actor, eventID, need string) error
+}
@@ -0,0 +1,275 @@
+package registrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/shared/postgres"
+)
+
+// querier — общее у *sql.DB и *sql.Tx: одни и те же запросы работают и в транзакции, и без неё.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// isUUID — id из пути запроса или билета может быть не UUID: такой записи просто нет (а не ошибка приведения типа в БД).
+func isUUID(ids ...string) bool {
+	for _, id := range ids {
+		if uuid.Validate(id) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+type postgresRepository struct {
+	db *postgres.DB
+}
+
+// NewPostgresRepository возвращает реализацию RegistrationRepository для PostgreSQL.
+func NewPostgresRepository(db *postgres.DB) RegistrationRepository {
+	return &postgresRepository{db: db}
+}
+
+func (r *postgresRepository) InEventLock(ctx context.Context, eventID string, fn func(store RegistrationStore) error) error {
+	if !isUUID(eventID) {
+		return ErrEventNotFound
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Блокировка строки события сериализует регистрации на него (и на его сессии):
+	// второй запрос ждёт, пока первый не закоммитит, и видит уже обновлённый счётчик.
+	var lockedID string
+	err = tx.QueryRowContext(ctx, `SELECT id FROM public.events WHERE id = $1 FOR UPDATE`, eventID).Scan(&lockedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Событие удалили между проверкой в сервисе и блокировкой.
+		return ErrEventNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&postgresStore{q: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *postgresRepository) GetByID(ctx context.Context, id string) (*Registration, error) {
+	return getByID(ctx, r.db, id)
+}
+
+func (r *postgresRepository) ListByUserID(ctx context.Context, userID string) ([]*Registration, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+registrationColumns+`
+		FROM public.registrations
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Registration
+	for rows.Next() {
+		reg, err := scanRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, reg)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresRepository) ListRegistrants(ctx context.Context, eventID, sessionID string) ([]*Registrant, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.id, r.event_id, r.session_id, r.user_id, r.status, r.created_at, r.updated_at, r.promoted_at, r.cancelled_at,
+			r.checked_in_at, r.checked_in_by, u.email, p.display_name
+		FROM public.registrations r
+		JOIN auth.users u ON u.id = r.user_id
+		LEFT JOIN public.user_profiles p ON p.user_id = r.user_id
+		WHERE r.event_id = $1 AND r.session_id = $2 AND r.status <> 'cancelled'
+		ORDER BY r.status, r.created_at, r.id
+	`, eventID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Registrant
+	for rows.Next() {
+		var reg Registrant
+		if err := rows.Scan(
+			&reg.ID, &reg.EventID, &reg.SessionID, &reg.UserID, &reg.Status,
+			&reg.CreatedAt, &reg.UpdatedAt, &reg.PromotedAt, &reg.CancelledAt,
+			&reg.CheckedInAt, &reg.CheckedInBy, &reg.Email, &reg.DisplayName,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, &reg)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresRepository) WaitlistPosition(ctx context.Context, reg *Registration) (int, error) {
+	var ahead int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count(*) FROM public.registrations
+		WHERE event_id = $1 AND session_id = $2 AND status = 'waitlisted'
+			AND (created_at, id) < ($3, $4)
+	`, reg.EventID, reg.SessionID, reg.CreatedAt, reg.ID).Scan(&ahead)
+	if err != nil {
+		return 0, err
+	}
+	return ahead + 1, nil
+}
+
+func (r *postgresRepository) CheckInStats(ctx context.Context, eventID, sessionID, timezone string) (*CheckInStats, error) {
+	stats := &CheckInStats{EventID: eventID, SessionID: sessionID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count(*), count(checked_in_at)
+		FROM public.registrations
+		WHERE event_id = $1 AND session_id = $2 AND status = 'confirmed'
+	`, eventID, sessionID).Scan(&stats.Confirmed, &stats.CheckedIn)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT (checked_in_at AT TIME ZONE $3)::date AS day, count(*)
+		FROM public.registrations
+		WHERE event_id = $1 AND session_id = $2 AND status = 'confirmed' AND checked_in_at IS NOT NULL
+		GROUP BY day
+		ORDER BY day
+	`, eventID, sessionID, timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day DayCheckIns
+		if err := rows.Scan(&day.Date, &day.CheckedIn); err != nil {
+			return nil, err
+		}
+		stats.Days = append(stats.Days, day)
+	}
+	return stats, rows.Err()
+}
+
+// — RegistrationStore (внутри транзакции InEventLock)
+
+type postgresStore struct {
+	q querier
+}
+
+func (s *postgresStore) GetByID(ctx context.Context, id string) (*Registration, error) {
+	return getByID(ctx, s.q, id)
+}
+
+func (s *postgresStore) GetActive(ctx context.Context, userID, eventID, sessionID string) (*Registration, error) {
+	row := s.q.QueryRowContext(ctx, `
+		SELECT `+registrationColumns+`
+		FROM public.registrations
+		WHERE user_id = $1 AND event_id = $2 AND session_id = $3 AND status <> 'cancelled'
+	`, userID, eventID, sessionID)
+	reg, err := scanRegistration(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return reg, err
+}
+
+func (s *postgresStore) CountConfirmed(ctx context.Context, eventID, sessionID string) (int, error) {
+	var n int
+	err := s.q.QueryRowContext(ctx, `
+		SELECT count(*) FROM public.registrations
+		WHERE event_id = $1 AND session_id = $2 AND status = 'confirmed'
+	`, eventID, sessionID).Scan(&n)
+	return n, err
+}
+
+func (s *postgresStore) NextWaitlisted(ctx context.Context, eventID, sessionID string) (*Registration, error) {
+	row := s.q.QueryRowContext(ctx, `
+		SELECT `+registrationColumns+`
+		FROM public.registrations
+		WHERE event_id = $1 AND session_id = $2 AND status = 'waitlisted'
+		ORDER BY created_at, id
+		LIMIT 1
+	`, eventID, sessionID)
+	reg, err := scanRegistration(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return reg, err
+}
+
+func (s *postgresStore) Create(ctx context.Context, reg *Registration) error {
+	if reg.ID == "" {
+		reg.ID = uuid.NewString()
+	}
+	now := time.Now()
+	if reg.CreatedAt.IsZero() {
+		reg.CreatedAt = now
+	}
+	reg.UpdatedAt = now
+	_, err := s.q.ExecContext(ctx, `
+		INSERT INTO public.registrations (id, event_id, session_id, user_id, status, created_at, updated_at, promoted_at, cancelled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, reg.ID, reg.EventID, reg.SessionID, reg.UserID, reg.Status, reg.CreatedAt, reg.UpdatedAt, reg.PromotedAt, reg.CancelledAt)
+	return err
+}
+
+func (s *postgresStore) Update(ctx context.Context, reg *Registration) error {
+	reg.UpdatedAt = time.Now()
+	_, err := s.q.ExecContext(ctx, `
+		UPDATE public.registrations
+		SET status = $1, updated_at = $2, promoted_at = $3, cancelled_at = $4, checked_in_at = $5, checked_in_by = $6
+		WHERE id = $7
+	`, reg.Status, reg.UpdatedAt, reg.PromotedAt, reg.CancelledAt, reg.CheckedInAt, reg.CheckedInBy, reg.ID)
+	return err
+}
+
+// — общие запросы
+
+const registrationColumns = `id, event_id, session_id, user_id, status, created_at, updated_at, promoted_at, cancelled_at, checked_in_at, checked_in_by`
+
+// rowScanner — общее у *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRegistration(row rowScanner) (*Registration, error) {
+	var reg Registration
+	err := row.Scan(&reg.ID, &reg.EventID, &reg.SessionID, &reg.UserID, &reg.Status,
+		&reg.CreatedAt, &reg.UpdatedAt, &reg.PromotedAt, &reg.CancelledAt, &reg.CheckedInAt, &reg.CheckedInBy)
+	if err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+func getByID(ctx context.Context, q querier, id string) (*Registration, error) {
+	if !isUUID(id) {
+		return nil, nil
+	}
+	row := q.QueryRowContext(ctx, `
+		SELECT `+registrationColumns+`
+		FROM public.registrations WHERE id = $1
+	`, id)
+	reg, err := scanRegistration(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return reg, err
+}
@@ -0,0 +1,34 @@
+package registrations
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/organizations"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/postgres"
+)
+
+// RegisterRoutes вешает эндпоинты регистраций на api (обычно /api).
+// Участники (любой JWT): регистрация, отмена, свои регистрации и QR-билеты. Команда события и администраторы
+// его организации (events.Access): список и CSV, счётчики — с правами viewer, check-in по билету — editor.
+func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
+	eventsRepos := events.NewPostgresRepository(db)
+	tickets := NewTicketSigner(cfg.TicketSigningSecret())
+	access := events.NewAccess(eventsRepos.Events, eventsRepos.Drafts, eventsRepos.Members, organizations.NewPostgresRepository(db))
+	svc := NewService(NewPostgresRepository(db), eventsRepos.Events, eventsRepos.Days, access, tickets)
+	h := NewHandler(svc)
+
+	requireAuth := middleware.RequireAuth(cfg)
+
+	api.Post("/events/:eventId/registrations", requireAuth, h.Register)
+	api.Get("/events/:eventId/registrations", requireAuth, h.ListRegistrants)
+	api.Post("/events/:id/check-in", requireAuth, h.CheckIn)
+	api.Get("/events/:id/check-in/stats", requireAuth, h.CheckInStats)
+	api.Get("/users/me/tickets/:id/qr", requireAuth, h.TicketQR)
+
+	g := api.Group("/registrations")
+	g.Get("/me", requireAuth, h.ListMine)
+	g.Delete("/:id", requireAuth, h.Cancel)
+}
@@ -0,0 +1,328 @@
+package registrations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"wdpl_back/internal/features/events"
+)
+
+// Service инкапсулирует регистрации (RSVP): лимиты, очередь ожидания и её автоматическое продвижение.
+// События и расписания читаются из опубликованных данных через репозитории фичи events.
+type Service struct {
+	repo       RegistrationRepository
+	eventsRepo events.EventRepository
+	daysRepo   events.EventDayRepository
+	access     EventAccess
+	tickets    *TicketSigner
+}
+
+// NewService создаёт сервис регистраций. access проверяет права организаторов и персонала на событие.
+func NewService(repo RegistrationRepository, eventsRepo events.EventRepository, daysRepo events.EventDayRepository, access EventAccess, tickets *TicketSigner) *Service {
+	return &Service{
+		repo:       repo,
+		eventsRepo: eventsRepo,
+		daysRepo:   daysRepo,
+		access:     access,
+		tickets:    tickets,
+	}
+}
+
+var (
+	ErrEventNotFound        = errors.New("event not found")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrRegistrationNotFound = errors.New("registration not found")
+	ErrNotConfirmed         = errors.New("registration is not confirmed")
+	ErrTicketOtherEvent     = errors.New("ticket is for another event")
+)
+
+// Register регистрирует пользователя на событие (sessionID == "") или на сессию.
+// При заполненном лимите регистрация попадает в очередь ожидания. Повторный вызов возвращает
+// уже существующую активную регистрацию (идемпотентность).
+func (s *Service) Register(ctx context.Context, userID, eventID, sessionID string) (*Registration, error) {
+	var reg *Registration
+	err := s.repo.InEventLock(ctx, eventID, func(store RegistrationStore) error {
+		existing, err := store.GetActive(ctx, userID, eventID, sessionID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			reg = existing
+			return nil
+		}
+		// На снятое с публикации событие новых регистраций нет; отмена прежних и очередь работают.
+		event, err := s.eventsRepo.GetByID(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if event == nil || event.IsArchived() {
+			return ErrEventNotFound
+		}
+		// Лимит читаем под блокировкой: так он согласован со счётчиком подтверждённых.
+		capacity, err := s.capacity(ctx, eventID, sessionID)
+		if err != nil {
+			return err
+		}
+		confirmed, err := store.CountConfirmed(ctx, eventID, sessionID)
+		if err != nil {
+			return err
+		}
+		reg = &Registration{
+			EventID:   eventID,
+			SessionID: sessionID,
+			UserID:    userID,
+			Status:    StatusConfirmed,
+			CreatedAt: time.Now(),
+		}
+		if capacity != nil && confirmed >= *capacity {
+			reg.Status = StatusWaitlisted
+		}
+		return store.Create(ctx, reg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.withWaitlistPosition(ctx, reg)
+}
+
+// Cancel отменяет регистрацию пользователя. Если освободилось подтверждённое место,
+// первые в очереди ожидания переводятся в confirmed (в той же транзакции).
+// Повторная отмена — не ошибка.
+func (s *Service) Cancel(ctx context.Context, userID, registrationID string) (*Registration, error) {
+	reg, err := s.repo.GetByID(ctx, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	if reg == nil || reg.UserID != userID {
+		return nil, ErrRegistrationNotFound
+	}
+
+	err = s.repo.InEventLock(ctx, reg.EventID, func(store RegistrationStore) error {
+		current, err := store.GetByID(ctx, registrationID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrRegistrationNotFound
+		}
+		reg = current
+		if reg.Status == StatusCancelled {
+			return nil
+		}
+		wasConfirmed := reg.Status == StatusConfirmed
+		now := time.Now()
+		reg.Status = StatusCancelled
+		reg.CancelledAt = &now
+		if err := store.Update(ctx, reg); err != nil {
+			return err
+		}
+		if !wasConfirmed {
+			return nil
+		}
+		return s.promote(ctx, store, reg.EventID, reg.SessionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// promote переводит первых из очереди ожидания в confirmed, пока есть свободные места.
+// Цикл, а не одна запись: лимит могли увеличить при перепубликации.
+func (s *Service) promote(ctx context.Context, store RegistrationStore, eventID, sessionID string) error {
+	capacity, err := s.capacity(ctx, eventID, sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		// Сессию сняли с программы — продвигать очередь некуда.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	confirmed, err := store.CountConfirmed(ctx, eventID, sessionID)
+	if err != nil {
+		return err
+	}
+	for capacity == nil || confirmed < *capacity {
+		next, err := store.NextWaitlisted(ctx, eventID, sessionID)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		now := time.Now()
+		next.Status = StatusConfirmed
+		next.PromotedAt = &now
+		if err := store.Update(ctx, next); err != nil {
+			return err
+		}
+		confirmed++
+	}
+	return nil
+}
+
+// ListMine возвращает регистрации пользователя (с местом в очереди для waitlisted).
+func (s *Service) ListMine(ctx context.Context, userID string) ([]*Registration, error) {
+	list, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i, reg := range list {
+		if list[i], err = s.withWaitlistPosition(ctx, reg); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// ListRegistrants возвращает активные регистрации события или сессии (для организаторов).
+// Сначала confirmed, затем waitlisted в порядке очереди. Нужны права просмотра события.
+func (s *Service) ListRegistrants(ctx context.Context, actor events.Actor, eventID, sessionID string) ([]*Registrant, error) {
+	if _, err := s.authorize(ctx, actor, eventID, events.MemberViewer); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListRegistrants(ctx, eventID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	position := 0
+	for _, reg := range list {
+		if reg.Status == StatusWaitlisted {
+			position++
+			p := position
+			reg.WaitlistPosition = &p
+		}
+	}
+	return list, nil
+}
+
+// Ticket возвращает билет (токен для QR-кода) своей регистрации. Билет есть только у confirmed.
+func (s *Service) Ticket(ctx context.Context, userID, registrationID string) (string, error) {
+	reg, err := s.repo.GetByID(ctx, registrationID)
+	if err != nil {
+		return "", err
+	}
+	if reg == nil || reg.UserID != userID {
+		return "", ErrRegistrationNotFound
+	}
+	if reg.Status != StatusConfirmed {
+		return "", ErrNotConfirmed
+	}
+	return s.tickets.Sign(reg), nil
+}
+
+// CheckIn отмечает проход по билету на входе события eventID. Повторное сканирование не ошибка:
+// возвращается исходная отметка и alreadyCheckedIn = true. Отмечает actor с правами редактора события.
+func (s *Service) CheckIn(ctx context.Context, actor events.Actor, eventID, token string) (reg *Registration, alreadyCheckedIn bool, err error) {
+	if _, err := s.authorize(ctx, actor, eventID, events.MemberEditor); err != nil {
+		return nil, false, err
+	}
+	registrationID, ticketEventID, err := s.tickets.Parse(token)
+	if err != nil {
+		return nil, false, err
+	}
+	if ticketEventID != eventID {
+		return nil, false, ErrTicketOtherEvent
+	}
+
+	err = s.repo.InEventLock(ctx, eventID, func(store RegistrationStore) error {
+		current, err := store.GetByID(ctx, registrationID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			// Подпись верна, но регистрации уже нет (удалено событие или пользователь).
+			return ErrInvalidTicket
+		}
+		reg = current
+		if reg.Status != StatusConfirmed {
+			return ErrNotConfirmed
+		}
+		if reg.CheckedInAt != nil {
+			alreadyCheckedIn = true
+			return nil
+		}
+		now := time.Now()
+		reg.CheckedInAt = &now
+		reg.CheckedInBy = &actor.UserID
+		return store.Update(ctx, reg)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return reg, alreadyCheckedIn, nil
+}
+
+// CheckInStats возвращает счётчики прохода по событию или сессии; дни — в часовом поясе события.
+// Нужны права просмотра события.
+func (s *Service) CheckInStats(ctx context.Context, actor events.Actor, eventID, sessionID string) (*CheckInStats, error) {
+	event, err := s.authorize(ctx, actor, eventID, events.MemberViewer)
+	if err != nil {
+		return nil, err
+	}
+	timezone := event.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return s.repo.CheckInStats(ctx, eventID, sessionID, timezone)
+}
+
+// authorize возвращает опубликованное событие eventID (ErrEventNotFound, если его нет), если actor
+// может действовать над ним с правами не ниже need (иначе events.ErrForbidden).
+func (s *Service) authorize(ctx context.Context, actor events.Actor, eventID, need string) (*events.Event, error) {
+	event, err := s.eventsRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEventNotFound
+	}
+	if err := s.access.Authorize(ctx, actor, eventID, need); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// capacity возвращает лимит события или сессии (nil — без ограничения).
+// Регистрироваться можно только на публично видимые и не отменённые сессии.
+func (s *Service) capacity(ctx context.Context, eventID, sessionID string) (*int, error) {
+	event, err := s.eventsRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEventNotFound
+	}
+	if sessionID == "" {
+		return event.Capacity, nil
+	}
+	days, err := s.daysRepo.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		schedule, err := day.ParseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range schedule.Sessions {
+			if session.ID == sessionID && session.IsPubliclyVisible() {
+				return session.Capacity, nil
+			}
+		}
+	}
+	return nil, ErrSessionNotFound
+}
+
+func (s *Service) withWaitlistPosition(ctx context.Context, reg *Registration) (*Registration, error) {
+	if reg.Status != StatusWaitlisted {
+		return reg, nil
+	}
+	position, err := s.repo.WaitlistPosition(ctx, reg)
+	if err != nil {
+		return nil, err
+	}
+	reg.WaitlistPosition = &position
+	return reg, nil
+}
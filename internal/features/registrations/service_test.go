@@ -0,0 +1,542 @@
+package registrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/events/eventstest"
+	"wdpl_back/internal/features/organizations"
+)
+
+// mockRegistrationRepo — in-memory реализация RegistrationRepository и RegistrationStore.
+// InEventLock сериализует вызовы мьютексом (аналог блокировки строки события).
+type mockRegistrationRepo struct {
+	mu   sync.Mutex
+	list []*Registration
+	seq  int
+}
+
+func (m *mockRegistrationRepo) InEventLock(_ context.Context, _ string, fn func(store RegistrationStore) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(m)
+}
+
+func (m *mockRegistrationRepo) GetByID(_ context.Context, id string) (*Registration, error) {
+	for _, r := range m.list {
+		if r.ID == id {
+			copied := *r
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRegistrationRepo) ListByUserID(_ context.Context, userID string) ([]*Registration, error) {
+	var result []*Registration
+	for _, r := range m.list {
+		if r.UserID == userID {
+			copied := *r
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRegistrationRepo) ListRegistrants(_ context.Context, eventID, sessionID string) ([]*Registrant, error) {
+	var result []*Registrant
+	for _, r := range m.list {
+		if r.EventID == eventID && r.SessionID == sessionID && r.Status != StatusCancelled {
+			result = append(result, &Registrant{Registration: *r, Email: r.UserID + "@example.com"})
+		}
+	}
+	// Как в Postgres: сначала confirmed, затем очередь по времени записи.
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Status == StatusConfirmed && result[j].Status != StatusConfirmed
+	})
+	return result, nil
+}
+
+func (m *mockRegistrationRepo) WaitlistPosition(_ context.Context, reg *Registration) (int, error) {
+	position := 0
+	for _, r := range m.list {
+		if r.EventID == reg.EventID && r.SessionID == reg.SessionID && r.Status == StatusWaitlisted {
+			position++
+			if r.ID == reg.ID {
+				return position, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+func (m *mockRegistrationRepo) CheckInStats(_ context.Context, eventID, sessionID, timezone string) (*CheckInStats, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	stats := &CheckInStats{EventID: eventID, SessionID: sessionID}
+	byDay := map[time.Time]int{}
+	for _, r := range m.list {
+		if r.EventID != eventID || r.SessionID != sessionID || r.Status != StatusConfirmed {
+			continue
+		}
+		stats.Confirmed++
+		if r.CheckedInAt != nil {
+			stats.CheckedIn++
+			local := r.CheckedInAt.In(loc)
+			byDay[time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)]++
+		}
+	}
+	for date, count := range byDay {
+		stats.Days = append(stats.Days, DayCheckIns{Date: date, CheckedIn: count})
+	}
+	sort.Slice(stats.Days, func(i, j int) bool { return stats.Days[i].Date.Before(stats.Days[j].Date) })
+	return stats, nil
+}
+
+func (m *mockRegistrationRepo) GetActive(_ context.Context, userID, eventID, sessionID string) (*Registration, error) {
+	for _, r := range m.list {
+		if r.UserID == userID && r.EventID == eventID && r.SessionID == sessionID && r.Status != StatusCancelled {
+			copied := *r
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRegistrationRepo) CountConfirmed(_ context.Context, eventID, sessionID string) (int, error) {
+	count := 0
+	for _, r := range m.list {
+		if r.EventID == eventID && r.SessionID == sessionID && r.Status == StatusConfirmed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockRegistrationRepo) NextWaitlisted(_ context.Context, eventID, sessionID string) (*Registration, error) {
+	for _, r := range m.list {
+		if r.EventID == eventID && r.SessionID == sessionID && r.Status == StatusWaitlisted {
+			copied := *r
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRegistrationRepo) Create(_ context.Context, reg *Registration) error {
+	m.seq++
+	reg.ID = fmt.Sprintf("reg-%d", m.seq)
+	copied := *reg
+	m.list = append(m.list, &copied)
+	return nil
+}
+
+func (m *mockRegistrationRepo) Update(_ context.Context, reg *Registration) error {
+	for i, r := range m.list {
+		if r.ID == reg.ID {
+			copied := *reg
+			copied.WaitlistPosition = nil
+			m.list[i] = &copied
+			return nil
+		}
+	}
+	return nil
+}
+
+// Организаторы и персонал фикстуры: администратор организации события, редакторы и наблюдатель его команды
+// (глобальная роль не важна), администратор чужой организации и редактор без организации и команды.
+var (
+	organizer = events.Actor{UserID: "organizer-1", Role: auth.RoleOrganizer}
+	staff1    = events.Actor{UserID: "staff-1", Role: auth.RoleStaff}
+	staff2    = events.Actor{UserID: "staff-2", Role: auth.RoleStaff}
+	volunteer = events.Actor{UserID: "volunteer-1", Role: auth.RoleUser}
+	observer  = events.Actor{UserID: "observer-1", Role: auth.RoleUser}
+	outsider  = events.Actor{UserID: "organizer-2", Role: auth.RoleOrganizer}
+	editor    = events.Actor{UserID: "editor-1", Role: auth.RoleEditor}
+)
+
+// newRegistrationsFixture: событие на 2 места, сессия s-1 на 1 место, s-2 без лимита, s-3 скрыта.
+// Права — как у events: organizer — администратор организации события, staff1, staff2 и volunteer — редакторы
+// его команды, observer — наблюдатель, outsider — администратор другой организации.
+func newRegistrationsFixture(t *testing.T) (*Service, *mockRegistrationRepo, *eventstest.EventRepository) {
+	t.Helper()
+	capacity := 2
+	eventsRepo, daysRepo := eventstest.Fixture(t, events.Event{Capacity: &capacity},
+		eventstest.Session("s-1", "Workshop", "10:00", "11:00", eventstest.Capacity(1)),
+		eventstest.Session("s-2", "Keynote", "12:00", "13:00"),
+		eventstest.JuryBriefing("s-3"),
+	)
+	members := &eventstest.MemberRepository{}
+	members.Add(eventstest.EventID, staff1.UserID, events.MemberEditor)
+	members.Add(eventstest.EventID, staff2.UserID, events.MemberEditor)
+	members.Add(eventstest.EventID, volunteer.UserID, events.MemberEditor)
+	members.Add(eventstest.EventID, observer.UserID, events.MemberViewer)
+	orgs := &eventstest.OrganizationDirectory{}
+	orgs.AddMember(eventstest.OrganizationID, organizer.UserID, organizations.RoleAdmin)
+	orgs.AddMember("org-2", outsider.UserID, organizations.RoleAdmin)
+
+	repo := &mockRegistrationRepo{}
+	access := eventstest.NewAccess(eventsRepo, members, orgs)
+	return NewService(repo, eventsRepo, daysRepo, access, NewTicketSigner("test-ticket-secret")), repo, eventsRepo
+}
+
+func TestRegister_WaitlistsWhenFull(t *testing.T) {
+	svc, _, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	for _, userID := range []string{"user-1", "user-2"} {
+		reg, err := svc.Register(ctx, userID, "event-1", "")
+		require.NoError(t, err)
+		assert.Equal(t, StatusConfirmed, reg.Status)
+		assert.Nil(t, reg.WaitlistPosition)
+	}
+
+	reg, err := svc.Register(ctx, "user-3", "event-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, StatusWaitlisted, reg.Status)
+	require.NotNil(t, reg.WaitlistPosition)
+	assert.Equal(t, 1, *reg.WaitlistPosition)
+
+	reg, err = svc.Register(ctx, "user-4", "event-1", "")
+	require.NoError(t, err)
+	require.NotNil(t, reg.WaitlistPosition)
+	assert.Equal(t, 2, *reg.WaitlistPosition)
+}
+
+func TestRegister_Idempotent(t *testing.T) {
+	svc, repo, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	first, err := svc.Register(ctx, "user-1", "event-1", "s-1")
+	require.NoError(t, err)
+	second, err := svc.Register(ctx, "user-1", "event-1", "s-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, first.ID, second.ID)
+	assert.Len(t, repo.list, 1)
+}
+
+func TestRegister_UnpublishedEventClosed(t *testing.T) {
+	svc, _, eventsRepo := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	reg, err := svc.Register(ctx, "user-1", "event-1", "")
+	require.NoError(t, err)
+	event, err := eventsRepo.GetByID(ctx, "event-1")
+	require.NoError(t, err)
+	event.Status = events.EventStatusArchived
+
+	_, err = svc.Register(ctx, "user-2", "event-1", "")
+	require.ErrorIs(t, err, ErrEventNotFound)
+	// Прежние регистрации можно отменить.
+	_, err = svc.Cancel(ctx, "user-1", reg.ID)
+	require.NoError(t, err)
+}
+
+func TestRegister_SessionCapacityAndVisibility(t *testing.T) {
+	svc, _, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	reg, err := svc.Register(ctx, "user-1", "event-1", "s-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusConfirmed, reg.Status)
+
+	// Лимит сессии (1) независим от лимита события (2).
+	reg, err = svc.Register(ctx, "user-2", "event-1", "s-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusWaitlisted, reg.Status)
+
+	// У s-2 лимита нет.
+	for _, userID := range []string{"user-1", "user-2", "user-3"} {
+		reg, err = svc.Register(ctx, userID, "event-1", "s-2")
+		require.NoError(t, err)
+		assert.Equal(t, StatusConfirmed, reg.Status)
+	}
+
+	_, err = svc.Register(ctx, "user-1", "event-1", "s-3")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	_, err = svc.Register(ctx, "user-1", "missing", "")
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestCancel_PromotesWaitlistFIFO(t *testing.T) {
+	svc, repo, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	regs := map[string]*Registration{}
+	for _, userID := range []string{"user-1", "user-2", "user-3", "user-4"} {
+		reg, err := svc.Register(ctx, userID, "event-1", "")
+		require.NoError(t, err)
+		regs[userID] = reg
+	}
+
+	cancelled, err := svc.Cancel(ctx, "user-1", regs["user-1"].ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.CancelledAt)
+
+	promoted, err := repo.GetByID(ctx, regs["user-3"].ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusConfirmed, promoted.Status)
+	assert.NotNil(t, promoted.PromotedAt)
+
+	mine, err := svc.ListMine(ctx, "user-4")
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, StatusWaitlisted, mine[0].Status)
+	require.NotNil(t, mine[0].WaitlistPosition)
+	assert.Equal(t, 1, *mine[0].WaitlistPosition)
+
+	// Повторная отмена — не ошибка и не продвигает очередь второй раз.
+	_, err = svc.Cancel(ctx, "user-1", regs["user-1"].ID)
+	require.NoError(t, err)
+	stillWaiting, err := repo.GetByID(ctx, regs["user-4"].ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusWaitlisted, stillWaiting.Status)
+}
+
+func TestCancel_WaitlistedDoesNotPromote(t *testing.T) {
+	svc, repo, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	regs := map[string]*Registration{}
+	for _, userID := range []string{"user-1", "user-2", "user-3", "user-4"} {
+		reg, err := svc.Register(ctx, userID, "event-1", "")
+		require.NoError(t, err)
+		regs[userID] = reg
+	}
+
+	_, err := svc.Cancel(ctx, "user-3", regs["user-3"].ID)
+	require.NoError(t, err)
+
+	next, err := repo.GetByID(ctx, regs["user-4"].ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusWaitlisted, next.Status)
+	position, err := repo.WaitlistPosition(ctx, next)
+	require.NoError(t, err)
+	assert.Equal(t, 1, position)
+}
+
+func TestCancel_OnlyOwner(t *testing.T) {
+	svc, _, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	reg, err := svc.Register(ctx, "user-1", "event-1", "")
+	require.NoError(t, err)
+
+	_, err = svc.Cancel(ctx, "user-2", reg.ID)
+	require.ErrorIs(t, err, ErrRegistrationNotFound)
+
+	_, err = svc.Cancel(ctx, "user-1", "missing")
+	require.ErrorIs(t, err, ErrRegistrationNotFound)
+}
+
+func TestRegister_ConcurrentDoesNotOverbook(t *testing.T) {
+	svc, repo, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.Register(ctx, fmt.Sprintf("user-%d", i), "event-1", "")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	confirmed, err := repo.CountConfirmed(ctx, "event-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, confirmed)
+}
+
+func TestListRegistrants_AssignsWaitlistPositions(t *testing.T) {
+	svc, _, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	for _, userID := range []string{"user-1", "user-2", "user-3", "user-4"} {
+		_, err := svc.Register(ctx, userID, "event-1", "")
+		require.NoError(t, err)
+	}
+
+	list, err := svc.ListRegistrants(ctx, organizer, "event-1", "")
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	assert.Nil(t, list[1].WaitlistPosition)
+	require.NotNil(t, list[3].WaitlistPosition)
+	assert.Equal(t, 2, *list[3].WaitlistPosition)
+
+	_, err = svc.ListRegistrants(ctx, organizer, "missing", "")
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestTicketSigner_RoundTripAndTamper(t *testing.T) {
+	signer := NewTicketSigner("secret-1")
+	token := signer.Sign(&Registration{ID: "reg-1", EventID: "event-1"})
+
+	regID, eventID, err := signer.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, "reg-1", regID)
+	assert.Equal(t, "event-1", eventID)
+
+	_, _, err = NewTicketSigner("secret-2").Parse(token)
+	require.ErrorIs(t, err, ErrInvalidTicket)
+
+	forged := signer.Sign(&Registration{ID: "reg-2", EventID: "event-1"})
+	payload, _, _ := strings.Cut(forged, ".")
+	_, mac, _ := strings.Cut(token, ".")
+	_, _, err = signer.Parse(payload + "." + mac)
+	require.ErrorIs(t, err, ErrInvalidTicket)
+
+	for _, bad := range []string{"", "garbage", "a.b.c", "."} {
+		_, _, err = signer.Parse(bad)
+		require.ErrorIs(t, err, ErrInvalidTicket, bad)
+	}
+}
+
+func TestTicket_OnlyOwnerAndConfirmed(t *testing.T) {
+	svc, _, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	confirmed, err := svc.Register(ctx, "user-1", "event-1", "s-1")
+	require.NoError(t, err)
+	waitlisted, err := svc.Register(ctx, "user-2", "event-1", "s-1")
+	require.NoError(t, err)
+
+	token, err := svc.Ticket(ctx, "user-1", confirmed.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	_, err = svc.Ticket(ctx, "user-2", confirmed.ID)
+	require.ErrorIs(t, err, ErrRegistrationNotFound)
+
+	_, err = svc.Ticket(ctx, "user-2", waitlisted.ID)
+	require.ErrorIs(t, err, ErrNotConfirmed)
+}
+
+func TestCheckIn_IdempotentAndCounted(t *testing.T) {
+	svc, _, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	reg, err := svc.Register(ctx, "user-1", "event-1", "")
+	require.NoError(t, err)
+	_, err = svc.Register(ctx, "user-2", "event-1", "")
+	require.NoError(t, err)
+	token, err := svc.Ticket(ctx, "user-1", reg.ID)
+	require.NoError(t, err)
+
+	first, already, err := svc.CheckIn(ctx, staff1, "event-1", token)
+	require.NoError(t, err)
+	assert.False(t, already)
+	require.NotNil(t, first.CheckedInAt)
+	assert.Equal(t, "staff-1", *first.CheckedInBy)
+
+	second, already, err := svc.CheckIn(ctx, staff2, "event-1", token)
+	require.NoError(t, err)
+	assert.True(t, already)
+	assert.Equal(t, *first.CheckedInAt, *second.CheckedInAt)
+	assert.Equal(t, "staff-1", *second.CheckedInBy)
+
+	stats, err := svc.CheckInStats(ctx, organizer, "event-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Confirmed)
+	assert.Equal(t, 1, stats.CheckedIn)
+	require.Len(t, stats.Days, 1)
+	assert.Equal(t, 1, stats.Days[0].CheckedIn)
+
+	_, err = svc.CheckInStats(ctx, organizer, "missing", "")
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestCheckIn_RejectsOtherEventAndCancelled(t *testing.T) {
+	svc, _, eventsRepo := newRegistrationsFixture(t)
+	ctx := context.Background()
+	require.NoError(t, eventsRepo.Upsert(context.Background(), &events.Event{ID: "event-2", OrganizationID: eventstest.OrganizationID}))
+
+	reg, err := svc.Register(ctx, "user-1", "event-1", "")
+	require.NoError(t, err)
+	token, err := svc.Ticket(ctx, "user-1", reg.ID)
+	require.NoError(t, err)
+
+	_, _, err = svc.CheckIn(ctx, organizer, "event-2", token)
+	require.ErrorIs(t, err, ErrTicketOtherEvent)
+
+	_, _, err = svc.CheckIn(ctx, staff1, "event-1", "forged.token")
+	require.ErrorIs(t, err, ErrInvalidTicket)
+
+	_, err = svc.Cancel(ctx, "user-1", reg.ID)
+	require.NoError(t, err)
+	_, _, err = svc.CheckIn(ctx, staff1, "event-1", token)
+	require.ErrorIs(t, err, ErrNotConfirmed)
+}
+
+func TestRegistrants_OtherOrganizationForbidden(t *testing.T) {
+	svc, _, eventsRepo := newRegistrationsFixture(t)
+	ctx := context.Background()
+	require.NoError(t, eventsRepo.Upsert(ctx, &events.Event{ID: "event-2", OrganizationID: "org-2"}))
+
+	reg, err := svc.Register(ctx, "user-1", "event-1", "")
+	require.NoError(t, err)
+	token, err := svc.Ticket(ctx, "user-1", reg.ID)
+	require.NoError(t, err)
+
+	// Администратор чужой организации не видит регистрации и не отмечает проход.
+	_, err = svc.ListRegistrants(ctx, outsider, "event-1", "")
+	require.ErrorIs(t, err, events.ErrForbidden)
+	_, err = svc.CheckInStats(ctx, outsider, "event-1", "")
+	require.ErrorIs(t, err, events.ErrForbidden)
+	_, _, err = svc.CheckIn(ctx, outsider, "event-1", token)
+	require.ErrorIs(t, err, events.ErrForbidden)
+
+	// Команда event-1 и администраторы его организации не получают прав на событие другой организации.
+	_, err = svc.ListRegistrants(ctx, staff1, "event-2", "")
+	require.ErrorIs(t, err, events.ErrForbidden)
+	_, err = svc.ListRegistrants(ctx, organizer, "event-2", "")
+	require.ErrorIs(t, err, events.ErrForbidden)
+
+	_, err = svc.ListRegistrants(ctx, outsider, "missing", "")
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestCheckIn_EventTeamRolesNotGlobalRoles(t *testing.T) {
+	svc, _, _ := newRegistrationsFixture(t)
+	ctx := context.Background()
+
+	reg, err := svc.Register(ctx, "user-1", "event-1", "")
+	require.NoError(t, err)
+	token, err := svc.Ticket(ctx, "user-1", reg.ID)
+	require.NoError(t, err)
+
+	// Глобальная роль editor без команды события и организации доступа не даёт.
+	_, err = svc.ListRegistrants(ctx, editor, "event-1", "")
+	require.ErrorIs(t, err, events.ErrForbidden)
+	_, _, err = svc.CheckIn(ctx, editor, "event-1", token)
+	require.ErrorIs(t, err, events.ErrForbidden)
+
+	// Наблюдатель команды читает список и счётчики, но не отмечает проход.
+	_, err = svc.ListRegistrants(ctx, observer, "event-1", "")
+	require.NoError(t, err)
+	_, err = svc.CheckInStats(ctx, observer, "event-1", "")
+	require.NoError(t, err)
+	_, _, err = svc.CheckIn(ctx, observer, "event-1", token)
+	require.ErrorIs(t, err, events.ErrForbidden)
+
+	// Редактор команды с глобальной ролью user отмечает проход.
+	checked, _, err := svc.CheckIn(ctx, volunteer, "event-1", token)
+	require.NoError(t, err)
+	assert.Equal(t, volunteer.UserID, *checked.CheckedInBy)
+}
@@ -0,0 +1,58 @@
+package registrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidTicket — билет повреждён, подделан или не относится ни к одной регистрации.
+var ErrInvalidTicket = errors.New("invalid ticket")
+
+// TicketSigner выпускает и проверяет билеты — подписанные токены регистраций для QR-кода.
+// Формат: base64url("<registrationID>:<eventID>") + "." + base64url(HMAC-SHA256).
+// Угадать билет нельзя без секрета; событие в подписи позволяет отклонить чужой билет без запроса к БД.
+type TicketSigner struct {
+	secret []byte
+}
+
+// NewTicketSigner создаёт подписыватель билетов с секретом из конфига.
+func NewTicketSigner(secret string) *TicketSigner {
+	return &TicketSigner{secret: []byte(secret)}
+}
+
+// Sign возвращает билет для регистрации.
+func (t *TicketSigner) Sign(reg *Registration) string {
+	payload := []byte(reg.ID + ":" + reg.EventID)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(t.mac(payload))
+}
+
+// Parse проверяет подпись билета и возвращает id регистрации и события.
+func (t *TicketSigner) Parse(token string) (registrationID, eventID string, err error) {
+	encodedPayload, encodedMAC, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return "", "", ErrInvalidTicket
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", "", ErrInvalidTicket
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, t.mac(payload)) {
+		return "", "", ErrInvalidTicket
+	}
+	registrationID, eventID, ok = strings.Cut(string(payload), ":")
+	if !ok || registrationID == "" || eventID == "" {
+		return "", "", ErrInvalidTicket
+	}
+	return registrationID, eventID, nil
+}
+
+func (t *TicketSigner) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, t.secret)
+	h.Write([]byte("ticket:"))
+	h.Write(payload)
+	return h.Sum(nil)
+}
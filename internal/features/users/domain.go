@@ -1,6 +1,10 @@
 package users
 
-import "time"
+import (
+	"time"
+
+	"wdpl_back/internal/features/events"
+)
 
 // UserProfile — доменная модель профиля пользователя.
 type UserProfile struct {
@@ -13,3 +17,48 @@ type UserProfile struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
+
+// SessionBookmark — сессия опубликованного события, отмеченная пользователем («звёздочка»).
+// Title/StartsAt/EndsAt — снимок на момент добавления: нужен, если сессию потом удалят из программы.
+type SessionBookmark struct {
+	UserID    string
+	EventID   string
+	SessionID string
+	Title     string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedAt time.Time
+}
+
+// Состояния пункта личной программы относительно текущего опубликованного расписания.
+const (
+	AgendaStateActive    = "active"
+	AgendaStateCancelled = "cancelled"
+	AgendaStateRemoved   = "removed"
+)
+
+// AgendaItem — пункт личной программы: закладка + актуальные данные сессии (если она ещё есть).
+type AgendaItem struct {
+	Bookmark *SessionBookmark
+	Date     *time.Time
+	Session  *events.SessionData
+	State    string
+	// ConflictsWith — id других активных сессий программы, пересекающихся по времени с этой.
+	ConflictsWith []string
+}
+
+// StartsAt — актуальное начало сессии, для удалённой — из снимка.
+func (i *AgendaItem) StartsAt() time.Time {
+	if i.Session != nil {
+		return i.Session.StartsAt
+	}
+	return i.Bookmark.StartsAt
+}
+
+// EndsAt — актуальный конец сессии, для удалённой — из снимка.
+func (i *AgendaItem) EndsAt() time.Time {
+	if i.Session != nil {
+		return i.Session.EndsAt
+	}
+	return i.Bookmark.EndsAt
+}
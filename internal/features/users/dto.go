@@ -1,14 +1,21 @@
 package users
 
+import (
+	"time"
+
+	"wdpl_back/internal/features/events"
+)
+
 // UserProfileResponse — ответ с профилем пользователя (GET /api/users/me, PUT /api/users/me).
 type UserProfileResponse struct {
-	UserID      string  `json:"userID"`
-	Email       string  `json:"email"`
-	DisplayName string  `json:"displayName"`
-	AvatarURL   *string `json:"avatarURL,omitempty"`
-	Bio         *string `json:"bio,omitempty"`
-	Locale      string  `json:"locale"`
-	Timezone    string  `json:"timezone"`
+	UserID        string  `json:"userID"`
+	Email         string  `json:"email"`
+	EmailVerified bool    `json:"emailVerified"`
+	DisplayName   string  `json:"displayName"`
+	AvatarURL     *string `json:"avatarURL,omitempty"`
+	Bio           *string `json:"bio,omitempty"`
+	Locale        string  `json:"locale"`
+	Timezone      string  `json:"timezone"`
 }
 
 // UpdateProfileRequest — тело запроса PUT /api/users/me.
@@ -19,3 +26,24 @@ type UpdateProfileRequest struct {
 	Locale      *string `json:"locale"`
 	Timezone    *string `json:"timezone"`
 }
+
+// AddBookmarkRequest — тело POST /api/users/me/agenda.
+type AddBookmarkRequest struct {
+	EventID   string `json:"eventId" validate:"required"`
+	SessionID string `json:"sessionId" validate:"required"`
+}
+
+// AgendaItemResponse — пункт личной программы (GET /api/users/me/agenda).
+// title/startsAt/endsAt — актуальные, для удалённой сессии — на момент добавления в программу.
+type AgendaItemResponse struct {
+	EventID       string              `json:"eventId"`
+	SessionID     string              `json:"sessionId"`
+	State         string              `json:"state"`
+	Title         string              `json:"title"`
+	StartsAt      time.Time           `json:"startsAt"`
+	EndsAt        time.Time           `json:"endsAt"`
+	Date          *time.Time          `json:"date,omitempty"`
+	Session       *events.SessionData `json:"session,omitempty"`
+	ConflictsWith []string            `json:"conflictsWith"`
+	BookmarkedAt  time.Time           `json:"bookmarkedAt"`
+}
@@ -1,6 +1,7 @@
 package users
 
 import (
+	"errors"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -39,17 +40,21 @@ func (h *Handler) getClaims(c *fiber.Ctx) (*authutils.UserClaims, bool) {
 	return claims, ok
 }
 
-// profileToResponse собирает ответ из профиля и email (email из auth.users).
-func profileToResponse(profile *UserProfile, email string) UserProfileResponse {
-	return UserProfileResponse{
+// profileToResponse собирает ответ из профиля и пользователя (email и его подтверждение из auth.users).
+func profileToResponse(profile *UserProfile, user *auth.User) UserProfileResponse {
+	resp := UserProfileResponse{
 		UserID:      profile.UserID,
-		Email:       email,
 		DisplayName: profile.DisplayName,
 		AvatarURL:   profile.AvatarURL,
 		Bio:         profile.Bio,
 		Locale:      profile.Locale,
 		Timezone:    profile.Timezone,
 	}
+	if user != nil {
+		resp.Email = user.Email
+		resp.EmailVerified = user.EmailVerified()
+	}
+	return resp
 }
 
 // GetMe — GET /api/users/me. Профиль текущего пользователя по JWT.
@@ -75,7 +80,7 @@ func (h *Handler) GetMe(c *fiber.Ctx) error {
 	if err != nil {
 		return response.WriteInternalError(c, err)
 	}
-	return c.JSON(profileToResponse(profile, user.Email))
+	return c.JSON(profileToResponse(profile, user))
 }
 
 // PutMe — PUT /api/users/me. Обновление профиля текущего пользователя.
@@ -113,9 +118,106 @@ func (h *Handler) PutMe(c *fiber.Ctx) error {
 	if err != nil {
 		return response.WriteInternalError(c, err)
 	}
-	email := ""
-	if user != nil {
-		email = user.Email
+	return c.JSON(profileToResponse(profile, user))
+}
+
+// AgendaHandler реализует HTTP-эндпоинты личной программы (/api/users/me/agenda).
+type AgendaHandler struct {
+	agenda   *AgendaService
+	validate *validator.Validate
+}
+
+// NewAgendaHandler создаёт handler личной программы.
+func NewAgendaHandler(agenda *AgendaService) *AgendaHandler {
+	return &AgendaHandler{
+		agenda:   agenda,
+		validate: validator.New(),
+	}
+}
+
+// GetAgenda — GET /api/users/me/agenda. Отмеченные сессии по времени, с пометками конфликтов и отмен.
+func (h *AgendaHandler) GetAgenda(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	items, err := h.agenda.Agenda(ctx, claims.UserID)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	resp := make([]AgendaItemResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, agendaItemToResponse(item))
+	}
+	return c.JSON(resp)
+}
+
+// AddBookmark — POST /api/users/me/agenda. Отметить сессию опубликованного события.
+func (h *AgendaHandler) AddBookmark(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	var req AddBookmarkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if _, err := h.agenda.AddBookmark(ctx, claims.UserID, req.EventID, req.SessionID); err != nil {
+		if errors.Is(err, ErrEventNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "event not found")
+		}
+		if errors.Is(err, ErrSessionNotFound) {
+			return response.WriteError(c, fiber.StatusNotFound, "session not found")
+		}
+		return response.WriteInternalError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RemoveBookmark — DELETE /api/users/me/agenda/:eventId/:sessionId.
+func (h *AgendaHandler) RemoveBookmark(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	eventID := c.Params("eventId")
+	sessionID := c.Params("sessionId")
+	if eventID == "" || sessionID == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := h.agenda.RemoveBookmark(ctx, claims.UserID, eventID, sessionID); err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func agendaItemToResponse(item *AgendaItem) AgendaItemResponse {
+	title := item.Bookmark.Title
+	if item.Session != nil {
+		title = item.Session.Title
+	}
+	return AgendaItemResponse{
+		EventID:       item.Bookmark.EventID,
+		SessionID:     item.Bookmark.SessionID,
+		State:         item.State,
+		Title:         title,
+		StartsAt:      item.StartsAt(),
+		EndsAt:        item.EndsAt(),
+		Date:          item.Date,
+		Session:       item.Session,
+		ConflictsWith: item.ConflictsWith,
+		BookmarkedAt:  item.Bookmark.CreatedAt,
 	}
-	return c.JSON(profileToResponse(profile, email))
 }
@@ -31,6 +31,15 @@ func (m *mockAuthUserRepo) GetUserByID(_ context.Context, userID string) (*auth.
 	}
 	return nil, nil
 }
+func (m *mockAuthUserRepo) LockUser(ctx context.Context, userID string) (*auth.User, error) {
+	return m.GetUserByID(ctx, userID)
+}
+func (m *mockAuthUserRepo) LockActiveAdmins(_ context.Context) ([]string, error) { return nil, nil }
+func (m *mockAuthUserRepo) ListUsers(_ context.Context, _ auth.UserFilter) ([]*auth.User, error) {
+	return nil, nil
+}
+func (m *mockAuthUserRepo) UpdateUser(_ context.Context, _ *auth.User) error      { return nil }
+func (m *mockAuthUserRepo) BumpTokenGeneration(_ context.Context, _ string) error { return nil }
 
 func testUsersHandlerConfig(t *testing.T) *config.Config {
 	t.Helper()
@@ -75,6 +84,7 @@ func TestHandler_GetMe_Success(t *testing.T) {
 	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
 	require.Equal(t, "user-me-1", body.UserID)
 	require.Equal(t, "me@example.com", body.Email)
+	require.False(t, body.EmailVerified)
 	// При первом запросе профиль создаётся с displayName = email.
 	require.Equal(t, "me@example.com", body.DisplayName)
 }
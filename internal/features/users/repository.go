@@ -8,3 +8,11 @@ type ProfileRepository interface {
 	Create(ctx context.Context, profile *UserProfile) error
 	Update(ctx context.Context, profile *UserProfile) error
 }
+
+// BookmarkRepository описывает операции с таблицей session_bookmarks.
+type BookmarkRepository interface {
+	ListByUserID(ctx context.Context, userID string) ([]*SessionBookmark, error)
+	// Add идемпотентен: повторная отметка той же сессии не ошибка.
+	Add(ctx context.Context, bookmark *SessionBookmark) error
+	Remove(ctx context.Context, userID, eventID, sessionID string) error
+}
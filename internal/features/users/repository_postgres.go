@@ -95,3 +95,62 @@ func (r *postgresProfileRepo) Update(ctx context.Context, profile *UserProfile)
 	)
 	return err
 }
+
+type postgresBookmarkRepo struct {
+	db *postgres.DB
+}
+
+// NewPostgresBookmarkRepository возвращает реализацию BookmarkRepository для PostgreSQL.
+func NewPostgresBookmarkRepository(db *postgres.DB) BookmarkRepository {
+	return &postgresBookmarkRepo{db: db}
+}
+
+func (r *postgresBookmarkRepo) ListByUserID(ctx context.Context, userID string) ([]*SessionBookmark, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id, event_id, session_id, session_title, session_starts_at, session_ends_at, created_at
+		FROM public.session_bookmarks
+		WHERE user_id = $1
+		ORDER BY session_starts_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*SessionBookmark
+	for rows.Next() {
+		var b SessionBookmark
+		if err := rows.Scan(&b.UserID, &b.EventID, &b.SessionID, &b.Title, &b.StartsAt, &b.EndsAt, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &b)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresBookmarkRepo) Add(ctx context.Context, bookmark *SessionBookmark) error {
+	if bookmark.CreatedAt.IsZero() {
+		bookmark.CreatedAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO public.session_bookmarks (user_id, event_id, session_id, session_title, session_starts_at, session_ends_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, event_id, session_id) DO NOTHING
+	`,
+		bookmark.UserID,
+		bookmark.EventID,
+		bookmark.SessionID,
+		bookmark.Title,
+		bookmark.StartsAt,
+		bookmark.EndsAt,
+		bookmark.CreatedAt,
+	)
+	return err
+}
+
+func (r *postgresBookmarkRepo) Remove(ctx context.Context, userID, eventID, sessionID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM public.session_bookmarks
+		WHERE user_id = $1 AND event_id = $2 AND session_id = $3
+	`, userID, eventID, sessionID)
+	return err
+}
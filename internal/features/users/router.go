@@ -4,20 +4,28 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/features/events"
 	"wdpl_back/internal/shared/config"
 	"wdpl_back/internal/shared/http/middleware"
 	"wdpl_back/internal/shared/postgres"
 )
 
 // RegisterRoutes вешает эндпоинты профиля пользователя на api (под /api/users).
-// Требуется авторизация (JWT) для GET/PUT /me.
+// Требуется авторизация (JWT) для GET/PUT /me и личной программы /me/agenda.
 func RegisterRoutes(api fiber.Router, db *postgres.DB, cfg *config.Config) {
 	authRepo := auth.NewPostgresRepository(db)
 	profileRepo := NewPostgresProfileRepository(db)
 	svc := NewService(profileRepo)
 	h := NewHandler(svc, authRepo)
 
+	eventsRepos := events.NewPostgresRepository(db)
+	agenda := NewAgendaService(NewPostgresBookmarkRepository(db), eventsRepos.Events, eventsRepos.Days)
+	agendaHandler := NewAgendaHandler(agenda)
+
 	g := api.Group("/users")
 	g.Get("/me", middleware.RequireAuth(cfg), h.GetMe)
 	g.Put("/me", middleware.RequireAuth(cfg), h.PutMe)
+	g.Get("/me/agenda", middleware.RequireAuth(cfg), agendaHandler.GetAgenda)
+	g.Post("/me/agenda", middleware.RequireAuth(cfg), agendaHandler.AddBookmark)
+	g.Delete("/me/agenda/:eventId/:sessionId", middleware.RequireAuth(cfg), agendaHandler.RemoveBookmark)
 }
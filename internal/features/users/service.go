@@ -2,7 +2,11 @@ package users
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"time"
+
+	"wdpl_back/internal/features/events"
 )
 
 // UpdateProfileInput — входные данные для обновления профиля (слой сервиса).
@@ -86,3 +90,167 @@ func (s *Service) Update(ctx context.Context, userID string, input UpdateProfile
 	}
 	return profile, nil
 }
+
+var (
+	ErrEventNotFound   = errors.New("event not found")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// AgendaService — личная программа пользователя. Расписания читает из опубликованных
+// событий через репозитории фичи events (черновики сюда не попадают).
+type AgendaService struct {
+	bookmarks  BookmarkRepository
+	eventsRepo events.EventRepository
+	daysRepo   events.EventDayRepository
+}
+
+// NewAgendaService создаёт сервис личной программы.
+func NewAgendaService(bookmarks BookmarkRepository, eventsRepo events.EventRepository, daysRepo events.EventDayRepository) *AgendaService {
+	return &AgendaService{
+		bookmarks:  bookmarks,
+		eventsRepo: eventsRepo,
+		daysRepo:   daysRepo,
+	}
+}
+
+// AddBookmark отмечает сессию опубликованного события. Отмечать можно только публично видимые сессии;
+// событие, снятое с публикации (archived), — как несуществующее.
+func (s *AgendaService) AddBookmark(ctx context.Context, userID, eventID, sessionID string) (*SessionBookmark, error) {
+	event, err := s.eventsRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil || event.IsArchived() {
+		return nil, ErrEventNotFound
+	}
+	sessions, err := s.publishedSessions(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	found, ok := sessions[sessionID]
+	if !ok || !found.session.IsPubliclyVisible() {
+		return nil, ErrSessionNotFound
+	}
+
+	bookmark := &SessionBookmark{
+		UserID:    userID,
+		EventID:   eventID,
+		SessionID: sessionID,
+		Title:     found.session.Title,
+		StartsAt:  found.session.StartsAt,
+		EndsAt:    found.session.EndsAt,
+		CreatedAt: time.Now(),
+	}
+	if err := s.bookmarks.Add(ctx, bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+// RemoveBookmark снимает отметку (идемпотентно).
+func (s *AgendaService) RemoveBookmark(ctx context.Context, userID, eventID, sessionID string) error {
+	return s.bookmarks.Remove(ctx, userID, eventID, sessionID)
+}
+
+// Agenda собирает личную программу, отсортированную по времени начала.
+// Закладки сверяются с текущим опубликованным расписанием: отменённые сессии помечаются cancelled,
+// исчезнувшие, скрытые от публики или из снятого с публикации события — removed. Пересечения считаются
+// только между активными пунктами.
+func (s *AgendaService) Agenda(ctx context.Context, userID string) ([]*AgendaItem, error) {
+	bookmarks, err := s.bookmarks.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byEvent := make(map[string]map[string]scheduledSession)
+	items := make([]*AgendaItem, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		sessions, ok := byEvent[b.EventID]
+		if !ok {
+			sessions, err = s.publishedSessions(ctx, b.EventID)
+			if err != nil {
+				return nil, err
+			}
+			byEvent[b.EventID] = sessions
+		}
+
+		item := &AgendaItem{Bookmark: b, State: AgendaStateRemoved, ConflictsWith: []string{}}
+		if found, ok := sessions[b.SessionID]; ok {
+			session := found.session
+			date := found.date
+			item.Session = &session
+			item.Date = &date
+			switch {
+			case session.Status == events.SessionStatusCancelled:
+				item.State = AgendaStateCancelled
+			case session.IsPubliclyVisible():
+				item.State = AgendaStateActive
+			default:
+				// Сессию вернули в planned или сделали непубличной — для участника её больше нет.
+				item.Session = nil
+				item.Date = nil
+			}
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].StartsAt().Before(items[j].StartsAt())
+	})
+	markConflicts(items)
+	return items, nil
+}
+
+// scheduledSession — сессия из опубликованного расписания и дата её дня.
+type scheduledSession struct {
+	session events.SessionData
+	date    time.Time
+}
+
+// publishedSessions индексирует сессии всех опубликованных дней события по id. У события, которого нет
+// или которое снято с публикации (archived), сессий нет.
+func (s *AgendaService) publishedSessions(ctx context.Context, eventID string) (map[string]scheduledSession, error) {
+	event, err := s.eventsRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil || event.IsArchived() {
+		return map[string]scheduledSession{}, nil
+	}
+	days, err := s.daysRepo.ListByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]scheduledSession)
+	for _, day := range days {
+		schedule, err := day.ParseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range schedule.Sessions {
+			index[session.ID] = scheduledSession{session: session, date: day.Date}
+		}
+	}
+	return index, nil
+}
+
+// markConflicts проставляет ConflictsWith для пересекающихся активных пунктов.
+// items отсортированы по началу, поэтому для каждого пункта достаточно смотреть вперёд,
+// пока следующие начинаются раньше его конца. Стык (конец = начало) конфликтом не считается.
+func markConflicts(items []*AgendaItem) {
+	for i, a := range items {
+		if a.State != AgendaStateActive {
+			continue
+		}
+		for _, b := range items[i+1:] {
+			if !b.StartsAt().Before(a.EndsAt()) {
+				break
+			}
+			if b.State != AgendaStateActive {
+				continue
+			}
+			a.ConflictsWith = append(a.ConflictsWith, b.Bookmark.SessionID)
+			b.ConflictsWith = append(b.ConflictsWith, a.Bookmark.SessionID)
+		}
+	}
+}
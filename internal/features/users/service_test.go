@@ -6,6 +6,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/events/eventstest"
 )
 
 // mockProfileRepo — in-memory реализация ProfileRepository для TDD.
@@ -103,3 +106,144 @@ func TestUpdate_UpdatesOnlyProvidedFields(t *testing.T) {
 	assert.Equal(t, "ru", profile.Locale)
 	assert.Equal(t, "UTC", profile.Timezone)
 }
+
+// mockBookmarkRepo — in-memory реализация BookmarkRepository.
+type mockBookmarkRepo struct {
+	list []*SessionBookmark
+}
+
+func (m *mockBookmarkRepo) ListByUserID(_ context.Context, userID string) ([]*SessionBookmark, error) {
+	var result []*SessionBookmark
+	for _, b := range m.list {
+		if b.UserID == userID {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockBookmarkRepo) Add(_ context.Context, bookmark *SessionBookmark) error {
+	for _, b := range m.list {
+		if b.UserID == bookmark.UserID && b.EventID == bookmark.EventID && b.SessionID == bookmark.SessionID {
+			return nil
+		}
+	}
+	m.list = append(m.list, bookmark)
+	return nil
+}
+
+func (m *mockBookmarkRepo) Remove(_ context.Context, userID, eventID, sessionID string) error {
+	for i, b := range m.list {
+		if b.UserID == userID && b.EventID == eventID && b.SessionID == sessionID {
+			m.list = append(m.list[:i], m.list[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func newAgendaFixture(t *testing.T) (*AgendaService, *mockBookmarkRepo, *eventstest.DayRepository) {
+	t.Helper()
+	eventsRepo, daysRepo := eventstest.Fixture(t, events.Event{},
+		eventstest.Session("s-1", "Keynote", "10:00", "11:00"),
+		eventstest.Session("s-2", "Workshop", "10:30", "12:00"),
+		eventstest.Session("s-3", "Lunch talk", "12:00", "13:00"),
+		eventstest.JuryBriefing("s-4"),
+	)
+	bookmarks := &mockBookmarkRepo{}
+	return NewAgendaService(bookmarks, eventsRepo, daysRepo), bookmarks, daysRepo
+}
+
+func TestAddBookmark_OnlyPublicSessions(t *testing.T) {
+	svc, bookmarks, _ := newAgendaFixture(t)
+	ctx := context.Background()
+
+	b, err := svc.AddBookmark(ctx, "user-1", "event-1", "s-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Keynote", b.Title)
+
+	_, err = svc.AddBookmark(ctx, "user-1", "event-1", "s-4")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	_, err = svc.AddBookmark(ctx, "user-1", "missing", "s-1")
+	require.ErrorIs(t, err, ErrEventNotFound)
+
+	assert.Len(t, bookmarks.list, 1)
+}
+
+func TestAgenda_SortedWithConflicts(t *testing.T) {
+	svc, _, _ := newAgendaFixture(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"s-3", "s-2", "s-1"} {
+		_, err := svc.AddBookmark(ctx, "user-1", "event-1", id)
+		require.NoError(t, err)
+	}
+
+	items, err := svc.Agenda(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, "s-1", items[0].Bookmark.SessionID)
+	assert.Equal(t, []string{"s-2"}, items[0].ConflictsWith)
+	assert.Equal(t, []string{"s-1"}, items[1].ConflictsWith)
+	// s-3 начинается ровно в конце s-2 — это не конфликт.
+	assert.Empty(t, items[2].ConflictsWith)
+}
+
+func TestAgenda_FlagsCancelledAndRemovedAfterRepublish(t *testing.T) {
+	svc, _, daysRepo := newAgendaFixture(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"s-1", "s-2", "s-3"} {
+		_, err := svc.AddBookmark(ctx, "user-1", "event-1", id)
+		require.NoError(t, err)
+	}
+
+	// Перепубликация: s-1 отменена, s-2 удалена, s-3 перенесена (id тот же).
+	daysRepo.Days[0] = eventstest.Day(t,
+		eventstest.Session("s-1", "Keynote", "10:00", "11:00", eventstest.Status(events.SessionStatusCancelled)),
+		eventstest.Session("s-3", "Lunch talk", "10:15", "10:45"),
+	)
+
+	items, err := svc.Agenda(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	states := map[string]string{}
+	for _, item := range items {
+		states[item.Bookmark.SessionID] = item.State
+		// Отменённые и удалённые не участвуют в конфликтах.
+		assert.Empty(t, item.ConflictsWith)
+	}
+	assert.Equal(t, AgendaStateCancelled, states["s-1"])
+	assert.Equal(t, AgendaStateRemoved, states["s-2"])
+	assert.Equal(t, AgendaStateActive, states["s-3"])
+
+	for _, item := range items {
+		if item.Bookmark.SessionID == "s-2" {
+			assert.Nil(t, item.Session)
+			assert.Equal(t, "Workshop", item.Bookmark.Title)
+		}
+	}
+}
+
+func TestAgenda_UnpublishedEventRemoved(t *testing.T) {
+	svc, _, _ := newAgendaFixture(t)
+	ctx := context.Background()
+	_, err := svc.AddBookmark(ctx, "user-1", eventstest.EventID, "s-1")
+	require.NoError(t, err)
+
+	event, err := svc.eventsRepo.GetByID(ctx, eventstest.EventID)
+	require.NoError(t, err)
+	event.Status = events.EventStatusArchived
+
+	_, err = svc.AddBookmark(ctx, "user-1", eventstest.EventID, "s-3")
+	require.ErrorIs(t, err, ErrEventNotFound)
+
+	items, err := svc.Agenda(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, AgendaStateRemoved, items[0].State)
+	assert.Nil(t, items[0].Session)
+	assert.Equal(t, "Keynote", items[0].Bookmark.Title)
+}
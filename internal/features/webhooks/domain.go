@@ -0,0 +1,100 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"wdpl_back/internal/features/events"
+)
+
+// Subscription — подписка партнёра на доменные события. Secret — ключ HMAC-подписи доставок.
+type Subscription struct {
+	ID          string
+	URL         string
+	Secret      string
+	EventTypes  []string
+	IsActive    bool
+	Description *string
+	CreatedBy   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Matches — подписана ли подписка на тип события.
+func (s *Subscription) Matches(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Статусы доставки.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Delivery — доставка одного доменного события одной подписке. Body — готовое тело запроса:
+// повторы и ручной replay отправляют те же байты (подпись считается заново на каждую попытку).
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	MessageID      int64
+	EventType      string
+	Body           json.RawMessage
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// Attempt — запись журнала доставки: одна HTTP-попытка.
+type Attempt struct {
+	ID         int64
+	DeliveryID string
+	// Attempt — номер попытки в серии; после replay счёт начинается заново.
+	Attempt      int
+	StatusCode   *int
+	Error        *string
+	ResponseBody *string
+	DurationMS   int64
+	CreatedAt    time.Time
+}
+
+// Succeeded — ответ 2xx.
+func (a *Attempt) Succeeded() bool {
+	return a.Error == nil && a.StatusCode != nil && *a.StatusCode >= 200 && *a.StatusCode < 300
+}
+
+// EventTypes — доменные события, на которые можно подписаться.
+var EventTypes = []string{
+	events.DomainEventPublished,
+	events.DomainEventUnpublished,
+	events.DomainEventDayChanged,
+	events.DomainDraftSaved,
+}
+
+// IsKnownEventType — есть ли такой тип среди EventTypes.
+func IsKnownEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Envelope — тело запроса доставки: тип и payload доменного события.
+// ID — id сообщения outbox: по нему получатель отбрасывает дубли (доставка «хотя бы один раз»).
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
@@ -0,0 +1,72 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CreateSubscriptionRequest — тело POST /api/webhooks. Без secret сервер сгенерирует его сам.
+type CreateSubscriptionRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes  []string `json:"eventTypes" validate:"required,min=1"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16,max=256"`
+	Description *string  `json:"description" validate:"omitempty,max=500"`
+}
+
+// UpdateSubscriptionRequest — тело PATCH /api/webhooks/:id. Отсутствующие поля не меняются.
+type UpdateSubscriptionRequest struct {
+	URL         *string  `json:"url" validate:"omitempty,url,max=2048"`
+	EventTypes  []string `json:"eventTypes" validate:"omitempty,min=1"`
+	IsActive    *bool    `json:"isActive"`
+	Description *string  `json:"description" validate:"omitempty,max=500"`
+}
+
+// SubscriptionResponse — подписка в ответе API. Секрет не возвращается.
+type SubscriptionResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"eventTypes"`
+	IsActive    bool      `json:"isActive"`
+	Description *string   `json:"description,omitempty"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// CreatedSubscriptionResponse — ответ на создание: единственный раз, когда виден секрет.
+type CreatedSubscriptionResponse struct {
+	SubscriptionResponse
+	Secret string `json:"secret"`
+}
+
+// DeliveryResponse — доставка в ответе API.
+type DeliveryResponse struct {
+	ID             string     `json:"id"`
+	SubscriptionID string     `json:"subscriptionId"`
+	MessageID      int64      `json:"messageId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty"`
+	LastStatusCode *int       `json:"lastStatusCode,omitempty"`
+	LastError      *string    `json:"lastError,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// DeliveryDetailResponse — доставка с телом запроса и журналом попыток.
+type DeliveryDetailResponse struct {
+	DeliveryResponse
+	Body     json.RawMessage   `json:"body"`
+	Attempts []AttemptResponse `json:"attemptLog"`
+}
+
+// AttemptResponse — одна попытка доставки.
+type AttemptResponse struct {
+	Attempt      int       `json:"attempt"`
+	StatusCode   *int      `json:"statusCode,omitempty"`
+	Error        *string   `json:"error,omitempty"`
+	ResponseBody *string   `json:"responseBody,omitempty"`
+	DurationMS   int64     `json:"durationMs"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
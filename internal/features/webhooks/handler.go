@@ -0,0 +1,258 @@
+package webhooks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/shared/http/handler"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/http/response"
+)
+
+// Лимиты списка доставок (?limit=).
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 200
+)
+
+// Handler реализует HTTP-эндпоинты управления вебхуками (только admin).
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+// NewHandler создаёт handler вебхуков.
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Create — POST /api/webhooks. Ответ содержит секрет подписи — больше его не покажут.
+func (h *Handler) Create(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return response.WriteError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	var req CreateSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	sub, err := h.service.Create(ctx, CreateInput{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+		Description: req.Description,
+	}, claims.UserID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(CreatedSubscriptionResponse{
+		SubscriptionResponse: subscriptionToResponse(sub),
+		Secret:               sub.Secret,
+	})
+}
+
+// List — GET /api/webhooks.
+func (h *Handler) List(c *fiber.Ctx) error {
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	subs, err := h.service.List(ctx)
+	if err != nil {
+		return response.WriteInternalError(c, err)
+	}
+	resp := make([]SubscriptionResponse, 0, len(subs))
+	for _, sub := range subs {
+		resp = append(resp, subscriptionToResponse(sub))
+	}
+	return c.JSON(resp)
+}
+
+// Get — GET /api/webhooks/:id.
+func (h *Handler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	sub, err := h.service.Get(ctx, id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(subscriptionToResponse(sub))
+}
+
+// Update — PATCH /api/webhooks/:id. Адрес, типы событий, isActive, описание.
+func (h *Handler) Update(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	var req UpdateSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "invalid body")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return response.WriteError(c, fiber.StatusBadRequest, "validation failed")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	sub, err := h.service.Update(ctx, id, UpdateInput{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		IsActive:    req.IsActive,
+		Description: req.Description,
+	})
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(subscriptionToResponse(sub))
+}
+
+// Delete — DELETE /api/webhooks/:id. Удаляет подписку и журнал её доставок.
+func (h *Handler) Delete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListDeliveries — GET /api/webhooks/:id/deliveries?limit=. Последние доставки, новые первыми.
+func (h *Handler) ListDeliveries(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	limit := c.QueryInt("limit", defaultDeliveriesLimit)
+	if limit <= 0 || limit > maxDeliveriesLimit {
+		limit = defaultDeliveriesLimit
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	list, err := h.service.ListDeliveries(ctx, id, limit)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := make([]DeliveryResponse, 0, len(list))
+	for _, d := range list {
+		resp = append(resp, deliveryToResponse(d))
+	}
+	return c.JSON(resp)
+}
+
+// GetDelivery — GET /api/webhooks/deliveries/:deliveryId. Тело запроса и журнал попыток с кодами ответа.
+func (h *Handler) GetDelivery(c *fiber.Ctx) error {
+	id := c.Params("deliveryId")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	d, attempts, err := h.service.GetDelivery(ctx, id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	resp := DeliveryDetailResponse{
+		DeliveryResponse: deliveryToResponse(d),
+		Body:             d.Body,
+		Attempts:         make([]AttemptResponse, 0, len(attempts)),
+	}
+	for _, a := range attempts {
+		resp.Attempts = append(resp.Attempts, AttemptResponse{
+			Attempt:      a.Attempt,
+			StatusCode:   a.StatusCode,
+			Error:        a.Error,
+			ResponseBody: a.ResponseBody,
+			DurationMS:   a.DurationMS,
+			CreatedAt:    a.CreatedAt,
+		})
+	}
+	return c.JSON(resp)
+}
+
+// Replay — POST /api/webhooks/deliveries/:deliveryId/replay. 202 — доставка снова в очереди.
+func (h *Handler) Replay(c *fiber.Ctx) error {
+	id := c.Params("deliveryId")
+	if id == "" {
+		return response.WriteError(c, fiber.StatusBadRequest, "missing id")
+	}
+	ctx, cancel := handler.TimeoutContext(c, 5*time.Second)
+	defer cancel()
+
+	d, err := h.service.Replay(ctx, id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(deliveryToResponse(d))
+}
+
+func writeServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrSubscriptionNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "webhook not found")
+	case errors.Is(err, ErrDeliveryNotFound):
+		return response.WriteError(c, fiber.StatusNotFound, "delivery not found")
+	case errors.Is(err, ErrDeliveryPending):
+		return response.WriteError(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrInvalidEventTypes), errors.Is(err, ErrSecretTooShort):
+		return response.WriteError(c, fiber.StatusBadRequest, err.Error())
+	default:
+		return response.WriteInternalError(c, err)
+	}
+}
+
+func subscriptionToResponse(sub *Subscription) SubscriptionResponse {
+	return SubscriptionResponse{
+		ID:          sub.ID,
+		URL:         sub.URL,
+		EventTypes:  sub.EventTypes,
+		IsActive:    sub.IsActive,
+		Description: sub.Description,
+		CreatedBy:   sub.CreatedBy,
+		CreatedAt:   sub.CreatedAt,
+		UpdatedAt:   sub.UpdatedAt,
+	}
+}
+
+func deliveryToResponse(d *Delivery) DeliveryResponse {
+	resp := DeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		MessageID:      d.MessageID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+	if d.Status == DeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/authutils/authtest"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+)
+
+// newTestWebhooksApp поднимает маршруты вебхуков поверх моков (без БД).
+func newTestWebhooksApp(t *testing.T) (*fiber.App, *config.Config) {
+	t.Helper()
+	cfg := &config.Config{
+		JWTSecret:         "test-jwt-secret-at-least-32-bytes-for-webhooks",
+		AccessTokenTTLMin: 15,
+	}
+	deliveries := &mockDeliveryRepo{now: time.Now}
+	h := NewHandler(NewService(&mockSubscriptionRepo{}, deliveries, nil, slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	requireAuth := middleware.RequireAuth(cfg)
+	requireAdmin := middleware.RequireRole(AdminRoles...)
+	app := fiber.New()
+	app.Post("/api/webhooks", requireAuth, requireAdmin, h.Create)
+	app.Get("/api/webhooks", requireAuth, requireAdmin, h.List)
+	app.Get("/api/webhooks/:id", requireAuth, requireAdmin, h.Get)
+	app.Patch("/api/webhooks/:id", requireAuth, requireAdmin, h.Update)
+	app.Delete("/api/webhooks/:id", requireAuth, requireAdmin, h.Delete)
+	return app, cfg
+}
+
+func TestHandler_AdminOnly(t *testing.T) {
+	app, cfg := newTestWebhooksApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "GET", "/api/webhooks", "user-1", "editor", ""))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+	res, err = app.Test(httptest.NewRequest("GET", "/api/webhooks", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+}
+
+func TestHandler_CreateShowsSecretOnce(t *testing.T) {
+	app, cfg := newTestWebhooksApp(t)
+
+	res, err := app.Test(authtest.NewRequest(t, cfg, "POST", "/api/webhooks", "user-1", "admin",
+		`{"url":"https://tickets.example.com/hooks","eventTypes":["event.published","event.day.changed"]}`))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, res.StatusCode)
+	var created CreatedSubscriptionResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&created))
+	assert.NotEmpty(t, created.Secret)
+	assert.True(t, created.IsActive)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/webhooks/"+created.ID, "user-1", "admin", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	raw, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), created.Secret)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "PATCH", "/api/webhooks/"+created.ID, "user-1", "admin", `{"eventTypes":["event.deleted"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "DELETE", "/api/webhooks/"+created.ID, "user-1", "admin", ""))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, res.StatusCode)
+
+	res, err = app.Test(authtest.NewRequest(t, cfg, "GET", "/api/webhooks/"+created.ID, "user-1", "admin", ""))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+}
@@ -0,0 +1,36 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+)
+
+// SubscriptionRepository описывает хранение подписок.
+type SubscriptionRepository interface {
+	Create(ctx context.Context, sub *Subscription) error
+	GetByID(ctx context.Context, id string) (*Subscription, error)
+	List(ctx context.Context) ([]*Subscription, error)
+	Update(ctx context.Context, sub *Subscription) error
+	// Delete удаляет подписку вместе с журналом доставок. false — подписки не было.
+	Delete(ctx context.Context, id string) (bool, error)
+	// ListActiveByEventType — активные подписки на тип события.
+	ListActiveByEventType(ctx context.Context, eventType string) ([]*Subscription, error)
+}
+
+// DeliveryRepository описывает очередь и журнал доставок.
+type DeliveryRepository interface {
+	// CreateForMessage ставит доставки в очередь. Повтор для той же пары (подписка, сообщение)
+	// игнорируется: outbox может передать сообщение повторно.
+	CreateForMessage(ctx context.Context, deliveries []*Delivery) error
+	// Claim берёт до limit доставок со статусом pending, у которых подошло время попытки,
+	// и арендует их на lease, чтобы другие реплики их не взяли.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error)
+	// RecordAttempt сохраняет попытку в журнал и новое состояние доставки (статус, счётчик, время следующей попытки), снимает аренду.
+	RecordAttempt(ctx context.Context, delivery *Delivery, attempt *Attempt) error
+	GetByID(ctx context.Context, id string) (*Delivery, error)
+	// ListBySubscription — последние доставки подписки (новые первыми).
+	ListBySubscription(ctx context.Context, subscriptionID string, limit int) ([]*Delivery, error)
+	ListAttempts(ctx context.Context, deliveryID string) ([]*Attempt, error)
+	// Requeue возвращает доставку в очередь на немедленную отправку (ручной replay) со сброшенным счётчиком попыток.
+	Requeue(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,274 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wdpl_back/internal/shared/postgres"
+)
+
+// — SubscriptionRepository
+
+type subscriptionRepoImpl struct{ db *postgres.DB }
+
+// NewPostgresSubscriptionRepository возвращает реализацию SubscriptionRepository для PostgreSQL.
+func NewPostgresSubscriptionRepository(db *postgres.DB) SubscriptionRepository {
+	return &subscriptionRepoImpl{db: db}
+}
+
+const subscriptionColumns = `id, url, secret, event_types, is_active, description, created_by, created_at, updated_at`
+
+// rowScanner — общее у *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row rowScanner) (*Subscription, error) {
+	var sub Subscription
+	var eventTypes []byte
+	if err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, &eventTypes, &sub.IsActive, &sub.Description,
+		&sub.CreatedBy, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(eventTypes, &sub.EventTypes); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *subscriptionRepoImpl) Create(ctx context.Context, sub *Subscription) error {
+	if sub.ID == "" {
+		sub.ID = uuid.NewString()
+	}
+	eventTypes, err := json.Marshal(sub.EventTypes)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO public.webhook_subscriptions (id, url, secret, event_types, is_active, description, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, sub.ID, sub.URL, sub.Secret, eventTypes, sub.IsActive, sub.Description, sub.CreatedBy, sub.CreatedAt, sub.UpdatedAt)
+	return err
+}
+
+func (r *subscriptionRepoImpl) GetByID(ctx context.Context, id string) (*Subscription, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+subscriptionColumns+` FROM public.webhook_subscriptions WHERE id = $1`, id)
+	sub, err := scanSubscription(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+func (r *subscriptionRepoImpl) List(ctx context.Context) ([]*Subscription, error) {
+	return r.list(ctx, `SELECT `+subscriptionColumns+` FROM public.webhook_subscriptions ORDER BY created_at`)
+}
+
+func (r *subscriptionRepoImpl) ListActiveByEventType(ctx context.Context, eventType string) ([]*Subscription, error) {
+	return r.list(ctx, `
+		SELECT `+subscriptionColumns+` FROM public.webhook_subscriptions
+		WHERE is_active AND event_types ? $1
+		ORDER BY created_at
+	`, eventType)
+}
+
+func (r *subscriptionRepoImpl) list(ctx context.Context, query string, args ...any) ([]*Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sub)
+	}
+	return list, rows.Err()
+}
+
+func (r *subscriptionRepoImpl) Update(ctx context.Context, sub *Subscription) error {
+	eventTypes, err := json.Marshal(sub.EventTypes)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE public.webhook_subscriptions
+		SET url = $1, secret = $2, event_types = $3, is_active = $4, description = $5, updated_at = $6
+		WHERE id = $7
+	`, sub.URL, sub.Secret, eventTypes, sub.IsActive, sub.Description, sub.UpdatedAt, sub.ID)
+	return err
+}
+
+func (r *subscriptionRepoImpl) Delete(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM public.webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// — DeliveryRepository
+
+type deliveryRepoImpl struct{ db *postgres.DB }
+
+// NewPostgresDeliveryRepository возвращает реализацию DeliveryRepository для PostgreSQL.
+func NewPostgresDeliveryRepository(db *postgres.DB) DeliveryRepository {
+	return &deliveryRepoImpl{db: db}
+}
+
+const deliveryColumns = `id, subscription_id, message_id, event_type, body, status, attempts, next_attempt_at,
+	last_status_code, last_error, created_at, delivered_at`
+
+func scanDelivery(row rowScanner) (*Delivery, error) {
+	var d Delivery
+	var body []byte
+	if err := row.Scan(&d.ID, &d.SubscriptionID, &d.MessageID, &d.EventType, &body, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt); err != nil {
+		return nil, err
+	}
+	d.Body = body
+	return &d, nil
+}
+
+func (r *deliveryRepoImpl) CreateForMessage(ctx context.Context, deliveries []*Delivery) error {
+	for _, d := range deliveries {
+		if d.ID == "" {
+			d.ID = uuid.NewString()
+		}
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO public.webhook_deliveries (id, subscription_id, message_id, event_type, body, status, next_attempt_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (subscription_id, message_id) DO NOTHING
+		`, d.ID, d.SubscriptionID, d.MessageID, d.EventType, string(d.Body), d.Status, d.NextAttemptAt, d.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *deliveryRepoImpl) Claim(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE public.webhook_deliveries d
+		SET locked_until = now() + make_interval(secs => $2)
+		WHERE d.id IN (
+			SELECT p.id FROM public.webhook_deliveries p
+			WHERE p.status = 'pending' AND p.next_attempt_at <= now()
+				AND (p.locked_until IS NULL OR p.locked_until < now())
+			ORDER BY p.next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+func (r *deliveryRepoImpl) RecordAttempt(ctx context.Context, d *Delivery, a *Attempt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO public.webhook_delivery_attempts (delivery_id, attempt, status_code, error, response_body, duration_ms, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, a.DeliveryID, a.Attempt, a.StatusCode, a.Error, a.ResponseBody, a.DurationMS, a.CreatedAt).Scan(&a.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE public.webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5,
+			delivered_at = $6, locked_until = NULL
+		WHERE id = $7
+	`, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt, d.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *deliveryRepoImpl) GetByID(ctx context.Context, id string) (*Delivery, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM public.webhook_deliveries WHERE id = $1`, id)
+	d, err := scanDelivery(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return d, err
+}
+
+func (r *deliveryRepoImpl) ListBySubscription(ctx context.Context, subscriptionID string, limit int) ([]*Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+` FROM public.webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+func (r *deliveryRepoImpl) ListAttempts(ctx context.Context, deliveryID string) ([]*Attempt, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, delivery_id, attempt, status_code, error, response_body, duration_ms, created_at
+		FROM public.webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY id
+	`, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Attempt
+	for rows.Next() {
+		var a Attempt
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.Attempt, &a.StatusCode, &a.Error, &a.ResponseBody, &a.DurationMS, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &a)
+	}
+	return list, rows.Err()
+}
+
+func (r *deliveryRepoImpl) Requeue(ctx context.Context, id string, at time.Time) error {
+	// Новая серия повторов: счётчик с нуля, журнал прежних попыток остаётся.
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE public.webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = $2, delivered_at = NULL, locked_until = NULL
+		WHERE id = $1
+	`, id, at)
+	return err
+}
@@ -0,0 +1,42 @@
+package webhooks
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/features/auth"
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/http/middleware"
+	"wdpl_back/internal/shared/logger"
+	"wdpl_back/internal/shared/outbox"
+	"wdpl_back/internal/shared/postgres"
+)
+
+// AdminRoles — кто управляет вебхуками.
+var AdminRoles = []string{auth.RoleAdmin}
+
+// RegisterRoutes вешает эндпоинты вебхуков на api (обычно /api), подписывает сервис на доменные
+// события в dispatcher и запускает фоновую отправку доставок до отмены ctx (время жизни приложения).
+// Все эндпоинты — только admin.
+func RegisterRoutes(ctx context.Context, api fiber.Router, db *postgres.DB, cfg *config.Config, log logger.Logger, dispatcher *outbox.Dispatcher) {
+	svc := NewService(NewPostgresSubscriptionRepository(db), NewPostgresDeliveryRepository(db), nil, log)
+	h := NewHandler(svc)
+
+	for _, eventType := range EventTypes {
+		dispatcher.Subscribe(eventType, "webhooks", svc.HandleMessage)
+	}
+	go svc.Run(ctx)
+
+	requireAuth := middleware.RequireAuth(cfg)
+	requireAdmin := middleware.RequireRole(AdminRoles...)
+
+	api.Post("/webhooks", requireAuth, requireAdmin, h.Create)
+	api.Get("/webhooks", requireAuth, requireAdmin, h.List)
+	api.Get("/webhooks/deliveries/:deliveryId", requireAuth, requireAdmin, h.GetDelivery)
+	api.Post("/webhooks/deliveries/:deliveryId/replay", requireAuth, requireAdmin, h.Replay)
+	api.Get("/webhooks/:id", requireAuth, requireAdmin, h.Get)
+	api.Patch("/webhooks/:id", requireAuth, requireAdmin, h.Update)
+	api.Delete("/webhooks/:id", requireAuth, requireAdmin, h.Delete)
+	api.Get("/webhooks/:id/deliveries", requireAuth, requireAdmin, h.ListDeliveries)
+}
@@ -0,0 +1,420 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"wdpl_back/internal/shared/logger"
+	"wdpl_back/internal/shared/outbox"
+)
+
+// Параметры доставки.
+const (
+	deliveryTimeout      = 10 * time.Second
+	deliveryPollInterval = 5 * time.Second
+	deliveryBatchSize    = 20
+	// deliveryLease больше таймаута запроса: аренда не истечёт посреди отправки.
+	deliveryLease       = time.Minute
+	defaultMaxAttempts  = 8
+	defaultBaseDelay    = 30 * time.Second
+	maxRetryDelay       = time.Hour
+	maxResponseBodySize = 1024
+	minSecretLength     = 16
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrDeliveryPending      = errors.New("webhook delivery is already pending")
+	ErrInvalidURL           = errors.New("url must be an absolute http(s) URL")
+	ErrInvalidEventTypes    = errors.New("unknown or empty event types")
+	ErrSecretTooShort       = errors.New("secret must be at least 16 characters")
+)
+
+// Service управляет подписками и доставляет им доменные события из outbox:
+// HandleMessage ставит доставки в очередь, Run отправляет их с HMAC-подписью и повторами.
+type Service struct {
+	subs       SubscriptionRepository
+	deliveries DeliveryRepository
+	client     *http.Client
+	log        logger.Logger
+
+	now         func() time.Time
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+// NewService создаёт сервис вебхуков. client == nil — http.Client с таймаутом deliveryTimeout.
+func NewService(subs SubscriptionRepository, deliveries DeliveryRepository, client *http.Client, log logger.Logger) *Service {
+	if client == nil {
+		client = &http.Client{Timeout: deliveryTimeout}
+	}
+	return &Service{
+		subs:        subs,
+		deliveries:  deliveries,
+		client:      client,
+		log:         log,
+		now:         time.Now,
+		maxAttempts: defaultMaxAttempts,
+		baseDelay:   defaultBaseDelay,
+	}
+}
+
+// CreateInput — данные новой подписки. Пустой Secret — сгенерировать.
+type CreateInput struct {
+	URL         string
+	EventTypes  []string
+	Secret      string
+	Description *string
+}
+
+// UpdateInput — частичное обновление подписки: nil — поле не меняется.
+type UpdateInput struct {
+	URL         *string
+	EventTypes  []string
+	IsActive    *bool
+	Description *string
+}
+
+// Create создаёт активную подписку. Секрет возвращается в Subscription — показать его можно только здесь.
+func (s *Service) Create(ctx context.Context, input CreateInput, createdBy string) (*Subscription, error) {
+	if err := validateURL(input.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeEventTypes(input.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret := input.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	} else if len(secret) < minSecretLength {
+		return nil, ErrSecretTooShort
+	}
+	now := s.now()
+	sub := &Subscription{
+		URL:         input.URL,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		IsActive:    true,
+		Description: input.Description,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.subs.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Get возвращает подписку или ErrSubscriptionNotFound.
+func (s *Service) Get(ctx context.Context, id string) (*Subscription, error) {
+	sub, err := s.subs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+// List возвращает все подписки.
+func (s *Service) List(ctx context.Context) ([]*Subscription, error) {
+	return s.subs.List(ctx)
+}
+
+// Update меняет адрес, типы событий, активность или описание подписки.
+func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*Subscription, error) {
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.URL != nil {
+		if err := validateURL(*input.URL); err != nil {
+			return nil, err
+		}
+		sub.URL = *input.URL
+	}
+	if input.EventTypes != nil {
+		if sub.EventTypes, err = normalizeEventTypes(input.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if input.IsActive != nil {
+		sub.IsActive = *input.IsActive
+	}
+	if input.Description != nil {
+		sub.Description = input.Description
+	}
+	sub.UpdatedAt = s.now()
+	if err := s.subs.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Delete удаляет подписку вместе с журналом доставок.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	deleted, err := s.subs.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// ListDeliveries — последние доставки подписки.
+func (s *Service) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*Delivery, error) {
+	if _, err := s.Get(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.deliveries.ListBySubscription(ctx, subscriptionID, limit)
+}
+
+// GetDelivery возвращает доставку с журналом попыток.
+func (s *Service) GetDelivery(ctx context.Context, id string) (*Delivery, []*Attempt, error) {
+	d, err := s.deliveries.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if d == nil {
+		return nil, nil, ErrDeliveryNotFound
+	}
+	attempts, err := s.deliveries.ListAttempts(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, attempts, nil
+}
+
+// Replay ставит завершённую (успешно или нет) доставку в очередь заново с тем же телом
+// и новой серией повторов.
+func (s *Service) Replay(ctx context.Context, id string) (*Delivery, error) {
+	d, err := s.deliveries.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, ErrDeliveryNotFound
+	}
+	if d.Status == DeliveryPending {
+		return nil, ErrDeliveryPending
+	}
+	now := s.now()
+	if err := s.deliveries.Requeue(ctx, id, now); err != nil {
+		return nil, err
+	}
+	d.Status = DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = now
+	d.DeliveredAt = nil
+	return d, nil
+}
+
+// HandleMessage — обработчик outbox: ставит доставку события каждой активной подписке на его тип.
+// Повторная передача того же сообщения доставки не дублирует.
+func (s *Service) HandleMessage(ctx context.Context, msg *outbox.Message) error {
+	subs, err := s.subs.ListActiveByEventType(ctx, msg.Type)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(Envelope{
+		ID:        strconv.FormatInt(msg.ID, 10),
+		Type:      msg.Type,
+		CreatedAt: msg.CreatedAt,
+		Data:      msg.Payload,
+	})
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	deliveries := make([]*Delivery, 0, len(subs))
+	for _, sub := range subs {
+		deliveries = append(deliveries, &Delivery{
+			SubscriptionID: sub.ID,
+			MessageID:      msg.ID,
+			EventType:      msg.Type,
+			Body:           body,
+			Status:         DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	return s.deliveries.CreateForMessage(ctx, deliveries)
+}
+
+// Run отправляет доставки из очереди до отмены ctx. Запускать в отдельной горутине.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(deliveryPollInterval)
+	defer ticker.Stop()
+	for {
+		n, err := s.DeliverDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.log.Error("webhook delivery failed", "error", err)
+		}
+		if err == nil && n == deliveryBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue отправляет одну пачку доставок, у которых подошло время. Возвращает размер пачки.
+func (s *Service) DeliverDue(ctx context.Context) (int, error) {
+	batch, err := s.deliveries.Claim(ctx, deliveryBatchSize, deliveryLease)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range batch {
+		if err := s.deliver(ctx, d); err != nil {
+			return len(batch), err
+		}
+	}
+	return len(batch), nil
+}
+
+// deliver делает одну попытку и записывает её в журнал. Ошибка — только ошибка записи в БД.
+func (s *Service) deliver(ctx context.Context, d *Delivery) error {
+	sub, err := s.subs.GetByID(ctx, d.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	d.Attempts++
+	var attempt *Attempt
+	disabled := sub == nil || !sub.IsActive
+	if disabled {
+		// Подписку отключили после постановки в очередь — не шлём, но оставляем след в журнале.
+		msg := "subscription is disabled"
+		attempt = &Attempt{DeliveryID: d.ID, Attempt: d.Attempts, Error: &msg, CreatedAt: s.now()}
+	} else {
+		attempt = s.send(ctx, sub, d)
+	}
+
+	d.LastStatusCode = attempt.StatusCode
+	d.LastError = attempt.Error
+	if d.LastError == nil && !attempt.Succeeded() {
+		msg := fmt.Sprintf("unexpected status %d", *attempt.StatusCode)
+		d.LastError = &msg
+	}
+	switch {
+	case attempt.Succeeded():
+		d.Status = DeliverySucceeded
+		d.DeliveredAt = &attempt.CreatedAt
+	case disabled || d.Attempts >= s.maxAttempts:
+		d.Status = DeliveryFailed
+	default:
+		d.NextAttemptAt = attempt.CreatedAt.Add(s.retryDelay(d.Attempts))
+	}
+	return s.deliveries.RecordAttempt(ctx, d, attempt)
+}
+
+// send отправляет тело доставки POST-запросом с подписью.
+func (s *Service) send(ctx context.Context, sub *Subscription, d *Delivery) *Attempt {
+	started := s.now()
+	attempt := &Attempt{DeliveryID: d.ID, Attempt: d.Attempts, CreatedAt: started}
+	fail := func(err error) *Attempt {
+		msg := err.Error()
+		attempt.Error = &msg
+		attempt.DurationMS = time.Since(started).Milliseconds()
+		return attempt
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return fail(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wdpl-webhooks/1")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(started.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, started, d.Body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBodySize))
+	attempt.StatusCode = &res.StatusCode
+	if len(body) > 0 {
+		text := strings.ToValidUTF8(string(body), "")
+		attempt.ResponseBody = &text
+	}
+	attempt.DurationMS = time.Since(started).Milliseconds()
+	return attempt
+}
+
+// retryDelay — пауза после attempt неудачных попыток: baseDelay, ×2, ×4… не больше maxRetryDelay.
+func (s *Service) retryDelay(attempt int) time.Duration {
+	delay := s.baseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// normalizeEventTypes проверяет типы и убирает дубли, сохраняя порядок.
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, ErrInvalidEventTypes
+	}
+	seen := make(map[string]bool, len(eventTypes))
+	result := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !IsKnownEventType(t) {
+			return nil, ErrInvalidEventTypes
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// generateSecret — 256 бит случайности в hex с префиксом, чтобы секрет узнавался в конфигурации партнёра.
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
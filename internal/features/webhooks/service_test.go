@@ -0,0 +1,357 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/shared/outbox"
+)
+
+// mockSubscriptionRepo — in-memory SubscriptionRepository.
+type mockSubscriptionRepo struct {
+	subs map[string]*Subscription
+	seq  int
+}
+
+func (m *mockSubscriptionRepo) Create(_ context.Context, sub *Subscription) error {
+	if m.subs == nil {
+		m.subs = make(map[string]*Subscription)
+	}
+	m.seq++
+	sub.ID = fmt.Sprintf("sub-%d", m.seq)
+	copied := *sub
+	m.subs[sub.ID] = &copied
+	return nil
+}
+
+func (m *mockSubscriptionRepo) GetByID(_ context.Context, id string) (*Subscription, error) {
+	sub, ok := m.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+func (m *mockSubscriptionRepo) List(_ context.Context) ([]*Subscription, error) {
+	list := make([]*Subscription, 0, len(m.subs))
+	for _, sub := range m.subs {
+		list = append(list, sub)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (m *mockSubscriptionRepo) Update(_ context.Context, sub *Subscription) error {
+	copied := *sub
+	m.subs[sub.ID] = &copied
+	return nil
+}
+
+func (m *mockSubscriptionRepo) Delete(_ context.Context, id string) (bool, error) {
+	_, ok := m.subs[id]
+	delete(m.subs, id)
+	return ok, nil
+}
+
+func (m *mockSubscriptionRepo) ListActiveByEventType(ctx context.Context, eventType string) ([]*Subscription, error) {
+	all, _ := m.List(ctx)
+	var list []*Subscription
+	for _, sub := range all {
+		if sub.IsActive && sub.Matches(eventType) {
+			list = append(list, sub)
+		}
+	}
+	return list, nil
+}
+
+// mockDeliveryRepo — in-memory DeliveryRepository; now — часы сервиса.
+type mockDeliveryRepo struct {
+	now        func() time.Time
+	deliveries []*Delivery
+	attempts   []*Attempt
+}
+
+func (m *mockDeliveryRepo) CreateForMessage(_ context.Context, deliveries []*Delivery) error {
+	for _, d := range deliveries {
+		duplicate := false
+		for _, existing := range m.deliveries {
+			if existing.SubscriptionID == d.SubscriptionID && existing.MessageID == d.MessageID {
+				duplicate = true
+			}
+		}
+		if duplicate {
+			continue
+		}
+		d.ID = fmt.Sprintf("delivery-%d", len(m.deliveries)+1)
+		copied := *d
+		m.deliveries = append(m.deliveries, &copied)
+	}
+	return nil
+}
+
+func (m *mockDeliveryRepo) Claim(_ context.Context, limit int, _ time.Duration) ([]*Delivery, error) {
+	var list []*Delivery
+	for _, d := range m.deliveries {
+		if d.Status == DeliveryPending && !d.NextAttemptAt.After(m.now()) && len(list) < limit {
+			copied := *d
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockDeliveryRepo) RecordAttempt(_ context.Context, d *Delivery, a *Attempt) error {
+	a.ID = int64(len(m.attempts) + 1)
+	m.attempts = append(m.attempts, a)
+	for i, existing := range m.deliveries {
+		if existing.ID == d.ID {
+			copied := *d
+			m.deliveries[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *mockDeliveryRepo) GetByID(_ context.Context, id string) (*Delivery, error) {
+	for _, d := range m.deliveries {
+		if d.ID == id {
+			copied := *d
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockDeliveryRepo) ListBySubscription(_ context.Context, subscriptionID string, limit int) ([]*Delivery, error) {
+	var list []*Delivery
+	for i := len(m.deliveries) - 1; i >= 0 && len(list) < limit; i-- {
+		if m.deliveries[i].SubscriptionID == subscriptionID {
+			list = append(list, m.deliveries[i])
+		}
+	}
+	return list, nil
+}
+
+func (m *mockDeliveryRepo) ListAttempts(_ context.Context, deliveryID string) ([]*Attempt, error) {
+	var list []*Attempt
+	for _, a := range m.attempts {
+		if a.DeliveryID == deliveryID {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockDeliveryRepo) Requeue(_ context.Context, id string, at time.Time) error {
+	for _, d := range m.deliveries {
+		if d.ID == id {
+			d.Status, d.Attempts, d.NextAttemptAt, d.DeliveredAt = DeliveryPending, 0, at, nil
+		}
+	}
+	return nil
+}
+
+// receiver — httptest-сервер партнёра: отвечает кодами из statuses по очереди и запоминает запросы.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := io.ReadAll(req.Body)
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte("ack"))
+}
+
+func newWebhooksFixture(t *testing.T, now *time.Time) (*Service, *mockDeliveryRepo) {
+	t.Helper()
+	clock := func() time.Time { return *now }
+	deliveries := &mockDeliveryRepo{now: clock}
+	svc := NewService(&mockSubscriptionRepo{}, deliveries, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	svc.now = clock
+	return svc, deliveries
+}
+
+func publishedMessage(t *testing.T, id int64) *outbox.Message {
+	t.Helper()
+	msg, err := outbox.NewMessage(events.AggregateEvent, "event-1", events.DomainEventPublished,
+		events.EventPublishedPayload{EventID: "event-1", Title: "Hackathon"})
+	require.NoError(t, err)
+	msg.ID = id
+	return msg
+}
+
+func TestService_DeliversSignedWithRetries(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	svc, deliveries := newWebhooksFixture(t, &now)
+	rcv := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusOK}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	ctx := context.Background()
+
+	sub, err := svc.Create(ctx, CreateInput{URL: srv.URL, EventTypes: []string{events.DomainEventPublished}}, "admin-1")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sub.Secret, "whsec_"))
+	_, err = svc.Create(ctx, CreateInput{URL: srv.URL, EventTypes: []string{events.DomainDraftSaved}}, "admin-1")
+	require.NoError(t, err)
+
+	// Сообщение приходит дважды (outbox доставляет «хотя бы один раз») — доставка одна, и только подписке на тип.
+	require.NoError(t, svc.HandleMessage(ctx, publishedMessage(t, 7)))
+	require.NoError(t, svc.HandleMessage(ctx, publishedMessage(t, 7)))
+	require.Len(t, deliveries.deliveries, 1)
+
+	n, err := svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	d := deliveries.deliveries[0]
+	assert.Equal(t, DeliveryPending, d.Status)
+	assert.Equal(t, 500, *d.LastStatusCode)
+	assert.Equal(t, now.Add(defaultBaseDelay), d.NextAttemptAt)
+
+	// До конца паузы повтора нет.
+	n, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	now = now.Add(defaultBaseDelay)
+	_, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	d = deliveries.deliveries[0]
+	assert.Equal(t, DeliverySucceeded, d.Status)
+	assert.Equal(t, 2, d.Attempts)
+
+	require.Len(t, rcv.requests, 2)
+	req := rcv.requests[1]
+	assert.Equal(t, events.DomainEventPublished, req.Header.Get(HeaderEvent))
+	assert.Equal(t, d.ID, req.Header.Get(HeaderDelivery))
+	assert.True(t, Verify(sub.Secret, req.Header.Get(HeaderSignature), req.Header.Get(HeaderTimestamp), rcv.bodies[1], now, 5*time.Minute))
+	assert.False(t, Verify("wrong-secret-wrong-secret", req.Header.Get(HeaderSignature), req.Header.Get(HeaderTimestamp), rcv.bodies[1], now, 5*time.Minute))
+	assert.Equal(t, rcv.bodies[0], rcv.bodies[1])
+
+	var envelope Envelope
+	require.NoError(t, json.Unmarshal(rcv.bodies[1], &envelope))
+	assert.Equal(t, "7", envelope.ID)
+	assert.JSONEq(t, `{"eventId":"event-1","organizationId":"","draftId":"","title":"Hackathon","publishedAt":"0001-01-01T00:00:00Z"}`, string(envelope.Data))
+
+	_, attempts, err := svc.GetDelivery(ctx, d.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, 500, *attempts[0].StatusCode)
+	assert.Equal(t, "ack", *attempts[0].ResponseBody)
+	assert.Equal(t, 200, *attempts[1].StatusCode)
+}
+
+func TestService_FailsAfterMaxAttemptsAndReplays(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	svc, deliveries := newWebhooksFixture(t, &now)
+	svc.maxAttempts = 2
+	rcv := &receiver{statuses: []int{http.StatusBadGateway, http.StatusBadGateway}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, CreateInput{URL: srv.URL, EventTypes: []string{events.DomainEventPublished}, Secret: "partner-secret-123456"}, "admin-1")
+	require.NoError(t, err)
+	require.NoError(t, svc.HandleMessage(ctx, publishedMessage(t, 1)))
+
+	for i := 0; i < 2; i++ {
+		_, err = svc.DeliverDue(ctx)
+		require.NoError(t, err)
+		now = now.Add(maxRetryDelay)
+	}
+	d := deliveries.deliveries[0]
+	assert.Equal(t, DeliveryFailed, d.Status)
+	assert.Equal(t, "unexpected status 502", *d.LastError)
+
+	// Ручной replay: новая серия попыток, тело то же.
+	replayed, err := svc.Replay(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DeliveryPending, replayed.Status)
+	_, err = svc.Replay(ctx, d.ID)
+	require.ErrorIs(t, err, ErrDeliveryPending)
+
+	_, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, DeliverySucceeded, deliveries.deliveries[0].Status)
+	require.Len(t, rcv.bodies, 3)
+	assert.Equal(t, rcv.bodies[0], rcv.bodies[2])
+}
+
+func TestService_ValidatesSubscription(t *testing.T) {
+	now := time.Now()
+	svc, _ := newWebhooksFixture(t, &now)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, CreateInput{URL: "ftp://example.com", EventTypes: []string{events.DomainEventPublished}}, "admin-1")
+	require.ErrorIs(t, err, ErrInvalidURL)
+	_, err = svc.Create(ctx, CreateInput{URL: "https://example.com/hook", EventTypes: []string{"event.deleted"}}, "admin-1")
+	require.ErrorIs(t, err, ErrInvalidEventTypes)
+	_, err = svc.Create(ctx, CreateInput{URL: "https://example.com/hook", EventTypes: []string{events.DomainEventPublished}, Secret: "short"}, "admin-1")
+	require.ErrorIs(t, err, ErrSecretTooShort)
+
+	sub, err := svc.Create(ctx, CreateInput{URL: "https://example.com/hook", EventTypes: []string{events.DomainEventPublished, events.DomainEventPublished}}, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{events.DomainEventPublished}, sub.EventTypes)
+
+	inactive := false
+	updated, err := svc.Update(ctx, sub.ID, UpdateInput{IsActive: &inactive})
+	require.NoError(t, err)
+	assert.False(t, updated.IsActive)
+	assert.Equal(t, sub.Secret, updated.Secret)
+}
+
+func TestService_DisabledSubscriptionIsNotCalled(t *testing.T) {
+	now := time.Now()
+	svc, deliveries := newWebhooksFixture(t, &now)
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	ctx := context.Background()
+
+	sub, err := svc.Create(ctx, CreateInput{URL: srv.URL, EventTypes: []string{events.DomainEventPublished}}, "admin-1")
+	require.NoError(t, err)
+	require.NoError(t, svc.HandleMessage(ctx, publishedMessage(t, 1)))
+	inactive := false
+	_, err = svc.Update(ctx, sub.ID, UpdateInput{IsActive: &inactive})
+	require.NoError(t, err)
+
+	_, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, rcv.requests)
+	assert.Equal(t, DeliveryFailed, deliveries.deliveries[0].Status)
+}
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	sig := Sign("secret", ts, []byte(`{"a":1}`))
+	assert.Equal(t, "sha256=", sig[:7])
+	assert.True(t, Verify("secret", sig, "1700000000", []byte(`{"a":1}`), ts.Add(time.Minute), 5*time.Minute))
+	// Просроченный timestamp и изменённое тело отвергаются.
+	assert.False(t, Verify("secret", sig, "1700000000", []byte(`{"a":1}`), ts.Add(time.Hour), 5*time.Minute))
+	assert.False(t, Verify("secret", sig, "1700000000", []byte(`{"a":2}`), ts, 5*time.Minute))
+}
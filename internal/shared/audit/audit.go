@@ -0,0 +1,159 @@
+// Package audit — журнал аудита: кто, когда и что изменил. Записи только добавляются (public.audit_log,
+// UPDATE и DELETE отвергает триггер базы) и пишутся в той же транзакции, что и само изменение.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Entry — запись журнала. Before/After — состояние цели до и после изменения (JSON, может отсутствовать).
+// APIKeyID — ключ, которым выполнен запрос (пусто — запрос по JWT); ActorID тогда — владелец ключа.
+type Entry struct {
+	ID         int64
+	ActorID    string
+	APIKeyID   string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	IP         string
+	UserAgent  string
+	CreatedAt  time.Time
+}
+
+// NewEntry собирает запись, сериализуя before и after в JSON (nil — без состояния).
+func NewEntry(actorID, action, targetType, targetID string, before, after any) (*Entry, error) {
+	e := &Entry{ActorID: actorID, Action: action, TargetType: targetType, TargetID: targetID}
+	var err error
+	if e.Before, err = marshalState(before); err != nil {
+		return nil, err
+	}
+	if e.After, err = marshalState(after); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func marshalState(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Writer добавляет записи в журнал. Реализация привязана к транзакции изменения.
+type Writer interface {
+	Append(ctx context.Context, entries ...*Entry) error
+}
+
+// RowQuerier — общее у *sql.DB и *sql.Tx, достаточное для записи в журнал (INSERT … RETURNING).
+type RowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type postgresWriter struct {
+	q RowQuerier
+}
+
+// NewPostgresWriter возвращает Writer поверх транзакции (или соединения) q.
+func NewPostgresWriter(q RowQuerier) Writer {
+	return &postgresWriter{q: q}
+}
+
+func (w *postgresWriter) Append(ctx context.Context, entries ...*Entry) error {
+	for _, e := range entries {
+		err := w.q.QueryRowContext(ctx, `
+			INSERT INTO public.audit_log (actor_id, api_key_id, action, target_type, target_id, before, after, ip, user_agent)
+			VALUES (NULLIF($1, '')::uuid, NULLIF($2, '')::uuid, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''))
+			RETURNING id, created_at
+		`, e.ActorID, e.APIKeyID, e.Action, e.TargetType, e.TargetID, nullJSON(e.Before), nullJSON(e.After), e.IP, e.UserAgent).
+			Scan(&e.ID, &e.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullJSON передаёт пустое состояние как NULL, а не как пустую строку (невалидный jsonb).
+func nullJSON(v json.RawMessage) any {
+	if len(v) == 0 {
+		return nil
+	}
+	return []byte(v)
+}
+
+// Filter — выборка из журнала. Пустые поля не ограничивают; From и To — полуинтервал [From, To).
+type Filter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+// Reader читает журнал — новые записи первыми.
+type Reader interface {
+	List(ctx context.Context, filter Filter) ([]*Entry, error)
+}
+
+// Querier — общее у *sql.DB и *sql.Tx, достаточное для чтения журнала.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type postgresReader struct {
+	q Querier
+}
+
+// NewPostgresReader возвращает Reader поверх соединения q.
+func NewPostgresReader(q Querier) Reader {
+	return &postgresReader{q: q}
+}
+
+func (r *postgresReader) List(ctx context.Context, f Filter) ([]*Entry, error) {
+	var from, to *time.Time
+	if !f.From.IsZero() {
+		from = &f.From
+	}
+	if !f.To.IsZero() {
+		to = &f.To
+	}
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT id, COALESCE(actor_id::text, ''), COALESCE(api_key_id::text, ''), action, target_type, target_id, before, after,
+		       COALESCE(ip, ''), COALESCE(user_agent, ''), created_at
+		FROM public.audit_log
+		WHERE ($1 = '' OR actor_id = NULLIF($1, '')::uuid)
+		  AND ($2 = '' OR action = $2)
+		  AND ($3 = '' OR target_type = $3)
+		  AND ($4 = '' OR target_id = $4)
+		  AND ($5::timestamptz IS NULL OR created_at >= $5)
+		  AND ($6::timestamptz IS NULL OR created_at < $6)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7 OFFSET $8
+	`, f.ActorID, f.Action, f.TargetType, f.TargetID, from, to, f.Limit, f.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*Entry
+	for rows.Next() {
+		e := &Entry{}
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.APIKeyID, &e.Action, &e.TargetType, &e.TargetID, &before, &after,
+			&e.IP, &e.UserAgent, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Before, e.After = before, after
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
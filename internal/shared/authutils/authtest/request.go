@@ -0,0 +1,28 @@
+// Package authtest — помощники тестов хендлеров за middleware.RequireAuth.
+package authtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/authutils"
+	"wdpl_back/internal/shared/config"
+)
+
+// NewRequest — запрос с access JWT пользователя userID с ролью role, подписанным секретом cfg.
+// Непустое body отправляется как JSON.
+func NewRequest(t *testing.T, cfg *config.Config, method, url, userID, role, body string) *http.Request {
+	t.Helper()
+	token, _, err := authutils.GenerateAccessToken(cfg, userID, role)
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
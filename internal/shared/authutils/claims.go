@@ -7,5 +7,30 @@ import "github.com/golang-jwt/jwt/v5"
 type UserClaims struct {
 	UserID string `json:"userID"`
 	Role   string `json:"role"`
+	// EmailUnverified — email не подтверждён: доступ только на чтение (см. middleware.RequireAuth).
+	// Флаг «от противного», чтобы токены, выданные до его появления, не считались неподтверждёнными.
+	EmailUnverified bool `json:"emailUnverified,omitempty"`
+	// Generation — поколение токенов пользователя на момент выдачи. Смена роли или активности
+	// увеличивает поколение в БД, и RequireAuth перестаёт принимать выданные раньше токены.
+	Generation int `json:"gen,omitempty"`
+	// SessionID — сессия (семья refresh-токенов), при входе в которую выдан токен.
+	SessionID string `json:"sid,omitempty"`
+	// APIKeyID и Scopes заполняются только для запросов с API-ключом (middleware.RequireAuthOrAPIKey),
+	// в JWT не попадают.
+	APIKeyID string   `json:"-"`
+	Scopes   []string `json:"-"`
 	jwt.RegisteredClaims
 }
+
+// HasScope — разрешено ли действие scope. Для JWT пользователя ограничений нет — только роль.
+func (c *UserClaims) HasScope(scope string) bool {
+	if c.APIKeyID == "" {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
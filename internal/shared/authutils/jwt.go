@@ -10,15 +10,16 @@ import (
 
 // GenerateAccessToken создаёт access JWT для пользователя.
 func GenerateAccessToken(cfg *config.Config, userID, role string) (string, time.Time, error) {
-	exp := time.Now().Add(time.Duration(cfg.AccessTokenTTLMin) * time.Minute)
+	return GenerateAccessTokenWithClaims(cfg, &UserClaims{UserID: userID, Role: role})
+}
 
-	claims := &UserClaims{
-		UserID: userID,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(exp),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// GenerateAccessTokenWithClaims создаёт access JWT с заданными клеймами пользователя
+// (флаг неподтверждённого email, поколение токенов). Срок и время выдачи проставляются здесь.
+func GenerateAccessTokenWithClaims(cfg *config.Config, claims *UserClaims) (string, time.Time, error) {
+	exp := time.Now().Add(time.Duration(cfg.AccessTokenTTLMin) * time.Minute)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(exp),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -70,3 +70,21 @@ func TestParseAccessToken_EmptyToken(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, claims)
 }
+
+func TestGenerateAccessTokenWithClaims(t *testing.T) {
+	cfg := testJWTConfig(t)
+
+	token, _, err := GenerateAccessTokenWithClaims(cfg, &UserClaims{UserID: "user-789", Role: "user", EmailUnverified: true, Generation: 3})
+	require.NoError(t, err)
+	claims, err := ParseAccessToken(cfg, token)
+	require.NoError(t, err)
+	assert.True(t, claims.EmailUnverified)
+	assert.Equal(t, 3, claims.Generation)
+
+	token, _, err = GenerateAccessToken(cfg, "user-789", "user")
+	require.NoError(t, err)
+	claims, err = ParseAccessToken(cfg, token)
+	require.NoError(t, err)
+	assert.False(t, claims.EmailUnverified)
+	assert.Zero(t, claims.Generation)
+}
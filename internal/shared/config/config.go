@@ -1,7 +1,11 @@
 package config
 
 import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -9,16 +13,39 @@ import (
 // Config описывает конфигурацию приложения.
 // Значения читаются из переменных окружения с помощью cleanenv.
 type Config struct {
-	ServerHost         string `env:"SERVER_HOST" env-default:"0.0.0.0"`
-	ServerPort         int    `env:"SERVER_PORT" env-default:"3000"`
-	DatabaseURL        string `env:"DATABASE_URL" env-required:"true"`
-	JWTSecret          string `env:"JWT_SECRET" env-required:"true"`
-	RefreshSecret      string `env:"REFRESH_SECRET" env-required:"true"`
-	AccessTokenTTLMin  int    `env:"ACCESS_TOKEN_TTL" env-default:"15"`
-	RefreshTokenTTLMin int    `env:"REFRESH_TOKEN_TTL" env-default:"30"`
-	LogLevel           string `env:"LOG_LEVEL" env-default:"info"`
-	LogFormat          string `env:"LOG_FORMAT" env-default:"text"`
-	Environment        string `env:"ENVIRONMENT" env-default:"development"`
+	ServerHost          string `env:"SERVER_HOST" env-default:"0.0.0.0"`
+	ServerPort          int    `env:"SERVER_PORT" env-default:"3000"`
+	DatabaseURL         string `env:"DATABASE_URL" env-required:"true"`
+	JWTSecret           string `env:"JWT_SECRET" env-required:"true"`
+	RefreshSecret       string `env:"REFRESH_SECRET" env-required:"true"`
+	TicketSecret        string `env:"TICKET_SECRET"`
+	APIKeySecret        string `env:"API_KEY_SECRET"`
+	ProfanityWordsFile  string `env:"PROFANITY_WORDS_FILE"`
+	AppURL              string `env:"APP_URL" env-default:"http://localhost:5173"`
+	SMTPHost            string `env:"SMTP_HOST"`
+	SMTPPort            int    `env:"SMTP_PORT" env-default:"587"`
+	SMTPUsername        string `env:"SMTP_USERNAME"`
+	SMTPPassword        string `env:"SMTP_PASSWORD"`
+	MailFrom            string `env:"MAIL_FROM" env-default:"no-reply@localhost"`
+	PasswordResetTTLMin int    `env:"PASSWORD_RESET_TTL" env-default:"60"`
+	EmailVerifyTTLMin   int    `env:"EMAIL_VERIFICATION_TTL" env-default:"1440"`
+	UnverifiedAccess    string `env:"UNVERIFIED_EMAIL_ACCESS" env-default:"read_only"`
+	AccessTokenTTLMin   int    `env:"ACCESS_TOKEN_TTL" env-default:"15"`
+	RefreshTokenTTLMin  int    `env:"REFRESH_TOKEN_TTL" env-default:"30"`
+	OIDCIssuerURL       string `env:"OIDC_ISSUER_URL"`
+	OIDCClientID        string `env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret    string `env:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL     string `env:"OIDC_REDIRECT_URL"`
+	OIDCScopes          string `env:"OIDC_SCOPES" env-default:"openid email profile"`
+	MFASecret           string `env:"MFA_SECRET"`
+	MFAIssuer           string `env:"MFA_ISSUER" env-default:"WDPL"`
+	MFARequired         bool   `env:"MFA_REQUIRED_FOR_EDITORS" env-default:"false"`
+	RateLimitStore      string `env:"RATE_LIMIT_STORE" env-default:"memory"`
+	LoginLockoutAfter   int    `env:"LOGIN_LOCKOUT_THRESHOLD" env-default:"5"`
+	TrustedProxies      string `env:"TRUSTED_PROXIES"`
+	LogLevel            string `env:"LOG_LEVEL" env-default:"info"`
+	LogFormat           string `env:"LOG_FORMAT" env-default:"text"`
+	Environment         string `env:"ENVIRONMENT" env-default:"development"`
 }
 
 // Load загружает конфигурацию из переменных окружения.
@@ -41,6 +68,93 @@ func MustLoad() *Config {
 	return cfg
 }
 
+// Метки HKDF для ключей, выводимых из JWT_SECRET, когда отдельный секрет не задан. Менять нельзя:
+// выданные билеты, API-ключи и подключённые аутентификаторы перестанут проходить проверку.
+const (
+	ticketKeyLabel = "wdpl/ticket-signing/v1"
+	apiKeyKeyLabel = "wdpl/api-key-hash/v1"
+	mfaKeyLabel    = "wdpl/mfa-encryption/v1"
+)
+
+// TicketSigningSecret возвращает секрет подписи билетов (QR). Без TICKET_SECRET — ключ, выведенный из JWT_SECRET.
+func (c *Config) TicketSigningSecret() string {
+	if c.TicketSecret != "" {
+		return c.TicketSecret
+	}
+	return c.derivedKey(ticketKeyLabel)
+}
+
+// APIKeyHashSecret возвращает ключ HMAC секретов API-ключей. Без API_KEY_SECRET — ключ, выведенный из JWT_SECRET.
+func (c *Config) APIKeyHashSecret() string {
+	if c.APIKeySecret != "" {
+		return c.APIKeySecret
+	}
+	return c.derivedKey(apiKeyKeyLabel)
+}
+
+// derivedKey — отдельный для назначения label ключ из JWT_SECRET (HKDF-SHA256, 32 байта в hex): утечка одного
+// из ключей не раскрывает ни JWT_SECRET, ни остальные, а токен, подписанный одним ключом, не подходит к другому.
+func (c *Config) derivedKey(label string) string {
+	key, err := hkdf.Key(sha256.New, []byte(c.JWTSecret), nil, label, sha256.Size)
+	if err != nil {
+		// HKDF-SHA256 отказывает только при длине больше 255*32 байт.
+		panic(fmt.Sprintf("derive %s key: %v", label, err))
+	}
+	return hex.EncodeToString(key)
+}
+
+// Значения UNVERIFIED_EMAIL_ACCESS.
+const (
+	UnverifiedAccessReadOnly = "read_only"
+	UnverifiedAccessDeny     = "deny"
+)
+
+// UnverifiedSignInAllowed — можно ли входить до подтверждения email (UNVERIFIED_EMAIL_ACCESS):
+// read_only — вход есть, но только чтение; deny — вход только после подтверждения.
+func (c *Config) UnverifiedSignInAllowed() bool {
+	return c.UnverifiedAccess != UnverifiedAccessDeny
+}
+
+// Значения RATE_LIMIT_STORE.
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+// TrustedProxyList — адреса (или подсети) обратных прокси из TRUSTED_PROXIES. Только от них принимается
+// X-Forwarded-For: иначе клиент подставил бы любой IP и обошёл лимиты по IP.
+func (c *Config) TrustedProxyList() []string {
+	var proxies []string
+	for _, p := range strings.Split(c.TrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// MFAEncryptionKey возвращает ключ шифрования TOTP-секретов в БД. Без MFA_SECRET — ключ, выведенный из JWT_SECRET.
+func (c *Config) MFAEncryptionKey() string {
+	if c.MFASecret != "" {
+		return c.MFASecret
+	}
+	return c.derivedKey(mfaKeyLabel)
+}
+
+// OIDCEnabled — вход через OIDC-провайдера настроен (OIDC_ISSUER_URL и OIDC_CLIENT_ID).
+func (c *Config) OIDCEnabled() bool {
+	return c.OIDCIssuerURL != "" && c.OIDCClientID != ""
+}
+
+// OIDCRedirect возвращает адрес, на который провайдер возвращает браузер после входа.
+// Без OIDC_REDIRECT_URL — страница фронтенда APP_URL/auth/oidc/callback.
+func (c *Config) OIDCRedirect() string {
+	if c.OIDCRedirectURL != "" {
+		return c.OIDCRedirectURL
+	}
+	return strings.TrimRight(c.AppURL, "/") + "/auth/oidc/callback"
+}
+
 func (c *Config) ServerAddress() string {
 	return fmt.Sprintf("%s:%d", c.ServerHost, c.ServerPort)
 }
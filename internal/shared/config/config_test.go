@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerivedKeys_SeparatePerPurpose(t *testing.T) {
+	cfg := &Config{JWTSecret: "jwt-secret"}
+
+	keys := []string{cfg.TicketSigningSecret(), cfg.APIKeyHashSecret(), cfg.MFAEncryptionKey()}
+	for i, key := range keys {
+		assert.Len(t, key, 64)
+		assert.NotEqual(t, cfg.JWTSecret, key)
+		for _, other := range keys[i+1:] {
+			assert.NotEqual(t, key, other)
+		}
+	}
+	// Ключ стабилен между запусками, иначе выданные билеты и ключи перестали бы проходить проверку.
+	assert.Equal(t, keys[0], (&Config{JWTSecret: "jwt-secret"}).TicketSigningSecret())
+
+	cfg.TicketSecret, cfg.APIKeySecret, cfg.MFASecret = "ticket", "api-key", "mfa"
+	assert.Equal(t, "ticket", cfg.TicketSigningSecret())
+	assert.Equal(t, "api-key", cfg.APIKeyHashSecret())
+	assert.Equal(t, "mfa", cfg.MFAEncryptionKey())
+}
@@ -0,0 +1,50 @@
+// Package csvexport — выгрузка CSV для открытия в Excel, LibreOffice и Google Sheets. Значения от пользователей
+// (имена, email, комментарии) экранируются от CSV-инъекции: ячейку, начинающуюся с =, +, -, @, табуляции
+// или CR, таблица выполнила бы как формулу.
+package csvexport
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Writer — csv.Writer, который экранирует каждую ячейку (SafeCell).
+type Writer struct {
+	w *csv.Writer
+}
+
+// NewWriter создаёт Writer поверх out.
+func NewWriter(out io.Writer) *Writer {
+	return &Writer{w: csv.NewWriter(out)}
+}
+
+// Write пишет строку с экранированными ячейками.
+func (w *Writer) Write(record []string) error {
+	safe := make([]string, len(record))
+	for i, cell := range record {
+		safe[i] = SafeCell(cell)
+	}
+	return w.w.Write(safe)
+}
+
+// Flush сбрасывает буфер в out.
+func (w *Writer) Flush() {
+	w.w.Flush()
+}
+
+// Error — ошибка последнего Write или Flush.
+func (w *Writer) Error() error {
+	return w.w.Error()
+}
+
+// SafeCell добавляет апостроф перед значением, которое таблица приняла бы за формулу.
+func SafeCell(v string) string {
+	if v == "" {
+		return v
+	}
+	switch v[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + v
+	}
+	return v
+}
@@ -0,0 +1,40 @@
+package csvexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeCell(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                       "",
+		"Alice":                  "Alice",
+		"alice@example.com":      "alice@example.com",
+		"2026-05-01T10:00:00Z":   "2026-05-01T10:00:00Z",
+		`=HYPERLINK("http://x")`: `'=HYPERLINK("http://x")`,
+		"+1+2":                   "'+1+2",
+		"-2+3":                   "'-2+3",
+		"@SUM(A1:A2)":            "'@SUM(A1:A2)",
+		"\t=1":                   "'\t=1",
+		"\r=1":                   "'\r=1",
+	} {
+		assert.Equal(t, want, SafeCell(in), in)
+	}
+}
+
+func TestWriter_EscapesEveryCell(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	require.NoError(t, w.Write([]string{"id", "comment"}))
+	require.NoError(t, w.Write([]string{"=1+1", "Great, thanks"}))
+	w.Flush()
+	require.NoError(t, w.Error())
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"id", "comment"}, {"'=1+1", "Great, thanks"}}, rows)
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/shared/authutils"
+	"wdpl_back/internal/shared/config"
+)
+
+// apiKeyScheme — схема заголовка Authorization для API-ключей: "ApiKey <ключ>".
+const apiKeyScheme = "ApiKey "
+
+// localsKeyAPIKeyValidator — ключ APIKeyValidator в fiber.Ctx.Locals (см. WithAPIKeyValidator).
+const localsKeyAPIKeyValidator = "apiKeyValidator"
+
+// APIKeyValidator проверяет API-ключ и возвращает клеймы пользователя, от имени которого он действует
+// (с APIKeyID и Scopes). Неизвестный, отозванный или истёкший ключ и неактивный пользователь — nil, nil.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (*authutils.UserClaims, error)
+}
+
+// WithAPIKeyValidator возвращает middleware, который делает validator доступным RequireAuthOrAPIKey
+// и OptionalAuthOrAPIKey ниже по цепочке. Вешается один раз на группу /api, как WithTokenValidator.
+// Без него запросы с API-ключом получают 401.
+func WithAPIKeyValidator(validator APIKeyValidator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(localsKeyAPIKeyValidator, validator)
+		return c.Next()
+	}
+}
+
+// apiKeyClaims проверяет ключ из заголовка Authorization. ok == false — заголовок не "ApiKey ...".
+func apiKeyClaims(c *fiber.Ctx) (claims *authutils.UserClaims, ok bool, err error) {
+	auth := c.Get("Authorization")
+	if !strings.HasPrefix(auth, apiKeyScheme) {
+		return nil, false, nil
+	}
+	validator, _ := c.Locals(localsKeyAPIKeyValidator).(APIKeyValidator)
+	if validator == nil {
+		return nil, true, nil
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 2*time.Second)
+	defer cancel()
+	claims, err = validator.ValidateAPIKey(ctx, strings.TrimPrefix(auth, apiKeyScheme))
+	return claims, true, err
+}
+
+// RequireAuthOrAPIKey — RequireAuth, который также принимает "Authorization: ApiKey <ключ>" с правом scope.
+// Ключ действует от имени своего пользователя: в c.Locals(LocalsKeyClaims) кладутся его текущие роль и id,
+// поэтому RequireRole и проверки прав в сервисах работают как для JWT. Scope лишь открывает маршрут:
+// ключ без него получает 403, невалидный ключ — 401. Маршруты без этого middleware ключи не принимают.
+func RequireAuthOrAPIKey(cfg *config.Config, scope string) fiber.Handler {
+	requireAuth := RequireAuth(cfg)
+	return func(c *fiber.Ctx) error {
+		claims, ok, err := apiKeyClaims(c)
+		if !ok {
+			return requireAuth(c)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal error"})
+		}
+		if claims == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid api key"})
+		}
+		if !claims.HasScope(scope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "insufficient scope"})
+		}
+		c.Locals(LocalsKeyClaims, claims)
+		return c.Next()
+	}
+}
+
+// OptionalAuthOrAPIKey — OptionalAuth, который также принимает API-ключ с правом scope.
+// Невалидный ключ или ключ без scope, как и невалидный JWT, дают анонимный запрос.
+func OptionalAuthOrAPIKey(cfg *config.Config, scope string) fiber.Handler {
+	optionalAuth := OptionalAuth(cfg)
+	return func(c *fiber.Ctx) error {
+		claims, ok, err := apiKeyClaims(c)
+		if !ok {
+			return optionalAuth(c)
+		}
+		if err == nil && claims != nil && claims.HasScope(scope) {
+			c.Locals(LocalsKeyClaims, claims)
+		}
+		return c.Next()
+	}
+}
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -12,8 +14,40 @@ import (
 // LocalsKeyClaims — ключ для хранения JWT‑клеймов в fiber.Ctx.Locals.
 const LocalsKeyClaims = "claims"
 
+// localsKeyTokenValidator — ключ TokenValidator в fiber.Ctx.Locals (см. WithTokenValidator).
+const localsKeyTokenValidator = "tokenValidator"
+
+// TokenValidator проверяет, что подписанный и не истёкший access-токен всё ещё действует для пользователя:
+// пользователь активен и поколение токенов (claims.Generation) не сменилось.
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, claims *authutils.UserClaims) (bool, error)
+}
+
+// WithTokenValidator возвращает middleware, который делает validator доступным RequireAuth и OptionalAuth
+// ниже по цепочке. Вешается один раз на группу /api, чтобы фичи не передавали его в каждый роутер.
+// Без него проверяются только подпись и срок токена.
+func WithTokenValidator(validator TokenValidator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(localsKeyTokenValidator, validator)
+		return c.Next()
+	}
+}
+
+// tokenStillValid спрашивает TokenValidator (если он подключён), действует ли токен.
+func tokenStillValid(c *fiber.Ctx, claims *authutils.UserClaims) (bool, error) {
+	validator, ok := c.Locals(localsKeyTokenValidator).(TokenValidator)
+	if !ok || validator == nil {
+		return true, nil
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 2*time.Second)
+	defer cancel()
+	return validator.ValidateToken(ctx, claims)
+}
+
 // RequireAuth возвращает middleware: извлекает Bearer JWT, парсит и кладёт клеймы в c.Locals(LocalsKeyClaims).
-// При отсутствии или невалидном токене отвечает 401.
+// При отсутствии или невалидном токене отвечает 401 — в том числе если TokenValidator отверг токен
+// (пользователь деактивирован или сменил роль после выдачи токена). Пользователю с неподтверждённым email
+// (claims.EmailUnverified) разрешены только чтение (GET, HEAD, OPTIONS), на остальное — 403.
 func RequireAuth(cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		auth := c.Get("Authorization")
@@ -29,11 +63,61 @@ func RequireAuth(cfg *config.Config) fiber.Handler {
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
 		}
+		valid, err := tokenStillValid(c, claims)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal error"})
+		}
+		if !valid {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token revoked"})
+		}
+		if claims.EmailUnverified && !isReadOnlyMethod(c.Method()) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "email not verified"})
+		}
+		c.Locals(LocalsKeyClaims, claims)
+		return c.Next()
+	}
+}
+
+// isReadOnlyMethod — метод не меняет данные.
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// OptionalAuth возвращает middleware для публичных эндпоинтов: если передан валидный Bearer JWT,
+// кладёт клеймы в c.Locals(LocalsKeyClaims), иначе пропускает запрос как анонимный.
+// Невалидный, просроченный или отозванный токен не даёт 401 — публичные данные доступны и без него.
+func OptionalAuth(cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		const prefix = "Bearer "
+		auth := c.Get("Authorization")
+		if !strings.HasPrefix(auth, prefix) {
+			return c.Next()
+		}
+		claims, err := authutils.ParseAccessToken(cfg, strings.TrimPrefix(auth, prefix))
+		if err != nil {
+			return c.Next()
+		}
+		if valid, err := tokenStillValid(c, claims); err != nil || !valid {
+			return c.Next()
+		}
 		c.Locals(LocalsKeyClaims, claims)
 		return c.Next()
 	}
 }
 
+// ClaimsFromCtx возвращает клеймы, положенные RequireAuth/OptionalAuth. Для анонимного запроса — nil, false.
+func ClaimsFromCtx(c *fiber.Ctx) (*authutils.UserClaims, bool) {
+	claims, ok := c.Locals(LocalsKeyClaims).(*authutils.UserClaims)
+	if !ok || claims == nil {
+		return nil, false
+	}
+	return claims, true
+}
+
 // RequireRole возвращает middleware: проверяет, что роль пользователя из клеймов входит в allowedRoles.
 // Ожидает, что RequireAuth уже выполнен и клеймы лежат в c.Locals(LocalsKeyClaims).
 func RequireRole(allowedRoles ...string) fiber.Handler {
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"wdpl_back/internal/shared/ratelimit"
+)
+
+// RateLimit возвращает middleware: не больше limit запросов за window с одного IP клиента.
+// name разделяет счётчики разных эндпоинтов. Сверх лимита — 429 с Retry-After (в секундах).
+func RateLimit(store ratelimit.Store, name string, limit int, window time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.Context(), 2*time.Second)
+		defer cancel()
+		ok, retryAfter, err := store.Allow(ctx, name+":ip:"+c.IP(), limit, window)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal error"})
+		}
+		if !ok {
+			SetRetryAfter(c, retryAfter)
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "too many requests"})
+		}
+		return c.Next()
+	}
+}
+
+// SetRetryAfter выставляет заголовок Retry-After: d, округлённое вверх до секунды (не меньше 1).
+func SetRetryAfter(c *fiber.Ctx, d time.Duration) {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(1, int(math.Ceil(d.Seconds())))))
+}
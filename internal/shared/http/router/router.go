@@ -1,31 +1,73 @@
 package router
 
 import (
+	"context"
+
 	"github.com/gofiber/fiber/v2"
 
+	"wdpl_back/internal/features/admin"
+	"wdpl_back/internal/features/apikeys"
 	"wdpl_back/internal/features/auth"
 	"wdpl_back/internal/features/events"
+	"wdpl_back/internal/features/feedback"
+	"wdpl_back/internal/features/organizations"
+	"wdpl_back/internal/features/qa"
+	"wdpl_back/internal/features/registrations"
 	"wdpl_back/internal/features/users"
+	"wdpl_back/internal/features/webhooks"
 	"wdpl_back/internal/shared/config"
 	"wdpl_back/internal/shared/http/middleware"
 	"wdpl_back/internal/shared/logger"
+	"wdpl_back/internal/shared/mail"
+	"wdpl_back/internal/shared/outbox"
 	"wdpl_back/internal/shared/postgres"
 )
 
-// NewFiberApp создаёт и настраивает Fiber‑приложение.
-func NewFiberApp(cfg *config.Config, log logger.Logger, db *postgres.DB) *fiber.App {
-	app := fiber.New(fiber.Config{
+// NewFiberApp создаёт и настраивает Fiber‑приложение. ctx — время жизни приложения: с его отменой
+// останавливаются фоновые задачи (доставка outbox и вебхуков, подписка на изменения событий).
+func NewFiberApp(ctx context.Context, cfg *config.Config, log logger.Logger, db *postgres.DB) *fiber.App {
+	fiberCfg := fiber.Config{
 		Prefork: false,
-	})
+	}
+	// За обратным прокси c.IP() — адрес клиента из X-Forwarded-For (по нему считаются лимиты входа).
+	if proxies := cfg.TrustedProxyList(); len(proxies) > 0 {
+		fiberCfg.ProxyHeader = fiber.HeaderXForwardedFor
+		fiberCfg.EnableTrustedProxyCheck = true
+		fiberCfg.TrustedProxies = proxies
+		fiberCfg.EnableIPValidation = true
+	}
+	app := fiber.New(fiberCfg)
 
 	app.Use(middleware.RequestLogger(log))
 
 	// TODO: добавить middleware: request_id, CORS.
 
-	api := app.Group("/api")
-	auth.RegisterRoutes(api, db, cfg)
-	events.RegisterRoutes(api, db, cfg)
+	// Доменные события фич (outbox) доставляются в фоне; подписчиков регистрируют до запуска.
+	dispatcher := outbox.NewDispatcher(outbox.NewPostgresStore(db), log)
+
+	mailer := mail.New(cfg, log)
+
+	// RequireAuth/OptionalAuth во всех фичах сверяют токен с текущими ролью, активностью и поколением пользователя.
+	// API-ключи принимают только маршруты с RequireAuthOrAPIKey/OptionalAuthOrAPIKey.
+	api := app.Group("/api",
+		middleware.WithTokenValidator(auth.NewTokenValidator(auth.NewPostgresRepository(db))),
+		middleware.WithAPIKeyValidator(apikeys.NewValidator(db, cfg)),
+	)
+	// Двухфакторная аутентификация обязательна (MFA_REQUIRED_FOR_EDITORS) тем, кто может публиковать:
+	// глобальным редакторам, редакторам и владельцам команд событий, владельцам и администраторам организаций.
+	publishers := events.NewPublishRights(events.NewPostgresRepository(db).Members, organizations.NewPostgresRepository(db))
+	auth.RegisterRoutes(api, db, cfg, log, mailer, events.DraftEditorRoles, publishers)
+	organizations.RegisterRoutes(api, db, cfg)
+	events.RegisterRoutes(ctx, api, db, cfg)
 	users.RegisterRoutes(api, db, cfg)
+	registrations.RegisterRoutes(api, db, cfg)
+	feedback.RegisterRoutes(api, db, cfg)
+	qa.RegisterRoutes(api, db, cfg)
+	webhooks.RegisterRoutes(ctx, api, db, cfg, log, dispatcher)
+	admin.RegisterRoutes(api, db, cfg)
+	apikeys.RegisterRoutes(api, db, cfg)
+
+	go dispatcher.Run(ctx)
 
 	// healthz — конвенция из Kubernetes (liveness/readiness). Суффикс "z" отличает от путей вроде /health/...
 	app.Get("/healthz", func(c *fiber.Ctx) error {
@@ -0,0 +1,49 @@
+// Package sse — минимальная запись Server-Sent Events поверх Fiber (fasthttp stream writer).
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Event — одно SSE-сообщение. Пустые ID и Name не пишутся.
+type Event struct {
+	ID   string
+	Name string
+	Data []byte
+}
+
+// SetHeaders выставляет заголовки потока. Вызывать до c.Context().SetBodyStreamWriter.
+// X-Accel-Buffering отключает буферизацию в nginx, иначе события приходят пачками.
+func SetHeaders(c *fiber.Ctx) {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+}
+
+// WriteEvent пишет событие и сбрасывает буфер.
+// Ошибка означает, что клиент отключился — цикл стрима нужно завершить.
+func WriteEvent(w *bufio.Writer, e Event) error {
+	if e.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", e.ID)
+	}
+	if e.Name != "" {
+		fmt.Fprintf(w, "event: %s\n", e.Name)
+	}
+	// Перевод строки внутри data разрывает сообщение — каждая строка идёт отдельным полем data.
+	for _, line := range bytes.Split(e.Data, []byte("\n")) {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	w.WriteString("\n")
+	return w.Flush()
+}
+
+// WriteComment пишет комментарий (keep-alive): клиенты его игнорируют, а прокси не рвут соединение.
+func WriteComment(w *bufio.Writer, text string) error {
+	fmt.Fprintf(w, ": %s\n\n", text)
+	return w.Flush()
+}
@@ -0,0 +1,35 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteEvent(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+
+	err := WriteEvent(w, Event{ID: "7", Name: "live", Data: []byte("line1\nline2")})
+	require.NoError(t, err)
+	assert.Equal(t, "id: 7\nevent: live\ndata: line1\ndata: line2\n\n", buf.String())
+}
+
+func TestWriteEvent_OnlyData(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+
+	require.NoError(t, WriteEvent(w, Event{Data: []byte(`{"ok":true}`)}))
+	assert.Equal(t, "data: {\"ok\":true}\n\n", buf.String())
+}
+
+func TestWriteComment(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+
+	require.NoError(t, WriteComment(w, "ping"))
+	assert.Equal(t, ": ping\n\n", buf.String())
+}
@@ -0,0 +1,52 @@
+// Package mail — отправка писем (сброс пароля, подтверждение email) через интерфейс Mailer:
+// SMTP в окружениях с почтовым сервером (локально — MailHog) и логирование в dev без SMTP.
+package mail
+
+import (
+	"context"
+
+	"wdpl_back/internal/shared/config"
+	"wdpl_back/internal/shared/logger"
+)
+
+// Message — текстовое письмо одному получателю.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer отправляет письма.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New выбирает Mailer по конфигурации: SMTP, если задан SMTP_HOST, иначе — письма пишутся в лог.
+func New(cfg *config.Config, log logger.Logger) Mailer {
+	if cfg.SMTPHost == "" {
+		return NewLogMailer(log)
+	}
+	return NewSMTPMailer(SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.MailFrom,
+	})
+}
+
+// LogMailer пишет письма в лог вместо отправки — для разработки без почтового сервера.
+// Ссылки с токенами попадают в лог, поэтому в production его не используют.
+type LogMailer struct {
+	log logger.Logger
+}
+
+// NewLogMailer создаёт LogMailer.
+func NewLogMailer(log logger.Logger) *LogMailer {
+	return &LogMailer{log: log}
+}
+
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	m.log.Info("mail (not sent, SMTP_HOST is empty)", "to", msg.To, "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTP — минимальный SMTP-сервер без TLS и AUTH (как MailHog): принимает одно письмо.
+type fakeSMTP struct {
+	listener net.Listener
+	from     string
+	rcpt     string
+	data     chan string
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTP{listener: l, data: make(chan string, 1)}
+	t.Cleanup(func() { l.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250-fake")
+			_ = tp.PrintfLine("250 8BITMIME")
+		case "MAIL":
+			s.from = line
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			s.rcpt = line
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			lines, err := tp.ReadDotLines()
+			if err != nil {
+				return
+			}
+			s.data <- strings.Join(lines, "\n")
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	srv := startFakeSMTP(t)
+	mailer := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: srv.port(), From: "no-reply@wdpl.test"})
+
+	err := mailer.Send(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Сброс пароля",
+		Text:    "Ссылка:\nhttps://app.test/reset-password?token=abc",
+	})
+	require.NoError(t, err)
+
+	body := <-srv.data
+	assert.Equal(t, "MAIL FROM:<no-reply@wdpl.test> BODY=8BITMIME", srv.from)
+	assert.Equal(t, "RCPT TO:<user@example.com>", srv.rcpt)
+	assert.Contains(t, body, "To: user@example.com")
+	assert.Contains(t, body, "Subject: =?utf-8?q?")
+	assert.Contains(t, body, "https://app.test/reset-password?token=abc")
+}
+
+func TestSMTPMailer_DialError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	mailer := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: port, From: "no-reply@wdpl.test"})
+	err = mailer.Send(context.Background(), Message{To: "user@example.com", Subject: "x", Text: "y"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "smtp dial")
+}
@@ -0,0 +1,53 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wdpl_back/internal/shared/oidc"
+	"wdpl_back/internal/shared/oidc/oidctest"
+)
+
+func TestProvider_ExchangeVerifiesIDToken(t *testing.T) {
+	srv := oidctest.NewServer(t)
+	p := oidc.NewProvider(srv.Config("https://app.example.com/cb"), srv.Client())
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge("verifier-1"))
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com/cb", u.Query().Get("redirect_uri"))
+
+	code, state := srv.Authorize(t, authURL, oidctest.User{Subject: "sub", Email: "A@Example.com", EmailVerified: true})
+	assert.Equal(t, "state-1", state)
+
+	_, err = p.Exchange(ctx, code, "wrong-verifier")
+	assert.ErrorIs(t, err, oidc.ErrExchangeFailed)
+
+	code, _ = srv.Authorize(t, authURL, oidctest.User{Subject: "sub", Email: "A@Example.com", EmailVerified: true})
+	token, err := p.Exchange(ctx, code, "verifier-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sub", token.Subject)
+	assert.Equal(t, "a@example.com", token.Email)
+	assert.True(t, token.EmailVerified)
+	assert.Equal(t, "nonce-1", token.Nonce)
+}
+
+func TestProvider_RejectsForeignIssuer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issuer":"https://evil.example","authorization_endpoint":"x","token_endpoint":"x","jwks_uri":"x"}`))
+	}))
+	defer srv.Close()
+
+	p := oidc.NewProvider(oidc.Config{IssuerURL: srv.URL, ClientID: "c"}, srv.Client())
+	_, err := p.AuthCodeURL(context.Background(), "s", "n", "c")
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"wdpl_back/internal/shared/logger"
+)
+
+// Параметры доставки по умолчанию.
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 50
+	// defaultLease — сколько сообщение считается занятым репликой; должно быть больше времени доставки.
+	defaultLease       = time.Minute
+	defaultMaxAttempts = 10
+	maxRetryDelay      = 5 * time.Minute
+)
+
+// Handler обрабатывает доменное событие. Ошибка — повторить доставку позже.
+type Handler func(ctx context.Context, msg *Message) error
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// Dispatcher доставляет сообщения outbox подписчикам процесса с повторами и экспоненциальной паузой.
+// Сообщение считается доставленным, когда его обработали все подписчики типа; при ошибке
+// повторяется доставка всем (обработчики идемпотентны). Сообщения без подписчиков отмечаются доставленными.
+type Dispatcher struct {
+	store Store
+	log   logger.Logger
+
+	mu   sync.RWMutex
+	subs map[string][]subscriber
+
+	pollInterval time.Duration
+	batchSize    int
+	lease        time.Duration
+	maxAttempts  int
+	now          func() time.Time
+}
+
+// NewDispatcher создаёт диспетчер поверх store. Подписчиков регистрируют до Run.
+func NewDispatcher(store Store, log logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		log:          log,
+		subs:         make(map[string][]subscriber),
+		pollInterval: defaultPollInterval,
+		batchSize:    defaultBatchSize,
+		lease:        defaultLease,
+		maxAttempts:  defaultMaxAttempts,
+		now:          time.Now,
+	}
+}
+
+// Subscribe регистрирует обработчик событий типа eventType. name — для логов.
+func (d *Dispatcher) Subscribe(eventType, name string, handler Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subs[eventType] = append(d.subs[eventType], subscriber{name: name, handler: handler})
+}
+
+// Run опрашивает outbox до отмены ctx. Запускать в отдельной горутине.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		// Полная пачка — возможно, в очереди ещё есть сообщения: берём следующую без паузы.
+		n, err := d.dispatchBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.log.Error("outbox dispatch failed", "error", err)
+		}
+		if err == nil && n == d.batchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchBatch берёт одну пачку сообщений и доставляет их. Возвращает размер пачки.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	msgs, err := d.store.Claim(ctx, d.batchSize, d.lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, msg := range msgs {
+		if err := d.deliver(ctx, msg); err != nil {
+			retryAt := d.now().Add(retryDelay(msg.Attempts))
+			dead := msg.Attempts >= d.maxAttempts
+			if dead {
+				d.log.Error("outbox message dropped after max attempts",
+					"id", msg.ID, "type", msg.Type, "aggregate", msg.AggregateID, "attempts", msg.Attempts, "error", err)
+			} else {
+				d.log.Warn("outbox delivery failed, will retry",
+					"id", msg.ID, "type", msg.Type, "attempt", msg.Attempts, "retry_at", retryAt, "error", err)
+			}
+			if err := d.store.MarkFailed(ctx, msg.ID, retryAt, err.Error(), dead); err != nil {
+				return len(msgs), err
+			}
+			continue
+		}
+		if err := d.store.MarkDelivered(ctx, msg.ID); err != nil {
+			return len(msgs), err
+		}
+	}
+	return len(msgs), nil
+}
+
+// deliver вызывает всех подписчиков типа; паника обработчика считается ошибкой доставки.
+func (d *Dispatcher) deliver(ctx context.Context, msg *Message) error {
+	d.mu.RLock()
+	subs := d.subs[msg.Type]
+	d.mu.RUnlock()
+	for _, sub := range subs {
+		if err := callHandler(ctx, sub.handler, msg); err != nil {
+			return fmt.Errorf("%s: %w", sub.name, err)
+		}
+	}
+	return nil
+}
+
+func callHandler(ctx context.Context, handler Handler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+// retryDelay — пауза перед попыткой attempt+1: 2s, 4s, 8s… не больше maxRetryDelay.
+func retryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 16 {
+		return maxRetryDelay
+	}
+	delay := time.Second << attempt
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
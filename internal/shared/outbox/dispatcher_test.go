@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore — in-memory Store с той же семантикой, что у Postgres: одно сообщение на агрегат за раз.
+type memoryStore struct {
+	now  func() time.Time
+	msgs []*storedMessage
+}
+
+type storedMessage struct {
+	msg       Message
+	retryAt   time.Time
+	delivered bool
+	dead      bool
+	lastErr   string
+}
+
+func (s *memoryStore) add(aggregateID, eventType string) {
+	s.msgs = append(s.msgs, &storedMessage{msg: Message{
+		ID: int64(len(s.msgs) + 1), AggregateType: "event", AggregateID: aggregateID, Type: eventType,
+	}})
+}
+
+func (s *memoryStore) Claim(_ context.Context, limit int, _ time.Duration) ([]*Message, error) {
+	blocked := make(map[string]bool)
+	var list []*Message
+	for _, m := range s.msgs {
+		if m.delivered || m.dead {
+			continue
+		}
+		if blocked[m.msg.AggregateID] {
+			continue
+		}
+		blocked[m.msg.AggregateID] = true
+		if m.retryAt.After(s.now()) || len(list) == limit {
+			continue
+		}
+		m.msg.Attempts++
+		msg := m.msg
+		list = append(list, &msg)
+	}
+	return list, nil
+}
+
+func (s *memoryStore) find(id int64) *storedMessage {
+	return s.msgs[id-1]
+}
+
+func (s *memoryStore) MarkDelivered(_ context.Context, id int64) error {
+	s.find(id).delivered = true
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(_ context.Context, id int64, retryAt time.Time, lastErr string, dead bool) error {
+	m := s.find(id)
+	m.retryAt, m.lastErr, m.dead = retryAt, lastErr, dead
+	return nil
+}
+
+func newTestDispatcher(store *memoryStore, now *time.Time) *Dispatcher {
+	store.now = func() time.Time { return *now }
+	d := NewDispatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.now = store.now
+	return d
+}
+
+func TestDispatcher_DeliversInOrderPerAggregateWithRetries(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := &memoryStore{}
+	store.add("a", "event.published")
+	store.add("a", "event.day.changed")
+	store.add("b", "event.published")
+	d := newTestDispatcher(store, &now)
+
+	var delivered []int64
+	failFirst := true
+	d.Subscribe("event.published", "test", func(_ context.Context, msg *Message) error {
+		if msg.ID == 1 && failFirst {
+			failFirst = false
+			return errors.New("temporary")
+		}
+		delivered = append(delivered, msg.ID)
+		return nil
+	})
+	d.Subscribe("event.day.changed", "test", func(_ context.Context, msg *Message) error {
+		delivered = append(delivered, msg.ID)
+		return nil
+	})
+
+	// Первое сообщение агрегата a упало — второе ждёт; агрегат b не блокируется.
+	_, err := d.dispatchBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, delivered)
+	assert.Equal(t, now.Add(2*time.Second), store.find(1).retryAt)
+	assert.Contains(t, store.find(1).lastErr, "test: temporary")
+
+	// До retryAt повторов нет.
+	n, err := d.dispatchBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	now = now.Add(2 * time.Second)
+	_, err = d.dispatchBatch(context.Background())
+	require.NoError(t, err)
+	_, err = d.dispatchBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 1, 2}, delivered)
+}
+
+func TestDispatcher_DropsAfterMaxAttemptsAndRecoversPanics(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := &memoryStore{}
+	store.add("a", "draft.saved")
+	store.add("a", "event.published")
+	d := newTestDispatcher(store, &now)
+	d.maxAttempts = 2
+
+	d.Subscribe("draft.saved", "broken", func(context.Context, *Message) error {
+		panic("boom")
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := d.dispatchBatch(context.Background())
+		require.NoError(t, err)
+		now = now.Add(maxRetryDelay)
+	}
+	assert.True(t, store.find(1).dead)
+	assert.Contains(t, store.find(1).lastErr, "panic: boom")
+	// Без подписчиков сообщение просто отмечается доставленным; мёртвое не держит очередь.
+	assert.True(t, store.find(2).delivered)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 2*time.Second, retryDelay(1))
+	assert.Equal(t, 16*time.Second, retryDelay(4))
+	assert.Equal(t, maxRetryDelay, retryDelay(9))
+	assert.Equal(t, maxRetryDelay, retryDelay(100))
+}
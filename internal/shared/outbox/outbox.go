@@ -0,0 +1,71 @@
+// Package outbox — транзакционный outbox: доменные события пишутся в public.outbox в той же транзакции,
+// что и изменение, а Dispatcher в фоне доставляет их подписчикам внутри процесса.
+// Доставка «хотя бы один раз»: обработчики должны быть идемпотентными.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Message — доменное событие в outbox. Порядок доставки гарантируется в пределах агрегата
+// (AggregateType + AggregateID): следующее сообщение агрегата ждёт, пока предыдущее не доставлено.
+type Message struct {
+	ID            int64
+	AggregateType string
+	AggregateID   string
+	Type          string
+	Payload       json.RawMessage
+	// Attempts — номер текущей попытки доставки (с 1).
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// NewMessage собирает сообщение, сериализуя payload в JSON.
+func NewMessage(aggregateType, aggregateID, eventType string, payload any) (*Message, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Type:          eventType,
+		Payload:       data,
+	}, nil
+}
+
+// Writer добавляет сообщения в outbox. Реализация привязана к транзакции изменения.
+type Writer interface {
+	Append(ctx context.Context, msgs ...*Message) error
+}
+
+// RowQuerier — общее у *sql.DB и *sql.Tx, достаточное для записи в outbox (INSERT … RETURNING).
+type RowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type postgresWriter struct {
+	q RowQuerier
+}
+
+// NewPostgresWriter возвращает Writer поверх транзакции (или соединения) q.
+func NewPostgresWriter(q RowQuerier) Writer {
+	return &postgresWriter{q: q}
+}
+
+func (w *postgresWriter) Append(ctx context.Context, msgs ...*Message) error {
+	for _, msg := range msgs {
+		err := w.q.QueryRowContext(ctx, `
+			INSERT INTO public.outbox (aggregate_type, aggregate_id, event_type, payload)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`, msg.AggregateType, msg.AggregateID, msg.Type, []byte(msg.Payload)).Scan(&msg.ID, &msg.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
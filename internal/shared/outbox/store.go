@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wdpl_back/internal/shared/postgres"
+)
+
+// Store — очередь outbox для Dispatcher.
+type Store interface {
+	// Claim берёт до limit сообщений, готовых к доставке, и арендует их на lease: пока аренда
+	// не истекла, другие реплики их не возьмут. Из каждого агрегата — только самое раннее
+	// недоставленное сообщение. Attempts увеличивается. Результат упорядочен по ID.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*Message, error)
+	// MarkDelivered отмечает сообщение доставленным.
+	MarkDelivered(ctx context.Context, id int64) error
+	// MarkFailed снимает аренду и откладывает следующую попытку до retryAt.
+	// dead — попытки исчерпаны: сообщение больше не доставляется и не держит очередь агрегата.
+	MarkFailed(ctx context.Context, id int64, retryAt time.Time, lastErr string, dead bool) error
+}
+
+type postgresStore struct {
+	db *postgres.DB
+}
+
+// NewPostgresStore возвращает Store поверх public.outbox.
+func NewPostgresStore(db *postgres.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]*Message, error) {
+	// SKIP LOCKED — параллельные реплики не ждут друг друга; NOT EXISTS держит порядок внутри агрегата:
+	// пока раннее сообщение не доставлено (в том числе арендовано другой репликой), следующее не берём.
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE public.outbox o
+		SET locked_until = now() + make_interval(secs => $2), attempts = o.attempts + 1
+		WHERE o.id IN (
+			SELECT p.id FROM public.outbox p
+			WHERE p.delivered_at IS NULL AND p.dead_at IS NULL
+				AND p.next_attempt_at <= now()
+				AND (p.locked_until IS NULL OR p.locked_until < now())
+				AND NOT EXISTS (
+					SELECT 1 FROM public.outbox e
+					WHERE e.aggregate_type = p.aggregate_type AND e.aggregate_id = p.aggregate_id
+						AND e.delivered_at IS NULL AND e.dead_at IS NULL AND e.id < p.id
+				)
+			ORDER BY p.id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING o.id, o.aggregate_type, o.aggregate_id, o.event_type, o.payload, o.attempts, o.created_at
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*Message
+	for rows.Next() {
+		var msg Message
+		var payload []byte
+		if err := rows.Scan(&msg.ID, &msg.AggregateType, &msg.AggregateID, &msg.Type, &payload, &msg.Attempts, &msg.CreatedAt); err != nil {
+			return nil, err
+		}
+		msg.Payload = payload
+		list = append(list, &msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING не гарантирует порядок.
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (s *postgresStore) MarkDelivered(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE public.outbox SET delivered_at = now(), locked_until = NULL, last_error = NULL WHERE id = $1
+	`, id)
+	return err
+}
+
+func (s *postgresStore) MarkFailed(ctx context.Context, id int64, retryAt time.Time, lastErr string, dead bool) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE public.outbox
+		SET locked_until = NULL, next_attempt_at = $2, last_error = $3,
+			dead_at = CASE WHEN $4 THEN now() ELSE NULL END
+		WHERE id = $1
+	`, id, retryAt, lastErr, dead)
+	return err
+}
@@ -18,8 +18,10 @@ import (
 var migrationsFS embed.FS
 
 // DB — тонкая обёртка над *sql.DB, чтобы при необходимости заменить реализацию.
+// log — логгер подключения: нужен фоновым задачам поверх БД (Listen), у которых нет своего.
 type DB struct {
 	*sql.DB
+	log logger.Logger
 }
 
 func Connect(cfg *config.Config, log logger.Logger) (*DB, error) {
@@ -38,7 +40,7 @@ func Connect(cfg *config.Config, log logger.Logger) (*DB, error) {
 
 	log.Info("connected to database")
 
-	return &DB{DB: db}, nil
+	return &DB{DB: db, log: log}, nil
 }
 
 // RunMigrations применяет простые SQL‑миграции из каталога migrations.
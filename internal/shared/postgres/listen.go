@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// listenRetryDelay — пауза перед переподключением LISTEN после ошибки.
+const listenRetryDelay = 2 * time.Second
+
+// NotificationHandler получает уведомления Postgres (NOTIFY) из канала Listen.
+type NotificationHandler interface {
+	HandleNotification(payload string)
+	// HandleReconnect вызывается после переподключения: уведомления за время разрыва потеряны,
+	// подписчикам нужно перечитать состояние из БД.
+	HandleReconnect()
+}
+
+// Listen слушает канал Postgres (LISTEN) на отдельном соединении из пула и передаёт уведомления handler.
+// Блокирует до отмены ctx; при обрыве соединения переподключается. Запускать в отдельной горутине.
+func (db *DB) Listen(ctx context.Context, channel string, handler NotificationHandler) {
+	for connected := false; ctx.Err() == nil; {
+		err := db.listenOnce(ctx, channel, handler, connected)
+		if ctx.Err() != nil {
+			return
+		}
+		connected = true
+		if db.log != nil {
+			db.log.Error("postgres listen failed, reconnecting", "channel", channel, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (db *DB) listenOnce(ctx context.Context, channel string, handler NotificationHandler, reconnect bool) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	// Соединение после LISTEN не возвращаем в пул: ErrBadConn из Raw помечает его сломанным,
+	// и Close закрывает его, а не отдаёт чужому запросу вместе с подпиской.
+	defer func() {
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		_ = conn.Close()
+	}()
+
+	return conn.Raw(func(driverConn any) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return fmt.Errorf("listen %s: %w", channel, err)
+		}
+		if reconnect {
+			handler.HandleReconnect()
+		}
+		for {
+			n, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			handler.HandleNotification(n.Payload)
+		}
+	})
+}
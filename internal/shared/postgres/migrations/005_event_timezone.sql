@@ -0,0 +1,5 @@
+-- Часовой пояс события (IANA, например Europe/Moscow). Нужен, чтобы понимать «сегодня» на площадке:
+-- какой день расписания сейчас идёт (экраны «сейчас и далее»).
+
+ALTER TABLE public.events ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE public.event_drafts ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,15 @@
+-- Личная программа: сессии, отмеченные пользователем (фича users).
+-- Сессии живут внутри JSONB event_days.schedule, поэтому ключ — (user_id, event_id, session_id),
+-- а session_id — текстовый id из JSONB. При перепубликации с теми же id закладки сохраняются.
+-- Название и время сессии на момент добавления храним, чтобы показать удалённую из программы сессию.
+
+CREATE TABLE IF NOT EXISTS public.session_bookmarks (
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES public.events (id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL,
+    session_title TEXT NOT NULL,
+    session_starts_at TIMESTAMPTZ NOT NULL,
+    session_ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, event_id, session_id)
+);
@@ -0,0 +1,29 @@
+-- Регистрации участников (RSVP) на опубликованные события и отдельные сессии.
+-- Лимит события — колонка capacity (NULL — без ограничения), лимит сессии — поле capacity в JSONB schedule.
+
+ALTER TABLE public.events ADD COLUMN IF NOT EXISTS capacity INT NULL;
+ALTER TABLE public.event_drafts ADD COLUMN IF NOT EXISTS capacity INT NULL;
+
+-- session_id = '' — регистрация на событие целиком, иначе — id сессии из JSONB.
+-- status: confirmed | waitlisted | cancelled. Очередь ожидания — FIFO по created_at.
+CREATE TABLE IF NOT EXISTS public.registrations (
+    id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES public.events (id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL DEFAULT '',
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    promoted_at TIMESTAMPTZ NULL,
+    cancelled_at TIMESTAMPTZ NULL
+);
+
+-- Одна активная регистрация пользователя на событие/сессию; отменённые не мешают зарегистрироваться снова.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_registrations_active
+    ON public.registrations (event_id, session_id, user_id) WHERE status <> 'cancelled';
+
+CREATE INDEX IF NOT EXISTS idx_registrations_event_session_status
+    ON public.registrations (event_id, session_id, status, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_registrations_user_id
+    ON public.registrations (user_id);
@@ -0,0 +1,6 @@
+-- Отметка прохода по билету (QR) на входе. NULL — ещё не проходил.
+ALTER TABLE public.registrations ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMPTZ NULL;
+ALTER TABLE public.registrations ADD COLUMN IF NOT EXISTS checked_in_by UUID NULL REFERENCES auth.users (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_registrations_event_checked_in
+    ON public.registrations (event_id, checked_in_at) WHERE checked_in_at IS NOT NULL;
@@ -0,0 +1,19 @@
+-- Оценки сессий участниками (фича feedback): 1–5 и комментарий, одна оценка на пользователя и сессию.
+-- session_id — текстовый id из JSONB event_days.schedule. Название и начало сессии храним на момент оценки,
+-- чтобы результаты не терялись после перепубликации без этой сессии.
+
+CREATE TABLE IF NOT EXISTS public.session_feedback (
+    id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES public.events (id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT NULL,
+    session_title TEXT NOT NULL,
+    session_starts_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (event_id, session_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_feedback_event_id
+    ON public.session_feedback (event_id, session_id);
@@ -0,0 +1,32 @@
+-- Вопросы к сессиям с голосованием (фича qa). session_id — текстовый id из JSONB event_days.schedule.
+
+-- Связь аккаунта с персоной из расписания (participants[].person_id): модератор сессии —
+-- пользователь, чей person_id указан среди её участников с ролью moderator. Заполняется администратором.
+ALTER TABLE public.user_profiles ADD COLUMN IF NOT EXISTS person_id UUID NULL;
+CREATE INDEX IF NOT EXISTS idx_user_profiles_person_id ON public.user_profiles (person_id);
+
+CREATE TABLE IF NOT EXISTS public.session_questions (
+    id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES public.events (id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    hidden BOOLEAN NOT NULL DEFAULT false,
+    pinned BOOLEAN NOT NULL DEFAULT false,
+    answered_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_questions_session
+    ON public.session_questions (event_id, session_id, created_at);
+
+-- Голос — строка, а не счётчик: повторный голос игнорируется, число голосов считается count(*).
+CREATE TABLE IF NOT EXISTS public.question_votes (
+    question_id UUID NOT NULL REFERENCES public.session_questions (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (question_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_votes_user_id ON public.question_votes (user_id);
@@ -0,0 +1,14 @@
+-- Журнал изменений опубликованных событий для SSE GET /api/events/:id/stream.
+-- id — монотонный номер: он же SSE id, по Last-Event-ID клиент догружает пропущенное.
+-- Записи добавляются при PublishDraft вместе с NOTIFY event_changes (payload — event_id) в одной транзакции.
+
+CREATE TABLE IF NOT EXISTS public.event_changes (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES public.events (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    date DATE NULL,
+    session_id TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_changes_event_id ON public.event_changes (event_id, id);
@@ -0,0 +1,23 @@
+-- Транзакционный outbox (internal/shared/outbox): доменные события пишутся в той же транзакции,
+-- что и изменение, Dispatcher доставляет их подписчикам. Порядок — по id внутри агрегата.
+-- locked_until — аренда на время доставки; dead_at — попытки исчерпаны, сообщение не держит очередь агрегата.
+
+CREATE TABLE IF NOT EXISTS public.outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ NULL,
+    last_error TEXT NULL,
+    delivered_at TIMESTAMPTZ NULL,
+    dead_at TIMESTAMPTZ NULL
+);
+
+-- Очередь: только ожидающие доставки сообщения.
+CREATE INDEX IF NOT EXISTS idx_outbox_pending
+    ON public.outbox (aggregate_type, aggregate_id, id)
+    WHERE delivered_at IS NULL AND dead_at IS NULL;
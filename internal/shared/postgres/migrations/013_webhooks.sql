@@ -0,0 +1,54 @@
+-- Исходящие вебхуки (фича webhooks): подписки партнёров на доменные события из outbox,
+-- очередь доставок и журнал попыток с кодами ответа.
+
+CREATE TABLE IF NOT EXISTS public.webhook_subscriptions (
+    id UUID PRIMARY KEY,
+    url TEXT NOT NULL,
+    -- secret хранится открыто: он нужен для HMAC-подписи каждой доставки.
+    secret TEXT NOT NULL,
+    event_types JSONB NOT NULL DEFAULT '[]'::jsonb,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    description TEXT NULL,
+    created_by UUID NOT NULL REFERENCES auth.users (id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Одна доставка на пару (подписка, сообщение outbox): повторная передача сообщения не дублирует запросы.
+-- status: pending | succeeded | failed. body — готовое тело запроса (повторы и replay шлют те же байты).
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES public.webhook_subscriptions (id) ON DELETE CASCADE,
+    message_id BIGINT NOT NULL,
+    event_type TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ NULL,
+    last_status_code INT NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ NULL,
+    UNIQUE (subscription_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending
+    ON public.webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription
+    ON public.webhook_deliveries (subscription_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS public.webhook_delivery_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id UUID NOT NULL REFERENCES public.webhook_deliveries (id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    status_code INT NULL,
+    error TEXT NULL,
+    response_body TEXT NULL,
+    duration_ms BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery
+    ON public.webhook_delivery_attempts (delivery_id, id);
@@ -0,0 +1,13 @@
+-- Сброс пароля по ссылке из письма (фича auth). Хранится только SHA-256 токена; токен одноразовый (used_at).
+
+CREATE TABLE IF NOT EXISTS auth.password_reset_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_password_reset_tokens_user_id
+    ON auth.password_reset_tokens (user_id);
@@ -0,0 +1,25 @@
+-- Подтверждение email (фича auth). Пользователи, зарегистрированные до появления колонки, считаются подтверждёнными:
+-- заполняем email_verified_at только в момент добавления колонки, чтобы повторный прогон миграций не подтвердил новых.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'auth' AND table_name = 'users' AND column_name = 'email_verified_at'
+    ) THEN
+        ALTER TABLE auth.users ADD COLUMN email_verified_at TIMESTAMPTZ NULL;
+        UPDATE auth.users SET email_verified_at = created_at;
+    END IF;
+END $$;
+
+-- Токены из письма подтверждения: хранится только SHA-256, токен одноразовый (used_at).
+CREATE TABLE IF NOT EXISTS auth.email_verification_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_email_verification_tokens_user_id
+    ON auth.email_verification_tokens (user_id);
@@ -0,0 +1,11 @@
+-- Ротация refresh-токенов (фича auth): каждый refresh гасит старый токен и выдаёт новый в той же семье (family_id).
+-- replaced_by — чем заменён токен; повторное предъявление заменённого токена отзывает всю семью.
+ALTER TABLE auth.refresh_tokens ADD COLUMN IF NOT EXISTS family_id UUID NULL;
+ALTER TABLE auth.refresh_tokens ADD COLUMN IF NOT EXISTS replaced_by UUID NULL;
+
+-- Токены, выданные до ротации, — каждый сам себе семья.
+UPDATE auth.refresh_tokens SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE auth.refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_auth_refresh_tokens_family_id
+    ON auth.refresh_tokens (family_id);
@@ -0,0 +1,13 @@
+-- Refresh-токены хранятся как HMAC-SHA256 с ключом REFRESH_SECRET (token_hash), а не открытым текстом.
+-- Пересчитать хеш старых токенов в SQL нельзя (секрет есть только у приложения), поэтому открытые
+-- значения стираются, а сами токены отзываются: пользователям со старыми токенами нужно войти заново.
+-- Колонка token остаётся (на неё ссылается индекс из 001_auth_init.sql) и больше не заполняется.
+ALTER TABLE auth.refresh_tokens ADD COLUMN IF NOT EXISTS token_hash TEXT NULL;
+ALTER TABLE auth.refresh_tokens ALTER COLUMN token DROP NOT NULL;
+
+UPDATE auth.refresh_tokens
+SET revoked_at = COALESCE(revoked_at, now()), token = NULL
+WHERE token IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_refresh_tokens_token_hash
+    ON auth.refresh_tokens (token_hash);
@@ -0,0 +1,18 @@
+-- Поколение токенов пользователя (фича auth). Входит в access JWT (claim gen); RequireAuth отвергает токены
+-- прошлых поколений. Триггер увеличивает поколение при смене роли или активности — при любом UPDATE,
+-- в том числе ручном, поэтому изменение доходит до уже выданных токенов без участия приложения.
+ALTER TABLE auth.users ADD COLUMN IF NOT EXISTS token_generation INTEGER NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION auth.bump_token_generation() RETURNS trigger AS $$
+BEGIN
+    IF NEW.role IS DISTINCT FROM OLD.role OR NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        NEW.token_generation := OLD.token_generation + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_auth_users_token_generation ON auth.users;
+CREATE TRIGGER trg_auth_users_token_generation
+    BEFORE UPDATE OF role, is_active ON auth.users
+    FOR EACH ROW EXECUTE FUNCTION auth.bump_token_generation();
@@ -0,0 +1,3 @@
+-- Последнее использование refresh-токена (вход или refresh) — для списка активных сессий (фича auth).
+ALTER TABLE auth.refresh_tokens ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMPTZ NULL;
+UPDATE auth.refresh_tokens SET last_used_at = created_at WHERE last_used_at IS NULL;
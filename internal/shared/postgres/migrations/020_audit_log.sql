@@ -0,0 +1,21 @@
+-- Журнал аудита (internal/shared/audit): кто, когда и что изменил. Только INSERT — записи не меняются
+-- и не удаляются приложением. actor_id без внешнего ключа: запись переживает удаление пользователя.
+
+CREATE TABLE IF NOT EXISTS public.audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id UUID NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    before JSONB NULL,
+    after JSONB NULL,
+    ip TEXT NULL,
+    user_agent TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target
+    ON public.audit_log (target_type, target_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor
+    ON public.audit_log (actor_id, created_at DESC);
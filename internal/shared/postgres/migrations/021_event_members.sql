@@ -0,0 +1,27 @@
+-- Команды событий (фича events): доступ к черновикам, дням и публикации — по членству, а не по глобальной роли.
+-- event_id без внешнего ключа: у нового события есть только черновик (id черновика = id будущего события).
+-- Авторы существующих черновиков становятся владельцами — только при создании таблицы, чтобы повторный
+-- прогон миграций не вернул в команду удалённых из неё.
+DO $$
+BEGIN
+    IF to_regclass('public.event_members') IS NULL THEN
+        CREATE TABLE public.event_members (
+            event_id UUID NOT NULL,
+            user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+            role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+            invited_by UUID NULL REFERENCES auth.users (id) ON DELETE SET NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+            PRIMARY KEY (event_id, user_id)
+        );
+
+        INSERT INTO public.event_members (event_id, user_id, role)
+        SELECT DISTINCT COALESCE(event_id, id), created_by, 'owner'
+        FROM public.event_drafts
+        WHERE created_by IS NOT NULL
+        ON CONFLICT DO NOTHING;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_event_members_user_id
+    ON public.event_members (user_id);
@@ -0,0 +1,60 @@
+-- Организации (фича organizations): владеют событиями; пользователь состоит в одной или нескольких
+-- организациях с ролью в каждой (owner, admin, member).
+CREATE TABLE IF NOT EXISTS public.organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS public.organization_members (
+    organization_id UUID NOT NULL REFERENCES public.organizations (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id
+    ON public.organization_members (user_id);
+
+-- Организация по умолчанию: ей принадлежат события, заведённые до появления организаций, её события отдаёт /api/events.
+INSERT INTO public.organizations (slug, name)
+VALUES ('default', 'Default')
+ON CONFLICT (slug) DO NOTHING;
+
+-- События и черновики получают организацию. Существующие строки переходят в организацию по умолчанию,
+-- а пользователи с ролями редакторов (admin, organizer, editor) становятся её участниками, чтобы
+-- по-прежнему заводить события. Только при добавлении колонки: повторный прогон ничего не меняет.
+DO $$
+DECLARE
+    default_org UUID;
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'public' AND table_name = 'events' AND column_name = 'organization_id'
+    ) THEN
+        SELECT id INTO default_org FROM public.organizations WHERE slug = 'default';
+
+        ALTER TABLE public.events ADD COLUMN organization_id UUID REFERENCES public.organizations (id);
+        UPDATE public.events SET organization_id = default_org;
+        ALTER TABLE public.events ALTER COLUMN organization_id SET NOT NULL;
+
+        ALTER TABLE public.event_drafts ADD COLUMN organization_id UUID REFERENCES public.organizations (id);
+        UPDATE public.event_drafts SET organization_id = default_org;
+        ALTER TABLE public.event_drafts ALTER COLUMN organization_id SET NOT NULL;
+
+        INSERT INTO public.organization_members (organization_id, user_id, role)
+        SELECT default_org, id, 'member' FROM auth.users
+        WHERE role IN ('admin', 'organizer', 'editor')
+        ON CONFLICT DO NOTHING;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_events_organization_start_date
+    ON public.events (organization_id, start_date DESC);
+
+CREATE INDEX IF NOT EXISTS idx_event_drafts_organization_id
+    ON public.event_drafts (organization_id);
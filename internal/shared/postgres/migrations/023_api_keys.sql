@@ -0,0 +1,20 @@
+-- API-ключи сервисов (фича apikeys): билетная система, табло и т.п. вместо JWT живого редактора.
+-- Ключ действует от имени пользователя user_id (сервисной учётной записи) в пределах scopes.
+-- В БД только префикс (виден в списке ключей) и HMAC секрета; сам ключ показывается один раз при выпуске.
+
+CREATE TABLE IF NOT EXISTS public.api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    prefix TEXT NOT NULL UNIQUE,
+    secret_hash TEXT NOT NULL,
+    name TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    scopes JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_by UUID NULL REFERENCES auth.users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id
+    ON public.api_keys (user_id);
@@ -0,0 +1,26 @@
+-- Вход через OIDC-провайдера (фича auth).
+-- oidc_login_states — незавершённые входы: state (хеш), code_verifier PKCE и nonce; запись одноразовая.
+-- user_identities — учётные записи провайдера (issuer + sub), привязанные к пользователю.
+
+CREATE TABLE IF NOT EXISTS auth.oidc_login_states (
+    state_hash TEXT PRIMARY KEY,
+    code_verifier TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_oidc_login_states_expires_at
+    ON auth.oidc_login_states (expires_at);
+
+CREATE TABLE IF NOT EXISTS auth.user_identities (
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_user_identities_user_id
+    ON auth.user_identities (user_id);
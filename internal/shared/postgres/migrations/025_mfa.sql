@@ -0,0 +1,36 @@
+-- Двухфакторная аутентификация (TOTP) — фича auth.
+-- user_mfa — TOTP-секрет пользователя (зашифрован ключом MFA_SECRET); enabled_at NULL — подключение не подтверждено.
+-- last_used_step — шаг последнего принятого кода: код нельзя предъявить дважды.
+-- mfa_recovery_codes — одноразовые коды восстановления (хранятся только хеши).
+-- mfa_challenges — второй шаг входа: пароль принят, ждём код (purpose = verify) или подключение TOTP (purpose = enroll).
+
+CREATE TABLE IF NOT EXISTS auth.user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES auth.users (id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS auth.mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, code_hash)
+);
+
+CREATE TABLE IF NOT EXISTS auth.mfa_challenges (
+    id UUID PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL CHECK (purpose IN ('verify', 'enroll')),
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_mfa_challenges_expires_at
+    ON auth.mfa_challenges (expires_at);
@@ -0,0 +1,22 @@
+-- Ограничение частоты и блокировки после неудач (internal/shared/ratelimit, RATE_LIMIT_STORE=postgres).
+-- rate_limit_counters — попадания ключа в фиксированное окно [window_start, expires_at).
+-- rate_limit_lockouts — неудачи ключа подряд, число блокировок (для экспоненциального роста) и срок текущей.
+
+CREATE TABLE IF NOT EXISTS public.rate_limit_counters (
+    key TEXT NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    hits INT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_counters_expires_at
+    ON public.rate_limit_counters (expires_at);
+
+CREATE TABLE IF NOT EXISTS public.rate_limit_lockouts (
+    key TEXT PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    lockouts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    last_failure_at TIMESTAMPTZ
+);
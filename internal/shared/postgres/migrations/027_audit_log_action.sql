@@ -0,0 +1,7 @@
+-- Просмотр журнала аудита администратором (GET /api/admin/audit): по действию и просто по времени.
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_action
+    ON public.audit_log (action, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at
+    ON public.audit_log (created_at DESC);
@@ -0,0 +1,9 @@
+-- Запросы с API-ключом (RequireAuthOrAPIKey) пишутся в журнал от имени владельца ключа; api_key_id —
+-- каким ключом выполнено изменение. Без внешнего ключа, как actor_id: запись переживает ключ.
+
+ALTER TABLE public.audit_log
+    ADD COLUMN IF NOT EXISTS api_key_id UUID NULL;
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_api_key
+    ON public.audit_log (api_key_id, created_at DESC)
+    WHERE api_key_id IS NOT NULL;
@@ -0,0 +1,52 @@
+-- Владельцы для данных, заведённых до команд событий (021) и организаций (022). 021 сделал владельцами
+-- только авторов черновиков, а 022 добавил глобальных редакторов в организацию по умолчанию участниками
+-- (member) — у опубликованных событий без черновика команды нет, у организации по умолчанию нет владельца,
+-- и править и снимать с публикации старые события мог только глобальный admin.
+--
+-- Повторный прогон ничего не меняет: владельца организации и последнего владельца события снять нельзя,
+-- поэтому условия «нет владельца» и «нет команды» выполняются только для ещё не засеянных данных.
+
+-- Организация по умолчанию без владельца: владельцами становятся глобальные admin.
+DO $$
+DECLARE
+    default_org UUID;
+BEGIN
+    SELECT id INTO default_org FROM public.organizations WHERE slug = 'default';
+    IF default_org IS NOT NULL AND NOT EXISTS (
+        SELECT 1 FROM public.organization_members
+        WHERE organization_id = default_org AND role = 'owner'
+    ) THEN
+        INSERT INTO public.organization_members (organization_id, user_id, role)
+        SELECT default_org, id, 'owner' FROM auth.users
+        WHERE role = 'admin' AND is_active
+        ON CONFLICT (organization_id, user_id) DO UPDATE SET role = 'owner', updated_at = now();
+    END IF;
+END $$;
+
+-- События без команды: владелец — автор черновика события, иначе автор черновика дня,
+-- иначе тот, кто публиковал событие (журнал аудита). Событие, для которого не нашлось никого,
+-- остаётся владельцам и администраторам своей организации.
+INSERT INTO public.event_members (event_id, user_id, role)
+SELECT DISTINCT e.id, d.created_by, 'owner'
+FROM public.events e
+JOIN public.event_drafts d ON COALESCE(d.event_id, d.id) = e.id
+JOIN auth.users u ON u.id = d.created_by
+WHERE NOT EXISTS (SELECT 1 FROM public.event_members m WHERE m.event_id = e.id)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO public.event_members (event_id, user_id, role)
+SELECT DISTINCT e.id, dd.created_by, 'owner'
+FROM public.events e
+JOIN public.event_days_drafts dd ON dd.event_id = e.id
+JOIN auth.users u ON u.id = dd.created_by
+WHERE NOT EXISTS (SELECT 1 FROM public.event_members m WHERE m.event_id = e.id)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO public.event_members (event_id, user_id, role)
+SELECT DISTINCT e.id, a.actor_id, 'owner'
+FROM public.events e
+JOIN public.audit_log a
+    ON a.action = 'event.published' AND a.target_type = 'event' AND a.target_id = e.id::text
+JOIN auth.users u ON u.id = a.actor_id
+WHERE NOT EXISTS (SELECT 1 FROM public.event_members m WHERE m.event_id = e.id)
+ON CONFLICT DO NOTHING;
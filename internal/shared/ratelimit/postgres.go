@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// PostgresStore — Store в Postgres (public.rate_limit_counters, public.rate_limit_lockouts): счётчики общие
+// для всех реплик. Время берётся из БД, чтобы расхождение часов реплик не влияло на окна.
+// Окно фиксированное (выровнено по window), а не скользящее: на стыке окон возможен всплеск до 2×limit.
+type PostgresStore struct {
+	db    *sql.DB
+	calls atomic.Int64
+}
+
+// NewPostgresStore создаёт хранилище поверх db.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	if err := s.maybeSweep(ctx); err != nil {
+		return false, 0, err
+	}
+	var hits int
+	var retryAfter float64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO public.rate_limit_counters AS c (key, window_start, hits, expires_at)
+		SELECT $1, w.start, 1, w.start + make_interval(secs => $2::float8)
+		FROM (SELECT to_timestamp(floor(extract(epoch FROM now()) / $2::float8) * $2::float8) AS start) w
+		ON CONFLICT (key, window_start) DO UPDATE SET hits = c.hits + 1
+		RETURNING c.hits, extract(epoch FROM c.expires_at - now())::float8
+	`, key, window.Seconds()).Scan(&hits, &retryAfter)
+	if err != nil {
+		return false, 0, err
+	}
+	if hits > limit {
+		return false, time.Duration(retryAfter * float64(time.Second)), nil
+	}
+	return true, 0, nil
+}
+
+func (s *PostgresStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	var seconds float64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT extract(epoch FROM locked_until - now())::float8
+		FROM public.rate_limit_lockouts WHERE key = $1 AND locked_until > now()
+	`, key).Scan(&seconds)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RecordFailure читает состояние ключа под блокировкой строки: параллельные неудачи не теряются
+// и не дают двух блокировок за один порог.
+func (s *PostgresStore) RecordFailure(ctx context.Context, key string, policy LockoutPolicy) (time.Duration, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO public.rate_limit_lockouts (key) VALUES ($1) ON CONFLICT (key) DO NOTHING
+	`, key); err != nil {
+		return 0, err
+	}
+	var st lockState
+	var lockedUntil, lastFailure sql.NullTime
+	var now time.Time
+	if err := tx.QueryRowContext(ctx, `
+		SELECT failures, lockouts, locked_until, last_failure_at, now()
+		FROM public.rate_limit_lockouts WHERE key = $1 FOR UPDATE
+	`, key).Scan(&st.failures, &st.lockouts, &lockedUntil, &lastFailure, &now); err != nil {
+		return 0, err
+	}
+	st.lockedUntil, st.lastFailure = lockedUntil.Time, lastFailure.Time
+
+	st, lock := policy.fail(st, now)
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE public.rate_limit_lockouts
+		SET failures = $2, lockouts = $3, locked_until = $4, last_failure_at = $5
+		WHERE key = $1
+	`, key, st.failures, st.lockouts, nullTime(st.lockedUntil), st.lastFailure); err != nil {
+		return 0, err
+	}
+	return lock, tx.Commit()
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM public.rate_limit_lockouts WHERE key = $1`, key)
+	return err
+}
+
+// maybeSweep раз в sweepEvery вызовов удаляет истёкшие окна и давно неактивные блокировки.
+func (s *PostgresStore) maybeSweep(ctx context.Context) error {
+	if s.calls.Add(1)%sweepEvery != 0 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM public.rate_limit_counters WHERE expires_at < now()`); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM public.rate_limit_lockouts
+		WHERE last_failure_at < now() - interval '7 days' AND (locked_until IS NULL OR locked_until < now())
+	`)
+	return err
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
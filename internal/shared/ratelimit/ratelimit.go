@@ -0,0 +1,72 @@
+// Package ratelimit — ограничение частоты действий по ключу и блокировка после неудач.
+// Limiter — скользящее окно в памяти: при нескольких инстансах лимит действует на каждый инстанс отдельно.
+// Store (MemoryStore, PostgresStore) — то же с блокировками и выбором хранилища для нескольких реплик.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery — раз во сколько вызовов Allow удалять ключи без свежих попаданий (чтобы карта не росла).
+const sweepEvery = 1024
+
+// Limiter разрешает не более limit действий на ключ за окно window.
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+	calls  int
+	now    func() time.Time
+}
+
+// New создаёт лимитер: limit действий за window.
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Allow учитывает действие по ключу. Если лимит исчерпан, действие не учитывается,
+// а retryAfter — через сколько освободится место.
+func (l *Limiter) Allow(key string) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	hits := l.recent(key, now)
+	if len(hits) >= l.limit {
+		l.hits[key] = hits
+		return false, hits[0].Add(l.window).Sub(now)
+	}
+	l.hits[key] = append(hits, now)
+	return true, 0
+}
+
+// recent возвращает попадания ключа внутри окна (по возрастанию времени).
+func (l *Limiter) recent(key string, now time.Time) []time.Time {
+	hits := l.hits[key]
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	for key := range l.hits {
+		if len(l.recent(key, now)) == 0 {
+			delete(l.hits, key)
+		}
+	}
+}
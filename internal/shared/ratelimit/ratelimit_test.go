@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_SlidingWindow(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	l := New(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	ok, _ := l.Allow("user-1")
+	assert.True(t, ok)
+	now = now.Add(20 * time.Second)
+	ok, _ = l.Allow("user-1")
+	assert.True(t, ok)
+
+	ok, retryAfter := l.Allow("user-1")
+	assert.False(t, ok)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	// Другой ключ считается отдельно.
+	ok, _ = l.Allow("user-2")
+	assert.True(t, ok)
+
+	// Первое попадание вышло из окна — место освободилось.
+	now = now.Add(41 * time.Second)
+	ok, _ = l.Allow("user-1")
+	assert.True(t, ok)
+	ok, _ = l.Allow("user-1")
+	assert.False(t, ok)
+}
+
+func TestLimiter_SweepDropsStaleKeys(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	l := New(1, time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.Allow("stale")
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < sweepEvery; i++ {
+		l.Allow("active")
+	}
+	_, ok := l.hits["stale"]
+	assert.False(t, ok)
+}
@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store — счётчики частоты и блокировки ключей, общие для всех потребителей. MemoryStore — для одного
+// инстанса, PostgresStore — для нескольких реплик (лимит общий, а не на каждый инстанс).
+type Store interface {
+	// Allow учитывает действие по ключу: не более limit за window. Если лимит исчерпан — ok == false
+	// и retryAfter — через сколько можно повторить.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (ok bool, retryAfter time.Duration, err error)
+	// LockedFor — сколько ещё действует блокировка ключа; 0 — не заблокирован.
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	// RecordFailure учитывает неудачу (например, неверный пароль). Если она довела число неудач подряд
+	// до порога policy, ключ блокируется и lockedFor — срок блокировки.
+	RecordFailure(ctx context.Context, key string, policy LockoutPolicy) (lockedFor time.Duration, err error)
+	// Reset сбрасывает неудачи и блокировки ключа (например, после успешного входа).
+	Reset(ctx context.Context, key string) error
+}
+
+// LockoutPolicy — блокировка после Threshold неудач подряд. Первая блокировка — BaseLock, каждая следующая
+// вдвое дольше, но не больше MaxLock. Если неудач не было ResetAfter, счёт начинается заново.
+type LockoutPolicy struct {
+	Threshold  int
+	BaseLock   time.Duration
+	MaxLock    time.Duration
+	ResetAfter time.Duration
+}
+
+// lockState — неудачи ключа: failures — подряд с последней блокировки, lockouts — сколько было блокировок.
+type lockState struct {
+	failures    int
+	lockouts    int
+	lockedUntil time.Time
+	lastFailure time.Time
+}
+
+// fail — состояние после ещё одной неудачи в момент now и срок блокировки, если она наступила.
+func (p LockoutPolicy) fail(st lockState, now time.Time) (lockState, time.Duration) {
+	if !st.lastFailure.IsZero() && now.Sub(st.lastFailure) > p.ResetAfter {
+		st = lockState{}
+	}
+	st.failures++
+	st.lastFailure = now
+	if st.failures < p.Threshold {
+		return st, 0
+	}
+	lock := p.BaseLock
+	for i := 0; i < st.lockouts && lock < p.MaxLock; i++ {
+		lock *= 2
+	}
+	lock = min(lock, p.MaxLock)
+	st.failures = 0
+	st.lockouts++
+	st.lockedUntil = now.Add(lock)
+	return st, lock
+}
+
+// MemoryStore — Store в памяти процесса: на каждом инстансе свои счётчики.
+type MemoryStore struct {
+	mu       sync.Mutex
+	limiters map[memoryRule]*Limiter
+	locks    map[string]lockState
+	calls    int
+	now      func() time.Time
+}
+
+type memoryRule struct {
+	limit  int
+	window time.Duration
+}
+
+// NewMemoryStore создаёт пустое хранилище.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		limiters: make(map[memoryRule]*Limiter),
+		locks:    make(map[string]lockState),
+		now:      time.Now,
+	}
+}
+
+// Allow — скользящее окно, как у Limiter (по одному Limiter на пару limit/window).
+func (s *MemoryStore) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	s.mu.Lock()
+	rule := memoryRule{limit: limit, window: window}
+	l, ok := s.limiters[rule]
+	if !ok {
+		l = New(limit, window)
+		l.now = s.now
+		s.limiters[rule] = l
+	}
+	s.mu.Unlock()
+	allowed, retryAfter := l.Allow(key)
+	return allowed, retryAfter, nil
+}
+
+func (s *MemoryStore) LockedFor(_ context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until := s.locks[key].lockedUntil; until.After(s.now()) {
+		return until.Sub(s.now()), nil
+	}
+	return 0, nil
+}
+
+func (s *MemoryStore) RecordFailure(_ context.Context, key string, policy LockoutPolicy) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.calls++
+	if s.calls%sweepEvery == 0 {
+		for k, st := range s.locks {
+			if now.Sub(st.lastFailure) > policy.ResetAfter && !st.lockedUntil.After(now) {
+				delete(s.locks, k)
+			}
+		}
+	}
+	st, lock := policy.fail(s.locks[key], now)
+	s.locks[key] = st
+	return lock, nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.locks, key)
+	return nil
+}
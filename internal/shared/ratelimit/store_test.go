@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = LockoutPolicy{Threshold: 3, BaseLock: time.Minute, MaxLock: 5 * time.Minute, ResetAfter: time.Hour}
+
+func TestLockoutPolicy_ExponentialBackoff(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	var st lockState
+	var locks []time.Duration
+	for range 12 {
+		var lock time.Duration
+		st, lock = testPolicy.fail(st, now)
+		if lock > 0 {
+			locks = append(locks, lock)
+		}
+		now = now.Add(time.Second)
+	}
+	// Каждые 3 неудачи — блокировка: 1, 2, 4 мин, дальше не больше MaxLock.
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute}, locks)
+
+	// Час без неудач — счёт сначала.
+	st, lock := testPolicy.fail(st, now.Add(2*time.Hour))
+	assert.Zero(t, lock)
+	assert.Equal(t, 1, st.failures)
+	assert.Equal(t, 0, st.lockouts)
+}
+
+func TestMemoryStore_AllowAndLockout(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	ok, _, err := s.Allow(ctx, "ip:1", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, retryAfter, _ := s.Allow(ctx, "ip:1", 1, time.Minute)
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, retryAfter)
+	// Другое правило — свой счётчик.
+	ok, _, _ = s.Allow(ctx, "ip:1", 5, time.Hour)
+	assert.True(t, ok)
+
+	for range testPolicy.Threshold - 1 {
+		lock, err := s.RecordFailure(ctx, "account:a", testPolicy)
+		require.NoError(t, err)
+		assert.Zero(t, lock)
+	}
+	lock, _ := s.RecordFailure(ctx, "account:a", testPolicy)
+	assert.Equal(t, time.Minute, lock)
+	lockedFor, _ := s.LockedFor(ctx, "account:a")
+	assert.Equal(t, time.Minute, lockedFor)
+
+	now = now.Add(61 * time.Second)
+	lockedFor, _ = s.LockedFor(ctx, "account:a")
+	assert.Zero(t, lockedFor)
+
+	require.NoError(t, s.Reset(ctx, "account:a"))
+	lock, _ = s.RecordFailure(ctx, "account:a", testPolicy)
+	assert.Zero(t, lock)
+}
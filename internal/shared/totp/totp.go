@@ -0,0 +1,101 @@
+// Package totp — одноразовые коды по времени (RFC 6238, HMAC-SHA1, шаг 30 с, 6 цифр): то, что показывают
+// Google Authenticator, 1Password и т.п. Секрет передаётся приложению в base32 через otpauth://-URI.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Period — шаг времени, с.
+	Period = 30
+	// Digits — длина кода.
+	Digits = 6
+	// Skew — сколько соседних шагов принимается (расхождение часов телефона и сервера).
+	Skew = 1
+	// secretSize — 160 бит, как рекомендует RFC 4226.
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret возвращает новый случайный секрет в base32 без паддинга.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(buf), nil
+}
+
+// Step — номер шага времени для t.
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// Code — код для секрета на шаге step.
+func Code(secret string, step int64) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, step), nil
+}
+
+// Validate проверяет код на момент now с допуском ±Skew шагов и возвращает шаг, которому код соответствует.
+// Повторное использование кода (шаг не новее последнего принятого) отсекает вызывающий.
+func Validate(secret, passcode string, now time.Time) (step int64, ok bool) {
+	passcode = strings.TrimSpace(passcode)
+	if len(passcode) != Digits {
+		return 0, false
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+	current := Step(now)
+	for delta := int64(-Skew); delta <= Skew; delta++ {
+		if subtle.ConstantTimeCompare([]byte(code(key, current+delta)), []byte(passcode)) == 1 {
+			return current + delta, true
+		}
+	}
+	return 0, false
+}
+
+// URI — otpauth://-ссылка для приложения-аутентификатора (её же кодируют в QR).
+// issuer — название сервиса в приложении, account — обычно email.
+func URI(secret, issuer, account string) string {
+	q := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(Digits)},
+		"period":    {fmt.Sprint(Period)},
+	}
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+func code(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1_000_000)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	return encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+}